@@ -24,7 +24,9 @@ func toFiberHandler(handler any) (Handler, bool) {
 		return adaptHTTPHandler(handler)
 	case fasthttp.RequestHandler, func(*fasthttp.RequestCtx) error: // (16)-(17) fasthttp handlers
 		return adaptFastHTTPHandler(handler)
-	default: // (18) unsupported handler type
+	case TypedHandler, *TypedHandler: // (18)-(19) handlers created with Typed
+		return adaptTypedHandler(handler)
+	default: // (20) unsupported handler type
 		return nil, false
 	}
 }
@@ -49,6 +51,23 @@ func adaptFiberHandler(handler any) (Handler, bool) {
 	}
 }
 
+func adaptTypedHandler(handler any) (Handler, bool) {
+	switch h := handler.(type) {
+	case TypedHandler: // (18) typed handler value
+		if h.handler == nil {
+			return nil, false
+		}
+		return h.handler, true
+	case *TypedHandler: // (19) typed handler pointer
+		if h == nil || h.handler == nil {
+			return nil, false
+		}
+		return h.handler, true
+	default:
+		return nil, false
+	}
+}
+
 func adaptExpressHandler(handler any) (Handler, bool) {
 	switch h := handler.(type) {
 	case func(Req, Res) error: // (3) Express-style handler with error return
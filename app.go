@@ -23,6 +23,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -82,6 +83,8 @@ type App struct {
 	pool sync.Pool
 	// Fasthttp server
 	server *fasthttp.Server
+	// net/http server serving the app with ListenConfig.EnableHTTP2
+	httpServer *http.Server
 	// Converts string to a byte slice
 	toBytes func(s string) (b []byte)
 	// Converts byte slice to a string
@@ -90,6 +93,8 @@ type App struct {
 	hooks *Hooks
 	// Latest route & group
 	latestRoute *Route
+	// Routes created by the latest registration, one per method
+	latestRoutes []*Route
 	// newCtxFunc
 	newCtxFunc func(app *App) CustomCtx
 	// TLS handler
@@ -108,13 +113,25 @@ type App struct {
 	sendfiles []*sendFileStore
 	// custom binders
 	customBinders []CustomBinder
-	// Route stack divided by HTTP methods and route prefixes
-	treeStack []map[int][]*Route
+	// codecs registered by media type
+	codecs map[string]registeredCodec
+	// types AutoFormat negotiates, the built-in ones and those of registered codecs
+	autoFormatTypes []string
+	// Route table requests are matched against, replaced as a whole when the routes change
+	routes *atomic.Pointer[routeTable]
+	// routesMutex serializes building and swapping the route table
+	routesMutex sync.Mutex
 	// sendfilesMutex is a mutex used for sendfile operations
 	sendfilesMutex sync.RWMutex
 	mutex          sync.Mutex
+	// Counts the route tree rebuilds, see RoutesGeneration
+	routesGeneration atomic.Uint64
 	// Amount of registered handlers
 	handlersCount uint32
+	// Notifies systemd of the shutdown, set once served with ListenConfig.EnableSystemdNotify
+	systemdNotify atomic.Bool
+	// Problems found by the route analysis during the last startup
+	routeIssues []RouteIssue
 	// contains the information if the route stack has been changed to build the optimized tree
 	hasRoutesRefreshed bool
 	// hasCustomCtx tracks whether app uses a custom context implementation
@@ -197,6 +214,28 @@ type Config struct { //nolint:govet // Aligning the struct fields is not necessa
 	// Default: false
 	DisableHeadAutoRegister bool `json:"disable_head_auto_register"`
 
+	// When set to true, OPTIONS requests to a path that only has routes for
+	// other methods are answered with 204 No Content and an Allow header listing
+	// those methods, instead of 405 Method Not Allowed. Explicit OPTIONS routes
+	// and middleware such as CORS, which answers preflight requests itself, take
+	// precedence.
+	//
+	// Default: false
+	EnableAutoOptions bool `json:"enable_auto_options"`
+
+	// When set to true, startup fails if the route analysis finds shadowed
+	// routes, duplicate patterns or impossible constraints. By default they
+	// are only reported as warnings in the startup message.
+	//
+	// Default: false
+	FailOnRouteIssues bool `json:"fail_on_route_issues"`
+
+	// Versioning configures how the routes registered with Router.Version
+	// select the API version of a request.
+	//
+	// Default: VersioningConfig{Header: "X-API-Version", MediaTypeParam: "version", PathPrefix: "v"}
+	Versioning VersioningConfig `json:"versioning"`
+
 	// When set to true, this relinquishes the 0-allocation promise in certain
 	// cases in order to access the handler values (e.g. request bodies) in an
 	// immutable fashion so that these values are available even if you return
@@ -309,9 +348,17 @@ type Config struct { //nolint:govet // Aligning the struct fields is not necessa
 
 	// ErrorHandler is executed when an error is returned from fiber.Handler.
 	//
-	// Default: DefaultErrorHandler
+	// Default: DefaultErrorHandler, or ProblemErrorHandler if ProblemDetails is enabled
 	ErrorHandler ErrorHandler `json:"-"`
 
+	// ProblemDetails renders errors as problem details documents (RFC 9457)
+	// instead of plain text. It makes ProblemErrorHandler the default
+	// ErrorHandler and switches the default rejections of the built-in
+	// middleware to problem documents.
+	//
+	// Default: false
+	ProblemDetails bool `json:"problem_details"`
+
 	// When set to true, disables keep-alive connections.
 	// The server will close incoming connections after sending the first response to client.
 	//
@@ -359,6 +406,18 @@ type Config struct { //nolint:govet // Aligning the struct fields is not necessa
 	// Default: false
 	StreamRequestBody bool
 
+	// CancelOnDisconnect watches the connection of in-flight requests and
+	// cancels c.Context() with ErrClientDisconnected as its cause when the
+	// client closes it, including while a streamed response is written.
+	// c.Done() and c.Err() follow the same context.
+	//
+	// The connection is watched without reading from it, so this works on
+	// TCP and Unix socket connections on Linux, macOS and the BSDs. Elsewhere,
+	// only failed writes of SendStreamFunc cancel the context.
+	//
+	// Default: false
+	CancelOnDisconnect bool `json:"cancel_on_disconnect"`
+
 	// Will not pre parse Multipart Form data if set to true.
 	//
 	// This option is useful for servers that desire to treat
@@ -671,6 +730,7 @@ func New(config ...Config) *App {
 		toBytes:       utils.UnsafeBytes,
 		toString:      utils.UnsafeString,
 		latestRoute:   &Route{},
+		routes:        &atomic.Pointer[routeTable]{},
 		customBinders: []CustomBinder{},
 		sendfiles:     []*sendFileStore{},
 	}
@@ -735,6 +795,22 @@ func New(config ...Config) *App {
 
 	if app.config.ErrorHandler == nil {
 		app.config.ErrorHandler = DefaultErrorHandler
+		if app.config.ProblemDetails {
+			app.config.ErrorHandler = ProblemErrorHandler
+		}
+	}
+
+	if app.config.Versioning.Header == "" {
+		app.config.Versioning.Header = "X-API-Version"
+	}
+	if app.config.Versioning.MediaTypeParam == "" {
+		app.config.Versioning.MediaTypeParam = "version"
+	}
+	if app.config.Versioning.PathPrefix == "" {
+		app.config.Versioning.PathPrefix = "v"
+	}
+	if app.config.Versioning.Default != "" {
+		app.config.Versioning.Default = normalizeVersion(app.config.Versioning.Default)
 	}
 
 	if app.config.JSONEncoder == nil {
@@ -763,7 +839,9 @@ func New(config ...Config) *App {
 	}
 	app.config.RegexHandler = validateRegexHandler(app.config.RegexHandler)
 
+	app.registerDefaultCodecs()
 	app.sharedState = newSharedState(&app.config)
+	app.sharedState.codec = app.Codec
 	if len(app.config.RequestMethods) == 0 {
 		app.config.RequestMethods = DefaultMethods
 	}
@@ -775,7 +853,6 @@ func New(config ...Config) *App {
 
 	// Create router stack
 	app.stack = make([][]*Route, len(app.config.RequestMethods))
-	app.treeStack = make([]map[int][]*Route, len(app.config.RequestMethods))
 
 	// Override colors
 	app.config.ColorScheme = defaultColors(&app.config.ColorScheme)
@@ -922,10 +999,7 @@ func (app *App) Name(name string) Router {
 
 	for _, routes := range app.stack {
 		for _, route := range routes {
-			isMethodValid := route.Method == app.latestRoute.Method || app.latestRoute.use ||
-				(app.latestRoute.Method == MethodGet && route.Method == MethodHead)
-
-			if route.Path == app.latestRoute.Path && isMethodValid {
+			if app.isLatestRoute(route) {
 				route.Name = name
 				if route.group != nil {
 					route.Name = route.group.name + route.Name
@@ -972,6 +1046,13 @@ func (app *App) GetRoutes(filterUseOption ...bool) []Route {
 	return rs
 }
 
+// RoutesGeneration returns a number that changes every time the route tree is
+// rebuilt, on startup, with RebuildTree or UpdateRoutes. Data derived from the
+// routes can be cached until it changes.
+func (app *App) RoutesGeneration() uint64 {
+	return app.routesGeneration.Load()
+}
+
 // Use registers a middleware route that will match requests
 // with the provided prefix (which is optional and defaults to "/").
 // Also, you can pass another app instance as a sub-router along a routing path.
@@ -1007,6 +1088,8 @@ func (app *App) Use(args ...any) Router {
 			subApp = arg
 		case []string:
 			prefixes = arg
+		case RouteConfig, *RouteConfig:
+			panic(useRouteConfigPanic)
 		default:
 			handler, ok := toFiberHandler(arg)
 			if !ok {
@@ -1093,8 +1176,9 @@ func (app *App) Query(path string, handler any, handlers ...any) Router {
 // Add allows you to specify multiple HTTP methods to register a route.
 // The provided handlers are executed in order, starting with `handler` and then the variadic `handlers`.
 func (app *App) Add(methods []string, path string, handler any, handlers ...any) Router {
-	converted := collectHandlers("add", append([]any{handler}, handlers...)...)
-	app.register(methods, path, nil, converted...)
+	args, opts := routeArgs(append([]any{handler}, handlers...))
+	converted := collectHandlers("add", args...)
+	app.registerRoute(methods, path, nil, opts, converted...)
 
 	return app
 }
@@ -1109,7 +1193,8 @@ func (app *App) All(path string, handler any, handlers ...any) Router {
 //	api := app.Group("/api")
 //	api.Get("/users", handler)
 func (app *App) Group(prefix string, handlers ...any) Router {
-	grp := &Group{Prefix: prefix, app: app}
+	handlers, opts := routeArgs(handlers)
+	grp := &Group{Prefix: prefix, app: app, config: opts.config}
 	if len(handlers) > 0 {
 		converted := collectHandlers("group", handlers...)
 		app.register([]string{methodUse}, prefix, grp, converted...)
@@ -1150,6 +1235,26 @@ func (app *App) Domain(host string) Router {
 	}
 }
 
+// Version creates a new router for the given API version. Every version
+// registers its own routes, a request is served by the latest version of the
+// route that is not newer than the version it asks for, read as configured in
+// Config.Versioning. Requests asking for no version are served by
+// VersioningConfig.Default or by the latest version. The config marks the
+// version deprecated.
+//
+// Like domain routing, the version is checked by wrapping the handlers, so
+// routes registered without a version are not affected.
+//
+//	v1 := app.Version("1", fiber.VersionConfig{Sunset: sunset})
+//	v1.Get("/users", listUsersV1)
+//	v1.Get("/orders", listOrders)
+//
+//	// requests for version 2 use the /orders route of version 1
+//	app.Version("2").Get("/users", listUsersV2)
+func (app *App) Version(version string, config ...VersionConfig) Router {
+	return newVersionRouter(app, nil, version, config)
+}
+
 // RouteChain creates a Registering instance that lets you declare a stack of
 // handlers for the same route. Handlers defined via the returned Register are
 // scoped to the provided path.
@@ -1235,9 +1340,16 @@ func (app *App) Config() Config {
 }
 
 // Handler returns the server handler.
+// When the startup fails, e.g. with ErrRouteIssues, the error is logged and
+// the returned handler answers every request with 500 Internal Server Error.
 func (app *App) Handler() fasthttp.RequestHandler { //revive:disable-line:confusing-naming // Having both a Handler() (uppercase) and a handler() (lowercase) is fine. TODO: Use nolint:revive directive instead. See https://github.com/golangci/golangci-lint/issues/3476
 	// prepare the server for the start
-	app.startupProcess()
+	if err := app.startupProcess(); err != nil {
+		log.Errorf("failed to start the app: %v", err)
+		return func(fctx *fasthttp.RequestCtx) {
+			fctx.Error(utils.StatusMessage(StatusInternalServerError), StatusInternalServerError)
+		}
+	}
 	return app.selectRequestHandler()
 }
 
@@ -1305,8 +1417,18 @@ func (app *App) ShutdownWithContext(ctx context.Context) error {
 
 	// Execute the Shutdown hook
 	app.hooks.executeOnPreShutdownHooks()
+	if app.systemdNotify.Load() {
+		if notifyErr := sdNotify("STOPPING=1"); notifyErr != nil {
+			log.Errorf("%v", notifyErr)
+		}
+	}
 	defer app.hooks.executeOnPostShutdownHooks(err)
 
+	if app.httpServer != nil {
+		err = app.httpServer.Shutdown(ctx)
+		app.httpServer = nil
+		return err
+	}
 	err = app.server.ShutdownWithContext(ctx)
 	return err
 }
@@ -1396,7 +1518,9 @@ func (app *App) Test(req *http.Request, config ...TestConfig) (*http.Response, e
 		return nil, fmt.Errorf("failed to write: %w", err)
 	}
 	// prepare the server for the start
-	app.startupProcess()
+	if err := app.startupProcess(); err != nil {
+		return nil, err
+	}
 
 	// Serve conn to server
 	channel := make(chan error, 1)
@@ -1646,7 +1770,9 @@ func (app *App) serverErrorHandler(fctx *fasthttp.RequestCtx, err error) {
 }
 
 // startupProcess Is the method which executes all the necessary processes just before the start of the server.
-func (app *App) startupProcess() {
+func (app *App) startupProcess() error {
+	app.routesMutex.Lock()
+	defer app.routesMutex.Unlock()
 	app.mutex.Lock()
 	defer app.mutex.Unlock()
 
@@ -1659,8 +1785,16 @@ func (app *App) startupProcess() {
 	}
 	app.mountStartupProcess()
 
+	// analyze the routes only when they changed since the last startup
+	if app.hasRoutesRefreshed {
+		app.routeIssues = app.analyzeRoutes()
+	}
+
 	// build route tree stack
 	app.buildTree()
+	app.raiseBodyLimit()
+
+	return app.routeIssuesError()
 }
 
 // Run onListen hooks. If they return an error, panic.
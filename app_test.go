@@ -249,6 +249,71 @@ func Test_App_QueryMethod_AllowHeader(t *testing.T) {
 	require.Contains(t, resp.Header.Get(HeaderAllow), MethodQuery)
 }
 
+func Test_App_AutoOptions(t *testing.T) {
+	t.Parallel()
+	app := New(Config{EnableAutoOptions: true})
+
+	app.Get("/users", testEmptyHandler)
+	app.Post("/users", testEmptyHandler)
+	app.Options("/custom", func(c Ctx) error {
+		c.Set(HeaderAllow, "CUSTOM")
+		return c.SendStatus(StatusOK)
+	})
+	app.Put("/custom", testEmptyHandler)
+
+	// OPTIONS is answered from the route table, auto HEAD routes included
+	resp, err := app.Test(httptest.NewRequest(MethodOptions, "/users", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusNoContent, resp.StatusCode)
+	require.Equal(t, "GET, HEAD, POST, OPTIONS", resp.Header.Get(HeaderAllow))
+
+	// 405 responses list OPTIONS as allowed
+	resp, err = app.Test(httptest.NewRequest(MethodDelete, "/users", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusMethodNotAllowed, resp.StatusCode)
+	require.Equal(t, "GET, HEAD, POST, OPTIONS", resp.Header.Get(HeaderAllow))
+
+	// explicit OPTIONS handlers take precedence
+	resp, err = app.Test(httptest.NewRequest(MethodOptions, "/custom", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+	require.Equal(t, "CUSTOM", resp.Header.Get(HeaderAllow))
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/custom", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusMethodNotAllowed, resp.StatusCode)
+	require.Equal(t, "PUT, OPTIONS", resp.Header.Get(HeaderAllow))
+
+	// unknown paths are still not found
+	resp, err = app.Test(httptest.NewRequest(MethodOptions, "/unknown", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusNotFound, resp.StatusCode)
+	require.Empty(t, resp.Header.Get(HeaderAllow))
+
+	// disabled by default
+	app = New()
+	app.Get("/users", testEmptyHandler)
+
+	resp, err = app.Test(httptest.NewRequest(MethodOptions, "/users", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusMethodNotAllowed, resp.StatusCode)
+	require.Equal(t, "GET, HEAD", resp.Header.Get(HeaderAllow))
+}
+
+func Test_App_AutoOptions_CustomCtx(t *testing.T) {
+	t.Parallel()
+	app := NewWithCustomCtx(func(app *App) CustomCtx {
+		return &customCtx{DefaultCtx: *NewDefaultCtx(app)}
+	}, Config{EnableAutoOptions: true})
+
+	app.Patch("/items/:id", testEmptyHandler)
+
+	resp, err := app.Test(httptest.NewRequest(MethodOptions, "/items/1", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusNoContent, resp.StatusCode)
+	require.Equal(t, "PATCH, OPTIONS", resp.Header.Get(HeaderAllow))
+}
+
 func Test_App_RegisterNetHTTPHandler(t *testing.T) {
 	t.Parallel()
 
@@ -75,6 +75,14 @@ func (e *BindError) Unwrap() error {
 }
 
 func extractFieldFromError(err error) string {
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		for i := range verrs {
+			if verrs[i].Field != "" {
+				return verrs[i].Field
+			}
+		}
+	}
 	var convErr schema.ConversionError
 	if errors.As(err, &convErr) {
 		return convErr.Key
@@ -348,7 +356,7 @@ func (b *Bind) XML(out any) error {
 func (b *Bind) Form(out any) error {
 	bind := binder.GetFromThePool[*binder.FormBinding](&binder.FormBinderPool)
 	bind.EnableSplitting = b.ctx.App().config.EnableSplittingOnParsers
-	bind.MaxBodySize = b.ctx.App().config.BodyLimit
+	bind.MaxBodySize = requestBodyLimit(b.ctx)
 
 	defer releasePooledBinder(&binder.FormBinderPool, bind)
 
@@ -392,11 +400,14 @@ func (b *Bind) MsgPack(out any) error {
 // Returns *BindError on parse failure (manual mode) or *Error with status 400 (auto-handling mode).
 // It supports decoding the following content types based on the Content-Type header:
 // application/json, application/xml, application/x-www-form-urlencoded, multipart/form-data
-// If none of the content types above are matched, it'll take a look custom binders by checking the MIMETypes() method of custom binder.
-// If there is no custom binder for mime type of body, it will return a ErrUnprocessableEntity error.
+// Custom binders are checked first by their MIMETypes() method, then codecs registered with App.RegisterCodec,
+// which also replace the decoders of the content types above. Types with a structured syntax suffix, e.g.
+// application/vnd.acme+yaml, fall back to the codec of the suffix.
+// If no binder or codec handles the content type, it will return a ErrUnprocessableEntity error.
 func (b *Bind) Body(out any) error {
 	// Get content-type
 	ctype := utils.UnsafeString(utilsbytes.UnsafeToLower(b.ctx.RequestCtx().Request.Header.ContentType()))
+	mediaType := binder.FilterFlags(ctype)
 	ctype = binder.FilterFlags(utils.ParseVendorSpecificContentType(ctype))
 
 	// Check custom binders
@@ -410,6 +421,11 @@ func (b *Bind) Body(out any) error {
 		}
 	}
 
+	// Registered codecs take precedence over the built-in decoders
+	if rc, ok := b.ctx.App().bodyCodec(mediaType); ok && !rc.builtin {
+		return b.codec(rc, out)
+	}
+
 	// Parse body accordingly
 	switch ctype {
 	case MIMEApplicationJSON:
@@ -428,6 +444,21 @@ func (b *Bind) Body(out any) error {
 	return ErrUnprocessableEntity
 }
 
+// codec binds the body with a codec registered by App.RegisterCodec.
+func (b *Bind) codec(rc registeredCodec, out any) error {
+	bind := binder.GetFromThePool[*binder.CodecBinding](&binder.CodecBinderPool)
+	bind.Decoder = rc.codec.Unmarshal
+	bind.Tag = rc.tag
+
+	defer releasePooledBinder(&binder.CodecBinderPool, bind)
+
+	if err := b.returnBindErr(bind.Bind(b.ctx.Body(), out), BindSourceBody); err != nil {
+		return err
+	}
+
+	return b.validateStruct(out)
+}
+
 // All binds values from URI params, the request body, the query string,
 // headers, and cookies into the provided struct in precedence order.
 // Returns *BindError on parse failure (manual mode) or *Error with status 400 (auto-handling mode).
@@ -85,6 +85,12 @@ var MsgPackBinderPool = sync.Pool{
 	},
 }
 
+var CodecBinderPool = sync.Pool{
+	New: func() any {
+		return &CodecBinding{}
+	},
+}
+
 // GetFromThePool retrieves a binder from the provided sync.Pool and panics if
 // the stored value cannot be cast to the requested type.
 func GetFromThePool[T any](pool *sync.Pool) T {
@@ -30,6 +30,7 @@ func Test_GetAndPutToThePool(t *testing.T) {
 	_ = GetFromThePool[*JSONBinding](&JSONBinderPool)
 	_ = GetFromThePool[*CBORBinding](&CBORBinderPool)
 	_ = GetFromThePool[*MsgPackBinding](&MsgPackBinderPool)
+	_ = GetFromThePool[*CodecBinding](&CodecBinderPool)
 }
 
 func Test_Binders_ErrorPaths(t *testing.T) {
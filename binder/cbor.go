@@ -17,8 +17,12 @@ func (*CBORBinding) Name() string {
 }
 
 // Bind parses the request body as CBOR and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *CBORBinding) Bind(body []byte, out any) error {
-	return b.CBORDecoder(body, out)
+	if err := b.CBORDecoder(body, out); err != nil {
+		return bodyValidationErrors("cbor", body, out, err)
+	}
+	return nil
 }
 
 // Reset resets the CBORBinding binder.
@@ -0,0 +1,30 @@
+package binder
+
+// CodecBinding is the binder for request bodies decoded by a codec
+// registered for their media type.
+type CodecBinding struct {
+	// Decoder decodes the request body into the destination.
+	Decoder func(data []byte, v any) error
+	// Tag is the struct tag naming the fields in the paths of ValidationErrors.
+	Tag string
+}
+
+// Name returns the binding name.
+func (*CodecBinding) Name() string {
+	return "codec"
+}
+
+// Bind decodes the request body with the codec and returns the result.
+// Decoding failures are returned as ValidationErrors.
+func (b *CodecBinding) Bind(body []byte, out any) error {
+	if err := b.Decoder(body, out); err != nil {
+		return bodyValidationErrors(b.Tag, body, out, err)
+	}
+	return nil
+}
+
+// Reset resets the CodecBinding binder.
+func (b *CodecBinding) Reset() {
+	b.Decoder = nil
+	b.Tag = ""
+}
@@ -0,0 +1,36 @@
+package binder
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Codec_Binding_Bind(t *testing.T) {
+	t.Parallel()
+
+	b := &CodecBinding{Decoder: json.Unmarshal, Tag: "yaml"}
+	require.Equal(t, "codec", b.Name())
+
+	type User struct {
+		Name string `yaml:"name"`
+		Age  int    `yaml:"age"`
+	}
+	var user User
+	require.NoError(t, b.Bind([]byte(`{"name":"john","age":42}`), &user))
+	require.Equal(t, "john", user.Name)
+	require.Equal(t, 42, user.Age)
+
+	// decoding failures are reported like the other body binders
+	requireValidationErrors(t, b.Bind([]byte(`{"age":`), &user), FieldError{Rule: RuleSyntax})
+
+	b.Decoder = func([]byte, any) error { return errors.New("bad row") }
+	verrs := requireValidationErrors(t, b.Bind([]byte(`name,age`), &user), FieldError{Rule: RuleDecode})
+	require.EqualError(t, verrs, "bad row")
+
+	b.Reset()
+	require.Nil(t, b.Decoder)
+	require.Empty(t, b.Tag)
+}
@@ -16,6 +16,7 @@ func (*CookieBinding) Name() string {
 }
 
 // Bind parses the request cookie and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *CookieBinding) Bind(req *fasthttp.Request, out any) error {
 	data := acquireDataMap()
 	defer releaseDataMap(data)
@@ -39,6 +39,7 @@ func (*FormBinding) Name() string {
 }
 
 // Bind parses the request body and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *FormBinding) Bind(req *fasthttp.Request, out any) error {
 	// Handle multipart form
 	if FilterFlags(utils.UnsafeString(req.Header.ContentType())) == MIMEMultipartForm {
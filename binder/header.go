@@ -16,6 +16,7 @@ func (*HeaderBinding) Name() string {
 }
 
 // Bind parses the request header and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *HeaderBinding) Bind(req *fasthttp.Request, out any) error {
 	data := acquireDataMap()
 	defer releaseDataMap(data)
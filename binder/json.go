@@ -15,8 +15,12 @@ func (*JSONBinding) Name() string {
 }
 
 // Bind parses the request body as JSON and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *JSONBinding) Bind(body []byte, out any) error {
-	return b.JSONDecoder(body, out)
+	if err := b.JSONDecoder(body, out); err != nil {
+		return bodyValidationErrors("json", body, out, err)
+	}
+	return nil
 }
 
 // Reset resets the JSONBinding binder.
@@ -117,7 +117,7 @@ func parseToStruct(aliasTag string, out any, data map[string][]string, files ...
 	// Alias tag is baked in at build time (see decoderBuilder); setting it here
 	// would reset the decoder's type cache on every request.
 	if err := schemaDecoder.Decode(out, data, files...); err != nil {
-		return fmt.Errorf("%w", err)
+		return schemaValidationErrors(aliasTag, out, data, fmt.Errorf("%w", err))
 	}
 
 	return nil
@@ -19,8 +19,12 @@ func (*MsgPackBinding) Name() string {
 }
 
 // Bind parses the request body as MsgPack and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *MsgPackBinding) Bind(body []byte, out any) error {
-	return b.MsgPackDecoder(body, out)
+	if err := b.MsgPackDecoder(body, out); err != nil {
+		return bodyValidationErrors("msgpack", body, out, err)
+	}
+	return nil
 }
 
 // Reset resets the MsgPackBinding binder.
@@ -16,6 +16,7 @@ func (*QueryBinding) Name() string {
 }
 
 // Bind parses the request query and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *QueryBinding) Bind(reqCtx *fasthttp.Request, out any) error {
 	args := reqCtx.URI().QueryArgs()
 	data := acquireDataMap()
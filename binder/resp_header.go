@@ -16,6 +16,7 @@ func (*RespHeaderBinding) Name() string {
 }
 
 // Bind parses the response header and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *RespHeaderBinding) Bind(resp *fasthttp.Response, out any) error {
 	data := acquireDataMap()
 	defer releaseDataMap(data)
@@ -9,6 +9,7 @@ func (*URIBinding) Name() string {
 }
 
 // Bind parses the URI parameters and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *URIBinding) Bind(params []string, paramsFunc func(key string, defaultValue ...string) string, out any) error {
 	data := make(map[string][]string, len(params))
 	for _, param := range params {
@@ -0,0 +1,312 @@
+package binder
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/schema"
+
+	"github.com/gofiber/fiber/v3/internal/redact"
+)
+
+// Rules reported by the binders in FieldError.Rule.
+const (
+	// RuleType reports a value that can't be converted to the type of the field.
+	RuleType = "type"
+	// RuleRequired reports a missing value of a field marked as required.
+	RuleRequired = "required"
+	// RuleUnknown reports a value for which no field exists.
+	RuleUnknown = "unknown"
+	// RuleSyntax reports a malformed body.
+	RuleSyntax = "syntax"
+	// RuleDecode reports any other failure of the decoder.
+	RuleDecode = "decode"
+)
+
+// MessageKeyPrefix is the prefix of FieldError.MessageKey, followed by the rule.
+const MessageKeyPrefix = "validation."
+
+// sensitiveTag is the struct tag marking fields whose rejected values must not
+// be reported, e.g. `form:"password" sensitive:"true"`.
+const sensitiveTag = "sensitive"
+
+// FieldError describes why the value of a single field was rejected.
+type FieldError struct {
+	// Value is the rejected value, redact.Mask for fields tagged
+	// `sensitive:"true"` and nil if it's unknown.
+	Value any `json:"value,omitempty" xml:"value,omitempty" msgpack:"value,omitempty" cbor:"value,omitempty"`
+	// Err is the error of the decoder or validator, if any.
+	Err error `json:"-" xml:"-" msgpack:"-" cbor:"-"`
+	// Field is the path of the field using the names of the binder tags,
+	// e.g. "page_size" or "address.zip_code". It is empty if the failure
+	// isn't related to a field, e.g. for a malformed body.
+	Field string `json:"field" xml:"field,attr" msgpack:"field" cbor:"field"`
+	// Rule is the rule the value failed, e.g. "type" or "required".
+	Rule string `json:"rule" xml:"rule,attr" msgpack:"rule" cbor:"rule"`
+	// Param is the parameter of the rule, e.g. "3" for "min=3".
+	Param string `json:"param,omitempty" xml:"param,attr,omitempty" msgpack:"param,omitempty" cbor:"param,omitempty"`
+	// MessageKey identifies the message to show for the failure, e.g.
+	// "validation.type", for translation by clients.
+	MessageKey string `json:"message_key" xml:"message_key,attr" msgpack:"message_key" cbor:"message_key"`
+}
+
+// NewFieldError creates a FieldError for the field and rule, with the message
+// key derived from the rule.
+func NewFieldError(field, rule string, value any) FieldError {
+	return FieldError{
+		Field:      field,
+		Rule:       rule,
+		Value:      value,
+		MessageKey: MessageKeyPrefix + rule,
+	}
+}
+
+// ValidationErrors lists the fields of a request that failed to decode or
+// validate.
+type ValidationErrors []FieldError
+
+// Error returns the messages of the underlying errors, or the fields and
+// rules that failed if there are none.
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for i := range e {
+		var message string
+		if e[i].Err != nil {
+			message = e[i].Err.Error()
+		} else {
+			message = e[i].Field + ": failed on the " + strconv.Quote(e[i].Rule) + " rule"
+		}
+		if !slices.Contains(messages, message) {
+			messages = append(messages, message)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the distinct underlying errors, so errors.As still finds
+// the errors of the decoders.
+func (e ValidationErrors) Unwrap() []error {
+	var errs []error
+	for i := range e {
+		if e[i].Err != nil && !slices.ContainsFunc(errs, func(err error) bool { return err == e[i].Err }) { //nolint:errorlint // identity, not equivalence
+			errs = append(errs, e[i].Err)
+		}
+	}
+	return errs
+}
+
+// MarshalXML encodes the errors as an <errors> element with an <error>
+// element per field.
+func (e ValidationErrors) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	// the type name is the default of top-level values
+	if start.Name.Local == "" || start.Name.Local == "ValidationErrors" {
+		start.Name.Local = "errors"
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := range e {
+		if err := enc.EncodeElement(e[i], xml.StartElement{Name: xml.Name{Local: "error"}}); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// schemaValidationErrors converts an error of the schema decoder into
+// ValidationErrors, reading the rejected values from data.
+func schemaValidationErrors(aliasTag string, out any, data map[string][]string, err error) error {
+	var multiErr schema.MultiError
+	if !errors.As(err, &multiErr) {
+		fieldErr, ok := schemaFieldError(aliasTag, out, data, err)
+		if !ok {
+			return err
+		}
+		fieldErr.Err = err
+		return ValidationErrors{fieldErr}
+	}
+
+	keys := make([]string, 0, len(multiErr))
+	for key := range multiErr {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	verrs := make(ValidationErrors, 0, len(keys))
+	for _, key := range keys {
+		fieldErr, ok := schemaFieldError(aliasTag, out, data, multiErr[key])
+		if !ok {
+			fieldErr = NewFieldError(key, RuleDecode, nil)
+		}
+		// keep the multi error in the chain, it is what the decoder returned
+		fieldErr.Err = err
+		verrs = append(verrs, fieldErr)
+	}
+	return verrs
+}
+
+func schemaFieldError(aliasTag string, out any, data map[string][]string, err error) (FieldError, bool) {
+	var (
+		convErr    schema.ConversionError
+		emptyField schema.EmptyFieldError
+		unknownKey schema.UnknownKeyError
+	)
+	switch {
+	case errors.As(err, &convErr):
+		return rejectedField(out, aliasTag, convErr.Key, RuleType, dataValue(data, convErr.Key, convErr.Index)), true
+	case errors.As(err, &emptyField):
+		return rejectedField(out, aliasTag, emptyField.Key, RuleRequired, nil), true
+	case errors.As(err, &unknownKey):
+		return rejectedField(out, aliasTag, unknownKey.Key, RuleUnknown, dataValue(data, unknownKey.Key, -1)), true
+	}
+	return FieldError{}, false
+}
+
+// dataValue returns a copy of the value sent for key, the value at index for
+// multi-value fields and the last one otherwise.
+func dataValue(data map[string][]string, key string, index int) any {
+	values := data[key]
+	if len(values) == 0 {
+		return nil
+	}
+	if index < 0 || index >= len(values) {
+		index = len(values) - 1
+	}
+	// values can point into the request, which is reused after the handler
+	return strings.Clone(values[index])
+}
+
+// bodyValidationErrors converts an error of a body decoder into
+// ValidationErrors. Errors of misconfigured decoders or invalid destinations
+// are returned as is.
+func bodyValidationErrors(aliasTag string, body []byte, out any, err error) error {
+	var invalidUnmarshal *json.InvalidUnmarshalError
+	if errors.Is(err, ErrUnimplementedCBOR) || errors.Is(err, ErrMsgPackNotConfigured) || errors.As(err, &invalidUnmarshal) {
+		return err
+	}
+
+	var (
+		fieldErr   FieldError
+		typeErr    *json.UnmarshalTypeError
+		jsonSyntax *json.SyntaxError
+		xmlSyntax  *xml.SyntaxError
+		numErr     *strconv.NumError
+	)
+	switch {
+	case errors.As(err, &typeErr):
+		fieldErr = rejectedField(out, aliasTag, typeErr.Field, RuleType, jsonValue(body, typeErr.Field))
+	case errors.As(err, &jsonSyntax), errors.As(err, &xmlSyntax), errors.Is(err, io.ErrUnexpectedEOF):
+		fieldErr = NewFieldError("", RuleSyntax, nil)
+	case errors.As(err, &numErr):
+		fieldErr = NewFieldError("", RuleType, numErr.Num)
+	default:
+		fieldErr = NewFieldError("", RuleDecode, nil)
+	}
+	fieldErr.Err = err
+	return ValidationErrors{fieldErr}
+}
+
+// jsonValue returns the scalar value at the dotted path in a JSON body, nil
+// if it can't be found.
+func jsonValue(body []byte, path string) any {
+	if path == "" {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+	for segment := range strings.SplitSeq(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[segment]
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	switch value.(type) {
+	case map[string]any, []any:
+		return nil
+	}
+	return value
+}
+
+// rejectedField creates a FieldError for the value rejected at path,
+// redacting it if the field is tagged as sensitive.
+func rejectedField(out any, aliasTag, path, rule string, value any) FieldError {
+	if value != nil && isSensitiveField(reflect.TypeOf(out), aliasTag, path) {
+		value = redact.Mask
+	}
+	return NewFieldError(path, rule, value)
+}
+
+// isSensitiveField reports whether the field at the dotted path of binder
+// tag names, or field names as fallback, is tagged `sensitive:"true"`.
+func isSensitiveField(t reflect.Type, aliasTag, path string) bool {
+	if t == nil || path == "" {
+		return false
+	}
+	var field *reflect.StructField
+	for segment := range strings.SplitSeq(path, ".") {
+		t = unwrapType(t)
+		switch t.Kind() {
+		case reflect.Map:
+			t = t.Elem()
+			continue
+		case reflect.Slice, reflect.Array:
+			if _, err := strconv.Atoi(segment); err == nil {
+				t = t.Elem()
+				continue
+			}
+		default:
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		field = findField(t, aliasTag, segment)
+		if field == nil {
+			return false
+		}
+		t = field.Type
+	}
+	return field != nil && field.Tag.Get(sensitiveTag) == "true"
+}
+
+// findField returns the field of the struct type t named name by the alias
+// tag, or by its Go name if it has none, searching embedded structs too.
+func findField(t reflect.Type, aliasTag, name string) *reflect.StructField {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !isExported(&f) && !f.Anonymous {
+			continue
+		}
+		tagName, _, _ := strings.Cut(f.Tag.Get(aliasTag), ",")
+		if tagName == "" && f.Anonymous {
+			if embedded := unwrapType(f.Type); embedded.Kind() == reflect.Struct {
+				if found := findField(embedded, aliasTag, name); found != nil {
+					return found
+				}
+			}
+			continue
+		}
+		if tagName == "" {
+			tagName = f.Name
+		}
+		if strings.EqualFold(tagName, name) {
+			return &f
+		}
+	}
+	return nil
+}
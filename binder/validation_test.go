@@ -0,0 +1,154 @@
+package binder
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gofiber/schema"
+	"github.com/shamaton/msgpack/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3/internal/redact"
+)
+
+func requireValidationErrors(t *testing.T, err error, expected ...FieldError) ValidationErrors {
+	t.Helper()
+	var verrs ValidationErrors
+	require.ErrorAs(t, err, &verrs)
+	require.Len(t, verrs, len(expected))
+	for i := range expected {
+		require.Equal(t, expected[i].Field, verrs[i].Field)
+		require.Equal(t, expected[i].Rule, verrs[i].Rule)
+		require.Equal(t, MessageKeyPrefix+expected[i].Rule, verrs[i].MessageKey)
+		require.Equal(t, expected[i].Value, verrs[i].Value)
+		require.Error(t, verrs[i].Err)
+	}
+	return verrs
+}
+
+func Test_ValidationErrors_Schema(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		Zip int `query:"zip_code"`
+	}
+	type Query struct {
+		Name     string  `query:"name,required"`
+		Password int     `query:"pin" sensitive:"true"`
+		PageSize int     `query:"page_size"`
+		Address  Address `query:"address"`
+	}
+
+	req := fasthttp.AcquireRequest()
+	t.Cleanup(func() { fasthttp.ReleaseRequest(req) })
+	req.URI().SetQueryString("page_size=ten&pin=secret&address.zip_code=abc")
+
+	err := (&QueryBinding{}).Bind(req, new(Query))
+	verrs := requireValidationErrors(t, err,
+		FieldError{Field: "address.zip_code", Rule: RuleType, Value: "abc"},
+		FieldError{Field: "name", Rule: RuleRequired},
+		FieldError{Field: "page_size", Rule: RuleType, Value: "ten"},
+		FieldError{Field: "pin", Rule: RuleType, Value: redact.Mask},
+	)
+
+	// the errors of the decoder are kept in the chain
+	require.ErrorAs(t, err, &schema.MultiError{})
+	require.Len(t, verrs.Unwrap(), 1)
+	require.Equal(t, verrs.Unwrap()[0].Error(), err.Error())
+
+	// other binders built on the schema decoder use their tag names
+	type Header struct {
+		Limit int `header:"x-limit"`
+	}
+	req.Header.Set("X-Limit", "many")
+	requireValidationErrors(t, (&HeaderBinding{}).Bind(req, new(Header)),
+		FieldError{Field: "X-Limit", Rule: RuleType, Value: "many"},
+	)
+
+	type URI struct {
+		ID int `uri:"id"`
+	}
+	params := func(string, ...string) string { return "me" }
+	requireValidationErrors(t, (&URIBinding{}).Bind([]string{"id"}, params, new(URI)),
+		FieldError{Field: "id", Rule: RuleType, Value: "me"},
+	)
+}
+
+func Test_ValidationErrors_Body(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		Zip int `json:"zip_code" cbor:"zip_code" msgpack:"zip_code"`
+	}
+	type Body struct {
+		Items    []Address `json:"items"`
+		Password int       `json:"password" sensitive:"true"`
+		Age      int       `json:"age" xml:"age" cbor:"age" msgpack:"age"`
+	}
+
+	b := &JSONBinding{JSONDecoder: json.Unmarshal}
+	requireValidationErrors(t, b.Bind([]byte(`{"items":[{"zip_code":"abc"}]}`), new(Body)),
+		FieldError{Field: "items.0.zip_code", Rule: RuleType, Value: "abc"},
+	)
+	requireValidationErrors(t, b.Bind([]byte(`{"password":"hunter2"}`), new(Body)),
+		FieldError{Field: "password", Rule: RuleType, Value: redact.Mask},
+	)
+	requireValidationErrors(t, b.Bind([]byte(`{"age":`), new(Body)),
+		FieldError{Rule: RuleSyntax},
+	)
+	var typeErr *json.UnmarshalTypeError
+	require.ErrorAs(t, b.Bind([]byte(`{"age":"old"}`), new(Body)), &typeErr)
+
+	// programming errors are not reported as rejected fields
+	var verrs ValidationErrors
+	require.False(t, errors.As(b.Bind([]byte(`{}`), Body{}), &verrs))
+
+	x := &XMLBinding{XMLDecoder: xml.Unmarshal}
+	requireValidationErrors(t, x.Bind([]byte(`<Body><age>old</age></Body>`), new(Body)),
+		FieldError{Rule: RuleType, Value: "old"},
+	)
+	requireValidationErrors(t, x.Bind([]byte(`<Body><age>`), new(Body)),
+		FieldError{Rule: RuleSyntax},
+	)
+
+	c := &CBORBinding{CBORDecoder: cbor.Unmarshal}
+	raw, err := cbor.Marshal(map[string]any{"age": "old"})
+	require.NoError(t, err)
+	requireValidationErrors(t, c.Bind(raw, new(Body)), FieldError{Rule: RuleDecode})
+	require.ErrorIs(t, (&CBORBinding{CBORDecoder: UnimplementedCborUnmarshal}).Bind(raw, new(Body)), ErrUnimplementedCBOR)
+	require.False(t, errors.As((&CBORBinding{CBORDecoder: UnimplementedCborUnmarshal}).Bind(raw, new(Body)), &verrs))
+
+	m := &MsgPackBinding{MsgPackDecoder: msgpack.Unmarshal}
+	raw, err = msgpack.Marshal(map[string]any{"age": "old"})
+	require.NoError(t, err)
+	requireValidationErrors(t, m.Bind(raw, new(Body)), FieldError{Rule: RuleDecode})
+}
+
+func Test_ValidationErrors_Format(t *testing.T) {
+	t.Parallel()
+
+	verrs := ValidationErrors{
+		NewFieldError("age", "min", 3),
+		NewFieldError("email", "email", "john"),
+	}
+	require.Equal(t, `age: failed on the "min" rule; email: failed on the "email" rule`, verrs.Error())
+	require.Empty(t, verrs.Unwrap())
+
+	raw, err := json.Marshal(verrs)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"field":"age","rule":"min","message_key":"validation.min","value":3},
+		{"field":"email","rule":"email","message_key":"validation.email","value":"john"}
+	]`, string(raw))
+
+	raw, err = xml.Marshal(verrs)
+	require.NoError(t, err)
+	require.Equal(t, `<errors>`+
+		`<error field="age" rule="min" message_key="validation.min"><value>3</value></error>`+
+		`<error field="email" rule="email" message_key="validation.email"><value>john</value></error>`+
+		`</errors>`, string(raw))
+}
@@ -17,9 +17,10 @@ func (*XMLBinding) Name() string {
 }
 
 // Bind parses the request body as XML and returns the result.
+// Decoding failures are returned as ValidationErrors.
 func (b *XMLBinding) Bind(body []byte, out any) error {
 	if err := b.XMLDecoder(body, out); err != nil {
-		return fmt.Errorf("failed to unmarshal xml: %w", err)
+		return bodyValidationErrors("xml", body, out, fmt.Errorf("failed to unmarshal xml: %w", err))
 	}
 
 	return nil
@@ -17,11 +17,13 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/fxamacker/cbor/v2"
+	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/log"
 
 	"github.com/gofiber/utils/v2"
@@ -55,6 +57,7 @@ type Client struct {
 	xmlUnmarshal  utils.XMLUnmarshal
 	cborMarshal   utils.CBORMarshal
 	cborUnmarshal utils.CBORUnmarshal
+	codecs        map[string]fiber.Codec
 
 	cookieJar            *CookieJar
 	retryConfig          *RetryConfig
@@ -279,6 +282,50 @@ func (c *Client) SetCBORUnmarshal(f utils.CBORUnmarshal) *Client {
 	return c
 }
 
+// RegisterCodec registers the codec encoding request bodies of a media type
+// set with Request.SetEncodedBody, e.g. "application/yaml". Codecs for
+// application/json, application/xml and application/cbor replace the
+// marshal functions of the client for those bodies.
+func (c *Client) RegisterCodec(mediaType string, codec fiber.Codec) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.codecs == nil {
+		c.codecs = make(map[string]fiber.Codec)
+	}
+	c.codecs[normalizeMediaType(mediaType)] = codec
+	return c
+}
+
+// Codec returns the codec used for request bodies of a media type, including
+// the built-in JSON, XML and CBOR ones.
+func (c *Client) Codec(mediaType string) (fiber.Codec, bool) {
+	mediaType = normalizeMediaType(mediaType)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if codec, ok := c.codecs[mediaType]; ok {
+		return codec, true
+	}
+	switch mediaType {
+	case applicationJSON:
+		return fiber.NewCodec(c.jsonMarshal, c.jsonUnmarshal), true
+	case applicationXML:
+		return fiber.NewCodec(c.xmlMarshal, c.xmlUnmarshal), true
+	case applicationCBOR:
+		return fiber.NewCodec(c.cborMarshal, c.cborUnmarshal), true
+	default:
+		return nil, false
+	}
+}
+
+// normalizeMediaType returns the lowercase media type without parameters.
+func normalizeMediaType(mediaType string) string {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return utils.ToLower(utils.TrimSpace(mediaType))
+}
+
 // TLSConfig returns the client's TLS configuration.
 // If none is set, it initializes a new one.
 func (c *Client) TLSConfig() *tls.Config {
@@ -967,7 +1014,7 @@ func newClient(transport httpClientTransport) *Client {
 		path:    &PathParam{},
 
 		userRequestHooks:     []RequestHook{},
-		builtinRequestHooks:  []RequestHook{parserRequestURL, parserRequestHeader, parserRequestBody},
+		builtinRequestHooks:  []RequestHook{parserRequestURL, parserRequestHeader, parserRequestBody, parserRequestDigest},
 		userResponseHooks:    []ResponseHook{},
 		builtinResponseHooks: []ResponseHook{parserResponseCookie, logger},
 		jsonMarshal:          json.Marshal,
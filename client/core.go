@@ -134,6 +134,11 @@ func (c *core) execFunc() (*Response, error) {
 			case <-errChan:
 			}
 		}()
+		// keep the cause, e.g. fiber.ErrClientDisconnected when ctx is the
+		// context of a server request
+		if cause := context.Cause(c.ctx); cause != nil && !errors.Is(cause, c.ctx.Err()) {
+			return nil, fmt.Errorf("%w: %w", ErrTimeoutOrCancel, cause)
+		}
 		return nil, ErrTimeoutOrCancel
 	}
 }
@@ -297,4 +302,5 @@ var (
 	ErrBodyType             = errors.New("the body type should be []byte")
 	ErrNotSupportSaveMethod = errors.New("only file paths and io.Writer are supported")
 	ErrBodyTypeNotSupported = errors.New("the body type is not supported")
+	ErrNoDigest             = errors.New("the response has no digest with a supported algorithm")
 )
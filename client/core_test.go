@@ -116,6 +116,27 @@ func Test_Exec_Func(t *testing.T) {
 		require.Equal(t, ErrTimeoutOrCancel, err)
 	})
 
+	t.Run("the request is canceled with a cause", func(t *testing.T) {
+		t.Parallel()
+		core, client, req := newCore(), New(), AcquireRequest()
+		ctx, cancel := context.WithCancelCause(context.Background())
+		time.AfterFunc(100*time.Millisecond, func() {
+			cancel(fiber.ErrClientDisconnected)
+		})
+
+		core.ctx = ctx
+		core.client = client
+		core.req = req
+
+		client.SetDial(func(_ string) (net.Conn, error) { return ln.Dial() })
+		req.RawRequest.SetRequestURI("http://example.com/hang-up")
+
+		_, err := core.execFunc()
+
+		require.ErrorIs(t, err, ErrTimeoutOrCancel)
+		require.ErrorIs(t, err, fiber.ErrClientDisconnected)
+	})
+
 	t.Run("cancel drains errChan", func(t *testing.T) {
 		core, client, req := newCore(), New(), AcquireRequest()
 		ctx, cancel := context.WithCancel(context.Background())
@@ -14,6 +14,9 @@ import (
 
 	"github.com/gofiber/utils/v2"
 	"github.com/valyala/fasthttp"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/httpdigest"
 )
 
 var protocolCheck = regexp.MustCompile(`^https?://.*$`)
@@ -142,6 +145,8 @@ func parserRequestHeader(c *Client, req *Request) error {
 		req.RawRequest.Header.SetContentType(applicationXML)
 	case cborBody:
 		req.RawRequest.Header.SetContentType(applicationCBOR)
+	case codecBody:
+		req.RawRequest.Header.SetContentType(req.mediaType)
 	case formBody:
 		req.RawRequest.Header.SetContentType(applicationForm)
 	case filesBody:
@@ -213,6 +218,16 @@ func parserRequestBody(c *Client, req *Request) error {
 			return err
 		}
 		req.RawRequest.SetBody(body)
+	case codecBody:
+		codec, ok := c.Codec(req.mediaType)
+		if !ok {
+			return fmt.Errorf("%w %q", fiber.ErrCodecNotRegistered, req.mediaType)
+		}
+		body, err := codec.Marshal(req.body)
+		if err != nil {
+			return err
+		}
+		req.RawRequest.SetBody(body)
 	case formBody:
 		req.RawRequest.SetBody(req.formData.QueryString())
 	case filesBody:
@@ -232,6 +247,20 @@ func parserRequestBody(c *Client, req *Request) error {
 	return nil
 }
 
+// parserRequestDigest sets the Content-Digest of the serialized request body,
+// see Request.SetContentDigest.
+func parserRequestDigest(_ *Client, req *Request) error {
+	if len(req.digestAlgorithms) == 0 || req.RawRequest.IsBodyStream() {
+		return nil
+	}
+	value, err := httpdigest.Compute(req.RawRequest.Body(), req.digestAlgorithms...)
+	if err != nil {
+		return fmt.Errorf("content digest: %w", err)
+	}
+	req.RawRequest.Header.Set(fiber.HeaderContentDigest, value)
+	return nil
+}
+
 // parserRequestBodyFile handles the case where the request contains files to be uploaded.
 func parserRequestBodyFile(req *Request) error {
 	mw := multipart.NewWriter(req.RawRequest.BodyWriter())
@@ -526,6 +526,33 @@ func Test_Parser_Request_Body(t *testing.T) {
 		require.Equal(t, encoded, req.RawRequest.Body())
 	})
 
+	t.Run("codec body", func(t *testing.T) {
+		t.Parallel()
+		client := New().RegisterCodec("Text/CSV; charset=utf-8", fiber.NewCodec(
+			func(v any) ([]byte, error) {
+				return []byte(strings.Join(v.([]string), ",")), nil //nolint:forcetypeassert,errcheck // test codec
+			},
+			func([]byte, any) error { return nil },
+		))
+
+		req := AcquireRequest().SetEncodedBody("text/csv", []string{"john", "doe"})
+		require.NoError(t, parserRequestHeader(client, req))
+		require.NoError(t, parserRequestBody(client, req))
+		require.Equal(t, "text/csv", string(req.RawRequest.Header.ContentType()))
+		require.Equal(t, "john,doe", string(req.RawRequest.Body()))
+
+		// the built-in codecs use the marshal functions of the client
+		req = AcquireRequest().SetEncodedBody("application/json", map[string]string{"name": "john"})
+		require.NoError(t, parserRequestBody(client, req))
+		require.JSONEq(t, `{"name":"john"}`, string(req.RawRequest.Body()))
+
+		req = AcquireRequest().SetEncodedBody("application/yaml", map[string]string{"name": "john"})
+		require.ErrorIs(t, parserRequestBody(client, req), fiber.ErrCodecNotRegistered)
+
+		req.Reset()
+		require.Empty(t, req.mediaType)
+	})
+
 	t.Run("form data body", func(t *testing.T) {
 		t.Parallel()
 		client := New()
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/httpdigest"
 	"github.com/gofiber/utils/v2"
 	"github.com/valyala/fasthttp"
 )
@@ -38,6 +39,7 @@ const (
 	filesBody
 	rawBody
 	cborBody
+	codecBody
 )
 
 var ErrClientNil = errors.New("client cannot be nil")
@@ -62,8 +64,11 @@ type Request struct {
 	userAgent  string
 	boundary   string
 	referer    string
+	mediaType  string
 	files      []*File
 
+	digestAlgorithms []string
+
 	timeout      time.Duration
 	maxRedirects int
 
@@ -426,6 +431,16 @@ func (r *Request) SetCBOR(v any) *Request {
 	return r
 }
 
+// SetEncodedBody sets the request body to a value encoded with the codec the
+// client registered for mediaType, see Client.RegisterCodec. The media type
+// is sent as the Content-Type.
+func (r *Request) SetEncodedBody(mediaType string, v any) *Request {
+	r.body = v
+	r.bodyType = codecBody
+	r.mediaType = mediaType
+	return r
+}
+
 // SetRawBody sets the request body to raw bytes.
 func (r *Request) SetRawBody(v []byte) *Request {
 	r.body = v
@@ -622,6 +637,23 @@ func (r *Request) SetDisablePathNormalizing(disable bool) *Request {
 	return r
 }
 
+// ContentDigest returns the algorithms of the Content-Digest set with
+// SetContentDigest.
+func (r *Request) ContentDigest() []string {
+	return r.digestAlgorithms
+}
+
+// SetContentDigest sends a Content-Digest header (RFC 9530) with the digests
+// of the request body for the given algorithms, digest.SHA256 if none are
+// given. Streamed request bodies aren't digested.
+func (r *Request) SetContentDigest(algorithms ...string) *Request {
+	if len(algorithms) == 0 {
+		algorithms = []string{httpdigest.SHA256}
+	}
+	r.digestAlgorithms = algorithms
+	return r
+}
+
 // checkClient ensures that a Client is set. If none is set, it defaults to the package default client via C().
 func (r *Request) checkClient() {
 	if r.client == nil {
@@ -692,6 +724,8 @@ func (r *Request) Reset() {
 	r.timeout = 0
 	r.maxRedirects = 0
 	r.bodyType = noBody
+	r.mediaType = ""
+	r.digestAlgorithms = nil
 	r.boundary = boundary
 	r.isPathNormalizingDisabled = false
 
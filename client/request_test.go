@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/digest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
@@ -1999,3 +2000,41 @@ func Benchmark_SetValWithStruct(b *testing.B) {
 		require.Equal(b, 0, p.Len())
 	})
 }
+
+func Test_Request_ContentDigest_With_Server(t *testing.T) {
+	t.Parallel()
+
+	app, ln, start := createHelperServer(t)
+	app.Use(digest.New(digest.Config{RequireDigest: true}))
+	app.Post("/", func(c fiber.Ctx) error {
+		return c.Send(c.Body())
+	})
+	go start()
+
+	client := New().SetDial(ln)
+
+	req := AcquireRequest().SetClient(client).SetJSON(map[string]int{"amount": 100})
+	require.Equal(t, []string{digest.SHA256}, req.SetContentDigest().ContentDigest())
+	resp, err := req.Post("http://example.com")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+	require.Equal(t, `{"amount":100}`, resp.String())
+	require.NoError(t, digest.Verify(string(req.RawRequest.Header.Peek(fiber.HeaderContentDigest)), resp.Body(), digest.SHA256))
+	require.NoError(t, resp.VerifyDigest())
+
+	resp, err = AcquireRequest().SetClient(client).SetRawBody([]byte("body")).SetContentDigest(digest.SHA512).Post("http://example.com")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode())
+
+	resp, err = AcquireRequest().SetClient(client).SetRawBody([]byte("body")).Post("http://example.com")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode())
+	require.Equal(t, "sha-256=10, sha-512=9", resp.Header(fiber.HeaderWantContentDigest))
+
+	_, err = AcquireRequest().SetClient(client).SetRawBody([]byte("body")).SetContentDigest("md5").Post("http://example.com")
+	require.ErrorIs(t, err, digest.ErrUnsupported)
+
+	req = AcquireRequest().SetContentDigest()
+	req.Reset()
+	require.Empty(t, req.ContentDigest())
+}
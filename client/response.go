@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/httpdigest"
 	"github.com/gofiber/utils/v2"
 	"github.com/valyala/fasthttp"
 )
@@ -105,6 +107,38 @@ func (r *Response) IsStreaming() bool {
 	return r.RawResponse.BodyStream() != nil
 }
 
+// VerifyDigest checks the Content-Digest of the response (RFC 9530) against
+// its body and its Repr-Digest against its decoded body, including digests
+// sent as trailers. Only the digests with the given algorithms, all
+// supported ones if none are given, are checked. It returns ErrNoDigest if
+// the response has no digest to check, or an error wrapping the one of
+// digest.Verify.
+func (r *Response) VerifyDigest(algorithms ...string) error {
+	verified := false
+	if field := r.Header(fiber.HeaderContentDigest); field != "" {
+		err := httpdigest.Verify(field, r.Body(), algorithms...)
+		if err != nil && !errors.Is(err, httpdigest.ErrUnsupported) {
+			return fmt.Errorf("%s: %w", fiber.HeaderContentDigest, err)
+		}
+		verified = err == nil
+	}
+	if field := r.Header(fiber.HeaderReprDigest); field != "" {
+		body, err := r.RawResponse.BodyUncompressed()
+		if err != nil {
+			return fmt.Errorf("%s: %w", fiber.HeaderReprDigest, err)
+		}
+		err = httpdigest.Verify(field, body, algorithms...)
+		if err != nil && !errors.Is(err, httpdigest.ErrUnsupported) {
+			return fmt.Errorf("%s: %w", fiber.HeaderReprDigest, err)
+		}
+		verified = verified || err == nil
+	}
+	if !verified {
+		return ErrNoDigest
+	}
+	return nil
+}
+
 // String returns the response body as a trimmed string.
 func (r *Response) String() string {
 	return utils.TrimSpace(string(r.Body()))
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/tls"
 	"encoding/xml"
@@ -17,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/digest"
 )
 
 func Test_Response_Status(t *testing.T) {
@@ -954,3 +956,46 @@ func (m *mockWriteCloser) Close() error {
 	m.closed = true
 	return nil
 }
+
+func Test_Response_VerifyDigest(t *testing.T) {
+	t.Parallel()
+
+	server := startTestServer(t, func(app *fiber.App) {
+		app.Use(digest.New())
+		app.Get("/", func(c fiber.Ctx) error {
+			return c.SendString("hello world")
+		})
+		app.Get("/stream", func(c fiber.Ctx) error {
+			return c.SendStreamWriter(func(w *bufio.Writer) {
+				w.WriteString("hello world") //nolint:errcheck // test stream
+			})
+		})
+		app.Get("/tampered", func(c fiber.Ctx) error {
+			c.Set(fiber.HeaderContentDigest, "sha-256=:AAAA:")
+			return c.SendString("hello world")
+		})
+		app.Get("/none", func(c fiber.Ctx) error {
+			c.Set(fiber.HeaderContentDigest, "md5=:AAAA:")
+			return c.SendString("hello world")
+		})
+	})
+	defer server.stop()
+
+	client := New().SetDial(server.dial())
+	verify := func(target string, algorithms ...string) error {
+		t.Helper()
+		resp, err := AcquireRequest().SetClient(client).Get("http://example.com" + target)
+		require.NoError(t, err)
+		defer resp.Close()
+		require.Equal(t, "hello world", resp.String())
+		return resp.VerifyDigest(algorithms...)
+	}
+
+	require.NoError(t, verify("/"))
+	require.NoError(t, verify("/", digest.SHA256))
+	require.ErrorIs(t, verify("/", digest.SHA512), ErrNoDigest)
+	// digests sent as trailers
+	require.NoError(t, verify("/stream"))
+	require.ErrorIs(t, verify("/tampered"), digest.ErrMismatch)
+	require.ErrorIs(t, verify("/none"), ErrNoDigest)
+}
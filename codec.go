@@ -0,0 +1,160 @@
+package fiber
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/utils/v2"
+)
+
+// ErrCodecNotRegistered is returned when no codec is registered for a media type.
+var ErrCodecNotRegistered = errors.New("fiber: no codec registered for media type")
+
+// Codec encodes and decodes values of a media type. Codecs registered with
+// App.RegisterCodec are used by Bind().Body, AutoFormat and SharedState.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// NewCodec returns a Codec using the given functions, e.g.
+//
+//	app.RegisterCodec("application/yaml", fiber.NewCodec(yaml.Marshal, yaml.Unmarshal))
+func NewCodec(marshal func(v any) ([]byte, error), unmarshal func(data []byte, v any) error) Codec {
+	return &funcCodec{marshal: marshal, unmarshal: unmarshal}
+}
+
+type funcCodec struct {
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+func (c *funcCodec) Marshal(v any) ([]byte, error) {
+	return c.marshal(v)
+}
+
+func (c *funcCodec) Unmarshal(data []byte, v any) error {
+	return c.unmarshal(data, v)
+}
+
+// registeredCodec is a codec of the registry with the struct tag naming the
+// fields it decodes, used in the paths of ValidationErrors.
+type registeredCodec struct {
+	codec   Codec
+	tag     string
+	builtin bool
+}
+
+// autoFormatTypes are the types AutoFormat offers before the registered codecs.
+var autoFormatTypes = []string{"html", "json", "txt", "xml", "msgpack", "cbor"}
+
+// builtinCodecTypes are the media types of the default codecs.
+var builtinCodecTypes = []string{
+	MIMEApplicationJSON, MIMEApplicationXML, MIMETextXML, MIMEApplicationMsgPack, MIMEApplicationCBOR,
+}
+
+// registerDefaultCodecs registers the codecs of the Config encoders and
+// decoders. They read the config on every use, so they follow its changes.
+func (app *App) registerDefaultCodecs() {
+	app.codecs = make(map[string]registeredCodec, len(builtinCodecTypes))
+	app.autoFormatTypes = autoFormatTypes
+
+	jsonCodec := NewCodec(
+		func(v any) ([]byte, error) { return app.config.JSONEncoder(v) },
+		func(data []byte, v any) error { return app.config.JSONDecoder(data, v) },
+	)
+	xmlCodec := NewCodec(
+		func(v any) ([]byte, error) { return app.config.XMLEncoder(v) },
+		func(data []byte, v any) error { return app.config.XMLDecoder(data, v) },
+	)
+	msgPackCodec := NewCodec(
+		func(v any) ([]byte, error) { return app.config.MsgPackEncoder(v) },
+		func(data []byte, v any) error { return app.config.MsgPackDecoder(data, v) },
+	)
+	cborCodec := NewCodec(
+		func(v any) ([]byte, error) { return app.config.CBOREncoder(v) },
+		func(data []byte, v any) error { return app.config.CBORDecoder(data, v) },
+	)
+
+	app.codecs[MIMEApplicationJSON] = registeredCodec{codec: jsonCodec, tag: "json", builtin: true}
+	app.codecs[MIMEApplicationXML] = registeredCodec{codec: xmlCodec, tag: "xml", builtin: true}
+	app.codecs[MIMETextXML] = registeredCodec{codec: xmlCodec, tag: "xml", builtin: true}
+	app.codecs[MIMEApplicationMsgPack] = registeredCodec{codec: msgPackCodec, tag: "msgpack", builtin: true}
+	app.codecs[MIMEApplicationCBOR] = registeredCodec{codec: cborCodec, tag: "cbor", builtin: true}
+}
+
+// RegisterCodec registers the codec for a media type, e.g. "application/yaml",
+// replacing the codec registered for it before. Registered codecs decode
+// request bodies of their type in Bind().Body, are offered by AutoFormat
+// after the built-in types, and can be used with SharedState.SetEncoded.
+// Codecs for the built-in types replace the Config encoders and decoders in
+// those places. Codecs must be registered before the app starts serving.
+//
+//	app.RegisterCodec("application/yaml", fiber.NewCodec(yaml.Marshal, yaml.Unmarshal))
+func (app *App) RegisterCodec(mediaType string, codec Codec) {
+	mediaType = normalizeMediaType(mediaType)
+	if mediaType == "" || !strings.Contains(mediaType, "/") {
+		panic(fmt.Sprintf("fiber: invalid codec media type %q", mediaType))
+	}
+	if codec == nil {
+		panic(fmt.Sprintf("fiber: codec for %q is nil", mediaType))
+	}
+
+	if _, ok := app.codecs[mediaType]; !ok && !slices.Contains(builtinCodecTypes, mediaType) {
+		app.autoFormatTypes = append(app.autoFormatTypes[:len(app.autoFormatTypes):len(app.autoFormatTypes)], mediaType)
+	}
+	app.codecs[mediaType] = registeredCodec{codec: codec, tag: codecTag(mediaType)}
+}
+
+// Codec returns the codec registered for a media type. Parameters such as
+// the charset are ignored.
+func (app *App) Codec(mediaType string) (Codec, bool) {
+	rc, ok := app.codecs[normalizeMediaType(mediaType)]
+	return rc.codec, ok
+}
+
+// bodyCodec returns the codec registered for the media type of a request
+// body, or for its structured syntax suffix, e.g. the codec of
+// "application/yaml" or "application/x-yaml" for "application/vnd.acme+yaml".
+func (app *App) bodyCodec(mediaType string) (registeredCodec, bool) {
+	if rc, ok := app.codecs[mediaType]; ok {
+		return rc, true
+	}
+
+	mainType, subtype, _ := strings.Cut(mediaType, "/")
+	i := strings.LastIndexByte(subtype, '+')
+	if i < 0 {
+		return registeredCodec{}, false
+	}
+	suffix := subtype[i+1:]
+	if rc, ok := app.codecs[mainType+"/"+suffix]; ok {
+		return rc, true
+	}
+	// the other codecs in registration order, so the pick doesn't vary
+	for _, t := range app.autoFormatTypes {
+		if rc, ok := app.codecs[t]; ok && rc.tag == suffix {
+			return rc, true
+		}
+	}
+	return registeredCodec{}, false
+}
+
+// normalizeMediaType returns the lowercase media type without parameters.
+func normalizeMediaType(mediaType string) string {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return utils.ToLower(utils.TrimSpace(mediaType))
+}
+
+// codecTag returns the struct tag likely naming the fields for a media type:
+// the structured syntax suffix or the subtype without "x-", e.g. "yaml" for
+// "application/yaml" and "application/vnd.api+yaml", "protobuf" for
+// "application/x-protobuf".
+func codecTag(mediaType string) string {
+	_, subtype, _ := strings.Cut(mediaType, "/")
+	if i := strings.LastIndexByte(subtype, '+'); i >= 0 {
+		return subtype[i+1:]
+	}
+	return strings.TrimPrefix(subtype, "x-")
+}
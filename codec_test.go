@@ -0,0 +1,226 @@
+package fiber
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// lineCodec encodes map[string]string values as "key=value" lines.
+var lineCodec = NewCodec(
+	func(v any) ([]byte, error) {
+		m, ok := v.(map[string]string)
+		if !ok {
+			return nil, errors.New("line codec: unsupported value")
+		}
+		var b strings.Builder
+		for _, k := range []string{"name", "role"} {
+			if val, ok := m[k]; ok {
+				b.WriteString(k + "=" + val + "\n")
+			}
+		}
+		return []byte(b.String()), nil
+	},
+	func(data []byte, v any) error {
+		m, ok := v.(*map[string]string)
+		if !ok {
+			return errors.New("line codec: unsupported destination")
+		}
+		*m = map[string]string{}
+		for line := range strings.SplitSeq(strings.TrimSpace(string(data)), "\n") {
+			k, val, found := strings.Cut(line, "=")
+			if !found {
+				return errors.New("line codec: missing =")
+			}
+			(*m)[k] = val
+		}
+		return nil
+	},
+)
+
+// go test -run Test_App_RegisterCodec
+func Test_App_RegisterCodec(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	for _, mediaType := range []string{MIMEApplicationJSON, MIMEApplicationXML, MIMETextXML, MIMEApplicationMsgPack, MIMEApplicationCBOR} {
+		_, ok := app.Codec(mediaType)
+		require.True(t, ok, mediaType)
+	}
+	_, ok := app.Codec("text/csv")
+	require.False(t, ok)
+
+	// the default codecs use the config encoders
+	codec, ok := app.Codec(MIMEApplicationJSONCharsetUTF8)
+	require.True(t, ok)
+	raw, err := codec.Marshal(Map{"name": "john"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"john"}`, string(raw))
+
+	app.RegisterCodec(" Text/Plain+Lines ; charset=utf-8", lineCodec)
+	codec, ok = app.Codec("text/plain+lines")
+	require.True(t, ok)
+	require.Equal(t, lineCodec, codec)
+	require.Equal(t, "lines", app.codecs["text/plain+lines"].tag)
+	require.Equal(t, append(autoFormatTypes[:len(autoFormatTypes):len(autoFormatTypes)], "text/plain+lines"), app.autoFormatTypes)
+
+	// replacing codecs doesn't offer their type twice
+	app.RegisterCodec("text/plain+lines", lineCodec)
+	app.RegisterCodec(MIMEApplicationJSON, lineCodec)
+	require.Len(t, app.autoFormatTypes, len(autoFormatTypes)+1)
+	require.Len(t, autoFormatTypes, 6)
+
+	require.Panics(t, func() { app.RegisterCodec("", lineCodec) })
+	require.Panics(t, func() { app.RegisterCodec("csv", lineCodec) })
+	require.Panics(t, func() { app.RegisterCodec("text/csv", nil) })
+
+	require.Equal(t, "protobuf", codecTag("application/x-protobuf"))
+	require.Equal(t, "yaml", codecTag("application/vnd.api+yaml"))
+	require.Equal(t, "csv", codecTag("text/csv"))
+}
+
+// go test -run Test_Bind_Body_Codec
+func Test_Bind_Body_Codec(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.RegisterCodec("text/x-lines", lineCodec)
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	t.Cleanup(func() { app.ReleaseCtx(c) })
+
+	c.Request().Header.SetContentType("text/x-lines; charset=utf-8")
+	c.Request().SetBody([]byte("name=john\nrole=admin"))
+	var out map[string]string
+	require.NoError(t, c.Bind().Body(&out))
+	require.Equal(t, map[string]string{"name": "john", "role": "admin"}, out)
+
+	// decoding failures are bind errors listing the rejected fields
+	c.Request().SetBody([]byte("john"))
+	err := c.Bind().Body(&out)
+	var bindErr *BindError
+	require.ErrorAs(t, err, &bindErr)
+	require.Equal(t, BindSourceBody, bindErr.Source)
+	var verrs ValidationErrors
+	require.ErrorAs(t, err, &verrs)
+	require.Equal(t, "decode", verrs[0].Rule)
+
+	// codecs replace the decoders of built-in types
+	c.Request().Header.SetContentType(MIMEApplicationJSON)
+	c.Request().SetBody([]byte("name=jane"))
+	require.Error(t, c.Bind().Body(&out))
+	app.RegisterCodec(MIMEApplicationJSON, lineCodec)
+	require.NoError(t, c.Bind().Body(&out))
+	require.Equal(t, map[string]string{"name": "jane"}, out)
+}
+
+// go test -run Test_Bind_Body_Codec_Suffix
+func Test_Bind_Body_Codec_Suffix(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.RegisterCodec("text/x-lines", lineCodec)
+	app.RegisterCodec("text/plain+rows", lineCodec)
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	t.Cleanup(func() { app.ReleaseCtx(c) })
+
+	for _, ctype := range []string{
+		// the registered type, with its suffix
+		"text/plain+rows",
+		// the suffix of a vendor type names the codec of another type
+		"text/vnd.acme+lines; charset=utf-8",
+		"application/vnd.acme+lines",
+	} {
+		c.Request().Header.SetContentType(ctype)
+		c.Request().SetBody([]byte("name=john"))
+		var out map[string]string
+		require.NoError(t, c.Bind().Body(&out), ctype)
+		require.Equal(t, map[string]string{"name": "john"}, out, ctype)
+	}
+
+	// built-in types keep their decoders
+	c.Request().Header.SetContentType("application/vnd.acme+json")
+	c.Request().SetBody([]byte(`{"name":"jane"}`))
+	var out map[string]string
+	require.NoError(t, c.Bind().Body(&out))
+	require.Equal(t, map[string]string{"name": "jane"}, out)
+
+	c.Request().Header.SetContentType("application/vnd.acme+csv")
+	require.ErrorIs(t, c.Bind().Body(&out), ErrUnprocessableEntity)
+}
+
+// go test -run Test_Ctx_AutoFormat_Codec
+func Test_Ctx_AutoFormat_Codec(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.RegisterCodec("text/x-lines", lineCodec)
+	app.Get("/", func(c Ctx) error {
+		return c.AutoFormat(map[string]string{"name": "john"})
+	})
+
+	req := httptest.NewRequest(MethodGet, "/", nil)
+	req.Header.Set(HeaderAccept, "text/x-lines")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, "text/x-lines", resp.Header.Get(HeaderContentType))
+
+	// the built-in types keep precedence for wildcards
+	req = httptest.NewRequest(MethodGet, "/", nil)
+	req.Header.Set(HeaderAccept, "*/*")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, MIMETextHTMLCharsetUTF8, resp.Header.Get(HeaderContentType))
+
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	t.Cleanup(func() { app.ReleaseCtx(c) })
+
+	c.Request().Header.Set(HeaderAccept, "text/x-lines")
+	require.NoError(t, c.AutoFormat(map[string]string{"name": "john", "role": "admin"}))
+	require.Equal(t, "name=john\nrole=admin\n", string(c.Response().Body()))
+
+	require.Error(t, c.AutoFormat("john"))
+
+	// codecs replace the encoders of built-in types
+	app.RegisterCodec(MIMEApplicationJSON, lineCodec)
+	c.Request().Header.Set(HeaderAccept, MIMEApplicationJSON)
+	require.NoError(t, c.AutoFormat(map[string]string{"name": "jane"}))
+	require.Equal(t, "name=jane\n", string(c.Response().Body()))
+	require.Equal(t, MIMEApplicationJSON, string(c.Response().Header.ContentType()))
+}
+
+// go test -run Test_SharedState_Encoded
+func Test_SharedState_Encoded(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{SharedStorage: newSharedStateMemoryStorage(t)})
+	app.RegisterCodec("text/x-lines", lineCodec)
+	state := app.SharedState()
+
+	require.NoError(t, state.SetEncoded("user", "text/x-lines", map[string]string{"name": "john"}, 0))
+	var out map[string]string
+	raw, found, err := state.GetEncoded("user", "text/x-lines", &out)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "name=john\n", string(raw))
+	require.Equal(t, map[string]string{"name": "john"}, out)
+
+	// the default codecs are registered too
+	require.NoError(t, state.SetEncoded("json", MIMEApplicationJSON, Map{"name": "john"}, 0))
+	var decoded map[string]any
+	_, found, err = state.GetEncoded("json", MIMEApplicationJSON, &decoded)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "john", decoded["name"])
+
+	require.ErrorIs(t, state.SetEncoded("user", "application/yaml", out, 0), ErrCodecNotRegistered)
+	_, _, err = state.GetEncoded("user", "application/yaml", &out)
+	require.ErrorIs(t, err, ErrCodecNotRegistered)
+
+	// shared state without an app has no codecs
+	standalone := newSharedState(&Config{SharedStorage: newSharedStateMemoryStorage(t)})
+	require.ErrorIs(t, standalone.SetEncoded("user", MIMEApplicationJSON, out, 0), ErrCodecNotRegistered)
+}
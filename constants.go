@@ -32,6 +32,9 @@ const (
 	MIMEMultipartForm         = "multipart/form-data"
 	MIMEApplicationMsgPack    = "application/vnd.msgpack"
 
+	MIMEApplicationProblemJSON = "application/problem+json"
+	MIMEApplicationProblemXML  = "application/problem+xml"
+
 	MIMETextXMLCharsetUTF8         = "text/xml; charset=utf-8"
 	MIMETextHTMLCharsetUTF8        = "text/html; charset=utf-8"
 	MIMETextPlainCharsetUTF8       = "text/plain; charset=utf-8"
@@ -39,6 +42,9 @@ const (
 	MIMETextCSSCharsetUTF8         = "text/css; charset=utf-8"
 	MIMEApplicationXMLCharsetUTF8  = "application/xml; charset=utf-8"
 	MIMEApplicationJSONCharsetUTF8 = "application/json; charset=utf-8"
+
+	MIMEApplicationProblemJSONCharsetUTF8 = "application/problem+json; charset=utf-8"
+	MIMEApplicationProblemXMLCharsetUTF8  = "application/problem+xml; charset=utf-8"
 )
 
 // HTTP status codes were copied from net/http with the following updates:
@@ -216,6 +222,10 @@ const (
 	HeaderContentLength                      = "Content-Length"
 	HeaderContentLocation                    = "Content-Location"
 	HeaderContentType                        = "Content-Type"
+	HeaderContentDigest                      = "Content-Digest"
+	HeaderReprDigest                         = "Repr-Digest"
+	HeaderWantContentDigest                  = "Want-Content-Digest"
+	HeaderWantReprDigest                     = "Want-Repr-Digest"
 	HeaderForwarded                          = "Forwarded"
 	HeaderVia                                = "Via"
 	HeaderXForwardedFor                      = "X-Forwarded-For"
@@ -269,6 +279,7 @@ const (
 	HeaderAcceptSignature                    = "Accept-Signature"
 	HeaderAltSvc                             = "Alt-Svc"
 	HeaderDate                               = "Date"
+	HeaderDeprecation                        = "Deprecation"
 	HeaderIndex                              = "Index"
 	HeaderLargeAllocation                    = "Large-Allocation"
 	HeaderLink                               = "Link"
@@ -278,6 +289,7 @@ const (
 	HeaderSignature                          = "Signature"
 	HeaderSignedHeaders                      = "Signed-Headers"
 	HeaderSourceMap                          = "SourceMap"
+	HeaderSunset                             = "Sunset"
 	HeaderUpgrade                            = "Upgrade"
 	HeaderXDNSPrefetchControl                = "X-DNS-Prefetch-Control"
 	HeaderXPingback                          = "X-Pingback"
@@ -29,8 +29,7 @@ const (
 
 const (
 	// maxParams defines the maximum number of parameters per route.
-	maxParams         = 30
-	maxDetectionPaths = 3
+	maxParams = 30
 )
 
 var (
@@ -55,30 +54,35 @@ const (
 //
 //go:generate ifacemaker --file ctx.go --file req.go --file res.go --struct DefaultCtx --iface Ctx --pkg fiber --promoted --output ctx_interface_gen.go --not-exported true --iface-comment "Ctx represents the Context which hold the HTTP request and response.\nIt has methods for the request query string, parameters, body, HTTP headers and so on."
 type DefaultCtx struct {
-	handlerCtx             CustomCtx            // Active custom context implementation, if any
-	DefaultReq                                  // Default request api
-	DefaultRes                                  // Default response api
-	app                    *App                 // Reference to *App
-	route                  *Route               // Reference to *Route
-	fasthttp               *fasthttp.RequestCtx // Reference to *fasthttp.RequestCtx
-	bind                   *Bind                // Default bind reference
-	redirect               *Redirect            // Default redirect reference
-	reclaim                *reclaimLatch        // Coordinates safe pool reclamation of an abandoned ctx; nil on the hot path
-	viewBindMap            Map                  // Default view map to bind template engine
-	values                 [maxParams]string    // Route parameter values
-	baseURI                string               // HTTP base uri
-	pathOriginal           string               // Original HTTP path
-	flashMessages          redirectionMsgs      // Flash messages
-	path                   []byte               // HTTP path with the modifications by the configuration
-	detectionPath          []byte               // Route detection path
-	treePathHash           int                  // Hash of the path for the search in the tree
-	indexRoute             int                  // Index of the current route
-	indexHandler           int                  // Index of the current handler
-	methodInt              int                  // HTTP method INT equivalent
-	isAbandoned            atomic.Bool          // If true, ctx won't be pooled until ForceRelease is called
-	isMatched              bool                 // Non use route matched
-	shouldSkipNonUseRoutes bool                 // Skip non-use routes while iterating middleware
-	isUserContextSet       bool                 // User context was stored in fasthttp user values
+	handlerCtx             CustomCtx                        // Active custom context implementation, if any
+	DefaultReq                                              // Default request api
+	DefaultRes                                              // Default response api
+	app                    *App                             // Reference to *App
+	route                  *Route                           // Reference to *Route
+	fasthttp               *fasthttp.RequestCtx             // Reference to *fasthttp.RequestCtx
+	bind                   *Bind                            // Default bind reference
+	redirect               *Redirect                        // Default redirect reference
+	reclaim                *reclaimLatch                    // Coordinates safe pool reclamation of an abandoned ctx; nil on the hot path
+	disconnect             context.Context                  //nolint:containedctx // Canceled when the client disconnects, see Config.CancelOnDisconnect
+	cancelDisconnect       context.CancelCauseFunc          // Cancels disconnect with ErrClientDisconnected
+	streamWrappers         []func(io.Reader, int) io.Reader // Registered with WrapBodyStream
+	viewBindMap            Map                              // Default view map to bind template engine
+	values                 [maxParams]string                // Route parameter values
+	baseURI                string                           // HTTP base uri
+	pathOriginal           string                           // Original HTTP path
+	flashMessages          redirectionMsgs                  // Flash messages
+	path                   []byte                           // HTTP path with the modifications by the configuration
+	detectionPath          []byte                           // Route detection path
+	indexRoute             int                              // Index of the current route
+	indexHandler           int                              // Index of the current handler
+	methodInt              int                              // HTTP method INT equivalent
+	isAbandoned            atomic.Bool                      // If true, ctx won't be pooled until ForceRelease is called
+	isMatched              bool                             // Non use route matched
+	shouldSkipNonUseRoutes bool                             // Skip non-use routes while iterating middleware
+	isUserContextSet       bool                             // User context was stored in fasthttp user values
+	routeConfig            RouteConfig                      // Settings of the route handling the request
+	table                  *routeTable                      // Route table the request is matched against
+	bodyStream             *limitedBodyStream               // Chunked request body read through the body limit of the route
 }
 
 // TLSHandler hosts the callback hooks Fiber invokes while negotiating TLS
@@ -168,18 +172,27 @@ func (*DefaultCtx) Deadline() (time.Time, bool) {
 // The close of the Done channel may happen asynchronously,
 // after the cancel function returns.
 //
-// Due to current limitations in how fasthttp works, Done operates as a nop.
+// With Config.CancelOnDisconnect, the channel is closed when the client
+// disconnects. Otherwise, Done returns nil, as fasthttp doesn't track
+// the connection while a handler runs.
 // See: https://github.com/valyala/fasthttp/issues/965#issuecomment-777268945
-func (*DefaultCtx) Done() <-chan struct{} {
-	return nil
+func (c *DefaultCtx) Done() <-chan struct{} {
+	if c.disconnect == nil {
+		return nil
+	}
+	return c.disconnect.Done()
 }
 
 // Err mirrors context.Err, returning nil until cancellation and then the terminal error value.
 //
-// Due to current limitations in how fasthttp works, Err operates as a nop.
-// See: https://github.com/valyala/fasthttp/issues/965#issuecomment-777268945
-func (*DefaultCtx) Err() error {
-	return nil
+// With Config.CancelOnDisconnect, Err returns context.Canceled once the
+// client disconnected; context.Cause(c.Context()) returns
+// ErrClientDisconnected. Otherwise, Err always returns nil.
+func (c *DefaultCtx) Err() error {
+	if c.disconnect == nil {
+		return nil
+	}
+	return c.disconnect.Err()
 }
 
 // Request return the *fasthttp.Request object
@@ -540,7 +553,7 @@ func (c *DefaultCtx) SaveFileToStorage(fileheader *multipart.FileHeader, path st
 	}
 	defer file.Close() //nolint:errcheck // not needed
 
-	maxUploadSize := c.app.config.BodyLimit
+	maxUploadSize := requestBodyLimit(c)
 	if maxUploadSize <= 0 {
 		maxUploadSize = DefaultBodyLimit
 	}
@@ -665,15 +678,6 @@ func (c *DefaultCtx) configDependentPaths() {
 	if !c.app.config.StrictRouting && len(c.detectionPath) > 1 && c.detectionPath[len(c.detectionPath)-1] == '/' {
 		c.detectionPath = utils.TrimRight(c.detectionPath, '/')
 	}
-
-	// Define the path for dividing routes into areas for fast tree detection, so that fewer routes need to be traversed,
-	// since the first three characters area select a list of routes
-	c.treePathHash = 0
-	if len(c.detectionPath) >= maxDetectionPaths {
-		c.treePathHash = int(c.detectionPath[0])<<16 |
-			int(c.detectionPath[1])<<8 |
-			int(c.detectionPath[2])
-	}
 }
 
 // Reset is a method to reset context fields by given request when to use server handlers.
@@ -723,9 +727,15 @@ func (c *DefaultCtx) release() {
 		c.redirect = nil
 	}
 	c.shouldSkipNonUseRoutes = false
+	c.routeConfig = RouteConfig{}
+	c.table = nil
+	c.bodyStream = nil
 	// performance: no need for using c.isAbandoned.Store(false) here, as it is always set to false when it was true in ForceRelease
 	c.reclaim = nil
 	c.handlerCtx = nil
+	c.disconnect = nil
+	c.cancelDisconnect = nil
+	c.streamWrappers = nil
 }
 
 // reclaimLatch coordinates the safe, automatic reclamation of an abandoned
@@ -854,10 +864,6 @@ func (c *DefaultCtx) getIndexRoute() int {
 	return c.indexRoute
 }
 
-func (c *DefaultCtx) getTreePathHash() int {
-	return c.treePathHash
-}
-
 func (c *DefaultCtx) getDetectionPath() string {
 	return c.app.toString(c.detectionPath)
 }
@@ -897,3 +903,35 @@ func (c *DefaultCtx) setRoute(route *Route) {
 func (c *DefaultCtx) getPathOriginal() string {
 	return c.pathOriginal
 }
+
+func (c *DefaultCtx) getRouteConfig() RouteConfig {
+	return c.routeConfig
+}
+
+func (c *DefaultCtx) setRouteConfig(cfg RouteConfig) {
+	c.routeConfig = cfg
+}
+
+// bodyLimitExceeded reports whether the streamed request body was read past
+// the body limit of the route.
+func (c *DefaultCtx) bodyLimitExceeded() bool {
+	return c.bodyStream != nil && c.bodyStream.exceeded
+}
+
+// getRouteTable returns the route table the request is matched against. The
+// table is pinned on first use, so a concurrent route update does not change
+// the routes in the middle of a request.
+func (c *DefaultCtx) getRouteTable() *routeTable {
+	if c.table == nil {
+		c.table = c.app.routes.Load()
+	}
+	return c.table
+}
+
+// setDisconnect installs the context canceled when the client disconnects
+// as the context of the request.
+func (c *DefaultCtx) setDisconnect(ctx context.Context, cancel context.CancelCauseFunc) {
+	c.disconnect = ctx
+	c.cancelDisconnect = cancel
+	c.SetContext(ctx)
+}
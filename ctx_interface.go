@@ -5,6 +5,8 @@
 package fiber
 
 import (
+	"context"
+
 	"github.com/valyala/fasthttp"
 )
 
@@ -35,7 +37,6 @@ type CustomCtx interface {
 	// Methods to use with next stack.
 	getMethodInt() int
 	getIndexRoute() int
-	getTreePathHash() int
 	getDetectionPath() string
 	getPathOriginal() string
 	getValues() *[maxParams]string
@@ -46,6 +47,11 @@ type CustomCtx interface {
 	setMatched(matched bool)
 	setSkipNonUseRoutes(skip bool)
 	setRoute(route *Route)
+	getRouteConfig() RouteConfig
+	setRouteConfig(cfg RouteConfig)
+	bodyLimitExceeded() bool
+	getRouteTable() *routeTable
+	setDisconnect(ctx context.Context, cancel context.CancelCauseFunc)
 }
 
 // NewDefaultCtx constructs the default context implementation bound to the
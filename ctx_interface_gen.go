@@ -41,13 +41,16 @@ type Ctx interface {
 	// The close of the Done channel may happen asynchronously,
 	// after the cancel function returns.
 	//
-	// Due to current limitations in how fasthttp works, Done operates as a nop.
+	// With Config.CancelOnDisconnect, the channel is closed when the client
+	// disconnects. Otherwise, Done returns nil, as fasthttp doesn't track
+	// the connection while a handler runs.
 	// See: https://github.com/valyala/fasthttp/issues/965#issuecomment-777268945
 	Done() <-chan struct{}
 	// Err mirrors context.Err, returning nil until cancellation and then the terminal error value.
 	//
-	// Due to current limitations in how fasthttp works, Err operates as a nop.
-	// See: https://github.com/valyala/fasthttp/issues/965#issuecomment-777268945
+	// With Config.CancelOnDisconnect, Err returns context.Canceled once the
+	// client disconnected; context.Cause(c.Context()) returns
+	// ErrClientDisconnected. Otherwise, Err always returns nil.
 	Err() error
 	// Request return the *fasthttp.Request object
 	// This allows you to use all fasthttp request methods
@@ -205,7 +208,6 @@ type Ctx interface {
 	// Methods to use with next stack.
 	getMethodInt() int
 	getIndexRoute() int
-	getTreePathHash() int
 	getDetectionPath() string
 	getValues() *[maxParams]string
 	getMatched() bool
@@ -216,6 +218,18 @@ type Ctx interface {
 	setSkipNonUseRoutes(skip bool)
 	setRoute(route *Route)
 	getPathOriginal() string
+	getRouteConfig() RouteConfig
+	setRouteConfig(cfg RouteConfig)
+	// bodyLimitExceeded reports whether the streamed request body was read past
+	// the body limit of the route.
+	bodyLimitExceeded() bool
+	// getRouteTable returns the route table the request is matched against. The
+	// table is pinned on first use, so a concurrent route update does not change
+	// the routes in the middle of a request.
+	getRouteTable() *routeTable
+	// setDisconnect installs the context canceled when the client disconnects
+	// as the context of the request.
+	setDisconnect(ctx context.Context, cancel context.CancelCauseFunc)
 	// FullURL returns the full request URL (protocol + host + original URL).
 	FullURL() string
 	// UserAgent returns the User-Agent request header.
@@ -296,6 +310,16 @@ type Ctx interface {
 	// reload request, this module will return false to make handling these requests transparent.
 	// https://github.com/jshttp/fresh/blob/master/index.js#L33
 	Fresh() bool
+	// CheckPreconditions evaluates the If-Match, If-Unmodified-Since,
+	// If-None-Match and If-Modified-Since request headers against the current
+	// validators of the target resource, in the order of RFC 9110 §13.2.2.
+	// Pass an empty etag when the resource doesn't exist and a zero lastModified
+	// when it's unknown.
+	// It returns StatusOK when the request can proceed, StatusNotModified for GET
+	// and HEAD requests whose cached representation is still valid, with the
+	// validators set as the ETag and Last-Modified response headers, and
+	// StatusPreconditionFailed otherwise.
+	CheckPreconditions(etag string, lastModified time.Time) int
 	// Host contains the host derived from the X-Forwarded-Host or Host HTTP header.
 	// Returned value is only valid within the handler. Do not store any references.
 	// In a network context, `Host` refers to the combination of a hostname and potentially a port number used for connecting,
@@ -351,6 +375,12 @@ type Ctx interface {
 	// MultipartForm parse form entries from binary.
 	// This returns a map[string][]string, so given a key, the value will be a string slice.
 	MultipartForm() (*multipart.Form, error)
+	// MultipartReader returns a reader streaming the parts of the
+	// multipart/form-data request body. With Config.StreamRequestBody, the parts
+	// are read from the connection as they are requested; set
+	// Config.DisablePreParseMultipartForm too, or fasthttp reads the whole form
+	// before the handler runs. The body can only be read once.
+	MultipartReader(config ...MultipartConfig) (*MultipartReader, error)
 	// Params is used to get the route parameters.
 	// Defaults to empty string "" if the param doesn't exist.
 	// If a default value is given, it will return that value if the param doesn't exist.
@@ -432,7 +462,9 @@ type Ctx interface {
 	// AutoFormat performs content-negotiation on the Accept HTTP header.
 	// It uses Accepts to select a proper format.
 	// The supported content types are text/html, text/plain, application/json, application/xml, application/vnd.msgpack, and application/cbor.
+	// The media types of codecs registered with App.RegisterCodec are supported too, after the ones above.
 	// When text/html is selected, the body is treated as plain text and HTML-escaped before being wrapped in a `<p>` element.
+	// ValidationErrors, or errors wrapping them, are rendered as a standard error body listing the rejected fields.
 	// For more flexible content negotiation, use Format.
 	// If the header is not specified or there is no proper format, text/plain is used.
 	AutoFormat(body any) error
@@ -475,6 +507,8 @@ type Ctx interface {
 	Render(name string, bind any, layouts ...string) error
 	// Send sets the HTTP response body without copying it.
 	// From this point onward the body argument must not be changed.
+	// Ranges of the body are served when the response announces
+	// Accept-Ranges: bytes.
 	Send(body []byte) error
 	// SendEarlyHints allows the server to hint to the browser what resources a page would need
 	// so the browser can preload them while waiting for the server's full response. Only Link
@@ -499,12 +533,38 @@ type Ctx interface {
 	// This means no type assertion, recommended for faster performance
 	SendString(body string) error
 	// SendStream sets response body stream and optional body size.
+	// For an io.ReadSeeker, the size defaults to the remaining bytes of the
+	// stream, and ranges are served from it when the response announces
+	// Accept-Ranges: bytes.
 	SendStream(stream io.Reader, size ...int) error
 	// SendStreamWriter sets response body stream writer
 	SendStreamWriter(streamWriter func(*bufio.Writer)) error
+	// SendStreamFunc sets a response body stream writer like SendStreamWriter.
+	// The StreamWriter passed to fn reports when the client disconnects and sets
+	// the values of the trailers declared with Trailer once the body is written.
+	SendStreamFunc(fn func(w *StreamWriter)) error
+	// WrapBodyStream registers wrap for the body streams set later in the
+	// request with SendStream, SendStreamWriter or SendStreamFunc, so that a
+	// middleware can observe streamed bodies before calling c.Next(). wrap gets
+	// the stream and its size, -1 if unknown, and returns the stream to send,
+	// or nil to leave it as is. The stream is closed once it's sent,
+	// even if the returned reader isn't an io.Closer. When ranges of a
+	// SendStream body are served, wrap reads the selected ranges, so it must
+	// not change the length of the stream. Streams set on the fasthttp
+	// response directly aren't wrapped.
+	WrapBodyStream(wrap func(stream io.Reader, size int) io.Reader)
 	// Set sets the response's HTTP header field to the specified key, value.
 	Set(key, val string)
 	setCanonical(key, val string)
+	// Trailer declares the given header fields as trailers of the response.
+	// Trailers are only sent with chunked bodies, i.e. with SendStreamWriter,
+	// SendStreamFunc or SendStream without a size. It returns ErrBadTrailer for
+	// fields that aren't allowed in trailers, such as Content-Length.
+	Trailer(keys ...string) error
+	// SetTrailer declares the header field as a trailer of the response, like
+	// Trailer, and sets its value. Values computed while the body is streamed
+	// are set with StreamWriter.SetTrailer.
+	SetTrailer(key, val string) error
 	// Type sets the Content-Type HTTP header to the MIME type specified by the file extension.
 	Type(extension string, charset ...string) Ctx
 	// Vary adds the given header field to the Vary response header.
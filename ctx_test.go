@@ -2189,6 +2189,44 @@ func Test_Ctx_AutoFormat_Struct(t *testing.T) {
 	)
 }
 
+// go test -run Test_Ctx_AutoFormat_ValidationErrors
+func Test_Ctx_AutoFormat_ValidationErrors(t *testing.T) {
+	t.Parallel()
+	app := New(Config{
+		MsgPackEncoder: msgpack.Marshal,
+		MsgPackDecoder: msgpack.Unmarshal,
+	})
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	t.Cleanup(func() {
+		app.ReleaseCtx(c)
+	})
+
+	type Query struct {
+		PageSize int `query:"page_size"`
+	}
+	c.Request().URI().SetQueryString("page_size=ten")
+	bindErr := c.Bind().Query(new(Query))
+	require.Error(t, bindErr)
+
+	c.Request().Header.Set(HeaderAccept, MIMEApplicationJSON)
+	require.NoError(t, c.AutoFormat(bindErr))
+	require.JSONEq(t, `{"errors":[{"field":"page_size","rule":"type","message_key":"validation.type","value":"ten"}]}`, string(c.Response().Body()))
+
+	c.Request().Header.Set(HeaderAccept, MIMEApplicationXML)
+	require.NoError(t, c.AutoFormat(bindErr))
+	require.Equal(t, `<errors><error field="page_size" rule="type" message_key="validation.type"><value>ten</value></error></errors>`, string(c.Response().Body()))
+
+	c.Request().Header.Set(HeaderAccept, MIMEApplicationMsgPack)
+	require.NoError(t, c.AutoFormat(bindErr))
+	var body map[string][]map[string]any
+	require.NoError(t, msgpack.Unmarshal(c.Response().Body(), &body))
+	require.Equal(t, "page_size", body["errors"][0]["field"])
+
+	c.Request().Header.Set(HeaderAccept, MIMETextPlain)
+	require.NoError(t, c.AutoFormat(bindErr))
+	require.Equal(t, `schema: error converting value for "page_size"`, string(c.Response().Body()))
+}
+
 // go test -run Test_Ctx_AutoFormat_XSS_Prevention
 func Test_Ctx_AutoFormat_XSS_Prevention(t *testing.T) {
 	t.Parallel()
@@ -2630,6 +2668,80 @@ func Test_Ctx_Fresh(t *testing.T) {
 	require.False(t, c.Fresh())
 }
 
+// go test -run Test_Ctx_CheckPreconditions
+func Test_Ctx_CheckPreconditions(t *testing.T) {
+	t.Parallel()
+
+	modified := time.Date(2015, time.October, 21, 7, 28, 0, 500, time.UTC)
+	const (
+		before = "Wed, 21 Oct 2015 07:27:59 GMT"
+		at     = "Wed, 21 Oct 2015 07:28:00 GMT"
+	)
+
+	testCases := []struct {
+		headers      map[string]string
+		name         string
+		method       string
+		etag         string
+		lastModified time.Time
+		expected     int
+	}{
+		{name: "unconditional", method: MethodPut, etag: `"a"`, expected: StatusOK},
+		{name: "if-match", method: MethodPut, etag: `"a"`, headers: map[string]string{HeaderIfMatch: `"b", "a"`}, expected: StatusOK},
+		{name: "if-match stale", method: MethodPatch, etag: `"a"`, headers: map[string]string{HeaderIfMatch: `"b"`}, expected: StatusPreconditionFailed},
+		{name: "if-match weak", method: MethodPut, etag: `W/"a"`, headers: map[string]string{HeaderIfMatch: `W/"a"`}, expected: StatusPreconditionFailed},
+		{name: "if-match any", method: MethodDelete, etag: `"a"`, headers: map[string]string{HeaderIfMatch: "*"}, expected: StatusOK},
+		{name: "if-match missing resource", method: MethodPut, headers: map[string]string{HeaderIfMatch: "*"}, expected: StatusPreconditionFailed},
+		{name: "if-match on get", method: MethodGet, etag: `"a"`, headers: map[string]string{HeaderIfMatch: `"b"`}, expected: StatusPreconditionFailed},
+		{name: "if-unmodified-since", method: MethodPut, lastModified: modified, headers: map[string]string{HeaderIfUnmodifiedSince: at}, expected: StatusOK},
+		{name: "if-unmodified-since stale", method: MethodPut, lastModified: modified, headers: map[string]string{HeaderIfUnmodifiedSince: before}, expected: StatusPreconditionFailed},
+		{name: "if-unmodified-since invalid", method: MethodPut, lastModified: modified, headers: map[string]string{HeaderIfUnmodifiedSince: "yesterday"}, expected: StatusOK},
+		{name: "if-unmodified-since unknown", method: MethodPut, headers: map[string]string{HeaderIfUnmodifiedSince: before}, expected: StatusOK},
+		{
+			name: "if-match before if-unmodified-since", method: MethodPut, etag: `"a"`, lastModified: modified,
+			headers:  map[string]string{HeaderIfMatch: `"a"`, HeaderIfUnmodifiedSince: before},
+			expected: StatusOK,
+		},
+		{name: "if-none-match get", method: MethodGet, etag: `"a"`, headers: map[string]string{HeaderIfNoneMatch: `W/"a"`}, expected: StatusNotModified},
+		{name: "if-none-match head", method: MethodHead, etag: `"a"`, headers: map[string]string{HeaderIfNoneMatch: `"a"`}, expected: StatusNotModified},
+		{name: "if-none-match changed", method: MethodGet, etag: `"a"`, headers: map[string]string{HeaderIfNoneMatch: `"b"`}, expected: StatusOK},
+		{name: "if-none-match put", method: MethodPut, etag: `"a"`, headers: map[string]string{HeaderIfNoneMatch: `"a"`}, expected: StatusPreconditionFailed},
+		{name: "if-none-match any create", method: MethodPut, headers: map[string]string{HeaderIfNoneMatch: "*"}, expected: StatusOK},
+		{name: "if-none-match any exists", method: MethodPut, etag: `"a"`, headers: map[string]string{HeaderIfNoneMatch: "*"}, expected: StatusPreconditionFailed},
+		{name: "if-modified-since", method: MethodGet, lastModified: modified, headers: map[string]string{HeaderIfModifiedSince: at}, expected: StatusNotModified},
+		{name: "if-modified-since changed", method: MethodGet, lastModified: modified, headers: map[string]string{HeaderIfModifiedSince: before}, expected: StatusOK},
+		{name: "if-modified-since post", method: MethodPost, lastModified: modified, headers: map[string]string{HeaderIfModifiedSince: at}, expected: StatusOK},
+		{
+			name: "if-none-match before if-modified-since", method: MethodGet, etag: `"a"`, lastModified: modified,
+			headers:  map[string]string{HeaderIfNoneMatch: `"b"`, HeaderIfModifiedSince: at},
+			expected: StatusOK,
+		},
+	}
+
+	app := New()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(c)
+
+			c.Method(tc.method)
+			for key, value := range tc.headers {
+				c.Request().Header.Set(key, value)
+			}
+			require.Equal(t, tc.expected, c.CheckPreconditions(tc.etag, tc.lastModified))
+		})
+	}
+
+	// 304 responses carry the validators
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Request().Header.Set(HeaderIfModifiedSince, at)
+	require.Equal(t, StatusNotModified, c.CheckPreconditions(`"a"`, modified))
+	require.Equal(t, `"a"`, string(c.Response().Header.Peek(HeaderETag)))
+	require.Equal(t, at, string(c.Response().Header.Peek(HeaderLastModified)))
+}
+
 // go test -v -run=^$ -bench=Benchmark_Ctx_Fresh_WithNoCache -benchmem -count=4
 func Benchmark_Ctx_Fresh_WithNoCache(b *testing.B) {
 	app := New()
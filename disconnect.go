@@ -0,0 +1,24 @@
+package fiber
+
+import (
+	"context"
+)
+
+// watchDisconnect gives the request of c a context that's canceled with
+// ErrClientDisconnected as its cause once the client closes the connection.
+func watchDisconnect(c CustomCtx) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	c.setDisconnect(ctx, cancel)
+	switch conn := c.RequestCtx().Conn().(type) {
+	case nil:
+	case interface{ requestContext() context.Context }:
+		// served by net/http, see ListenConfig.EnableHTTP2
+		context.AfterFunc(conn.requestContext(), func() {
+			cancel(ErrClientDisconnected)
+		})
+	default:
+		watchConn(conn, func() {
+			cancel(ErrClientDisconnected)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package fiber
+
+import (
+	"net"
+)
+
+// watchConn can't watch connections on this platform, so only failed
+// writes of SendStreamFunc report a disconnect.
+func watchConn(net.Conn, func()) {}
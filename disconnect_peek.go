@@ -0,0 +1,55 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package fiber
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// watchConn calls onClose from a new goroutine when the peer closes conn.
+// It peeks at the socket, so no data is consumed. It stops watching once
+// data arrives, as a close can't be told apart from a pipelined request
+// then, and when the server closes conn or its read deadline passes.
+func watchConn(conn net.Conn, onClose func()) {
+	// unwrap TLS and PROXY protocol connections
+	for {
+		wrapper, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			break
+		}
+		conn = wrapper.NetConn()
+	}
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return
+	}
+
+	go func() {
+		var (
+			buf    [1]byte
+			closed bool
+		)
+		err := raw.Read(func(fd uintptr) bool {
+			for {
+				n, _, err := syscall.Recvfrom(int(fd), buf[:], syscall.MSG_PEEK)
+				if errors.Is(err, syscall.EINTR) {
+					continue
+				}
+				if errors.Is(err, syscall.EAGAIN) {
+					return false // wait until the socket is readable
+				}
+				closed = err != nil || n == 0
+				return true
+			}
+		})
+		if err == nil && closed {
+			onClose()
+		}
+	}()
+}
@@ -0,0 +1,152 @@
+package fiber
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func listenDisconnectApp(t *testing.T, app *App) string {
+	t.Helper()
+
+	ln, err := net.Listen(NetworkTCP4, "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		if err := app.Listener(ln, ListenConfig{DisableStartupMessage: true}); err != nil && !errors.Is(err, net.ErrClosed) {
+			panic(err)
+		}
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+	return ln.Addr().String()
+}
+
+func skipWithoutConnWatch(t *testing.T) {
+	t.Helper()
+	switch runtime.GOOS {
+	case "linux", "darwin", "dragonfly", "freebsd", "netbsd", "openbsd":
+	default:
+		t.Skip("connections can't be watched on " + runtime.GOOS)
+	}
+}
+
+// go test -run Test_App_CancelOnDisconnect
+func Test_App_CancelOnDisconnect(t *testing.T) {
+	t.Parallel()
+	skipWithoutConnWatch(t)
+
+	started := make(chan struct{})
+	causes := make(chan error, 2)
+	app := New(Config{CancelOnDisconnect: true})
+	app.Get("/wait", func(c Ctx) error {
+		close(started)
+		select {
+		case <-c.Done():
+			causes <- context.Cause(c.Context())
+			causes <- c.Err()
+		case <-time.After(5 * time.Second):
+			causes <- errors.New("not canceled")
+		}
+		return nil
+	})
+	addr := listenDisconnectApp(t, app)
+
+	conn, err := net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET /wait HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	<-started
+	require.NoError(t, conn.Close())
+
+	require.ErrorIs(t, <-causes, ErrClientDisconnected)
+	require.ErrorIs(t, <-causes, context.Canceled)
+}
+
+// go test -run Test_App_CancelOnDisconnect_KeepAlive
+func Test_App_CancelOnDisconnect_KeepAlive(t *testing.T) {
+	t.Parallel()
+	skipWithoutConnWatch(t)
+
+	app := New(Config{CancelOnDisconnect: true})
+	app.Get("/", func(c Ctx) error {
+		if c.Err() != nil {
+			return c.Err()
+		}
+		return c.SendString("ok " + c.Query("n"))
+	})
+	addr := listenDisconnectApp(t, app)
+
+	conn, err := net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // test connection
+	reader := bufio.NewReader(conn)
+
+	read := func(n string) {
+		t.Helper()
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "ok "+n, string(body))
+	}
+
+	// the watcher must not consume the following requests
+	for _, n := range []string{"1", "2"} {
+		_, err = conn.Write([]byte("GET /?n=" + n + " HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+		require.NoError(t, err)
+		read(n)
+	}
+	_, err = conn.Write([]byte("GET /?n=3 HTTP/1.1\r\nHost: example.com\r\n\r\nGET /?n=4 HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	read("3")
+	read("4")
+}
+
+// go test -run Test_App_CancelOnDisconnect_Stream
+func Test_App_CancelOnDisconnect_Stream(t *testing.T) {
+	t.Parallel()
+	skipWithoutConnWatch(t)
+
+	causes := make(chan error, 1)
+	app := New(Config{CancelOnDisconnect: true})
+	app.Get("/events", func(c Ctx) error {
+		return c.SendStreamFunc(func(w *StreamWriter) {
+			if _, err := w.WriteString("data: hello\n\n"); err != nil {
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+			// an idle stream doesn't write, yet notices the disconnect
+			select {
+			case <-w.Context().Done():
+				causes <- context.Cause(w.Context())
+			case <-time.After(5 * time.Second):
+				causes <- errors.New("not canceled")
+			}
+		})
+	})
+	addr := listenDisconnectApp(t, app)
+
+	conn, err := net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET /events HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "data: hello\n", line)
+	require.NoError(t, conn.Close())
+
+	require.ErrorIs(t, <-causes, ErrClientDisconnected)
+}
@@ -332,6 +332,18 @@ func (d *domainRouter) registerPath(path string) string {
 	return path
 }
 
+// routeArgs returns the handlers in args with the options of a domain route.
+// Domain routes are skipped when looking up the route settings, so it panics
+// if args or the group of the router carry a RouteConfig.
+func (d *domainRouter) routeArgs(args []any) ([]any, routeOptions) {
+	args, opts := routeArgs(args)
+	if opts.config != (RouteConfig{}) || (d.group != nil && d.group.config != (RouteConfig{})) {
+		panic("domain: RouteConfig is not supported for domain routes")
+	}
+	opts.domain = true
+	return args, opts
+}
+
 // registerGroup returns the group to associate with routes, if any.
 func (d *domainRouter) registerGroup() *Group {
 	return d.group
@@ -361,6 +373,8 @@ func (d *domainRouter) Use(args ...any) Router {
 			prefixes = arg
 		case *App:
 			subApp = arg
+		case RouteConfig, *RouteConfig:
+			panic(useRouteConfigPanic)
 		default:
 			handler, ok := toFiberHandler(arg)
 			if !ok {
@@ -536,9 +550,10 @@ func (d *domainRouter) Query(path string, handler any, handlers ...any) Router {
 // Add allows you to specify multiple HTTP methods to register a route.
 // The handler only executes when the request hostname matches the domain pattern.
 func (d *domainRouter) Add(methods []string, path string, handler any, handlers ...any) Router {
-	converted := collectHandlers("domain", append([]any{handler}, handlers...)...)
+	args, opts := d.routeArgs(append([]any{handler}, handlers...))
+	converted := collectHandlers("domain", args...)
 	wrapped := d.wrapHandlers(converted)
-	d.app.register(methods, d.registerPath(path), d.registerGroup(), wrapped...)
+	d.app.registerRoute(methods, d.registerPath(path), d.registerGroup(), opts, wrapped...)
 
 	// Mark the underlying group so Name() can distinguish between
 	// group-name-prefix calls (before routes) and route-name calls (after routes).
@@ -560,6 +575,7 @@ func (d *domainRouter) All(path string, handler any, handlers ...any) Router {
 func (d *domainRouter) Group(prefix string, handlers ...any) Router {
 	fullPrefix := d.registerPath(prefix)
 
+	handlers, _ = d.routeArgs(handlers)
 	if len(handlers) > 0 {
 		converted := collectHandlers("domain", handlers...)
 		wrapped := d.wrapHandlers(converted)
@@ -568,6 +584,9 @@ func (d *domainRouter) Group(prefix string, handlers ...any) Router {
 
 	// Create a new group on the app
 	newGrp := &Group{Prefix: fullPrefix, app: d.app, parentGroup: d.group}
+	if d.group != nil {
+		newGrp.config = d.group.config
+	}
 	if err := d.app.hooks.executeOnGroupHooks(*newGrp); err != nil {
 		panic(err)
 	}
@@ -616,6 +635,18 @@ func (d *domainRouter) Name(name string) Router {
 	return d
 }
 
+// Meta attaches a metadata value to the most recently registered route.
+// When the domain router was created from a Group, this delegates to the
+// group's Meta method.
+func (d *domainRouter) Meta(key string, value any) Router {
+	if d.group != nil {
+		d.group.Meta(key, value)
+	} else {
+		d.app.Meta(key, value)
+	}
+	return d
+}
+
 // Domain creates a new domain router that inherits this domain router's
 // group (if any) but uses a different hostname pattern.
 func (d *domainRouter) Domain(host string) Router {
@@ -626,6 +657,11 @@ func (d *domainRouter) Domain(host string) Router {
 	}
 }
 
+// Version is not supported for domain routes and panics.
+func (*domainRouter) Version(_ string, _ ...VersionConfig) Router {
+	panic("domain: versioning is not supported for domain routes")
+}
+
 // domainRegistering provides route registration helpers for a specific path
 // on a domain router, implementing the [Register] interface.
 type domainRegistering struct {
@@ -685,9 +721,10 @@ func (r *domainRegistering) Query(handler any, handlers ...any) Register {
 }
 
 func (r *domainRegistering) Add(methods []string, handler any, handlers ...any) Register {
-	converted := collectHandlers("domain", append([]any{handler}, handlers...)...)
+	args, opts := r.domain.routeArgs(append([]any{handler}, handlers...))
+	converted := collectHandlers("domain", args...)
 	wrapped := r.domain.wrapHandlers(converted)
-	r.domain.app.register(methods, r.path, r.domain.registerGroup(), wrapped...)
+	r.domain.app.registerRoute(methods, r.path, r.domain.registerGroup(), opts, wrapped...)
 
 	return r
 }
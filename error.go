@@ -5,6 +5,8 @@ import (
 	"errors"
 
 	"github.com/gofiber/schema"
+
+	"github.com/gofiber/fiber/v3/binder"
 )
 
 // Wrap and return this for unreachable code if panicking is undesirable (i.e., in a handler).
@@ -22,6 +24,16 @@ var (
 	ErrNoViewEngineConfigured = errors.New("fiber: no view engine configured")
 	// ErrAutoCertWithCertFile indicates AutoCertManager cannot be used with CertFile/CertKeyFile.
 	ErrAutoCertWithCertFile = errors.New("tls: AutoCertManager cannot be combined with CertFile/CertKeyFile")
+	// ErrPreforkWithHTTP2 indicates EnablePrefork cannot be used with EnableHTTP2/EnableH2C.
+	ErrPreforkWithHTTP2 = errors.New("listen: EnablePrefork cannot be combined with EnableHTTP2/EnableH2C")
+	// ErrSocketActivation indicates EnableSocketActivation is set but systemd passed no socket to serve.
+	ErrSocketActivation = errors.New("systemd: no socket passed by socket activation")
+	// ErrPreforkWithSockets indicates EnablePrefork cannot serve several sockets of systemd socket activation.
+	ErrPreforkWithSockets = errors.New("listen: EnablePrefork cannot serve several activated sockets, set SocketActivationName")
+	// ErrRouteIssues is returned on startup when FailOnRouteIssues is enabled and the route analysis found problems.
+	ErrRouteIssues = errors.New("router: shadowed, duplicate or unsatisfiable routes found")
+	// ErrRouteUpdate is returned by UpdateRoutes when registering the staged routes failed.
+	ErrRouteUpdate = errors.New("router: invalid route update")
 )
 
 // Fiber redirection errors
@@ -38,6 +50,32 @@ var (
 	errRangeBound = errors.New("range: bound not parsable")
 )
 
+// Trailer and stream errors
+var (
+	// ErrBadTrailer is returned when a trailer field is forbidden in trailers
+	// (RFC 9110 §6.5.1) or isn't a valid header field name.
+	ErrBadTrailer = errors.New("trailer: forbidden or invalid trailer field")
+	// ErrTrailerNotDeclared is returned by StreamWriter.SetTrailer for a
+	// trailer field that wasn't declared before the body was streamed.
+	ErrTrailerNotDeclared = errors.New("trailer: field not declared before streaming")
+	// ErrClientDisconnected is the cause of the StreamWriter context once a
+	// write to the client failed.
+	ErrClientDisconnected = errors.New("stream: client disconnected")
+)
+
+// PROXY protocol errors
+var (
+	// ErrProxyProtocolMalformed reports a connection starting with a malformed PROXY protocol header, which is closed
+	// without response.
+	ErrProxyProtocolMalformed = errors.New("proxy protocol: malformed header")
+	// ErrProxyProtocolMissing reports a connection of a trusted source without PROXY protocol header while
+	// ProxyProtocolConfig.Required is set, which is closed without response.
+	ErrProxyProtocolMissing = errors.New("proxy protocol: missing header")
+	// ErrProxyProtocolNoTrustedSources is returned by Listen when the PROXY protocol is enabled for a TCP listener
+	// without ProxyProtocolConfig.TrustedSources.
+	ErrProxyProtocolNoTrustedSources = errors.New("proxy protocol: no trusted sources")
+)
+
 // Binder errors
 var ErrCustomBinderNotFound = errors.New("binder: custom binder not found, please be sure to enter the right name")
 
@@ -59,6 +97,16 @@ type (
 	MultiError = schema.MultiError
 )
 
+// binder validation errors
+type (
+	// ValidationErrors lists the fields of a request that failed to decode or
+	// validate. The binders return it, wrapped in *BindError, when decoding
+	// fails.
+	ValidationErrors = binder.ValidationErrors
+	// FieldError describes why the value of a single field was rejected.
+	FieldError = binder.FieldError
+)
+
 // encoding/json errors
 type (
 	// InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
@@ -90,3 +138,13 @@ var (
 	ErrFileRead      = errors.New("file: failed to read file")
 	ErrFileStore     = errors.New("file: failed to store file")
 )
+
+// Multipart errors, answered with 413 Request Entity Too Large
+var (
+	// ErrMultipartPartTooLarge is returned when a part exceeds MultipartConfig.MaxPartSize.
+	ErrMultipartPartTooLarge = NewError(StatusRequestEntityTooLarge, "multipart: part too large")
+	// ErrMultipartTooLarge is returned when the body exceeds MultipartConfig.MaxTotalSize.
+	ErrMultipartTooLarge = NewError(StatusRequestEntityTooLarge, "multipart: body too large")
+	// ErrMultipartTooManyParts is returned when the body has more parts than MultipartConfig.MaxParts.
+	ErrMultipartTooManyParts = NewError(StatusRequestEntityTooLarge, "multipart: too many parts")
+)
@@ -17,6 +17,8 @@ type Group struct {
 	name        string
 
 	Prefix      string
+	config      RouteConfig    // Settings inherited by the routes of the group
+	meta        map[string]any // Metadata inherited by the routes of the group
 	hasAnyRoute bool
 }
 
@@ -81,6 +83,8 @@ func (grp *Group) Use(args ...any) Router {
 			subApp = arg
 		case []string:
 			prefixes = arg
+		case RouteConfig, *RouteConfig:
+			panic(useRouteConfigPanic)
 		default:
 			handler, ok := toFiberHandler(arg)
 			if !ok {
@@ -171,8 +175,9 @@ func (grp *Group) Query(path string, handler any, handlers ...any) Router {
 // Add allows you to specify multiple HTTP methods to register a route.
 // The provided handlers are executed in order, starting with `handler` and then the variadic `handlers`.
 func (grp *Group) Add(methods []string, path string, handler any, handlers ...any) Router {
-	converted := collectHandlers("group", append([]any{handler}, handlers...)...)
-	grp.app.register(methods, getGroupPath(grp.Prefix, path), grp, converted...)
+	args, opts := routeArgs(append([]any{handler}, handlers...))
+	converted := collectHandlers("group", args...)
+	grp.app.registerRoute(methods, getGroupPath(grp.Prefix, path), grp, opts, converted...)
 	if !grp.hasAnyRoute {
 		grp.hasAnyRoute = true
 	}
@@ -192,13 +197,14 @@ func (grp *Group) All(path string, handler any, handlers ...any) Router {
 //	api.Get("/users", handler)
 func (grp *Group) Group(prefix string, handlers ...any) Router {
 	prefix = getGroupPath(grp.Prefix, prefix)
+	handlers, opts := routeArgs(handlers)
 	if len(handlers) > 0 {
 		converted := collectHandlers("group", handlers...)
 		grp.app.register([]string{methodUse}, prefix, grp, converted...)
 	}
 
 	// Create new group
-	newGrp := &Group{Prefix: prefix, app: grp.app, parentGroup: grp}
+	newGrp := &Group{Prefix: prefix, app: grp.app, parentGroup: grp, config: grp.config.merge(opts.config)}
 	if err := grp.app.hooks.executeOnGroupHooks(*newGrp); err != nil {
 		panic(err)
 	}
@@ -226,6 +232,16 @@ func (grp *Group) Domain(host string) Router {
 	}
 }
 
+// Version creates a new router for the given API version within this group.
+// Routes registered through the returned Router inherit the group prefix,
+// see [App.Version].
+//
+//	api := app.Group("/api")
+//	api.Version("2").Get("/users", listUsersV2)
+func (grp *Group) Version(version string, config ...VersionConfig) Router {
+	return newVersionRouter(grp.app, grp, version, config)
+}
+
 // RouteChain creates a Registering instance scoped to the group's prefix,
 // allowing chained route declarations for the same path.
 func (grp *Group) RouteChain(path string) Register {
@@ -748,6 +748,24 @@ func (app *App) isEtagStale(etag string, noneMatchBytes []byte) bool {
 	return !matchEtag(app.toString(noneMatchBytes[start:end]), etag)
 }
 
+// matchEtagList reports whether the If-Match or If-None-Match header value
+// lists etag or is "*", using the strong comparison for If-Match and the weak
+// one for If-None-Match (RFC 9110 §13.1.1, §13.1.2).
+func matchEtagList(header, etag string, strong bool) bool {
+	header = utils.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+
+	for entry := range strings.SplitSeq(header, ",") {
+		entry = utils.TrimSpace(entry)
+		if strong && matchEtagStrong(entry, etag) || !strong && matchEtag(entry, etag) {
+			return true
+		}
+	}
+	return false
+}
+
 func parseAddr(raw string) (host, port string) { //nolint:nonamedreturns // gocritic unnamedResult requires naming host and port parts for clarity
 	if raw == "" {
 		return "", ""
@@ -27,6 +27,10 @@ type (
 	OnPostShutdownHandler = func(error) error
 	// OnForkHandler runs inside a forked worker process and receives the worker ID.
 	OnForkHandler = func(int) error
+	// OnPreRestartHandler runs before a graceful restart starts the new process. Returning an error aborts the restart.
+	OnPreRestartHandler = func() error
+	// OnPostRestartHandler runs after a graceful restart and receives the PID of the new process and the restart result.
+	OnPostRestartHandler = func(int, error) error
 	// OnMountHandler runs after a sub-application mounts to a parent and receives the parent app reference.
 	OnMountHandler = func(*App) error
 )
@@ -47,6 +51,8 @@ type Hooks struct {
 	onPreShutdown  []OnPreShutdownHandler
 	onPostShutdown []OnPostShutdownHandler
 	onFork         []OnForkHandler
+	onPreRestart   []OnPreRestartHandler
+	onPostRestart  []OnPostRestartHandler
 	onMount        []OnMountHandler
 }
 
@@ -232,6 +238,8 @@ func newHooks(app *App) *Hooks {
 		onPreShutdown:  make([]OnPreShutdownHandler, 0),
 		onPostShutdown: make([]OnPostShutdownHandler, 0),
 		onFork:         make([]OnForkHandler, 0),
+		onPreRestart:   make([]OnPreRestartHandler, 0),
+		onPostRestart:  make([]OnPostRestartHandler, 0),
 		onMount:        make([]OnMountHandler, 0),
 	}
 }
@@ -314,6 +322,23 @@ func (h *Hooks) OnFork(handler ...OnForkHandler) {
 	h.app.mutex.Unlock()
 }
 
+// OnPreRestart is a hook to execute user functions before a graceful restart starts the new process.
+// If a handler returns an error, the restart is aborted and the app keeps serving.
+func (h *Hooks) OnPreRestart(handler ...OnPreRestartHandler) {
+	h.app.mutex.Lock()
+	h.onPreRestart = append(h.onPreRestart, handler...)
+	h.app.mutex.Unlock()
+}
+
+// OnPostRestart is a hook to execute user functions after a graceful restart.
+// It receives the PID of the new process and a nil error once the new process listens,
+// in which case the app shuts down next, or the error that aborted the restart.
+func (h *Hooks) OnPostRestart(handler ...OnPostRestartHandler) {
+	h.app.mutex.Lock()
+	h.onPostRestart = append(h.onPostRestart, handler...)
+	h.app.mutex.Unlock()
+}
+
 // OnMount is a hook to execute user function after mounting process.
 // The mount event is fired when sub-app is mounted on a parent app. The parent app is passed as a parameter.
 // It works for app and group mounting.
@@ -451,6 +476,24 @@ func (h *Hooks) executeOnForkHooks(pid int) {
 	}
 }
 
+func (h *Hooks) executeOnPreRestartHooks() error {
+	for _, v := range h.onPreRestart {
+		if err := v(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *Hooks) executeOnPostRestartHooks(pid int, err error) {
+	for _, v := range h.onPostRestart {
+		if hookErr := v(pid, err); hookErr != nil {
+			log.Errorf("failed to call post restart hook: %v", hookErr)
+		}
+	}
+}
+
 func (h *Hooks) executeOnMountHooks(app *App) error {
 	for _, v := range h.onMount {
 		if err := v(app); err != nil {
@@ -562,6 +562,43 @@ func Test_Hook_OnHook(t *testing.T) {
 	require.ErrorIs(t, err, prefork.ErrOverRecovery)
 }
 
+func Test_Hook_OnPreRestart(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	var calls []string
+	app.Hooks().OnPreRestart(func() error {
+		calls = append(calls, "first")
+		return errors.New("abort")
+	}, func() error {
+		calls = append(calls, "second")
+		return nil
+	})
+
+	require.EqualError(t, app.hooks.executeOnPreRestartHooks(), "abort")
+	require.Equal(t, []string{"first"}, calls)
+}
+
+func Test_Hook_OnPostRestart(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	restartErr := errors.New("restart failed")
+	var calls int
+	app.Hooks().OnPostRestart(func(pid int, err error) error {
+		calls++
+		require.Equal(t, 42, pid)
+		require.Equal(t, restartErr, err)
+		return errors.New("logged")
+	}, func(int, error) error {
+		calls++
+		return nil
+	})
+
+	app.hooks.executeOnPostRestartHooks(42, restartErr)
+	require.Equal(t, 2, calls)
+}
+
 func Test_Hook_OnMount(t *testing.T) {
 	t.Parallel()
 	app := New()
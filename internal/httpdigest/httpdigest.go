@@ -0,0 +1,223 @@
+// Package httpdigest computes and verifies the integrity fields of RFC 9530,
+// Content-Digest and Repr-Digest. It is shared by the digest middleware, the
+// compress middleware and the client, which must not depend on the
+// middleware itself.
+package httpdigest
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/utils/v2"
+	utilsstrings "github.com/gofiber/utils/v2/strings"
+)
+
+// Digest algorithms of the IANA Hash Algorithms for HTTP Digest Fields
+// registry that are supported.
+const (
+	SHA256 = "sha-256"
+	SHA512 = "sha-512"
+)
+
+// Errors returned by Verify.
+var (
+	// ErrMalformed is returned for digest fields that aren't structured field
+	// dictionaries of byte sequences.
+	ErrMalformed = errors.New("malformed digest field")
+	// ErrMismatch is returned when a digest doesn't match the content.
+	ErrMismatch = errors.New("digest doesn't match the content")
+	// ErrUnsupported is returned when a digest field has no digest with a
+	// supported algorithm.
+	ErrUnsupported = errors.New("no digest with a supported algorithm")
+)
+
+// NewHash returns a hash for the algorithm, nil if it isn't supported.
+func NewHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case SHA256:
+		return sha256.New()
+	case SHA512:
+		return sha512.New()
+	default:
+		return nil
+	}
+}
+
+// Supported reports whether the algorithm is supported.
+func Supported(algorithm string) bool {
+	return algorithm == SHA256 || algorithm == SHA512
+}
+
+// Compute returns a Content-Digest or Repr-Digest field value with the
+// digests of content for the given algorithms, SHA256 if none are given.
+func Compute(content []byte, algorithms ...string) (string, error) {
+	if len(algorithms) == 0 {
+		algorithms = []string{SHA256}
+	}
+	hashes := make([]hash.Hash, len(algorithms))
+	for i, algorithm := range algorithms {
+		if hashes[i] = NewHash(algorithm); hashes[i] == nil {
+			return "", fmt.Errorf("%w: %q", ErrUnsupported, algorithm)
+		}
+		hashes[i].Write(content) //nolint:errcheck // hashes never fail
+	}
+	return Format(algorithms, hashes), nil
+}
+
+// Recompute returns a field value with the digests of content for the
+// supported algorithms of field, e.g. after the content was transformed. It
+// returns ErrMalformed or ErrUnsupported if field has no supported digest.
+func Recompute(field string, content []byte) (string, error) {
+	members, err := parseDictionary(field)
+	if err != nil {
+		return "", err
+	}
+	var algorithms []string
+	for _, member := range members {
+		if Supported(member.key) && !slices.Contains(algorithms, member.key) {
+			algorithms = append(algorithms, member.key)
+		}
+	}
+	if len(algorithms) == 0 {
+		return "", ErrUnsupported
+	}
+	return Compute(content, algorithms...)
+}
+
+// Format returns the field value with the sums of hashes.
+func Format(algorithms []string, hashes []hash.Hash) string {
+	var b strings.Builder
+	for i, algorithm := range algorithms {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(algorithm)
+		b.WriteString("=:")
+		b.WriteString(base64.StdEncoding.EncodeToString(hashes[i].Sum(nil)))
+		b.WriteByte(':')
+	}
+	return b.String()
+}
+
+// Verify checks the digests of a Content-Digest or Repr-Digest field value
+// against content. Only the digests with the given algorithms, all supported
+// ones if none are given, are checked, and at least one of them must be
+// present. It returns ErrMalformed, an error wrapping ErrMismatch naming the
+// algorithm or ErrUnsupported.
+func Verify(field string, content []byte, algorithms ...string) error {
+	members, err := parseDictionary(field)
+	if err != nil {
+		return err
+	}
+	verified := false
+	for _, member := range members {
+		if len(algorithms) > 0 && !slices.Contains(algorithms, member.key) {
+			continue
+		}
+		h := NewHash(member.key)
+		if h == nil {
+			continue
+		}
+		sum, err := member.bytes()
+		if err != nil {
+			return err
+		}
+		h.Write(content) //nolint:errcheck // hashes never fail
+		if subtle.ConstantTimeCompare(h.Sum(nil), sum) != 1 {
+			return fmt.Errorf("%w: %s", ErrMismatch, member.key)
+		}
+		verified = true
+	}
+	if !verified {
+		return ErrUnsupported
+	}
+	return nil
+}
+
+// Negotiate returns the algorithm a Want-Content-Digest or Want-Repr-Digest
+// field value prefers among algorithms, which are ordered by the preference
+// of the server. It returns false if none of them is acceptable, or if the
+// field is malformed.
+func Negotiate(want string, algorithms []string) (string, bool) {
+	members, err := parseDictionary(want)
+	if err != nil {
+		return "", false
+	}
+	best, bestWeight := "", 0
+	for _, algorithm := range algorithms {
+		for _, member := range members {
+			if member.key != algorithm {
+				continue
+			}
+			weight, err := strconv.Atoi(member.value)
+			if err != nil || weight < 0 || weight > 10 {
+				return "", false
+			}
+			if weight > bestWeight {
+				best, bestWeight = algorithm, weight
+			}
+		}
+	}
+	return best, best != ""
+}
+
+// Want returns a Want-Content-Digest or Want-Repr-Digest field value that
+// asks for the algorithms in order of preference.
+func Want(algorithms ...string) string {
+	var b strings.Builder
+	for i, algorithm := range algorithms {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(algorithm)
+		b.WriteByte('=')
+		b.WriteString(strconv.Itoa(max(10-i, 1)))
+	}
+	return b.String()
+}
+
+type member struct {
+	key   string
+	value string
+}
+
+// bytes decodes the value of a member as a structured field byte sequence.
+func (m member) bytes() ([]byte, error) {
+	if len(m.value) < 2 || m.value[0] != ':' || m.value[len(m.value)-1] != ':' {
+		return nil, ErrMalformed
+	}
+	b, err := base64.StdEncoding.DecodeString(m.value[1 : len(m.value)-1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return b, nil
+}
+
+// parseDictionary parses the members of a structured field dictionary
+// (RFC 8941 §3.2), ignoring their parameters. Keys are lowercased.
+func parseDictionary(field string) ([]member, error) {
+	var members []member
+	for item := range strings.SplitSeq(field, ",") {
+		item = utils.TrimSpace(item)
+		if item == "" {
+			return nil, ErrMalformed
+		}
+		if i := strings.IndexByte(item, ';'); i >= 0 {
+			item = item[:i]
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || key == "" || value == "" {
+			return nil, ErrMalformed
+		}
+		members = append(members, member{key: utilsstrings.ToLower(key), value: value})
+	}
+	return members, nil
+}
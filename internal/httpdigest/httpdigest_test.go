@@ -0,0 +1,63 @@
+package httpdigest
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Field(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+func sha512Field(content []byte) string {
+	sum := sha512.Sum512(content)
+	return "sha-512=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+// go test -run Test_Fields
+func Test_Fields(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("hello world")
+	field, err := Compute(content, SHA256, SHA512)
+	require.NoError(t, err)
+	require.Equal(t, sha256Field(content)+", "+sha512Field(content), field)
+	_, err = Compute(content, "md5")
+	require.ErrorIs(t, err, ErrUnsupported)
+
+	require.NoError(t, Verify(field, content))
+	require.NoError(t, Verify(field, content, SHA512))
+	require.NoError(t, Verify("MD5=:AAAA:, "+sha256Field(content)+";param=1", content))
+	require.ErrorIs(t, Verify(sha256Field(content), []byte("tampered")), ErrMismatch)
+	require.ErrorIs(t, Verify(sha256Field(content), content, SHA512), ErrUnsupported)
+	require.ErrorIs(t, Verify("md5=:AAAA:", content), ErrUnsupported)
+	for _, malformed := range []string{"sha-256", "sha-256=abc", "sha-256=:!!:", "sha-256=:AAAA:,"} {
+		require.ErrorIs(t, Verify(malformed, content), ErrMalformed, malformed)
+	}
+
+	recomputed, err := Recompute("md5=:AAAA:, sha-512=:AAAA:", content)
+	require.NoError(t, err)
+	require.Equal(t, sha512Field(content), recomputed)
+
+	require.Equal(t, "sha-256=10, sha-512=9", Want(SHA256, SHA512))
+	for _, tc := range []struct {
+		want      string
+		algorithm string
+	}{
+		{want: "sha-256=1, sha-512=3", algorithm: SHA512},
+		{want: "sha-512=3, sha-256=3", algorithm: SHA256},
+		{want: "md5=10, sha-256=2", algorithm: SHA256},
+		{want: "sha-256=0", algorithm: ""},
+		{want: "sha-256=11", algorithm: ""},
+		{want: "md5=10", algorithm: ""},
+	} {
+		algorithm, ok := Negotiate(tc.want, []string{SHA256, SHA512})
+		require.Equal(t, tc.algorithm, algorithm, tc.want)
+		require.Equal(t, tc.algorithm != "", ok, tc.want)
+	}
+}
@@ -11,12 +11,14 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -104,6 +106,19 @@ type ListenConfig struct {
 	// Default : ""
 	CertClientFile string `json:"cert_client_file"`
 
+	// SocketActivationName selects the socket of systemd socket activation
+	// named with FileDescriptorName= in the socket unit. When empty, all the
+	// sockets passed by systemd are served.
+	//
+	// Default: ""
+	SocketActivationName string `json:"socket_activation_name"`
+
+	// ProxyProtocolConfig configures the PROXY protocol when
+	// EnableProxyProtocol is set.
+	//
+	// Default: ProxyProtocolConfig{HeaderTimeout: 10 * time.Second}
+	ProxyProtocolConfig ProxyProtocolConfig `json:"proxy_protocol_config"`
+
 	// When the graceful shutdown begins, use this field to set the timeout
 	// duration. If the timeout is reached, OnPostShutdown will be called with the error.
 	// Set to 0 to disable the timeout and wait indefinitely.
@@ -111,6 +126,12 @@ type ListenConfig struct {
 	// Default: 10 * time.Second
 	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
 
+	// RestartTimeout is the maximum duration to wait for the new process of
+	// a graceful restart to listen before the restart is aborted.
+	//
+	// Default: 30 * time.Second
+	RestartTimeout time.Duration `json:"restart_timeout"`
+
 	// FileMode to set for Unix Domain Socket (ListenerNetwork must be "unix")
 	//
 	// Default: 0770
@@ -143,6 +164,55 @@ type ListenConfig struct {
 	//
 	// Default: false
 	EnablePrintRoutes bool `json:"enable_print_routes"`
+
+	// When set to true, the app is served by net/http instead of fasthttp,
+	// which negotiates HTTP/2 over TLS with ALPN. Requests are converted to
+	// fasthttp requests, so handlers run unchanged, but some features degrade,
+	// see the documentation of ListenConfig. Prefork isn't supported.
+	//
+	// Default: false
+	EnableHTTP2 bool `json:"enable_http2"`
+
+	// When set to true, HTTP/2 is also accepted without TLS (h2c) from clients
+	// with prior knowledge. It implies EnableHTTP2.
+	//
+	// Default: false
+	EnableH2C bool `json:"enable_h2c"`
+
+	// When set to true, connections may start with a PROXY protocol header
+	// (v1 or v2), sent by TCP load balancers such as HAProxy, so that c.IP()
+	// and c.Port() return the address of the client instead of the one of
+	// the load balancer. Use ProxyProtocolConfig to restrict the sources
+	// allowed to send the header. See ProxyProtocol to access its TLVs.
+	//
+	// Default: false
+	EnableProxyProtocol bool `json:"enable_proxy_protocol"`
+
+	// When set to true, the app restarts gracefully on SIGHUP and SIGUSR2:
+	// the current executable is started again and inherits the listeners,
+	// and once its OnListen hooks and BeforeServeFunc ran, the app shuts
+	// down within ShutdownTimeout. Only supported on Linux and other
+	// Unix-like systems.
+	//
+	// Default: false
+	EnableGracefulRestart bool `json:"enable_graceful_restart"`
+
+	// When set to true, the app serves the sockets passed by systemd socket
+	// activation (LISTEN_FDS) instead of listening on the address given to
+	// Listen, see SocketActivationName. TLS and the PROXY protocol apply to
+	// them as to the other listeners.
+	//
+	// Default: false
+	EnableSocketActivation bool `json:"enable_socket_activation"`
+
+	// When set to true, systemd is notified with sd_notify: READY=1 once the
+	// OnListen hooks and BeforeServeFunc ran, STOPPING=1 with the
+	// OnPreShutdown hooks and WATCHDOG=1 at half the watchdog timeout of the
+	// service. It does nothing when the app isn't run by systemd
+	// (NOTIFY_SOCKET is unset).
+	//
+	// Default: false
+	EnableSystemdNotify bool `json:"enable_systemd_notify"`
 }
 
 // listenConfigDefault is a function to set default values of ListenConfig.
@@ -153,6 +223,10 @@ func listenConfigDefault(config ...ListenConfig) ListenConfig {
 			ListenerNetwork:    NetworkTCP4,
 			UnixSocketFileMode: 0o770,
 			ShutdownTimeout:    10 * time.Second,
+			RestartTimeout:     30 * time.Second,
+			ProxyProtocolConfig: ProxyProtocolConfig{
+				HeaderTimeout: 10 * time.Second,
+			},
 		}
 	}
 
@@ -173,6 +247,18 @@ func listenConfigDefault(config ...ListenConfig) ListenConfig {
 		panic("unsupported TLS version, please use tls.VersionTLS12 or tls.VersionTLS13")
 	}
 
+	if cfg.EnableH2C {
+		cfg.EnableHTTP2 = true
+	}
+
+	if cfg.RestartTimeout <= 0 {
+		cfg.RestartTimeout = 30 * time.Second
+	}
+
+	if cfg.ProxyProtocolConfig.HeaderTimeout <= 0 {
+		cfg.ProxyProtocolConfig.HeaderTimeout = 10 * time.Second
+	}
+
 	return cfg
 }
 
@@ -234,6 +320,10 @@ func (app *App) Listen(addr string, config ...ListenConfig) error {
 		}
 	}
 
+	if tlsConfig != nil && cfg.EnableHTTP2 {
+		tlsConfig.NextProtos = http2NextProtos(tlsConfig.NextProtos)
+	}
+
 	// Graceful shutdown
 	if cfg.GracefulContext != nil {
 		ctx, cancel := context.WithCancel(cfg.GracefulContext)
@@ -242,8 +332,16 @@ func (app *App) Listen(addr string, config ...ListenConfig) error {
 		go app.gracefulShutdown(ctx, &cfg)
 	}
 
+	// Graceful restart
+	if cfg.EnableGracefulRestart {
+		defer restarts.unwatch(app)
+	}
+
 	// Start prefork
 	if cfg.EnablePrefork {
+		if cfg.EnableHTTP2 {
+			return ErrPreforkWithHTTP2
+		}
 		return app.prefork(addr, tlsConfig, &cfg)
 	}
 
@@ -254,7 +352,13 @@ func (app *App) Listen(addr string, config ...ListenConfig) error {
 	}
 
 	// prepare the server for the start
-	app.startupProcess()
+	if err := app.startupProcess(); err != nil {
+		if closeErr := ln.Close(); closeErr != nil {
+			log.Errorf("failed to close listener: %v", closeErr)
+		}
+		return err
+	}
+	srv := app.newHTTPServer(&cfg)
 
 	listenData := app.prepareListenData(ln.Addr().String(), getTLSConfig(ln) != nil, &cfg, nil)
 
@@ -271,7 +375,17 @@ func (app *App) Listen(addr string, config ...ListenConfig) error {
 		}
 	}
 
-	return app.server.Serve(ln)
+	// Notify the process this one replaces
+	if cfg.EnableGracefulRestart {
+		restarts.listening(app)
+	}
+
+	// Notify systemd
+	if cfg.EnableSystemdNotify {
+		defer app.notifySystemd()()
+	}
+
+	return app.serve(ln, srv)
 }
 
 func applyClientCert(tlsConfig *tls.Config, certClientFile string) error {
@@ -300,6 +414,18 @@ func applyClientCert(tlsConfig *tls.Config, certClientFile string) error {
 func (app *App) Listener(ln net.Listener, config ...ListenConfig) error {
 	cfg := listenConfigDefault(config...)
 
+	// The PROXY protocol header precedes the TLS handshake
+	if cfg.EnableProxyProtocol {
+		if getTLSConfig(ln) != nil {
+			log.Warn("PROXY protocol isn't supported for TLS listeners, wrap the listener before TLS.")
+		} else {
+			var err error
+			if ln, err = newProxyProtocolListener(ln, cfg.ProxyProtocolConfig); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Graceful shutdown
 	if cfg.GracefulContext != nil {
 		ctx, cancel := context.WithCancel(cfg.GracefulContext)
@@ -309,7 +435,10 @@ func (app *App) Listener(ln net.Listener, config ...ListenConfig) error {
 	}
 
 	// prepare the server for the start
-	app.startupProcess()
+	if err := app.startupProcess(); err != nil {
+		return err
+	}
+	srv := app.newHTTPServer(&cfg)
 
 	listenData := app.prepareListenData(ln.Addr().String(), getTLSConfig(ln) != nil, &cfg, nil)
 
@@ -331,33 +460,118 @@ func (app *App) Listener(ln net.Listener, config ...ListenConfig) error {
 		log.Warn("Prefork isn't supported for custom listeners.")
 	}
 
+	// Graceful restart is not supported for custom listeners
+	if cfg.EnableGracefulRestart {
+		log.Warn("Graceful restart isn't supported for custom listeners.")
+	}
+
+	// Socket activation is not supported for custom listeners
+	if cfg.EnableSocketActivation {
+		log.Warn("Socket activation isn't supported for custom listeners.")
+	}
+
+	// Notify systemd
+	if cfg.EnableSystemdNotify {
+		defer app.notifySystemd()()
+	}
+
+	return app.serve(ln, srv)
+}
+
+// serve serves ln with fasthttp, or with srv, the net/http server created
+// when HTTP/2 is enabled.
+func (app *App) serve(ln net.Listener, srv *http.Server) error {
+	if srv != nil {
+		return serveHTTP2(ln, srv)
+	}
 	return app.server.Serve(ln)
 }
 
 // Create listener function.
-func (*App) createListener(addr string, tlsConfig *tls.Config, cfg *ListenConfig) (net.Listener, error) {
+func (app *App) createListener(addr string, tlsConfig *tls.Config, cfg *ListenConfig) (net.Listener, error) {
 	if cfg == nil {
 		cfg = &ListenConfig{}
 	}
-	var listener net.Listener
+	var listeners []net.Listener
+	var keys []string
 	var err error
 
+	if cfg.EnableSocketActivation {
+		// Serve the sockets passed by systemd
+		if listeners, keys, err = app.activatedListeners(cfg); err != nil {
+			return nil, err
+		}
+	} else {
+		var ln net.Listener
+		if ln, err = app.bindListener(addr, cfg); err != nil {
+			return nil, err
+		}
+		listeners = []net.Listener{ln}
+		keys = []string{restartKey(cfg.ListenerNetwork, addr)}
+	}
+
+	// Pass the listeners to the process replacing this one
+	if cfg.EnableGracefulRestart {
+		for i, ln := range listeners {
+			app.watchRestart(ln, keys[i], cfg)
+		}
+	}
+
+	listener := listeners[0]
+	if len(listeners) > 1 {
+		listener = newMultiListener(listeners)
+	}
+
+	// The PROXY protocol header precedes the TLS handshake
+	if cfg.EnableProxyProtocol {
+		proxyListener, err := newProxyProtocolListener(listener, cfg.ProxyProtocolConfig)
+		if err != nil {
+			if closeErr := listener.Close(); closeErr != nil {
+				log.Errorf("failed to close listener: %v", closeErr)
+			}
+			return nil, err
+		}
+		listener = proxyListener
+	}
+
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	if cfg.ListenerAddrFunc != nil {
+		cfg.ListenerAddrFunc(listener.Addr())
+	}
+
+	return listener, nil
+}
+
+// restartKey identifies the listener of network and addr passed to the new
+// process of a graceful restart.
+func restartKey(network, addr string) string {
+	return network + " " + addr
+}
+
+// bindListener listens on addr, or inherits the listener of the process this
+// one replaces.
+func (app *App) bindListener(addr string, cfg *ListenConfig) (net.Listener, error) {
+	if cfg.EnableGracefulRestart {
+		if ln := restarts.listener(app, cfg.ListenerNetwork, addr); ln != nil {
+			return ln, nil
+		}
+	}
+
 	// Remove previously created socket, to make sure it's possible to listen
 	if cfg.ListenerNetwork == NetworkUnix {
-		if err = os.Remove(addr); err != nil && !os.IsNotExist(err) {
+		if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
 			return nil, fmt.Errorf("unexpected error when trying to remove unix socket file %q: %w", addr, err)
 		}
 	}
 
-	if tlsConfig != nil {
-		listener, err = tls.Listen(cfg.ListenerNetwork, addr, tlsConfig)
-	} else {
-		listener, err = net.Listen(cfg.ListenerNetwork, addr)
-	}
+	listener, err := net.Listen(cfg.ListenerNetwork, addr)
 
 	// Check for error before using the listener
 	if err != nil {
-		// Wrap the error from tls.Listen/net.Listen
+		// Wrap the error from net.Listen
 		return nil, fmt.Errorf("failed to listen: %w", err)
 	}
 
@@ -367,10 +581,6 @@ func (*App) createListener(addr string, tlsConfig *tls.Config, cfg *ListenConfig
 		}
 	}
 
-	if cfg.ListenerAddrFunc != nil {
-		cfg.ListenerAddrFunc(listener.Addr())
-	}
-
 	return listener, nil
 }
 
@@ -458,6 +668,10 @@ func (app *App) startupMessage(listenData *ListenData, cfg *ListenConfig) {
 
 	preData.AddInfo("process_count", "Total process count", fmt.Sprintf("%s%d%s", colors.Blue, listenData.ProcessCount, colors.Reset), 4)
 
+	for i, issue := range app.RouteIssues() {
+		preData.AddWarning("route_issue_"+strconv.Itoa(i), issue.Kind.String(), issue.String())
+	}
+
 	if err := app.hooks.executeOnPreStartupMessageHooks(preData); err != nil {
 		log.Errorf("failed to call pre startup message hook: %v", err)
 	}
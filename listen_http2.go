@@ -0,0 +1,307 @@
+package fiber
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	stdlog "log" //nolint:depguard // http.Server.ErrorLog requires a *log.Logger
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/utils/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// errHTTPConn is returned by the connection of requests served by net/http.
+var errHTTPConn = errors.New("fiber: the connection is owned by net/http")
+
+// httpManagedHeaders are the response header fields set by net/http, which
+// manages the connections and the framing of the bodies.
+var httpManagedHeaders = []string{HeaderContentLength, HeaderConnection, HeaderTransferEncoding, HeaderKeepAlive, HeaderTrailer}
+
+// http2NextProtos returns the ALPN protocols of a TLS config serving HTTP/2,
+// adding "h2" first and "http/1.1" unless they're present.
+func http2NextProtos(protos []string) []string {
+	if !slices.Contains(protos, "http/1.1") {
+		protos = append([]string{"http/1.1"}, protos...)
+	}
+	if !slices.Contains(protos, "h2") {
+		protos = append([]string{"h2"}, protos...)
+	}
+	return protos
+}
+
+// newHTTPServer returns the net/http server serving the app when HTTP/2 is
+// enabled, nil otherwise. It's stored before the OnListen hooks run, so that
+// a shutdown from then on stops it, even before it serves.
+func (app *App) newHTTPServer(cfg *ListenConfig) *http.Server {
+	if !cfg.EnableHTTP2 {
+		return nil
+	}
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(cfg.EnableH2C)
+
+	srv := &http.Server{
+		Handler:      app.httpHandler(),
+		Protocols:    &protocols,
+		ReadTimeout:  app.config.ReadTimeout,
+		WriteTimeout: app.config.WriteTimeout,
+		IdleTimeout:  app.config.IdleTimeout,
+		ErrorLog:     stdlog.New(io.Discard, "", 0),
+	}
+	if app.config.ReadBufferSize > 0 {
+		srv.MaxHeaderBytes = app.config.ReadBufferSize
+	}
+	srv.SetKeepAlivesEnabled(!app.config.DisableKeepalive)
+
+	app.mutex.Lock()
+	app.httpServer = srv
+	app.mutex.Unlock()
+	return srv
+}
+
+// serveHTTP2 serves ln with net/http, which speaks HTTP/2 with the clients
+// negotiating it over TLS and, with EnableH2C, with the clients sending the
+// HTTP/2 preface over plain connections.
+func serveHTTP2(ln net.Listener, srv *http.Server) error {
+	// a server shut down before it serves closes ln and returns ErrServerClosed
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err //nolint:wrapcheck // the error of net/http is returned as is, like the one of fasthttp
+	}
+	return nil
+}
+
+// httpHandler returns a net/http handler that converts the requests to
+// fasthttp requests, serves them with the handler of the fasthttp server
+// and converts the responses back.
+func (app *App) httpHandler() http.HandlerFunc {
+	handler := app.server.Handler
+	return func(w http.ResponseWriter, r *http.Request) {
+		fctx := &fasthttp.RequestCtx{}
+		fctx.Init2(newHTTPConn(w, r), &disableLogger{}, app.config.ReduceMemoryUsage)
+		if err := readHTTPRequest(&fctx.Request, r, app.server.MaxRequestBodySize); err != nil {
+			app.serverErrorHandler(fctx, err)
+		} else {
+			handler(fctx)
+		}
+
+		if fctx.Hijacked() {
+			// fasthttp only runs the hijack handlers of the connections it serves
+			fctx.Response.Reset()
+			fctx.Error("Connection hijacking requires the fasthttp server", StatusNotImplemented)
+		}
+		app.writeHTTPResponse(w, r, &fctx.Response)
+	}
+}
+
+// readHTTPRequest copies r to req. Bodies larger than limit are rejected
+// with fasthttp.ErrBodyTooLarge, like fasthttp does.
+func readHTTPRequest(req *fasthttp.Request, r *http.Request, limit int) error {
+	req.Header.SetMethod(r.Method)
+	req.Header.SetProtocol(r.Proto)
+	req.SetRequestURI(r.RequestURI)
+	req.Header.SetHost(r.Host)
+	for key, values := range r.Header {
+		if key == HeaderCookie {
+			// HTTP/2 clients may split the cookies into several fields
+			values = []string{strings.Join(values, "; ")}
+		}
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if r.Body == nil {
+		return nil
+	}
+	if limit > 0 && r.ContentLength > int64(limit) {
+		return fasthttp.ErrBodyTooLarge
+	}
+	body := io.Reader(r.Body)
+	if limit > 0 {
+		body = io.LimitReader(r.Body, int64(limit)+1)
+	}
+	n, err := io.Copy(req.BodyWriter(), body)
+	if err != nil {
+		return err //nolint:wrapcheck // handled by the server error handler
+	}
+	if limit > 0 && n > int64(limit) {
+		return fasthttp.ErrBodyTooLarge
+	}
+	if n > 0 {
+		req.Header.SetContentLength(int(n))
+	}
+	return nil
+}
+
+// writeHTTPResponse writes resp to w. Body streams are flushed as they're
+// read, and the values of the declared trailers are sent after the body.
+func (app *App) writeHTTPResponse(w http.ResponseWriter, r *http.Request, resp *fasthttp.Response) {
+	header := w.Header()
+	var trailers []string
+	for trailer := range resp.Header.Trailers() {
+		trailers = append(trailers, string(trailer))
+	}
+	for key, value := range resp.Header.All() {
+		k := string(key)
+		isField := func(field string) bool { return utils.EqualFold(field, k) }
+		if slices.ContainsFunc(httpManagedHeaders, isField) || slices.ContainsFunc(trailers, isField) {
+			continue
+		}
+		header[k] = append(header[k], string(value))
+	}
+	if len(trailers) > 0 {
+		header[HeaderTrailer] = []string{strings.Join(trailers, ", ")}
+	}
+	if _, ok := header[HeaderServer]; !ok && app.config.ServerHeader != "" {
+		header[HeaderServer] = []string{app.config.ServerHeader}
+	}
+	if app.config.DisableDefaultDate {
+		header[HeaderDate] = nil
+	}
+	if resp.ConnectionClose() && r.ProtoMajor == 1 {
+		header[HeaderConnection] = []string{"close"}
+	}
+
+	status := resp.StatusCode()
+	if !resp.IsBodyStream() {
+		body := resp.Body()
+		size := len(body)
+		if r.Method == MethodHead && size == 0 {
+			size = resp.Header.ContentLength()
+		}
+		if size >= 0 && !statusDisallowsBody(status) {
+			header[HeaderContentLength] = []string{strconv.Itoa(size)}
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write(body) //nolint:errcheck // the client is gone
+	} else {
+		if size := resp.Header.ContentLength(); size >= 0 {
+			header[HeaderContentLength] = []string{strconv.Itoa(size)}
+		}
+		w.WriteHeader(status)
+		copyHTTPBodyStream(w, resp.BodyStream())
+		_ = resp.CloseBodyStream() //nolint:errcheck // the body is sent
+	}
+
+	for _, trailer := range trailers {
+		if value := resp.Header.Peek(trailer); len(value) > 0 {
+			header[http.CanonicalHeaderKey(trailer)] = []string{string(value)}
+		}
+	}
+}
+
+// copyHTTPBodyStream copies stream to w, flushing what's read right away so
+// that streamed responses, such as server-sent events, aren't delayed.
+func copyHTTPBodyStream(w http.ResponseWriter, stream io.Reader) {
+	rc := http.NewResponseController(w)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := stream.Read(buf)
+		if n > 0 {
+			if _, writeErr := w.Write(buf[:n]); writeErr != nil {
+				return
+			}
+			if flushErr := rc.Flush(); flushErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// httpConn stands in for the connection of the requests served by net/http,
+// which owns the actual connection. Reads and writes fail.
+type httpConn struct {
+	ctx    context.Context //nolint:containedctx // canceled when the client disconnects, see watchDisconnect
+	w      http.ResponseWriter
+	local  net.Addr
+	remote net.Addr
+}
+
+// httpTLSConn is the httpConn of the requests received over TLS, so that
+// fasthttp.RequestCtx.IsTLS and TLSConnectionState work.
+type httpTLSConn struct {
+	*httpConn
+	state tls.ConnectionState
+}
+
+func newHTTPConn(w http.ResponseWriter, r *http.Request) net.Conn {
+	conn := &httpConn{ctx: r.Context(), w: w}
+	if local, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		conn.local = local
+	}
+	if remote, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		conn.remote = net.TCPAddrFromAddrPort(remote)
+	}
+	if r.TLS != nil {
+		return &httpTLSConn{httpConn: conn, state: *r.TLS}
+	}
+	return conn
+}
+
+// requestContext returns the context of the net/http request, which is
+// canceled when the client disconnects or the request is served.
+func (c *httpConn) requestContext() context.Context {
+	return c.ctx
+}
+
+// earlyHints sends a 103 Early Hints response with the Link headers.
+func (c *httpConn) earlyHints(links []string) error {
+	header := c.w.Header()
+	header[HeaderLink] = links
+	c.w.WriteHeader(StatusEarlyHints)
+	delete(header, HeaderLink)
+	return nil
+}
+
+func (*httpConn) Read([]byte) (int, error) {
+	return 0, errHTTPConn
+}
+
+func (*httpConn) Write([]byte) (int, error) {
+	return 0, errHTTPConn
+}
+
+func (*httpConn) Close() error {
+	return nil
+}
+
+func (c *httpConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *httpConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (*httpConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (*httpConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (*httpConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (*httpTLSConn) Handshake() error {
+	return nil
+}
+
+func (c *httpTLSConn) ConnectionState() tls.ConnectionState {
+	return c.state
+}
@@ -0,0 +1,277 @@
+package fiber
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// listenAsync serves app with cfg and returns its address once it listens.
+func listenAsync(t *testing.T, app *App, cfg ListenConfig) string {
+	t.Helper()
+
+	addrs := make(chan string, 1)
+	served := make(chan error, 1)
+	cfg.DisableStartupMessage = true
+	cfg.ListenerAddrFunc = func(addr net.Addr) {
+		addrs <- addr.String()
+	}
+	go func() {
+		served <- app.Listen("127.0.0.1:0", cfg)
+	}()
+
+	select {
+	case addr := <-addrs:
+		t.Cleanup(func() {
+			require.NoError(t, app.Shutdown())
+			require.NoError(t, <-served)
+		})
+		return addr
+	case err := <-served:
+		t.Fatalf("listen: %v", err)
+		return ""
+	}
+}
+
+func http2Client(protocols func(p *http.Protocols)) *http.Client {
+	var p http.Protocols
+	protocols(&p)
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			Protocols:       &p,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // self-signed test certificate
+		},
+	}
+}
+
+// go test -run Test_Listen_HTTP2
+func Test_Listen_HTTP2(t *testing.T) {
+	app := New(Config{ServerHeader: "Fiber"})
+	app.Post("/users/:id", func(c Ctx) error {
+		c.Cookie(&Cookie{Name: "session", Value: "abc"})
+		return c.JSON(Map{
+			"protocol": c.Protocol(),
+			"scheme":   c.Scheme(),
+			"tls":      c.RequestCtx().TLSConnectionState() != nil,
+			"id":       c.Params("id"),
+			"query":    c.Query("q"),
+			"cookies":  c.Cookies("a") + c.Cookies("b"),
+			"body":     string(c.Body()),
+			"ip":       c.IP(),
+		})
+	})
+	app.Get("/stream", func(c Ctx) error {
+		if err := c.Trailer("X-Checksum"); err != nil {
+			return err
+		}
+		return c.SendStreamFunc(func(w *StreamWriter) {
+			_, _ = w.WriteString("chunk") //nolint:errcheck // tested by the client
+			_ = w.Flush()                 //nolint:errcheck // tested by the client
+			_ = w.SetTrailer("X-Checksum", "42")
+		})
+	})
+	addr := listenAsync(t, app, ListenConfig{
+		EnableHTTP2: true,
+		CertFile:    "./.github/testdata/ssl.pem",
+		CertKeyFile: "./.github/testdata/ssl.key",
+	})
+
+	client := http2Client(func(p *http.Protocols) {
+		p.SetHTTP2(true)
+	})
+
+	req, err := http.NewRequest(MethodPost, "https://"+addr+"/users/7?q=fiber", strings.NewReader("payload"))
+	require.NoError(t, err)
+	req.Header.Add(HeaderCookie, "a=1")
+	req.Header.Add(HeaderCookie, "b=2")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Equal(t, 2, resp.ProtoMajor)
+	require.Equal(t, StatusOK, resp.StatusCode)
+	require.Equal(t, MIMEApplicationJSONCharsetUTF8, resp.Header.Get(HeaderContentType))
+	require.Equal(t, "Fiber", resp.Header.Get(HeaderServer))
+	require.Equal(t, "session=abc; path=/; SameSite=Lax", resp.Header.Get(HeaderSetCookie))
+	require.Equal(t, int64(len(body)), resp.ContentLength)
+	require.JSONEq(t, `{"protocol":"HTTP/2.0","scheme":"https","tls":true,"id":"7","query":"fiber","cookies":"12","body":"payload","ip":"127.0.0.1"}`, string(body))
+
+	resp, err = client.Get("https://" + addr + "/stream")
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "chunk", string(body))
+	require.Equal(t, "42", resp.Trailer.Get("X-Checksum"))
+
+	// HTTP/1.1 clients are still served
+	client = http2Client(func(p *http.Protocols) {
+		p.SetHTTP1(true)
+	})
+	resp, err = client.Get("https://" + addr + "/stream")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, 1, resp.ProtoMajor)
+	require.Equal(t, StatusOK, resp.StatusCode)
+}
+
+// go test -run Test_Listen_H2C
+func Test_Listen_H2C(t *testing.T) {
+	var hooks []string
+	app := New(Config{BodyLimit: 4})
+	app.Hooks().OnListen(func(ListenData) error {
+		hooks = append(hooks, "listen")
+		return nil
+	})
+	app.Hooks().OnPostShutdown(func(error) error {
+		hooks = append(hooks, "shutdown")
+		return nil
+	})
+	app.Get("/", func(c Ctx) error {
+		return c.SendString(c.Protocol() + " " + c.Scheme())
+	})
+	app.Post("/", func(c Ctx) error {
+		return c.Send(c.Body())
+	})
+	app.Get("/hijack", func(c Ctx) error {
+		c.RequestCtx().Hijack(func(net.Conn) {})
+		return nil
+	})
+
+	served := make(chan error, 1)
+	addrs := make(chan string, 1)
+	go func() {
+		served <- app.Listen("127.0.0.1:0", ListenConfig{
+			EnableH2C:             true,
+			DisableStartupMessage: true,
+			ListenerAddrFunc: func(addr net.Addr) {
+				addrs <- addr.String()
+			},
+		})
+	}()
+	addr := <-addrs
+
+	client := http2Client(func(p *http.Protocols) {
+		p.SetUnencryptedHTTP2(true)
+	})
+	resp, err := client.Get("http://" + addr)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, 2, resp.ProtoMajor)
+	require.Equal(t, "HTTP/2.0 http", string(body))
+
+	resp, err = client.Post("http://"+addr, MIMETextPlain, strings.NewReader("too large"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp, err = client.Get("http://" + addr + "/hijack")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, StatusNotImplemented, resp.StatusCode)
+
+	require.NoError(t, app.ShutdownWithTimeout(time.Second))
+	require.NoError(t, <-served)
+	require.Equal(t, []string{"listen", "shutdown"}, hooks)
+
+	require.ErrorIs(t, New().Listen(":0", ListenConfig{EnableH2C: true, EnablePrefork: true}), ErrPreforkWithHTTP2)
+}
+
+// go test -run Test_Listen_HTTP2_EarlyHints
+func Test_Listen_HTTP2_EarlyHints(t *testing.T) {
+	app := New()
+	app.Get("/", func(c Ctx) error {
+		if err := c.SendEarlyHints([]string{"</style.css>; rel=preload; as=style"}); err != nil {
+			return err
+		}
+		return c.SendString("done")
+	})
+	addr := listenAsync(t, app, ListenConfig{EnableH2C: true})
+
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			require.Equal(t, StatusEarlyHints, code)
+			hints = append(hints, header.Values(HeaderLink)...)
+			return nil
+		},
+	}
+	client := http2Client(func(p *http.Protocols) {
+		p.SetUnencryptedHTTP2(true)
+	})
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), MethodGet, "http://"+addr, http.NoBody)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Equal(t, "done", string(body))
+	require.Equal(t, []string{"</style.css>; rel=preload; as=style"}, hints)
+	require.Equal(t, []string{"</style.css>; rel=preload; as=style"}, resp.Header.Values(HeaderLink))
+}
+
+// go test -run Test_Listen_HTTP2_CancelOnDisconnect
+func Test_Listen_HTTP2_CancelOnDisconnect(t *testing.T) {
+	canceled := make(chan error, 1)
+	app := New(Config{CancelOnDisconnect: true})
+	app.Get("/", func(c Ctx) error {
+		<-c.Context().Done()
+		canceled <- context.Cause(c.Context())
+		return nil
+	})
+	addr := listenAsync(t, app, ListenConfig{EnableHTTP2: true})
+
+	conn, err := net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: " + addr + "\r\n\r\n"))
+	require.NoError(t, err)
+	// wait for the request to be read before disconnecting
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, err = bufio.NewReader(conn).ReadByte()
+	require.Error(t, err)
+	require.NoError(t, conn.Close())
+
+	select {
+	case err := <-canceled:
+		require.ErrorIs(t, err, ErrClientDisconnected)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request context wasn't canceled")
+	}
+}
+
+// go test -run Test_Listen_HTTP2_ShutdownBeforeServe
+func Test_Listen_HTTP2_ShutdownBeforeServe(t *testing.T) {
+	app := New()
+	app.Hooks().OnListen(func(ListenData) error {
+		// the app isn't served yet
+		return app.Shutdown()
+	})
+
+	served := make(chan error, 1)
+	go func() {
+		served <- app.Listen("127.0.0.1:0", ListenConfig{DisableStartupMessage: true, EnableHTTP2: true})
+	}()
+	select {
+	case err := <-served:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the shutdown didn't stop the net/http server")
+	}
+}
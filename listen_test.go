@@ -864,6 +864,30 @@ func Test_StartupMessageCustomization(t *testing.T) {
 	require.False(t, post.Prevented)
 }
 
+// go test -run Test_StartupMessageRouteIssues
+func Test_StartupMessageRouteIssues(t *testing.T) {
+	cfg := ListenConfig{}
+	app := New()
+	app.Get("/users/:id", emptyHandler)
+	app.Get("/users/me", emptyHandler)
+	require.NoError(t, app.startupProcess())
+
+	var keys []string
+	app.Hooks().OnPreStartupMessage(func(data *PreStartupMessageData) error {
+		keys = data.EntryKeys()
+		return nil
+	})
+
+	listenData := app.prepareListenData(":8080", false, &cfg, nil)
+	startupMessage := captureOutput(func() {
+		app.startupMessage(listenData, &cfg)
+	})
+
+	require.Contains(t, keys, "route_issue_0")
+	require.Contains(t, startupMessage, "WARN")
+	require.Contains(t, startupMessage, "Shadowed route: \tGET /users/me: never reached, /users/:id registered before matches all its paths")
+}
+
 func Test_StartupMessageDisabledPostHook(t *testing.T) {
 	cfg := ListenConfig{DisableStartupMessage: true}
 	app := New()
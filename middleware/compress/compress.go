@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/httpdigest"
 	"github.com/gofiber/fiber/v3/middleware/etag"
 	"github.com/gofiber/utils/v2"
 	"github.com/valyala/fasthttp"
@@ -40,6 +41,14 @@ func shouldSkip(c fiber.Ctx) bool {
 		return true
 	}
 
+	// Trailers are computed over the stream as it's sent, e.g. its
+	// Content-Digest, so the stream must not be compressed
+	if c.Response().IsBodyStream() {
+		for range c.Response().Header.Trailers() {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -126,6 +135,17 @@ func New(config ...Config) fiber.Handler {
 			}
 		}
 
+		// The Content-Digest of the uncompressed body doesn't match anymore
+		if field := c.GetRespHeader(fiber.HeaderContentDigest); field != "" && c.GetRespHeader(fiber.HeaderContentEncoding) != "" {
+			if c.Response().IsBodyStream() {
+				c.Response().Header.Del(fiber.HeaderContentDigest)
+			} else if value, err := httpdigest.Recompute(field, c.Response().Body()); err == nil {
+				c.Set(fiber.HeaderContentDigest, value)
+			} else {
+				c.Response().Header.Del(fiber.HeaderContentDigest)
+			}
+		}
+
 		appendVaryAcceptEncoding(c)
 
 		return nil
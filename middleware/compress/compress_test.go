@@ -1,6 +1,7 @@
 package compress
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"errors"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/digest"
 	"github.com/gofiber/fiber/v3/middleware/etag"
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
@@ -957,3 +959,56 @@ func Test_Compress_Streaming_With_Compression(t *testing.T) {
 	require.NoError(t, err)
 	require.Less(t, len(body), len(filedata), "Compressed size should be smaller than original")
 }
+
+func Test_Compress_Content_Digest(t *testing.T) {
+	t.Parallel()
+
+	digestFirst := fiber.New()
+	digestFirst.Use(digest.New(digest.Config{ReprDigest: true}), New())
+	compressFirst := fiber.New()
+	compressFirst.Use(New(), digest.New(digest.Config{ReprDigest: true}))
+
+	for name, app := range map[string]*fiber.App{"digest first": digestFirst, "compress first": compressFirst} {
+		app.Get("/", func(c fiber.Ctx) error {
+			return c.Send(filedata)
+		})
+
+		req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set(fiber.HeaderWantContentDigest, "sha-512=3")
+
+		resp, err := app.Test(req, testConfig)
+		require.NoError(t, err, name)
+		require.Equal(t, "gzip", resp.Header.Get(fiber.HeaderContentEncoding), name)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, name)
+
+		// Content-Digest covers the compressed body, Repr-Digest the original one
+		require.NoError(t, digest.Verify(resp.Header.Get(fiber.HeaderContentDigest), body, digest.SHA512), name)
+		require.NoError(t, digest.Verify(resp.Header.Get(fiber.HeaderReprDigest), filedata, digest.SHA256), name)
+	}
+}
+
+func Test_Compress_Skip_Stream_With_Trailers(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+
+	app.Use(digest.New(), New())
+
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStreamWriter(func(w *bufio.Writer) {
+			w.Write(filedata) //nolint:errcheck // test stream
+		})
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := app.Test(req, testConfig)
+	require.NoError(t, err)
+	require.Empty(t, resp.Header.Get(fiber.HeaderContentEncoding))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, filedata, body)
+	require.NoError(t, digest.Verify(resp.Trailer.Get(fiber.HeaderContentDigest), body))
+}
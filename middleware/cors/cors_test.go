@@ -596,6 +596,35 @@ func Test_CORS_Next(t *testing.T) {
 	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
 }
 
+// go test -run Test_CORS_AutoOptions
+func Test_CORS_AutoOptions(t *testing.T) {
+	t.Parallel()
+	app := fiber.New(fiber.Config{EnableAutoOptions: true})
+	app.Use(New(Config{AllowOrigins: []string{"https://example.com"}}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	// preflight requests are answered by the middleware
+	req := httptest.NewRequest(fiber.MethodOptions, "/", http.NoBody)
+	req.Header.Set(fiber.HeaderOrigin, "https://example.com")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, fiber.MethodGet)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "https://example.com", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	require.NotEmpty(t, resp.Header.Get(fiber.HeaderAccessControlAllowMethods))
+	require.Empty(t, resp.Header.Get(fiber.HeaderAllow))
+
+	// other OPTIONS requests fall through to the router
+	req = httptest.NewRequest(fiber.MethodOptions, "/", http.NoBody)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "GET, HEAD, OPTIONS", resp.Header.Get(fiber.HeaderAllow))
+	require.Empty(t, resp.Header.Get(fiber.HeaderAccessControlAllowMethods))
+}
+
 // go test -run Test_CORS_Headers_BasedOnRequestType
 func Test_CORS_Headers_BasedOnRequestType(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,65 @@
+package digest
+
+import (
+	"github.com/gofiber/fiber/v3"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// Algorithms lists the digest algorithms that are accepted in requests
+	// and used for responses, in order of preference. Responses use the
+	// first one unless the client asks for another one with
+	// Want-Content-Digest or Want-Repr-Digest.
+	//
+	// Optional. Default: []string{SHA256, SHA512}
+	Algorithms []string
+
+	// RequireDigest rejects requests with a body but without a
+	// Content-Digest or Repr-Digest of an accepted algorithm with
+	// 400 Bad Request and a Want-Content-Digest header listing them.
+	//
+	// Optional. Default: false
+	RequireDigest bool
+
+	// ReprDigest adds a Repr-Digest to all responses, not only to those
+	// whose request has a Want-Repr-Digest header.
+	//
+	// Optional. Default: false
+	ReprDigest bool
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:          nil,
+	Algorithms:    []string{SHA256, SHA512},
+	RequireDigest: false,
+	ReprDigest:    false,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = ConfigDefault.Algorithms
+	}
+	for _, algorithm := range cfg.Algorithms {
+		if !Supported(algorithm) {
+			panic("digest: unsupported algorithm " + algorithm)
+		}
+	}
+
+	return cfg
+}
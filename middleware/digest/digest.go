@@ -0,0 +1,205 @@
+package digest
+
+import (
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/httpdigest"
+	"github.com/valyala/fasthttp"
+)
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+
+	want := Want(cfg.Algorithms...)
+
+	// Return new handler
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// Reject requests whose body doesn't match their digest
+		if err := verifyRequest(c, &cfg, want); err != nil {
+			return err
+		}
+
+		contentAlgorithm := negotiate(c.Get(fiber.HeaderWantContentDigest), cfg.Algorithms, cfg.Algorithms[0])
+		reprAlgorithm := ""
+		if cfg.ReprDigest {
+			reprAlgorithm = cfg.Algorithms[0]
+		}
+		reprAlgorithm = negotiate(c.Get(fiber.HeaderWantReprDigest), cfg.Algorithms, reprAlgorithm)
+		if contentAlgorithm == "" && reprAlgorithm == "" {
+			return c.Next()
+		}
+
+		// Streams of unknown size are chunked, so their digests are sent as
+		// trailers once the whole body is read
+		c.WrapBodyStream(func(stream io.Reader, size int) io.Reader {
+			if size >= 0 {
+				return nil
+			}
+			return newDigestReader(c, stream, contentAlgorithm, reprAlgorithm)
+		})
+
+		// Return err if next handler returns one
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		if skipResponse(c) {
+			return nil
+		}
+		return setDigests(c, contentAlgorithm, reprAlgorithm)
+	}
+}
+
+// verifyRequest checks the Content-Digest of the request against its body
+// and the Repr-Digest against its decoded body.
+func verifyRequest(c fiber.Ctx, cfg *Config, want string) error {
+	verified := false
+
+	if field := c.Get(fiber.HeaderContentDigest); field != "" {
+		ok, err := verifyField(fiber.HeaderContentDigest, field, c.Request().Body(), cfg.Algorithms)
+		if err != nil {
+			return err
+		}
+		verified = ok
+	}
+
+	if field := c.Get(fiber.HeaderReprDigest); field != "" {
+		body, err := c.Request().BodyUncompressedWithLimit(c.App().Config().BodyLimit)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid "+fiber.HeaderReprDigest+": "+err.Error())
+		}
+		ok, err := verifyField(fiber.HeaderReprDigest, field, body, cfg.Algorithms)
+		if err != nil {
+			return err
+		}
+		verified = verified || ok
+	}
+
+	if !verified && cfg.RequireDigest && len(c.Request().Body()) > 0 {
+		c.Set(fiber.HeaderWantContentDigest, want)
+		return fiber.NewError(fiber.StatusBadRequest, "missing "+fiber.HeaderContentDigest+" with one of the algorithms of "+fiber.HeaderWantContentDigest)
+	}
+
+	return nil
+}
+
+// verifyField verifies a digest field of the request. It reports false if
+// the field has no digest with an accepted algorithm.
+func verifyField(name, field string, content []byte, algorithms []string) (bool, error) {
+	err := Verify(field, content, algorithms...)
+	if errors.Is(err, ErrUnsupported) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fiber.NewError(fiber.StatusBadRequest, "invalid "+name+": "+err.Error())
+	}
+	return true, nil
+}
+
+// negotiate returns the algorithm for a response digest field, fallback
+// if the request has no Want-Content-Digest or Want-Repr-Digest header.
+func negotiate(want string, algorithms []string, fallback string) string {
+	if want == "" {
+		return fallback
+	}
+	algorithm, _ := Negotiate(want, algorithms)
+	return algorithm
+}
+
+// skipResponse reports whether the response has no content to digest.
+func skipResponse(c fiber.Ctx) bool {
+	if c.Method() == fiber.MethodHead || c.Response().IsBodyStream() {
+		return true
+	}
+	status := c.Response().StatusCode()
+	return status < fiber.StatusOK || status == fiber.StatusNoContent || status == fiber.StatusNotModified
+}
+
+// setDigests sets the digest fields of a response with a body in memory,
+// unless the handler set them already. Content-Digest covers the body as
+// sent, Repr-Digest the body before its Content-Encoding.
+func setDigests(c fiber.Ctx, contentAlgorithm, reprAlgorithm string) error {
+	resp := c.Response()
+	if contentAlgorithm != "" && len(resp.Header.Peek(fiber.HeaderContentDigest)) == 0 {
+		value, err := Compute(resp.Body(), contentAlgorithm)
+		if err != nil {
+			return err
+		}
+		resp.Header.Set(fiber.HeaderContentDigest, value)
+	}
+	if reprAlgorithm != "" && len(resp.Header.Peek(fiber.HeaderReprDigest)) == 0 {
+		body, err := resp.BodyUncompressed()
+		if err != nil {
+			// the representation can't be decoded to digest it
+			return nil //nolint:nilerr // the response is sent without Repr-Digest
+		}
+		value, err := Compute(body, reprAlgorithm)
+		if err != nil {
+			return err
+		}
+		resp.Header.Set(fiber.HeaderReprDigest, value)
+	}
+	return nil
+}
+
+// digestReader computes the digests of a body stream and sets them as
+// trailers once the stream is read.
+type digestReader struct {
+	io.Reader
+	header *fasthttp.ResponseHeader
+	fields []digestField
+}
+
+type digestField struct {
+	hash      hash.Hash
+	name      string
+	algorithm string
+}
+
+// newDigestReader declares the digest trailers of a stream and returns the
+// reader computing them, nil if there's nothing to digest.
+func newDigestReader(c fiber.Ctx, stream io.Reader, contentAlgorithm, reprAlgorithm string) io.Reader {
+	// the response outlives c, which is released before the stream is sent
+	header := &c.Response().Header
+	var fields []digestField
+	if contentAlgorithm != "" && len(header.Peek(fiber.HeaderContentDigest)) == 0 {
+		fields = append(fields, digestField{name: fiber.HeaderContentDigest, algorithm: contentAlgorithm})
+	}
+	// an encoded stream would have to be decoded for its Repr-Digest
+	if reprAlgorithm != "" && len(header.Peek(fiber.HeaderReprDigest)) == 0 && len(header.ContentEncoding()) == 0 {
+		fields = append(fields, digestField{name: fiber.HeaderReprDigest, algorithm: reprAlgorithm})
+	}
+	for i := range fields {
+		if err := c.Trailer(fields[i].name); err != nil {
+			return nil
+		}
+		fields[i].hash = httpdigest.NewHash(fields[i].algorithm)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return &digestReader{Reader: stream, header: header, fields: fields}
+}
+
+func (r *digestReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	for _, field := range r.fields {
+		field.hash.Write(p[:n]) //nolint:errcheck // hashes never fail
+	}
+	if errors.Is(err, io.EOF) {
+		for _, field := range r.fields {
+			r.header.Set(field.name, httpdigest.Format([]string{field.algorithm}, []hash.Hash{field.hash}))
+		}
+	}
+	return n, err //nolint:wrapcheck // io.EOF must be returned as is
+}
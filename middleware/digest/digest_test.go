@@ -0,0 +1,283 @@
+package digest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Field(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+func sha512Field(content []byte) string {
+	sum := sha512.Sum512(content)
+	return "sha-512=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+func digestRequest(t *testing.T, app *fiber.App, req *http.Request) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+// go test -run Test_Digest_Next
+func Test_Digest_Next(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+	app.Use(New(Config{
+		Next: func(_ fiber.Ctx) bool {
+			return true
+		},
+	}))
+	app.Post("/", func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader("body"))
+	req.Header.Set(fiber.HeaderContentDigest, sha256Field([]byte("other")))
+	resp, _ := digestRequest(t, app, req)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Empty(t, resp.Header.Get(fiber.HeaderContentDigest))
+}
+
+// go test -run Test_Digest_Config
+func Test_Digest_Config(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []string{SHA256, SHA512}, configDefault(Config{}).Algorithms)
+	require.PanicsWithValue(t, "digest: unsupported algorithm md5", func() {
+		New(Config{Algorithms: []string{"md5"}})
+	})
+}
+
+// go test -run Test_Digest_Request
+func Test_Digest_Request(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+	app.Use(New(Config{Algorithms: []string{SHA512, SHA256}}))
+	app.Post("/", func(c fiber.Ctx) error {
+		return c.Send(c.Body())
+	})
+
+	body := []byte(`{"amount":100}`)
+	post := func(headers ...string) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodPost, "/", bytes.NewReader(body))
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, respBody := digestRequest(t, app, req)
+		return resp, string(respBody)
+	}
+
+	for _, field := range []string{sha256Field(body), sha512Field(body), sha256Field(body) + ", " + sha512Field(body), "md5=:AAAA:"} {
+		resp, _ := post(fiber.HeaderContentDigest, field)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode, field)
+	}
+
+	resp, msg := post(fiber.HeaderContentDigest, sha256Field([]byte(`{"amount":999}`)))
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, "invalid Content-Digest: digest doesn't match the content: sha-256", msg)
+
+	resp, msg = post(fiber.HeaderContentDigest, sha256Field(body)+", "+sha512Field(nil))
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, "invalid Content-Digest: digest doesn't match the content: sha-512", msg)
+
+	resp, msg = post(fiber.HeaderContentDigest, "sha-256=abc")
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, "invalid Content-Digest: malformed digest field", msg)
+
+	// Repr-Digest covers the decoded body
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write([]byte("decoded"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	for _, tc := range []struct {
+		field  string
+		status int
+	}{
+		{field: sha256Field([]byte("decoded")), status: fiber.StatusOK},
+		{field: sha256Field(compressed.Bytes()), status: fiber.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(fiber.MethodPost, "/", bytes.NewReader(compressed.Bytes()))
+		req.Header.Set(fiber.HeaderContentEncoding, "gzip")
+		req.Header.Set(fiber.HeaderReprDigest, tc.field)
+		resp, _ := digestRequest(t, app, req)
+		require.Equal(t, tc.status, resp.StatusCode)
+	}
+}
+
+// go test -run Test_Digest_RequireDigest
+func Test_Digest_RequireDigest(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+	app.Use(New(Config{RequireDigest: true}))
+	app.All("/", func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	for _, field := range []string{"", "md5=:AAAA:"} {
+		req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader("body"))
+		if field != "" {
+			req.Header.Set(fiber.HeaderContentDigest, field)
+		}
+		resp, body := digestRequest(t, app, req)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, field)
+		require.Equal(t, "sha-256=10, sha-512=9", resp.Header.Get(fiber.HeaderWantContentDigest), field)
+		require.Equal(t, "missing Content-Digest with one of the algorithms of Want-Content-Digest", string(body))
+	}
+
+	req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader("body"))
+	req.Header.Set(fiber.HeaderReprDigest, sha256Field([]byte("body")))
+	resp, _ := digestRequest(t, app, req)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// requests without a body need no digest
+	resp, _ = digestRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+// go test -run Test_Digest_Response
+func Test_Digest_Response(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+	app.Use(New())
+	app.All("/", func(c fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+	app.Get("/custom", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderContentDigest, "sha-256=:custom:")
+		return c.SendString("Hello, World!")
+	})
+	app.Get("/empty", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	content := []byte("Hello, World!")
+	for _, tc := range []struct {
+		wantContent string
+		wantRepr    string
+		content     string
+		repr        string
+	}{
+		{content: sha256Field(content)},
+		{wantContent: "sha-512=5, sha-256=2", content: sha512Field(content)},
+		{wantContent: "sha-256=0"},
+		{wantContent: "sha-256=0", wantRepr: "sha-512=1", repr: sha512Field(content)},
+		{wantRepr: "sha-256=1", content: sha256Field(content), repr: sha256Field(content)},
+	} {
+		req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+		if tc.wantContent != "" {
+			req.Header.Set(fiber.HeaderWantContentDigest, tc.wantContent)
+		}
+		if tc.wantRepr != "" {
+			req.Header.Set(fiber.HeaderWantReprDigest, tc.wantRepr)
+		}
+		resp, body := digestRequest(t, app, req)
+		require.Equal(t, "Hello, World!", string(body))
+		require.Equal(t, tc.content, resp.Header.Get(fiber.HeaderContentDigest), tc)
+		require.Equal(t, tc.repr, resp.Header.Get(fiber.HeaderReprDigest), tc)
+	}
+
+	resp, _ := digestRequest(t, app, httptest.NewRequest(fiber.MethodHead, "/", http.NoBody))
+	require.Empty(t, resp.Header.Get(fiber.HeaderContentDigest))
+	resp, _ = digestRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/empty", http.NoBody))
+	require.Empty(t, resp.Header.Get(fiber.HeaderContentDigest))
+	resp, _ = digestRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/custom", http.NoBody))
+	require.Equal(t, "sha-256=:custom:", resp.Header.Get(fiber.HeaderContentDigest))
+
+	repr := fiber.New()
+	repr.Use(New(Config{ReprDigest: true}))
+	repr.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("Hello, World!")
+	})
+	resp, _ = digestRequest(t, repr, httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.Equal(t, sha256Field(content), resp.Header.Get(fiber.HeaderReprDigest))
+}
+
+// go test -run Test_Digest_Stream
+func Test_Digest_Stream(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+	app.Use(New(Config{ReprDigest: true}))
+	app.Get("/writer", func(c fiber.Ctx) error {
+		return c.SendStreamWriter(func(w *bufio.Writer) {
+			for _, chunk := range []string{"hello ", "streaming ", "world"} {
+				if _, err := w.WriteString(chunk); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		})
+	})
+	app.Get("/func", func(c fiber.Ctx) error {
+		if err := c.Trailer("Grpc-Status"); err != nil {
+			return err
+		}
+		return c.SendStreamFunc(func(w *fiber.StreamWriter) {
+			if _, err := w.WriteString("hello streaming world"); err != nil {
+				return
+			}
+			_ = w.SetTrailer("Grpc-Status", "0") //nolint:errcheck // declared above
+		})
+	})
+	app.Get("/sized", func(c fiber.Ctx) error {
+		return c.SendStream(strings.NewReader("hello streaming world"), len("hello streaming world"))
+	})
+
+	content := []byte("hello streaming world")
+	for _, target := range []string{"/writer", "/func"} {
+		resp, body := digestRequest(t, app, httptest.NewRequest(fiber.MethodGet, target, http.NoBody))
+		require.Equal(t, string(content), string(body), target)
+		require.Empty(t, resp.Header.Get(fiber.HeaderContentDigest), target)
+		require.Equal(t, sha256Field(content), resp.Trailer.Get(fiber.HeaderContentDigest), target)
+		require.Equal(t, sha256Field(content), resp.Trailer.Get(fiber.HeaderReprDigest), target)
+		if target == "/func" {
+			require.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+		}
+	}
+
+	// streams of known size are sent without digests
+	resp, body := digestRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/sized", http.NoBody))
+	require.Equal(t, string(content), string(body))
+	require.Empty(t, resp.Header.Get(fiber.HeaderContentDigest))
+	require.Empty(t, resp.Trailer)
+}
+
+// go test -run Test_Digest_Range
+func Test_Digest_Range(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+	app.Use(New(Config{ReprDigest: true}))
+	app.Get("/", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderAcceptRanges, "bytes")
+		return c.SendString("0123456789")
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", http.NoBody)
+	req.Header.Set(fiber.HeaderRange, "bytes=2-5")
+	resp, body := digestRequest(t, app, req)
+	require.Equal(t, fiber.StatusPartialContent, resp.StatusCode)
+	require.Equal(t, "2345", string(body))
+	// Content-Digest would cover the whole content, not the range sent
+	require.Empty(t, resp.Header.Get(fiber.HeaderContentDigest))
+	require.Equal(t, sha256Field([]byte("0123456789")), resp.Header.Get(fiber.HeaderReprDigest))
+}
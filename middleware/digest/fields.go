@@ -0,0 +1,65 @@
+package digest
+
+import (
+	"github.com/gofiber/fiber/v3/internal/httpdigest"
+)
+
+// Digest algorithms of the IANA Hash Algorithms for HTTP Digest Fields
+// registry that are supported.
+const (
+	SHA256 = httpdigest.SHA256
+	SHA512 = httpdigest.SHA512
+)
+
+// Errors returned by Verify.
+var (
+	// ErrMalformed is returned for digest fields that aren't structured field
+	// dictionaries of byte sequences.
+	ErrMalformed = httpdigest.ErrMalformed
+	// ErrMismatch is returned when a digest doesn't match the content.
+	ErrMismatch = httpdigest.ErrMismatch
+	// ErrUnsupported is returned when a digest field has no digest with a
+	// supported algorithm.
+	ErrUnsupported = httpdigest.ErrUnsupported
+)
+
+// Supported reports whether the algorithm is supported.
+func Supported(algorithm string) bool {
+	return httpdigest.Supported(algorithm)
+}
+
+// Compute returns a Content-Digest or Repr-Digest field value with the
+// digests of content for the given algorithms, SHA256 if none are given.
+func Compute(content []byte, algorithms ...string) (string, error) {
+	return httpdigest.Compute(content, algorithms...) //nolint:wrapcheck // the errors are ours
+}
+
+// Recompute returns a field value with the digests of content for the
+// supported algorithms of field, e.g. after the content was transformed. It
+// returns ErrMalformed or ErrUnsupported if field has no supported digest.
+func Recompute(field string, content []byte) (string, error) {
+	return httpdigest.Recompute(field, content) //nolint:wrapcheck // the errors are ours
+}
+
+// Verify checks the digests of a Content-Digest or Repr-Digest field value
+// against content. Only the digests with the given algorithms, all supported
+// ones if none are given, are checked, and at least one of them must be
+// present. It returns ErrMalformed, an error wrapping ErrMismatch naming the
+// algorithm or ErrUnsupported.
+func Verify(field string, content []byte, algorithms ...string) error {
+	return httpdigest.Verify(field, content, algorithms...) //nolint:wrapcheck // the errors are ours
+}
+
+// Negotiate returns the algorithm a Want-Content-Digest or Want-Repr-Digest
+// field value prefers among algorithms, which are ordered by the preference
+// of the server. It returns false if none of them is acceptable, or if the
+// field is malformed.
+func Negotiate(want string, algorithms []string) (string, bool) {
+	return httpdigest.Negotiate(want, algorithms)
+}
+
+// Want returns a Want-Content-Digest or Want-Repr-Digest field value that
+// asks for the algorithms in order of preference.
+func Want(algorithms ...string) string {
+	return httpdigest.Want(algorithms...)
+}
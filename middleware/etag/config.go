@@ -19,12 +19,32 @@ type Config struct {
 	// when byte range requests are used, but strong etags mean range
 	// requests can still be cached.
 	Weak bool
+
+	// CurrentETag returns the current ETag of the resource targeted by a PUT,
+	// PATCH or DELETE request, empty if it doesn't exist. When set, these
+	// requests are evaluated with c.CheckPreconditions before the next
+	// handlers run, and rejected with 412 Precondition Failed when their
+	// If-Match or If-None-Match header doesn't hold, e.g. because the
+	// resource changed since the client read it. If-Match uses the strong
+	// comparison, so weak ETags never match it.
+	//
+	// Optional. Default: nil
+	CurrentETag func(c fiber.Ctx) (string, error)
+
+	// RequireIfMatch rejects PUT, PATCH and DELETE requests without an
+	// If-Match header with 428 Precondition Required, so that clients can't
+	// overwrite changes they haven't seen. Requires CurrentETag.
+	//
+	// Optional. Default: false
+	RequireIfMatch bool
 }
 
 // ConfigDefault is the default config
 var ConfigDefault = Config{
-	Weak: false,
-	Next: nil,
+	Weak:           false,
+	Next:           nil,
+	CurrentETag:    nil,
+	RequireIfMatch: false,
 }
 
 // Helper function to set default values
@@ -38,6 +58,9 @@ func configDefault(config ...Config) Config {
 	cfg := config[0]
 
 	// Set default values
+	if cfg.RequireIfMatch && cfg.CurrentETag == nil {
+		panic("etag: RequireIfMatch requires CurrentETag")
+	}
 
 	return cfg
 }
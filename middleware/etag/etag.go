@@ -5,6 +5,7 @@ import (
 	"hash/crc32"
 	"math"
 	"slices"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/utils/v2"
@@ -55,6 +56,13 @@ func New(config ...Config) fiber.Handler {
 			return c.Next()
 		}
 
+		// Reject writes based on a stale representation
+		if cfg.CurrentETag != nil && isWriteMethod(c.Method()) {
+			if err := checkWritePreconditions(c, &cfg); err != nil {
+				return err
+			}
+		}
+
 		// Return err if next handler returns one
 		if err := c.Next(); err != nil {
 			return err
@@ -102,6 +110,28 @@ func New(config ...Config) fiber.Handler {
 	}
 }
 
+// isWriteMethod reports whether the method replaces, modifies or deletes the
+// target resource.
+func isWriteMethod(method string) bool {
+	return method == fiber.MethodPut || method == fiber.MethodPatch || method == fiber.MethodDelete
+}
+
+// checkWritePreconditions evaluates the preconditions of a write request
+// against the current ETag of its resource.
+func checkWritePreconditions(c fiber.Ctx, cfg *Config) error {
+	if cfg.RequireIfMatch && len(c.Request().Header.Peek(fiber.HeaderIfMatch)) == 0 {
+		return fiber.ErrPreconditionRequired
+	}
+	current, err := cfg.CurrentETag(c)
+	if err != nil {
+		return err
+	}
+	if c.CheckPreconditions(current, time.Time{}) != fiber.StatusOK {
+		return fiber.ErrPreconditionFailed
+	}
+	return nil
+}
+
 // isNoneMatch reports whether any entity tag in the If-None-Match header value
 // matches the response ETag, using the weak comparison required for
 // If-None-Match by RFC 9110 §8.8.3.2.
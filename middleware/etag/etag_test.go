@@ -245,6 +245,66 @@ func Test_ETag_CustomEtagPut(t *testing.T) {
 	require.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
 }
 
+// go test -run Test_ETag_CurrentETag
+func Test_ETag_CurrentETag(t *testing.T) {
+	t.Parallel()
+
+	version := []byte("v1")
+	current := func(_ fiber.Ctx) (string, error) {
+		return string(Generate(version)), nil
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{CurrentETag: current, RequireIfMatch: true}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.Send(version)
+	})
+	app.Put("/", func(c fiber.Ctx) error {
+		version = append([]byte(nil), c.Body()...)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Post("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	require.Equal(t, string(Generate([]byte("v1"))), etag)
+
+	put := func(ifMatch, body string) int {
+		req := httptest.NewRequest(fiber.MethodPut, "/", bytes.NewBufferString(body))
+		if ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	require.Equal(t, fiber.StatusPreconditionRequired, put("", "v2"))
+	require.Equal(t, fiber.StatusNoContent, put(etag, "v2"))
+	// the first write changed the resource
+	require.Equal(t, fiber.StatusPreconditionFailed, put(etag, "v3"))
+	require.Equal(t, "v2", string(version))
+
+	// other methods are not checked
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodPost, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	// errors of CurrentETag are returned
+	errApp := fiber.New()
+	errApp.Use(New(Config{CurrentETag: func(_ fiber.Ctx) (string, error) {
+		return "", fiber.ErrNotFound
+	}}))
+	resp, err = errApp.Test(httptest.NewRequest(fiber.MethodDelete, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	require.Panics(t, func() { New(Config{RequireIfMatch: true}) })
+}
+
 // go test -v -run=^$ -bench=Benchmark_Etag -benchmem -count=4
 func Benchmark_Etag(b *testing.B) {
 	app := fiber.New()
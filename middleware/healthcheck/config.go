@@ -40,6 +40,13 @@ type Config struct {
 	//
 	// Optional. Default: FormatText
 	ResponseFormat ResponseFormat
+
+	// MediaType selects the codec registered with app.RegisterCodec to
+	// encode the response with, e.g. "application/yaml". When set, it takes
+	// precedence over ResponseFormat.
+	//
+	// Optional. Default: ""
+	MediaType string
 }
 
 const (
@@ -1,10 +1,12 @@
 package healthcheck
 
 import (
+	"fmt"
+
 	"github.com/gofiber/fiber/v3"
 )
 
-// healthResponse represents the JSON/XML/MsgPack/CBOR response structure.
+// healthResponse represents the structure of encoded responses.
 type healthResponse struct {
 	Status string `json:"status" xml:"status" msgpack:"status" cbor:"status"`
 }
@@ -36,6 +38,19 @@ func New(config ...Config) fiber.Handler {
 		// Set the status code
 		c.Status(statusCode)
 
+		if cfg.MediaType != "" {
+			codec, ok := c.App().Codec(cfg.MediaType)
+			if !ok {
+				return fmt.Errorf("healthcheck: %w %q", fiber.ErrCodecNotRegistered, cfg.MediaType)
+			}
+			raw, err := codec.Marshal(healthResponse{Status: statusMessage})
+			if err != nil {
+				return fmt.Errorf("healthcheck: failed to encode response: %w", err)
+			}
+			c.Set(fiber.HeaderContentType, cfg.MediaType)
+			return c.Send(raw)
+		}
+
 		// Return response based on configured format
 		switch cfg.ResponseFormat {
 		case FormatJSON:
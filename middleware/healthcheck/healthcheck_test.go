@@ -1,6 +1,7 @@
 package healthcheck
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -439,3 +440,34 @@ func Test_HealthCheck_CBOR_Format(t *testing.T) {
 	require.NotContains(t, readyzResponse, "Status")
 	require.Equal(t, "Service Unavailable", readyzResponse["status"])
 }
+
+func Test_HealthCheck_Codec_Format(t *testing.T) {
+	t.Parallel()
+
+	const mediaType = "application/vnd.health+json"
+
+	app := fiber.New()
+	app.RegisterCodec(mediaType, fiber.NewCodec(json.Marshal, json.Unmarshal))
+
+	app.Get(LivenessEndpoint, New(Config{
+		ResponseFormat: FormatXML,
+		MediaType:      mediaType,
+	}))
+	app.Get(ReadinessEndpoint, New(Config{
+		MediaType: "application/yaml",
+	}))
+
+	req, err := app.Test(httptest.NewRequest(fiber.MethodGet, LivenessEndpoint, http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, req.StatusCode)
+	require.Equal(t, mediaType, req.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"status":"OK"}`, string(body))
+
+	// codecs that aren't registered are reported as errors
+	req, err = app.Test(httptest.NewRequest(fiber.MethodGet, ReadinessEndpoint, http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusInternalServerError, req.StatusCode)
+}
@@ -30,7 +30,8 @@ type Config struct {
 	// ErrorHandler defines a function which is executed for an invalid key.
 	// It may be used to define a custom error.
 	//
-	// Optional. Default: 401 Missing or invalid API Key
+	// Optional. Default: 401 Missing or invalid API Key, as problem document
+	// if fiber.Config.ProblemDetails is enabled
 	ErrorHandler fiber.ErrorHandler
 
 	// Validator is a function to validate the key.
@@ -92,6 +93,9 @@ var ConfigDefault = Config{
 		return c.Next()
 	},
 	ErrorHandler: func(c fiber.Ctx, _ error) error {
+		if c.App().Config().ProblemDetails {
+			return fiber.SendProblem(c, fiber.NewProblem(fiber.StatusUnauthorized, ErrMissingOrMalformedAPIKey.Error()))
+		}
 		return c.Status(fiber.StatusUnauthorized).SendString(ErrMissingOrMalformedAPIKey.Error())
 	},
 	Realm:     "Restricted",
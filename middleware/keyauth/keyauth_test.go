@@ -1188,3 +1188,26 @@ func Test_New_ErrorURIAbsolute(t *testing.T) {
 		})
 	})
 }
+
+func Test_ProblemDetails(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New(fiber.Config{ProblemDetails: true})
+	app.Use(New(Config{
+		Validator: func(_ fiber.Ctx, key string) (bool, error) {
+			return key == CorrectKey, nil
+		},
+	}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	require.Equal(t, fiber.MIMEApplicationProblemJSONCharsetUTF8, resp.Header.Get(fiber.HeaderContentType))
+	require.Equal(t, `Bearer realm="Restricted"`, resp.Header.Get(fiber.HeaderWWWAuthenticate))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"missing or invalid API Key"}`, string(body))
+}
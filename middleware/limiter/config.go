@@ -51,6 +51,7 @@ type Config struct {
 	KeyGenerator func(fiber.Ctx) string
 
 	// LimitReached is called when a request hits the limit
+	// The default sends a problem document if fiber.Config.ProblemDetails is enabled.
 	//
 	// Default: func(c fiber.Ctx) error {
 	//   return c.SendStatus(fiber.StatusTooManyRequests)
@@ -101,6 +102,9 @@ var ConfigDefault = Config{
 		return c.IP()
 	},
 	LimitReached: func(c fiber.Ctx) error {
+		if c.App().Config().ProblemDetails {
+			return fiber.SendProblem(c, fiber.NewProblem(fiber.StatusTooManyRequests))
+		}
 		return c.SendStatus(fiber.StatusTooManyRequests)
 	},
 	SkipFailedRequests:     false,
@@ -1863,3 +1863,25 @@ func Test_Config_currentSecond_NegativeClock(t *testing.T) {
 	cfg.clock = func() time.Time { return time.Unix(42, 0) }
 	require.Equal(t, uint64(42), cfg.currentSecond())
 }
+
+func TestLimiterProblemDetails(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New(fiber.Config{ProblemDetails: true})
+	app.Use(New(Config{Max: 1}))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, fiber.MIMEApplicationProblemJSONCharsetUTF8, resp.Header.Get(fiber.HeaderContentType))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"about:blank","title":"Too Many Requests","status":429}`, string(body))
+}
@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"github.com/gofiber/fiber/v3"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// Operations describes the registered routes. The key is either the route
+	// name or the HTTP method followed by the registered path, e.g. "GET /users/:id".
+	//
+	// Optional. Default: nil
+	Operations map[string]Operation
+
+	// SecuritySchemes are published in the components section of the document.
+	//
+	// Optional. Default: nil
+	SecuritySchemes map[string]SecurityScheme
+
+	// Title of the API.
+	//
+	// Optional. Default: "Fiber API"
+	Title string
+
+	// Version of the API, not to be confused with the OpenAPI version.
+	//
+	// Optional. Default: "1.0.0"
+	Version string
+
+	// Description of the API.
+	//
+	// Optional. Default: ""
+	Description string
+
+	// Path on which the JSON document is served.
+	//
+	// Optional. Default: "/openapi.json"
+	Path string
+
+	// YAMLPath on which the YAML document is served.
+	//
+	// Optional. Default: "/openapi.yaml"
+	YAMLPath string
+
+	// Servers lists the base URLs of the API.
+	//
+	// Optional. Default: nil
+	Servers []Server
+
+	// Security lists the security requirements applied to every operation
+	// that does not declare its own.
+	//
+	// Optional. Default: nil
+	Security []SecurityRequirement
+
+	// CacheControl defines how the Cache-Control header of the document response should be set.
+	//
+	// Optional. Default: "no-cache"
+	CacheControl string
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:         nil,
+	Title:        "Fiber API",
+	Version:      "1.0.0",
+	Path:         "/openapi.json",
+	YAMLPath:     "/openapi.yaml",
+	CacheControl: "no-cache",
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	cfg := config[0]
+
+	if cfg.Title == "" {
+		cfg.Title = ConfigDefault.Title
+	}
+	if cfg.Version == "" {
+		cfg.Version = ConfigDefault.Version
+	}
+	if cfg.Path == "" {
+		cfg.Path = ConfigDefault.Path
+	}
+	if cfg.YAMLPath == "" {
+		cfg.YAMLPath = ConfigDefault.YAMLPath
+	}
+	if cfg.CacheControl == "" {
+		cfg.CacheControl = ConfigDefault.CacheControl
+	}
+
+	return cfg
+}
@@ -0,0 +1,162 @@
+package openapi
+
+// SpecVersion is the OpenAPI specification version of the generated documents.
+const SpecVersion = "3.1.0"
+
+// Document is the root object of an OpenAPI 3.1 document.
+type Document struct {
+	Paths      map[string]PathItem   `json:"paths"`
+	Components *Components           `json:"components,omitempty"`
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Info provides metadata about the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server describes a base URL of the API.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to the operations of a single path.
+type PathItem map[string]*OperationObject
+
+// OperationObject describes a single API operation on a path.
+type OperationObject struct {
+	RequestBody *RequestBody               `json:"requestBody,omitempty"`
+	Responses   map[string]*ResponseObject `json:"responses"`
+	OperationID string                     `json:"operationId,omitempty"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []*Parameter               `json:"parameters,omitempty"`
+	Security    *[]SecurityRequirement     `json:"security,omitempty"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+}
+
+// Parameter describes a single path, query, header or cookie parameter.
+type Parameter struct {
+	Schema      *Schema `json:"schema,omitempty"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Content     map[string]*MediaType `json:"content"`
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+}
+
+// ResponseObject describes a single response of an operation.
+type ResponseObject struct {
+	Content     map[string]*MediaType `json:"content,omitempty"`
+	Description string                `json:"description"`
+}
+
+// MediaType provides the schema for a content type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the reusable objects of the document.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// Schema is a JSON Schema (draft 2020-12) object as used by OpenAPI 3.1.
+type Schema struct {
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+}
+
+// SecurityScheme defines a security scheme that can be used by the operations.
+type SecurityScheme struct {
+	Flows            map[string]OAuthFlow `json:"flows,omitempty"`
+	Type             string               `json:"type"`
+	Description      string               `json:"description,omitempty"`
+	Name             string               `json:"name,omitempty"`
+	In               string               `json:"in,omitempty"`
+	Scheme           string               `json:"scheme,omitempty"`
+	BearerFormat     string               `json:"bearerFormat,omitempty"`
+	OpenIDConnectURL string               `json:"openIdConnectUrl,omitempty"`
+}
+
+// OAuthFlow describes a single OAuth2 flow, keyed in SecurityScheme.Flows by
+// "implicit", "password", "clientCredentials" or "authorizationCode".
+type OAuthFlow struct {
+	Scopes           map[string]string `json:"scopes"`
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+}
+
+// SecurityRequirement maps security scheme names to the required scopes.
+type SecurityRequirement map[string][]string
+
+// Operation describes a registered route for the document.
+type Operation struct {
+	// Request is a value or reflect.Type of the request struct. Fields tagged with
+	// `query`, `header` or `cookie` become parameters, fields tagged with `uri`
+	// refine the path parameters and all other fields make up the request body.
+	// Defaults to the request type of routes created with fiber.Typed.
+	Request any
+
+	// Responses maps status codes to the responses of the operation.
+	// When empty, a single "200" response is documented, with the response type
+	// of routes created with fiber.Typed as content.
+	Responses map[int]Response
+
+	// RequestContentType of the request body.
+	//
+	// Optional. Default: "application/json"
+	RequestContentType string
+
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	// Security overrides Config.Security for the operation. Use an empty, non-nil
+	// slice to mark an operation as public.
+	Security   []SecurityRequirement
+	Deprecated bool
+}
+
+// Response describes a single response of an Operation.
+type Response struct {
+	// Body is a value or reflect.Type of the response body, nil for responses without content.
+	Body any
+
+	// Description of the response.
+	//
+	// Optional. Default: the HTTP status message
+	Description string
+
+	// ContentType of the response body.
+	//
+	// Optional. Default: "application/json"
+	ContentType string
+}
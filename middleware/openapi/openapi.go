@@ -0,0 +1,535 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/utils/v2"
+)
+
+const (
+	mimeJSON = "application/json"
+	mimeYAML = "application/yaml"
+)
+
+// supportedMethods lists the HTTP methods an OpenAPI path item can describe.
+var supportedMethods = map[string]struct{}{
+	fiber.MethodGet:     {},
+	fiber.MethodPut:     {},
+	fiber.MethodPost:    {},
+	fiber.MethodDelete:  {},
+	fiber.MethodOptions: {},
+	fiber.MethodHead:    {},
+	fiber.MethodPatch:   {},
+	fiber.MethodTrace:   {},
+}
+
+// parameterTags lists the struct tags that turn request fields into parameters.
+var parameterTags = []struct {
+	tag string
+	in  string
+}{
+	{tag: "query", in: "query"},
+	{tag: "header", in: "header"},
+	{tag: "cookie", in: "cookie"},
+}
+
+// New creates a new middleware handler that serves the OpenAPI document of the app.
+// The document is generated from the routes on the first request and generated
+// again once they change, see fiber.App.RoutesGeneration.
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+
+	var (
+		mu         sync.Mutex
+		built      bool
+		generation uint64
+		jsonDoc    []byte
+		yamlDoc    []byte
+		buildErr   error
+	)
+
+	build := func(app *fiber.App) ([]byte, []byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		current := app.RoutesGeneration()
+		if built && generation == current {
+			return jsonDoc, yamlDoc, buildErr
+		}
+		built, generation = true, current
+		jsonDoc, yamlDoc = nil, nil
+
+		doc, err := Generate(app, cfg)
+		if err == nil {
+			jsonDoc, err = doc.JSON()
+		}
+		if err == nil {
+			yamlDoc, err = doc.YAML()
+		}
+		buildErr = err
+		return jsonDoc, yamlDoc, buildErr
+	}
+
+	// Return new handler
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		path := c.Path()
+		if path != cfg.Path && path != cfg.YAMLPath {
+			return c.Next()
+		}
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+
+		jsonBody, yamlBody, err := build(c.App())
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderCacheControl, cfg.CacheControl)
+		if path == cfg.YAMLPath {
+			c.Set(fiber.HeaderContentType, mimeYAML)
+			return c.Send(yamlBody)
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(jsonBody)
+	}
+}
+
+// Generate builds the OpenAPI document from the routes registered on the app.
+// Middleware routes are left out, as are automatically generated HEAD routes.
+func Generate(app *fiber.App, config ...Config) (*Document, error) {
+	cfg := configDefault(config...)
+
+	doc := &Document{
+		OpenAPI: SpecVersion,
+		Info: Info{
+			Title:       cfg.Title,
+			Version:     cfg.Version,
+			Description: cfg.Description,
+		},
+		Servers:  cfg.Servers,
+		Paths:    make(map[string]PathItem),
+		Security: cfg.Security,
+	}
+
+	routes := app.GetRoutes(true)
+	getRoutes := make(map[string]struct{}, len(routes))
+	for i := range routes {
+		if routes[i].Method == fiber.MethodGet {
+			getRoutes[routes[i].Path] = struct{}{}
+		}
+	}
+
+	registry := newSchemaRegistry()
+	for i := range routes {
+		route := &routes[i]
+		if _, ok := supportedMethods[route.Method]; !ok {
+			continue
+		}
+		if route.Method == fiber.MethodHead {
+			if _, ok := getRoutes[route.Path]; ok {
+				continue
+			}
+		}
+
+		op := lookupOperation(cfg.Operations, route)
+		method := utils.ToLower(route.Method)
+		for n, template := range pathTemplates(routeSegments(route)) {
+			item, ok := doc.Paths[template.path]
+			if !ok {
+				item = make(PathItem)
+				doc.Paths[template.path] = item
+			}
+			if _, exists := item[method]; exists {
+				continue
+			}
+
+			operation, err := buildOperation(registry, route, &op, template.params)
+			if err != nil {
+				return nil, fmt.Errorf("openapi: %s %s: %w", route.Method, route.Path, err)
+			}
+			// operation IDs must be unique, number the variants of optional parameters
+			if n > 0 && operation.OperationID != "" {
+				operation.OperationID += strconv.Itoa(n + 1)
+			}
+			item[method] = operation
+		}
+	}
+
+	if len(registry.schemas) > 0 || len(cfg.SecuritySchemes) > 0 {
+		doc.Components = &Components{
+			Schemas:         registry.schemas,
+			SecuritySchemes: cfg.SecuritySchemes,
+		}
+	}
+
+	return doc, nil
+}
+
+// lookupOperation returns the operation registered for the route name or for "METHOD path".
+func lookupOperation(operations map[string]Operation, route *fiber.Route) Operation {
+	if route.Name != "" {
+		if op, ok := operations[route.Name]; ok {
+			return op
+		}
+	}
+	return operations[route.Method+" "+route.Path]
+}
+
+// routeSegments returns the segments of the route with the constant parts as
+// registered, Route.Segments lowercases them when routing is case-insensitive.
+func routeSegments(route *fiber.Route) []fiber.RouteSegment {
+	segs := route.Segments()
+	path := fiber.RemoveEscapeChar(route.Path)
+	lower := utils.ToLower(path)
+
+	offset := 0
+	for i := range segs {
+		if segs[i].IsParam {
+			continue
+		}
+		idx := strings.Index(lower[offset:], utils.ToLower(segs[i].Const))
+		if idx < 0 {
+			continue
+		}
+		start := offset + idx
+		offset = start + len(segs[i].Const)
+		segs[i].Const = path[start:offset]
+	}
+
+	return segs
+}
+
+// pathTemplate is an OpenAPI path template with the route segments of its parameters.
+type pathTemplate struct {
+	path   string
+	params []fiber.RouteSegment
+}
+
+// pathTemplates converts the route segments to OpenAPI path templates. OpenAPI
+// path parameters are always required, so every trailing optional parameter
+// adds a template without it.
+func pathTemplates(segs []fiber.RouteSegment) []pathTemplate {
+	templates := []pathTemplate{buildTemplate(segs)}
+
+	for i := len(segs) - 1; i >= 0; i-- {
+		seg := segs[i]
+		if !seg.IsParam {
+			continue
+		}
+		if !seg.IsOptional {
+			break
+		}
+		templates = append(templates, buildTemplate(segs[:i]))
+	}
+
+	return templates
+}
+
+func buildTemplate(segs []fiber.RouteSegment) pathTemplate {
+	var (
+		sb     strings.Builder
+		params []fiber.RouteSegment
+	)
+	for _, seg := range segs {
+		if !seg.IsParam {
+			sb.WriteString(seg.Const)
+			continue
+		}
+		sb.WriteString("{" + parameterName(seg.Param) + "}")
+		params = append(params, seg)
+	}
+
+	path := sb.String()
+	for strings.Contains(path, "//") {
+		path = strings.ReplaceAll(path, "//", "/")
+	}
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	return pathTemplate{path: path, params: params}
+}
+
+// parameterName converts the generated names of wildcard ("*1") and plus ("+1")
+// parameters to valid template expressions.
+func parameterName(name string) string {
+	if name == "" {
+		return name
+	}
+	switch name[0] {
+	case '*':
+		return "wildcard" + name[1:]
+	case '+':
+		return "plus" + name[1:]
+	default:
+		return name
+	}
+}
+
+func buildOperation(registry *schemaRegistry, route *fiber.Route, op *Operation, params []fiber.RouteSegment) (*OperationObject, error) {
+	operation := &OperationObject{
+		OperationID: op.OperationID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Deprecated:  op.Deprecated,
+		Responses:   make(map[string]*ResponseObject),
+	}
+	if op.Security != nil {
+		security := op.Security
+		operation.Security = &security
+	}
+
+	// path parameters without constraints take their schema from the request type
+	untypedParams := make(map[string]*Parameter, len(params))
+	for _, seg := range params {
+		param := &Parameter{
+			Name:     parameterName(seg.Param),
+			In:       "path",
+			Required: true,
+			Schema:   constraintSchema(seg.Constraints),
+		}
+		if len(seg.Constraints) == 0 {
+			untypedParams[seg.Param] = param
+		}
+		operation.Parameters = append(operation.Parameters, param)
+	}
+
+	requestType := typeOf(op.Request)
+	if requestType == nil {
+		requestType = route.RequestType
+	}
+	if requestType != nil {
+		if err := addRequest(registry, operation, route.Method, op, requestType, untypedParams); err != nil {
+			return nil, err
+		}
+	}
+
+	responses := op.Responses
+	if len(responses) == 0 {
+		// endpoints created with fiber.Typed document their response type
+		responses = map[int]Response{fiber.StatusOK: {}}
+		if route.ResponseType != nil {
+			responses[fiber.StatusOK] = Response{Body: route.ResponseType}
+		}
+	}
+
+	for status, resp := range responses {
+		response := &ResponseObject{Description: resp.Description}
+		if response.Description == "" {
+			response.Description = utils.StatusMessage(status)
+		}
+		if bodyType := typeOf(resp.Body); bodyType != nil {
+			schema, err := registry.schemaFor(bodyType)
+			if err != nil {
+				return nil, err
+			}
+			contentType := resp.ContentType
+			if contentType == "" {
+				contentType = mimeJSON
+			}
+			response.Content = map[string]*MediaType{contentType: {Schema: schema}}
+		}
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+
+	return operation, nil
+}
+
+// addRequest documents the parameters and the body described by the request type.
+func addRequest(
+	registry *schemaRegistry,
+	operation *OperationObject,
+	method string,
+	op *Operation,
+	requestType reflect.Type,
+	untypedParams map[string]*Parameter,
+) error {
+	for requestType.Kind() == reflect.Pointer {
+		requestType = requestType.Elem()
+	}
+	if requestType.Kind() != reflect.Struct {
+		return fmt.Errorf("request type %s is not a struct", requestType)
+	}
+
+	var queryParams []*Parameter
+	hasBody := false
+	for field := range structFields(requestType) {
+		if !field.IsExported() {
+			continue
+		}
+
+		if name, ok := field.Tag.Lookup("uri"); ok {
+			if param, found := untypedParams[strings.Split(name, ",")[0]]; found {
+				schema, err := registry.schemaFor(field.Type)
+				if err != nil {
+					return err
+				}
+				param.Schema = schema
+			}
+			continue
+		}
+
+		isParam := false
+		for _, pt := range parameterTags {
+			name, ok := field.Tag.Lookup(pt.tag)
+			if !ok {
+				continue
+			}
+			isParam = true
+			schema, err := registry.schemaFor(field.Type)
+			if err != nil {
+				return err
+			}
+			queryParams = append(queryParams, &Parameter{
+				Name:        strings.Split(name, ",")[0],
+				In:          pt.in,
+				Description: field.Tag.Get("doc"),
+				Required:    hasValidateRule(field, "required"),
+				Schema:      schema,
+			})
+			break
+		}
+		if !isParam {
+			hasBody = true
+		}
+	}
+
+	sort.SliceStable(queryParams, func(i, j int) bool {
+		if queryParams[i].In != queryParams[j].In {
+			return queryParams[i].In < queryParams[j].In
+		}
+		return queryParams[i].Name < queryParams[j].Name
+	})
+	operation.Parameters = append(operation.Parameters, queryParams...)
+
+	if !hasBody || method == fiber.MethodGet || method == fiber.MethodHead {
+		return nil
+	}
+
+	body, err := registry.structSchema(requestType, isParameterField)
+	if err != nil {
+		return err
+	}
+	contentType := op.RequestContentType
+	if contentType == "" {
+		contentType = mimeJSON
+	}
+	operation.RequestBody = &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{contentType: {Schema: body}},
+	}
+
+	return nil
+}
+
+// isParameterField reports whether a request field is documented as parameter
+// instead of being part of the request body.
+func isParameterField(field reflect.StructField) bool {
+	if _, ok := field.Tag.Lookup("uri"); ok {
+		return true
+	}
+	for _, pt := range parameterTags {
+		if _, ok := field.Tag.Lookup(pt.tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// constraintSchema maps route constraints to schema keywords.
+func constraintSchema(constraints []*fiber.Constraint) *Schema {
+	schema := &Schema{Type: "string"}
+
+	for _, c := range constraints {
+		args := constraintArgs(c)
+		switch utils.ToLower(c.Name) {
+		case fiber.ConstraintInt:
+			schema.Type = "integer"
+		case fiber.ConstraintBool:
+			schema.Type = "boolean"
+		case fiber.ConstraintFloat:
+			schema.Type = "number"
+		case fiber.ConstraintAlpha:
+			schema.Pattern = "^[a-zA-Z]+$"
+		case fiber.ConstraintGUID:
+			schema.Format = "uuid"
+		case fiber.ConstraintDatetime:
+			if len(args) > 0 {
+				schema.Description = "Date and time in the Go layout " + args[0]
+			}
+		case fiber.ConstraintMinLenLower:
+			schema.MinLength = intArg(args, 0)
+		case fiber.ConstraintMaxLenLower:
+			schema.MaxLength = intArg(args, 0)
+		case fiber.ConstraintLen:
+			schema.MinLength, schema.MaxLength = intArg(args, 0), intArg(args, 0)
+		case fiber.ConstraintBetweenLenLower:
+			schema.MinLength, schema.MaxLength = intArg(args, 0), intArg(args, 1)
+		case fiber.ConstraintMin:
+			schema.Type, schema.Minimum = "integer", floatArg(args, 0)
+		case fiber.ConstraintMax:
+			schema.Type, schema.Maximum = "integer", floatArg(args, 0)
+		case fiber.ConstraintRange:
+			schema.Type, schema.Minimum, schema.Maximum = "integer", floatArg(args, 0), floatArg(args, 1)
+		case fiber.ConstraintRegex:
+			if len(c.Data) > 0 {
+				schema.Pattern = c.Data[0]
+			}
+		default:
+			// custom constraints cannot be expressed as schema keywords
+		}
+	}
+
+	return schema
+}
+
+// constraintArgs splits the raw constraint data, e.g. "range(1,10)" into ["1", "10"].
+func constraintArgs(c *fiber.Constraint) []string {
+	if len(c.Data) != 1 {
+		return c.Data
+	}
+	args := strings.Split(c.Data[0], ",")
+	for i := range args {
+		args[i] = fiber.RemoveEscapeChar(strings.TrimSpace(args[i]))
+	}
+	return args
+}
+
+func intArg(args []string, i int) *int {
+	if i >= len(args) {
+		return nil
+	}
+	n, err := strconv.Atoi(args[i])
+	if err != nil {
+		return nil
+	}
+	return intPtr(n)
+}
+
+func floatArg(args []string, i int) *float64 {
+	if i >= len(args) {
+		return nil
+	}
+	n, err := strconv.ParseFloat(args[i], 64)
+	if err != nil {
+		return nil
+	}
+	return float64Ptr(n)
+}
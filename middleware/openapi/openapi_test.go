@@ -0,0 +1,456 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type testUser struct {
+	CreatedAt time.Time   `json:"created_at"`
+	Manager   *testUser   `json:"manager,omitempty"`
+	Name      string      `json:"name" doc:"Display name"`
+	Email     string      `json:"email,omitempty"`
+	Tags      []string    `json:"tags"`
+	Internal  string      `json:"-"`
+	ID        int64       `json:"id"`
+	Address   testAddress `json:"address"`
+}
+
+type testAddress struct {
+	Street string `json:"street"`
+}
+
+type testCreateUser struct {
+	Tenant string `header:"X-Tenant" validate:"required"`
+	Name   string `json:"name" validate:"required,min=3"`
+	Email  string `json:"email,omitempty"`
+	Dry    bool   `query:"dry_run"`
+}
+
+type testListUsers struct {
+	Page   int    `query:"page"`
+	Filter string `query:"filter"`
+}
+
+type testGetUser struct {
+	ID int `uri:"id"`
+}
+
+func handler(c fiber.Ctx) error {
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func generate(t *testing.T, app *fiber.App, cfg ...Config) *Document {
+	t.Helper()
+	doc, err := Generate(app, cfg...)
+	require.NoError(t, err)
+	return doc
+}
+
+// go test -run Test_OpenAPI_Generate_Paths
+func Test_OpenAPI_Generate_Paths(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		return c.Next()
+	})
+	app.Get("/users", handler)
+	app.Post("/users", handler)
+	app.Get("/users/:id", handler)
+	app.Get("/files/*", handler)
+	app.Get("/posts/:slug?", handler)
+	app.Add([]string{fiber.MethodQuery}, "/search", handler)
+
+	doc := generate(t, app)
+
+	require.Equal(t, SpecVersion, doc.OpenAPI)
+	require.Equal(t, "Fiber API", doc.Info.Title)
+	require.Equal(t, "1.0.0", doc.Info.Version)
+
+	require.Contains(t, doc.Paths, "/users")
+	require.Contains(t, doc.Paths["/users"], "get")
+	require.Contains(t, doc.Paths["/users"], "post")
+	// automatically generated HEAD routes are not documented
+	require.NotContains(t, doc.Paths["/users"], "head")
+
+	require.Contains(t, doc.Paths, "/users/{id}")
+	params := doc.Paths["/users/{id}"]["get"].Parameters
+	require.Len(t, params, 1)
+	require.Equal(t, "id", params[0].Name)
+	require.Equal(t, "path", params[0].In)
+	require.True(t, params[0].Required)
+	require.Equal(t, "string", params[0].Schema.Type)
+
+	require.Contains(t, doc.Paths, "/files/{wildcard1}")
+	require.Contains(t, doc.Paths, "/files")
+
+	require.Contains(t, doc.Paths, "/posts/{slug}")
+	require.Contains(t, doc.Paths, "/posts")
+	require.Empty(t, doc.Paths["/posts"]["get"].Parameters)
+
+	// QUERY cannot be described by OpenAPI 3.1
+	require.NotContains(t, doc.Paths, "/search")
+
+	require.Equal(t, "OK", doc.Paths["/users"]["get"].Responses["200"].Description)
+}
+
+// go test -run Test_OpenAPI_Generate_PathCase
+func Test_OpenAPI_Generate_PathCase(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/Users/:userID/Posts/", handler)
+	app.Get("/API/v1/Items/:id?", handler)
+
+	doc := generate(t, app)
+	require.Contains(t, doc.Paths, "/Users/{userID}/Posts")
+	require.Contains(t, doc.Paths, "/API/v1/Items/{id}")
+	require.Contains(t, doc.Paths, "/API/v1/Items")
+	require.NotContains(t, doc.Paths, "/users/{userID}/posts")
+}
+
+// go test -run Test_OpenAPI_Generate_Constraints
+func Test_OpenAPI_Generate_Constraints(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/int/:id<int>", handler)
+	app.Get("/guid/:id<guid>", handler)
+	app.Get("/range/:n<range(1,10)>", handler)
+	app.Get("/min/:n<min(5)>", handler)
+	app.Get("/len/:s<minLen(2);maxLen(8)>", handler)
+	app.Get("/between/:s<betweenLen(2,4)>", handler)
+	app.Get("/regex/:s<regex(^[a-z]+$)>", handler)
+	app.Get("/alpha/:s<alpha>", handler)
+	app.Get("/bool/:b<bool>", handler)
+
+	doc := generate(t, app)
+	schema := func(path string) *Schema {
+		t.Helper()
+		require.Contains(t, doc.Paths, path)
+		return doc.Paths[path]["get"].Parameters[0].Schema
+	}
+
+	require.Equal(t, "integer", schema("/int/{id}").Type)
+
+	s := schema("/guid/{id}")
+	require.Equal(t, "string", s.Type)
+	require.Equal(t, "uuid", s.Format)
+
+	s = schema("/range/{n}")
+	require.Equal(t, "integer", s.Type)
+	require.InDelta(t, 1, *s.Minimum, 0)
+	require.InDelta(t, 10, *s.Maximum, 0)
+
+	s = schema("/min/{n}")
+	require.InDelta(t, 5, *s.Minimum, 0)
+	require.Nil(t, s.Maximum)
+
+	s = schema("/len/{s}")
+	require.Equal(t, 2, *s.MinLength)
+	require.Equal(t, 8, *s.MaxLength)
+
+	s = schema("/between/{s}")
+	require.Equal(t, 2, *s.MinLength)
+	require.Equal(t, 4, *s.MaxLength)
+
+	require.Equal(t, "^[a-z]+$", schema("/regex/{s}").Pattern)
+	require.Equal(t, "^[a-zA-Z]+$", schema("/alpha/{s}").Pattern)
+	require.Equal(t, "boolean", schema("/bool/{b}").Type)
+}
+
+// go test -run Test_OpenAPI_Generate_Operations
+func Test_OpenAPI_Generate_Operations(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/users", handler).Name("listUsers")
+	app.Post("/users", handler)
+	app.Get("/users/:id", handler)
+	app.Get("/health", handler)
+
+	doc := generate(t, app, Config{
+		Title:   "Users",
+		Version: "2.0.0",
+		SecuritySchemes: map[string]SecurityScheme{
+			"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+		Security: []SecurityRequirement{{"bearer": {}}},
+		Operations: map[string]Operation{
+			"listUsers": {
+				Summary:   "List users",
+				Tags:      []string{"users"},
+				Request:   testListUsers{},
+				Responses: map[int]Response{fiber.StatusOK: {Body: []testUser{}}},
+			},
+			"POST /users": {
+				OperationID: "createUser",
+				Request:     &testCreateUser{},
+				Responses: map[int]Response{
+					fiber.StatusCreated:             {Body: testUser{}, Description: "Created user"},
+					fiber.StatusUnprocessableEntity: {},
+				},
+			},
+			"GET /users/:id": {
+				Request:   reflect.TypeFor[testGetUser](),
+				Responses: map[int]Response{fiber.StatusOK: {Body: testUser{}}},
+			},
+			"GET /health": {
+				Security: []SecurityRequirement{},
+			},
+		},
+	})
+
+	require.Equal(t, "Users", doc.Info.Title)
+	require.Equal(t, "2.0.0", doc.Info.Version)
+	require.Equal(t, []SecurityRequirement{{"bearer": {}}}, doc.Security)
+	require.Equal(t, "bearer", doc.Components.SecuritySchemes["bearer"].Scheme)
+
+	list := doc.Paths["/users"]["get"]
+	require.Equal(t, "List users", list.Summary)
+	require.Equal(t, []string{"users"}, list.Tags)
+	require.Len(t, list.Parameters, 2)
+	require.Equal(t, "filter", list.Parameters[0].Name)
+	require.Equal(t, "query", list.Parameters[0].In)
+	require.Equal(t, "page", list.Parameters[1].Name)
+	require.Equal(t, "integer", list.Parameters[1].Schema.Type)
+	require.Nil(t, list.RequestBody)
+	require.Equal(t, "array", list.Responses["200"].Content["application/json"].Schema.Type)
+	require.Equal(t, "#/components/schemas/testUser", list.Responses["200"].Content["application/json"].Schema.Items.Ref)
+
+	create := doc.Paths["/users"]["post"]
+	require.Equal(t, "createUser", create.OperationID)
+	require.Len(t, create.Parameters, 2)
+	require.Equal(t, "X-Tenant", create.Parameters[0].Name)
+	require.Equal(t, "header", create.Parameters[0].In)
+	require.True(t, create.Parameters[0].Required)
+	require.Equal(t, "dry_run", create.Parameters[1].Name)
+	require.False(t, create.Parameters[1].Required)
+	body := create.RequestBody.Content["application/json"].Schema
+	require.Equal(t, []string{"name"}, body.Required)
+	require.Contains(t, body.Properties, "name")
+	require.Contains(t, body.Properties, "email")
+	require.NotContains(t, body.Properties, "Tenant")
+	require.Equal(t, "Created user", create.Responses["201"].Description)
+	require.Equal(t, "Unprocessable Entity", create.Responses["422"].Description)
+	require.Nil(t, create.Responses["422"].Content)
+
+	get := doc.Paths["/users/{id}"]["get"]
+	require.Equal(t, "integer", get.Parameters[0].Schema.Type)
+
+	health := doc.Paths["/health"]["get"]
+	require.NotNil(t, health.Security)
+	require.Empty(t, *health.Security)
+
+	user := doc.Components.Schemas["testUser"]
+	require.NotNil(t, user)
+	require.Equal(t, "object", user.Type)
+	require.ElementsMatch(t, []string{"created_at", "name", "tags", "id", "address"}, user.Required)
+	require.Equal(t, "date-time", user.Properties["created_at"].Format)
+	require.Equal(t, "#/components/schemas/testUser", user.Properties["manager"].Ref)
+	require.Equal(t, "Display name", user.Properties["name"].Description)
+	require.Equal(t, "int64", user.Properties["id"].Format)
+	require.NotContains(t, user.Properties, "Internal")
+	require.NotContains(t, user.Properties, "-")
+	require.Equal(t, "#/components/schemas/testAddress", user.Properties["address"].Ref)
+	require.Contains(t, doc.Components.Schemas, "testAddress")
+}
+
+// go test -run Test_OpenAPI_Generate_Typed
+func Test_OpenAPI_Generate_Typed(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Post("/users", fiber.Typed(func(_ fiber.Ctx, _ testCreateUser) (testUser, error) {
+		return testUser{}, nil
+	}))
+	app.Get("/users/:id", fiber.Typed(func(_ fiber.Ctx, _ testGetUser) (testUser, error) {
+		return testUser{}, nil
+	}))
+
+	doc := generate(t, app, Config{
+		Operations: map[string]Operation{
+			"GET /users/:id": {
+				Responses: map[int]Response{fiber.StatusOK: {Body: testUser{}, Description: "The user"}},
+			},
+		},
+	})
+
+	create := doc.Paths["/users"]["post"]
+	require.Len(t, create.Parameters, 2)
+	require.Equal(t, []string{"name"}, create.RequestBody.Content["application/json"].Schema.Required)
+	require.Equal(t, "#/components/schemas/testUser", create.Responses["200"].Content["application/json"].Schema.Ref)
+
+	get := doc.Paths["/users/{id}"]["get"]
+	require.Equal(t, "integer", get.Parameters[0].Schema.Type)
+	require.Equal(t, "The user", get.Responses["200"].Description)
+}
+
+// go test -run Test_OpenAPI_Generate_UnsupportedType
+func Test_OpenAPI_Generate_UnsupportedType(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/", handler)
+
+	_, err := Generate(app, Config{
+		Operations: map[string]Operation{
+			"GET /": {Responses: map[int]Response{fiber.StatusOK: {Body: struct{ C chan int }{}}}},
+		},
+	})
+	require.ErrorContains(t, err, "unsupported type chan int")
+}
+
+// go test -run Test_OpenAPI_Middleware
+func Test_OpenAPI_Middleware(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{Title: "Served API"}))
+	app.Get("/users/:id<int>", handler)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/openapi.json", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, fiber.MIMEApplicationJSONCharsetUTF8, resp.Header.Get(fiber.HeaderContentType))
+	require.Equal(t, "no-cache", resp.Header.Get(fiber.HeaderCacheControl))
+
+	var doc map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+	require.Equal(t, "3.1.0", doc["openapi"])
+	require.Contains(t, doc["paths"], "/users/{id}")
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/openapi.yaml", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/yaml", resp.Header.Get(fiber.HeaderContentType))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "openapi: \"3.1.0\"\n")
+	require.Contains(t, string(body), "  title: Served API\n")
+	require.Contains(t, string(body), "  \"/users/{id}\":\n")
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodPost, "/openapi.json", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+// go test -run Test_OpenAPI_Middleware_RouteChanges
+func Test_OpenAPI_Middleware_RouteChanges(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New())
+	app.Get("/users", handler)
+
+	paths := func() map[string]any {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/openapi.json", http.NoBody))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var doc struct {
+			Paths map[string]any `json:"paths"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+		return doc.Paths
+	}
+
+	require.Contains(t, paths(), "/users")
+	require.NotContains(t, paths(), "/posts")
+
+	// the document follows the routes added at runtime
+	app.Get("/posts", handler)
+	app.RebuildTree()
+	require.Contains(t, paths(), "/posts")
+
+	app.RemoveRoute("/users", fiber.MethodGet)
+	require.NotContains(t, paths(), "/users")
+}
+
+// go test -run Test_OpenAPI_Middleware_Next
+func Test_OpenAPI_Middleware_Next(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Path: "/docs/openapi.json",
+		Next: func(_ fiber.Ctx) bool {
+			return true
+		},
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/docs/openapi.json", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+// go test -run Test_OpenAPI_YAML
+func Test_OpenAPI_YAML(t *testing.T) {
+	t.Parallel()
+
+	doc := &Document{
+		OpenAPI: SpecVersion,
+		Info:    Info{Title: "yes", Version: "1.0.0", Description: "line one\nline two"},
+		Paths: map[string]PathItem{
+			"/": {"get": {Responses: map[string]*ResponseObject{"200": {Description: "OK"}}, Tags: []string{"a", "b c"}}},
+		},
+		Servers: []Server{{URL: "https://example.com"}},
+	}
+
+	data, err := doc.YAML()
+	require.NoError(t, err)
+
+	expected := strings.Join([]string{
+		`info:`,
+		`  description: "line one\nline two"`,
+		`  title: "yes"`,
+		`  version: "1.0.0"`,
+		`openapi: "3.1.0"`,
+		`paths:`,
+		`  "/":`,
+		`    get:`,
+		`      responses:`,
+		`        "200":`,
+		`          description: OK`,
+		`      tags:`,
+		`      - a`,
+		`      - b c`,
+		`servers:`,
+		`- url: "https://example.com"`,
+		``,
+	}, "\n")
+	require.Equal(t, expected, string(data))
+}
+
+// go test -v -run=^$ -bench=Benchmark_OpenAPI_Generate -benchmem -count=4
+func Benchmark_OpenAPI_Generate(b *testing.B) {
+	app := fiber.New()
+	app.Get("/users", handler).Name("listUsers")
+	app.Get("/users/:id<int>", handler)
+	app.Post("/users", handler)
+
+	cfg := Config{
+		Operations: map[string]Operation{
+			"listUsers":   {Responses: map[int]Response{fiber.StatusOK: {Body: []testUser{}}}},
+			"POST /users": {Request: testCreateUser{}},
+		},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := Generate(app, cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
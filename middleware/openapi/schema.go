@@ -0,0 +1,259 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const componentSchemaPrefix = "#/components/schemas/"
+
+var (
+	timeType         = reflect.TypeFor[time.Time]()
+	invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// schemaRegistry generates schemas for Go types and collects the named
+// struct types as reusable component schemas.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// typeOf resolves a value or reflect.Type to its type, nil stays nil.
+func typeOf(v any) reflect.Type {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case reflect.Type:
+		return t
+	default:
+		return reflect.TypeOf(v)
+	}
+}
+
+// schemaFor returns the schema of the type, named structs are returned as references.
+func (r *schemaRegistry) schemaFor(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Minimum: float64Ptr(0)}, nil
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := r.schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("openapi: unsupported map key type %s", t.Key())
+		}
+		values, err := r.schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t, nil)
+		}
+		return r.componentRef(t)
+	default:
+		return nil, fmt.Errorf("openapi: unsupported type %s", t)
+	}
+}
+
+// componentRef registers a named struct type as component schema and returns a reference to it.
+func (r *schemaRegistry) componentRef(t reflect.Type) (*Schema, error) {
+	if name, ok := r.names[t]; ok {
+		return &Schema{Ref: componentSchemaPrefix + name}, nil
+	}
+
+	name := invalidNameChars.ReplaceAllString(t.Name(), "_")
+	for i := 2; ; i++ {
+		if _, taken := r.schemas[name]; !taken {
+			break
+		}
+		name = invalidNameChars.ReplaceAllString(t.Name(), "_") + strconv.Itoa(i)
+	}
+
+	// register the name before walking the fields to support recursive types
+	r.names[t] = name
+	r.schemas[name] = nil
+
+	schema, err := r.structSchema(t, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.schemas[name] = schema
+
+	return &Schema{Ref: componentSchemaPrefix + name}, nil
+}
+
+// structSchema returns the object schema of a struct. Fields for which skip
+// returns true are left out.
+func (r *schemaRegistry) structSchema(t reflect.Type, skip func(reflect.StructField) bool) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if err := r.addStructFields(schema, t, skip); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (r *schemaRegistry) addStructFields(schema *Schema, t reflect.Type, skip func(reflect.StructField) bool) error {
+	for field := range structFields(t) {
+		if skip != nil && skip(field) {
+			continue
+		}
+
+		name, omitEmpty, ok := fieldName(field, "json")
+		if !ok {
+			continue
+		}
+
+		// flatten embedded structs without an explicit name
+		if field.Anonymous && !hasTagName(field, "json") {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := r.addStructFields(schema, embedded, skip); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldSchema, err := r.schemaFor(field.Type)
+		if err != nil {
+			return fmt.Errorf("openapi: field %s.%s: %w", t.Name(), field.Name, err)
+		}
+		if description := field.Tag.Get("doc"); description != "" {
+			fieldSchema = withDescription(fieldSchema, description)
+		}
+		schema.Properties[name] = fieldSchema
+
+		if isRequiredField(field, omitEmpty) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return nil
+}
+
+// structFields yields the exported and embedded fields of a struct type.
+func structFields(t reflect.Type) func(yield func(reflect.StructField) bool) {
+	return func(yield func(reflect.StructField) bool) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			if !yield(field) {
+				return
+			}
+		}
+	}
+}
+
+// fieldName returns the name of the field for the given tag, whether the
+// field is marked as omitempty and false when the field is skipped.
+func fieldName(field reflect.StructField, tag string) (string, bool, bool) {
+	value, ok := field.Tag.Lookup(tag)
+	if !ok {
+		return field.Name, false, true
+	}
+	if value == "-" {
+		return "", false, false
+	}
+
+	name, options, _ := strings.Cut(value, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, strings.Contains(options, "omitempty") || strings.Contains(options, "omitzero"), true
+}
+
+func hasTagName(field reflect.StructField, tag string) bool {
+	value, ok := field.Tag.Lookup(tag)
+	if !ok {
+		return false
+	}
+	name, _, _ := strings.Cut(value, ",")
+	return name != ""
+}
+
+// isRequiredField reports whether a field must be present in a document.
+func isRequiredField(field reflect.StructField, omitEmpty bool) bool {
+	if hasValidateRule(field, "required") {
+		return true
+	}
+	return !omitEmpty && field.Type.Kind() != reflect.Pointer
+}
+
+// hasValidateRule reports whether the validate tag of the field contains the rule.
+func hasValidateRule(field reflect.StructField, rule string) bool {
+	for r := range strings.SplitSeq(field.Tag.Get("validate"), ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// withDescription returns a copy of the schema with a description. OpenAPI 3.1
+// allows sibling keywords next to "$ref", so references are copied as well.
+func withDescription(schema *Schema, description string) *Schema {
+	described := *schema
+	described.Description = description
+	return &described
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
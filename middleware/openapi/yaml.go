@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSON encodes the document as JSON.
+func (d *Document) JSON() ([]byte, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: failed to encode JSON document: %w", err)
+	}
+	return data, nil
+}
+
+// YAML encodes the document as YAML. Mapping keys are sorted, strings that
+// could be mistaken for other scalars are quoted.
+func (d *Document) YAML() ([]byte, error) {
+	data, err := d.JSON()
+	if err != nil {
+		return nil, err
+	}
+
+	// the JSON representation already honors all omitempty rules
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("openapi: failed to decode JSON document: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writeYAMLValue(&buf, tree, 0, false)
+	return buf.Bytes(), nil
+}
+
+// writeYAMLValue writes a block value at the given indentation. When compact is
+// true, the first line continues the current line, e.g. after a "- " indicator.
+func writeYAMLValue(buf *bytes.Buffer, value any, indent int, compact bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			if i > 0 || !compact {
+				writeIndent(buf, indent)
+			}
+			buf.WriteString(yamlKey(key))
+			buf.WriteByte(':')
+			writeYAMLChild(buf, v[key], indent)
+		}
+	case []any:
+		for i, item := range v {
+			if i > 0 || !compact {
+				writeIndent(buf, indent)
+			}
+			buf.WriteByte('-')
+			if m, ok := item.(map[string]any); ok && len(m) > 0 {
+				buf.WriteByte(' ')
+				writeYAMLValue(buf, m, indent+2, true)
+				continue
+			}
+			// nested sequences must be indented below their indicator
+			writeYAMLChild(buf, item, indent+2)
+		}
+	default:
+		if !compact {
+			writeIndent(buf, indent)
+		}
+		buf.WriteString(yamlScalar(v))
+		buf.WriteByte('\n')
+	}
+}
+
+// writeYAMLChild writes the value following a "key:" or "-" indicator.
+func writeYAMLChild(buf *bytes.Buffer, value any, indent int) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYAMLValue(buf, v, indent+2, false)
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYAMLValue(buf, v, indent, false)
+	default:
+		buf.WriteByte(' ')
+		buf.WriteString(yamlScalar(v))
+		buf.WriteByte('\n')
+	}
+}
+
+func writeIndent(buf *bytes.Buffer, indent int) {
+	for range indent {
+		buf.WriteByte(' ')
+	}
+}
+
+// yamlKey returns the key as plain scalar when it is safe to do so.
+func yamlKey(key string) string {
+	if isPlainYAML(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		if isPlainYAML(v) {
+			return v
+		}
+		return strconv.Quote(v)
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
+
+// isPlainYAML reports whether the string can be written without quotes. Only
+// strings starting with a letter and made of a conservative set of characters
+// qualify, and words YAML 1.1 parsers read as booleans or null are excluded.
+func isPlainYAML(s string) bool {
+	if s == "" {
+		return false
+	}
+	first := s[0]
+	if (first < 'a' || first > 'z') && (first < 'A' || first > 'Z') {
+		return false
+	}
+	for i := range len(s) {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '_', c == '-', c == '.', c == '/', c == ' ' && i < len(s)-1:
+		default:
+			return false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "nan", "inf":
+		return false
+	}
+	return true
+}
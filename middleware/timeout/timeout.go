@@ -81,6 +81,12 @@ func New(h fiber.Handler, config ...Config) fiber.Handler {
 			return fiber.ErrInternalServerError
 
 		case <-tCtx.Done():
+			if parent.Err() != nil {
+				// The parent context was canceled, e.g. because the client
+				// disconnected (fiber.Config.CancelOnDisconnect), so there is
+				// nobody left to answer.
+				return handleCanceled(parent, ctx, cancel, handlerDone)
+			}
 			// Timeout occurred - abandon context and return immediately.
 			// Reclamation is scheduled so the abandoned fiber.Ctx is returned to
 			// the pool once the handler goroutine finishes, instead of leaking.
@@ -131,6 +137,32 @@ func handleTimeout(
 		ctx.RequestCtx().TimeoutErrorWithResponse(&ctx.RequestCtx().Response)
 	}
 
+	abandon(parent, ctx, cancel, handlerDone)
+	return timeoutErr
+}
+
+// handleCanceled abandons the context like handleTimeout when the parent
+// context was canceled. The response is replaced so that the handler
+// goroutine can keep writing to the abandoned one.
+func handleCanceled(
+	parent context.Context,
+	ctx fiber.Ctx,
+	cancel context.CancelFunc,
+	handlerDone <-chan struct{},
+) error {
+	ctx.RequestCtx().TimeoutErrorWithCode(fiber.ErrRequestTimeout.Message, fiber.StatusRequestTimeout)
+	abandon(parent, ctx, cancel, handlerDone)
+	return nil
+}
+
+// abandon hands the context over to the handler goroutine that is still
+// running and reclaims it once the goroutine finishes.
+func abandon(
+	parent context.Context,
+	ctx fiber.Ctx,
+	cancel context.CancelFunc,
+	handlerDone <-chan struct{},
+) {
 	// Schedule race-free reclamation of the abandoned context. The context is
 	// returned to the pool only after BOTH the handler goroutine finishes AND
 	// Fiber's requestHandler releases the context (after any ErrorHandler runs),
@@ -144,7 +176,7 @@ func handleTimeout(
 	// signal, causing the reclamation goroutine to block forever.
 	if dc, ok := ctx.(*fiber.DefaultCtx); ok {
 		dc.ScheduleReclaim(handlerDone, cancel)
-		return timeoutErr
+		return
 	}
 
 	// Custom context implementations fall back to the previous behavior: once the
@@ -159,8 +191,6 @@ func handleTimeout(
 		cancel()
 		ctx.SetContext(parent)
 	}()
-
-	return timeoutErr
 }
 
 // invokeOnTimeout calls the OnTimeout handler if configured
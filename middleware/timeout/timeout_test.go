@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -650,3 +652,54 @@ func TestTimeout_AbandonWithoutReclaimNotPooled(t *testing.T) {
 	app.ReleaseCtx(ctx)
 	require.True(t, ctx.IsAbandoned(), "abandoned, un-armed context must not be auto-reclaimed")
 }
+
+// TestTimeout_ClientDisconnect verifies that the handler context is canceled
+// when the client disconnects, without calling OnTimeout.
+func TestTimeout_ClientDisconnect(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("connections can't be watched on windows")
+	}
+
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	var timeouts atomic.Int32
+
+	app := fiber.New(fiber.Config{CancelOnDisconnect: true})
+	app.Get("/", New(func(c fiber.Ctx) error {
+		close(started)
+		err := sleepWithContext(c.Context(), 5*time.Second, context.Cause(c.Context()))
+		causes <- context.Cause(c.Context())
+		return err
+	}, Config{
+		Timeout: 5 * time.Second,
+		OnTimeout: func(fiber.Ctx) error {
+			timeouts.Add(1)
+			return fiber.ErrRequestTimeout
+		},
+	}))
+
+	ln, err := net.Listen(fiber.NetworkTCP4, "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) //nolint:errcheck // closed by Shutdown
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	conn, err := net.Dial(fiber.NetworkTCP4, ln.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	<-started
+	require.NoError(t, conn.Close())
+
+	select {
+	case cause := <-causes:
+		require.ErrorIs(t, cause, fiber.ErrClientDisconnected)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the handler context wasn't canceled")
+	}
+	require.Zero(t, timeouts.Load())
+}
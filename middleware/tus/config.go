@@ -0,0 +1,104 @@
+package tus
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+)
+
+// CompleteHandler is called once all the content of an upload is received.
+// The file reads the assembled content and is only valid during the call.
+type CompleteHandler func(c fiber.Ctx, upload *Upload, file io.Reader) error
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// Storage stores the offsets and metadata of uploads.
+	//
+	// Optional. Default: an in-memory storage for this process only.
+	Storage fiber.Storage
+
+	// Sink stores the content of uploads.
+	//
+	// Optional. Default: NewFileSink(filepath.Join(os.TempDir(), "fiber-tus"))
+	Sink BlobSink
+
+	// OnComplete is called after the last chunk of an upload is received.
+	// An error is returned to the client of the last PATCH request, the
+	// upload stays complete.
+	//
+	// Optional. Default: nil
+	OnComplete CompleteHandler
+
+	// BasePath is the path uploads are created at, uploads are available at
+	// BasePath + "/" + id. Note that it should start with (but not end with)
+	// a slash.
+	//
+	// Optional. Default: "/files"
+	BasePath string
+
+	// MaxSize is the max size in bytes of an upload, announced with
+	// Tus-Max-Size. Larger uploads are rejected at creation.
+	//
+	// Optional. Default: 0 (no limit)
+	MaxSize int64
+
+	// Expiration is the time an upload can be resumed after its creation or
+	// its last chunk. Expired uploads are forgotten by the storage, sinks
+	// implementing ExpiringSink, like FileSink, are swept every half
+	// expiration to remove their content, from the first request of the
+	// handler until the app shuts down.
+	//
+	// Optional. Default: 24 * time.Hour
+	Expiration time.Duration
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:       nil,
+	Storage:    nil, // Set in configDefault so we don't allocate data here.
+	Sink:       nil, // Set in configDefault so we don't allocate data here.
+	OnComplete: nil,
+	BasePath:   "/files",
+	MaxSize:    0,
+	Expiration: 24 * time.Hour,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	cfg := ConfigDefault
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	// Set default values
+	cfg.BasePath = strings.TrimRight(cfg.BasePath, "/")
+	if cfg.BasePath == "" {
+		cfg.BasePath = ConfigDefault.BasePath
+	}
+	if cfg.MaxSize < 0 {
+		cfg.MaxSize = ConfigDefault.MaxSize
+	}
+	if cfg.Expiration <= 0 {
+		cfg.Expiration = ConfigDefault.Expiration
+	}
+	if cfg.Storage == nil {
+		cfg.Storage = memory.New(memory.Config{
+			GCInterval: cfg.Expiration / 2,
+		})
+	}
+	if cfg.Sink == nil {
+		cfg.Sink = NewFileSink(filepath.Join(os.TempDir(), "fiber-tus"))
+	}
+
+	return cfg
+}
@@ -0,0 +1,140 @@
+package tus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BlobSink stores the content of uploads. The ids passed to it are generated
+// by the middleware and safe to use as file names.
+//
+// The offset stored with an upload is the commit point of its content: data
+// written past it, e.g. by a chunk failing its checksum, is written again by
+// the next chunk, so WriteAt must overwrite existing content.
+type BlobSink interface {
+	// Create prepares the content of a new upload of size bytes.
+	Create(ctx context.Context, id string, size int64) error
+
+	// WriteAt writes the content read from r at offset and returns the
+	// number of bytes written, also when it returns an error.
+	WriteAt(ctx context.Context, id string, offset int64, r io.Reader) (int64, error)
+
+	// Open returns a reader of the content of an upload.
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+
+	// Delete removes the content of an upload. Deleting missing content is
+	// not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// ExpiringSink is a BlobSink listing the uploads it stores. The middleware
+// uses it to remove the content of expired uploads that are never requested
+// again, once the storage forgot their state.
+type ExpiringSink interface {
+	BlobSink
+
+	// Unchanged returns the ids of the uploads whose content was not written
+	// since before.
+	Unchanged(ctx context.Context, before time.Time) ([]string, error)
+}
+
+// FileSink stores the content of uploads as files in a directory, named by
+// the id of the upload.
+type FileSink struct {
+	dir string
+}
+
+// NewFileSink creates a sink storing files in dir. The directory is created
+// with the first upload.
+func NewFileSink(dir string) *FileSink {
+	return &FileSink{dir: dir}
+}
+
+// Path returns the path of the file of an upload.
+func (s *FileSink) Path(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+// Create creates the empty file of an upload.
+func (s *FileSink) Create(_ context.Context, id string, _ int64) error {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("tus: failed to create directory: %w", err)
+	}
+	file, err := os.OpenFile(s.Path(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("tus: failed to create file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("tus: failed to create file: %w", err)
+	}
+	return nil
+}
+
+// WriteAt writes the content read from r to the file at offset.
+func (s *FileSink) WriteAt(_ context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	file, err := os.OpenFile(s.Path(id), os.O_WRONLY, 0)
+	if err != nil {
+		return 0, fmt.Errorf("tus: failed to open file: %w", err)
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close() //nolint:errcheck // the seek error is reported
+		return 0, fmt.Errorf("tus: failed to seek file: %w", err)
+	}
+
+	n, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, fmt.Errorf("tus: failed to write file: %w", err)
+	}
+	return n, nil
+}
+
+// Open opens the file of an upload. The reader is an *os.File.
+func (s *FileSink) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	file, err := os.Open(s.Path(id))
+	if err != nil {
+		return nil, fmt.Errorf("tus: failed to open file: %w", err)
+	}
+	return file, nil
+}
+
+// Delete removes the file of an upload.
+func (s *FileSink) Delete(_ context.Context, id string) error {
+	if err := os.Remove(s.Path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("tus: failed to remove file: %w", err)
+	}
+	return nil
+}
+
+// Unchanged returns the ids of the files not modified since before.
+func (s *FileSink) Unchanged(_ context.Context, before time.Time) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tus: failed to read directory: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // removed meanwhile
+		}
+		if info.ModTime().Before(before) {
+			ids = append(ids, entry.Name())
+		}
+	}
+	return ids, nil
+}
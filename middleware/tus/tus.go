@@ -0,0 +1,488 @@
+package tus
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by the checksum extension
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/utils/v2"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/log"
+)
+
+const (
+	// Version is the version of the tus protocol implemented.
+	Version = "1.0.0"
+	// Extensions are the tus extensions implemented.
+	Extensions = "creation,expiration,termination,checksum"
+	// ChecksumAlgorithms are the algorithms supported by the checksum extension.
+	ChecksumAlgorithms = "sha1,sha256,sha512"
+
+	// StatusChecksumMismatch is the status of chunks failing their checksum.
+	StatusChecksumMismatch = 460
+
+	// MIMEOffsetOctetStream is the content type of PATCH requests.
+	MIMEOffsetOctetStream = "application/offset+octet-stream"
+)
+
+// Headers of the tus protocol.
+const (
+	HeaderTusResumable         = "Tus-Resumable"
+	HeaderTusVersion           = "Tus-Version"
+	HeaderTusExtension         = "Tus-Extension"
+	HeaderTusMaxSize           = "Tus-Max-Size"
+	HeaderTusChecksumAlgorithm = "Tus-Checksum-Algorithm"
+	HeaderUploadOffset         = "Upload-Offset"
+	HeaderUploadLength         = "Upload-Length"
+	HeaderUploadMetadata       = "Upload-Metadata"
+	HeaderUploadExpires        = "Upload-Expires"
+	HeaderUploadChecksum       = "Upload-Checksum"
+	HeaderXHTTPMethodOverride  = "X-HTTP-Method-Override"
+)
+
+// ErrChecksumMismatch is returned for chunks failing their Upload-Checksum.
+// Their content isn't committed and has to be sent again.
+var ErrChecksumMismatch = fiber.NewError(StatusChecksumMismatch, "Checksum Mismatch")
+
+// idLength is the length of the hex-encoded random upload ids.
+const idLength = 32
+
+var checksumHashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+type handler struct {
+	locks    map[string]struct{}
+	cfg      Config
+	mu       sync.Mutex
+	sweeping sync.Once
+}
+
+// New creates a new middleware handler serving tus uploads at
+// Config.BasePath.
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+
+	h := &handler{cfg: cfg, locks: make(map[string]struct{})}
+	sink, expiring := cfg.Sink.(ExpiringSink)
+
+	// Return new handler
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		if expiring {
+			h.sweeping.Do(func() {
+				go h.sweep(c.App(), sink)
+			})
+		}
+
+		path, found := strings.CutPrefix(c.Path(), cfg.BasePath)
+		if !found {
+			return c.Next()
+		}
+		var id string
+		if path != "" && path != "/" {
+			if path[0] != '/' || strings.IndexByte(path[1:], '/') >= 0 {
+				return c.Next()
+			}
+			id = path[1:]
+		}
+
+		method := c.Method()
+		if override := c.Get(HeaderXHTTPMethodOverride); override != "" && method == fiber.MethodPost {
+			method = utils.ToUpper(override)
+		}
+
+		var serve func(c fiber.Ctx, id string) error
+		switch {
+		case method == fiber.MethodOptions:
+			serve = h.options
+		case method == fiber.MethodPost && id == "":
+			serve = h.create
+		case method == fiber.MethodHead && id != "":
+			serve = h.head
+		case method == fiber.MethodPatch && id != "":
+			serve = h.patch
+		case method == fiber.MethodDelete && id != "":
+			serve = h.terminate
+		default:
+			return c.Next()
+		}
+
+		c.Set(HeaderTusResumable, Version)
+		if method != fiber.MethodOptions && c.Get(HeaderTusResumable) != Version {
+			c.Set(HeaderTusVersion, Version)
+			return fiber.ErrPreconditionFailed
+		}
+		return serve(c, id)
+	}
+}
+
+// options announces the supported protocol versions and extensions.
+func (h *handler) options(c fiber.Ctx, _ string) error {
+	c.Set(HeaderTusVersion, Version)
+	c.Set(HeaderTusExtension, Extensions)
+	c.Set(HeaderTusChecksumAlgorithm, ChecksumAlgorithms)
+	if h.cfg.MaxSize > 0 {
+		c.Set(HeaderTusMaxSize, strconv.FormatInt(h.cfg.MaxSize, 10))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// create creates an upload, implementing the creation extension.
+func (h *handler) create(c fiber.Ctx, _ string) error {
+	size, err := strconv.ParseInt(c.Get(HeaderUploadLength), 10, 64)
+	if err != nil || size < 0 {
+		return fiber.ErrBadRequest
+	}
+	if h.cfg.MaxSize > 0 && size > h.cfg.MaxSize {
+		return fiber.ErrRequestEntityTooLarge
+	}
+	metadata, err := parseMetadata(c.Get(HeaderUploadMetadata))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	upload := &Upload{
+		ID:        id,
+		Size:      size,
+		Metadata:  metadata,
+		ExpiresAt: time.Now().Add(h.cfg.Expiration),
+	}
+
+	ctx := c.Context()
+	if err := h.cfg.Sink.Create(ctx, id, size); err != nil {
+		return err //nolint:wrapcheck // sinks describe their errors
+	}
+	if err := h.save(ctx, upload); err != nil {
+		_ = h.cfg.Sink.Delete(ctx, id) //nolint:errcheck // the storage error is reported
+		return err
+	}
+
+	c.Set(fiber.HeaderLocation, h.cfg.BasePath+"/"+id)
+	c.Set(HeaderUploadExpires, upload.ExpiresAt.UTC().Format(http.TimeFormat))
+
+	// empty uploads are complete at creation
+	if upload.Complete() {
+		if err := h.complete(c, upload); err != nil {
+			return err
+		}
+	}
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// head returns the offset of an upload.
+func (h *handler) head(c fiber.Ctx, id string) error {
+	upload, err := h.find(c, id)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(HeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	c.Set(HeaderUploadLength, strconv.FormatInt(upload.Size, 10))
+	c.Set(HeaderUploadExpires, upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if len(upload.Metadata) > 0 {
+		c.Set(HeaderUploadMetadata, encodeMetadata(upload.Metadata))
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// patch writes a chunk of an upload at its offset.
+func (h *handler) patch(c fiber.Ctx, id string) error {
+	if !strings.EqualFold(utils.TrimSpace(c.Get(fiber.HeaderContentType)), MIMEOffsetOctetStream) {
+		return fiber.ErrUnsupportedMediaType
+	}
+	offset, err := strconv.ParseInt(c.Get(HeaderUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return fiber.ErrBadRequest
+	}
+	hasher, sum, err := parseChecksum(c.Get(HeaderUploadChecksum))
+	if err != nil {
+		return err
+	}
+
+	if !h.lock(id) {
+		return fiber.ErrLocked
+	}
+	defer h.unlock(id)
+
+	upload, err := h.find(c, id)
+	if err != nil {
+		return err
+	}
+	if offset != upload.Offset {
+		return fiber.ErrConflict
+	}
+	remaining := upload.Size - offset
+	if length := c.Request().Header.ContentLength(); length > 0 && int64(length) > remaining {
+		return fiber.ErrRequestEntityTooLarge
+	}
+
+	var body io.Reader
+	if c.Request().IsBodyStream() {
+		body = c.Request().BodyStream()
+	} else {
+		body = bytes.NewReader(c.BodyRaw())
+	}
+	body = io.LimitReader(body, remaining)
+	if hasher != nil {
+		body = io.TeeReader(body, hasher)
+	}
+
+	ctx := c.Context()
+	n, writeErr := h.cfg.Sink.WriteAt(ctx, id, offset, body)
+	if hasher != nil {
+		// unverified content isn't committed
+		if writeErr != nil {
+			return writeErr //nolint:wrapcheck // sinks describe their errors
+		}
+		if subtle.ConstantTimeCompare(hasher.Sum(nil), sum) != 1 {
+			return ErrChecksumMismatch
+		}
+	}
+
+	upload.Offset += n
+	upload.ExpiresAt = time.Now().Add(h.cfg.Expiration)
+	if err := h.save(ctx, upload); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr //nolint:wrapcheck // sinks describe their errors
+	}
+
+	c.Set(HeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	c.Set(HeaderUploadExpires, upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if n > 0 && upload.Complete() {
+		if err := h.complete(c, upload); err != nil {
+			return err
+		}
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// terminate deletes an upload, implementing the termination extension.
+func (h *handler) terminate(c fiber.Ctx, id string) error {
+	if !h.lock(id) {
+		return fiber.ErrLocked
+	}
+	defer h.unlock(id)
+
+	if _, err := h.find(c, id); err != nil {
+		return err
+	}
+	if err := h.remove(c.Context(), id); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// complete passes the content of a complete upload to OnComplete.
+func (h *handler) complete(c fiber.Ctx, upload *Upload) error {
+	if h.cfg.OnComplete == nil {
+		return nil
+	}
+	file, err := h.cfg.Sink.Open(c.Context(), upload.ID)
+	if err != nil {
+		return err //nolint:wrapcheck // sinks describe their errors
+	}
+	defer file.Close() //nolint:errcheck // the file is only read
+
+	return h.cfg.OnComplete(c, upload, file)
+}
+
+// find returns the upload with the id, fiber.ErrNotFound for unknown ids
+// and fiber.ErrGone for expired uploads, which are removed.
+func (h *handler) find(c fiber.Ctx, id string) (*Upload, error) {
+	if !validID(id) {
+		return nil, fiber.ErrNotFound
+	}
+	ctx := c.Context()
+	upload, err := h.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload == nil {
+		return nil, fiber.ErrNotFound
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		if err := h.remove(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, fiber.ErrGone
+	}
+	return upload, nil
+}
+
+// load returns the upload with the id, nil if the storage does not know it.
+func (h *handler) load(ctx context.Context, id string) (*Upload, error) {
+	raw, err := h.cfg.Storage.GetWithContext(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("tus: failed to get upload: %w", err)
+	}
+	if raw == nil {
+		return nil, nil //nolint:nilnil // unknown uploads are not an error
+	}
+
+	upload := new(Upload)
+	if err := json.Unmarshal(raw, upload); err != nil {
+		return nil, fmt.Errorf("tus: failed to decode upload: %w", err)
+	}
+	return upload, nil
+}
+
+// save stores the upload until it expires.
+func (h *handler) save(ctx context.Context, upload *Upload) error {
+	raw, err := json.Marshal(upload)
+	if err != nil {
+		return fmt.Errorf("tus: failed to encode upload: %w", err)
+	}
+	if err := h.cfg.Storage.SetWithContext(ctx, upload.ID, raw, time.Until(upload.ExpiresAt)); err != nil {
+		return fmt.Errorf("tus: failed to store upload: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the content and the state of an upload.
+func (h *handler) remove(ctx context.Context, id string) error {
+	if err := h.cfg.Sink.Delete(ctx, id); err != nil {
+		return err //nolint:wrapcheck // sinks describe their errors
+	}
+	if err := h.cfg.Storage.DeleteWithContext(ctx, id); err != nil {
+		return fmt.Errorf("tus: failed to delete upload: %w", err)
+	}
+	return nil
+}
+
+// sweep removes the expired uploads of sink every half expiration, until app
+// is shut down. It's started by the first request of the handler.
+func (h *handler) sweep(app *fiber.App, sink ExpiringSink) {
+	done := make(chan struct{})
+	stop := sync.OnceFunc(func() { close(done) })
+	// registered here, a request must not wait for the app mutex held by
+	// Shutdown
+	app.Hooks().OnPostShutdown(func(error) error {
+		stop()
+		return nil
+	})
+
+	ticker := time.NewTicker(h.cfg.Expiration / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.removeExpired(context.Background(), sink)
+		case <-done:
+			return
+		}
+	}
+}
+
+// removeExpired removes the uploads of sink not written to during the
+// expiration time, when they expired or the storage forgot them. The storage
+// deletes the state of expired uploads, their content would stay forever if
+// they are never requested again.
+func (h *handler) removeExpired(ctx context.Context, sink ExpiringSink) {
+	ids, err := sink.Unchanged(ctx, time.Now().Add(-h.cfg.Expiration))
+	if err != nil {
+		log.Errorf("tus: failed to list uploads: %v", err)
+		return
+	}
+	for _, id := range ids {
+		if !validID(id) || !h.lock(id) {
+			continue
+		}
+		upload, err := h.load(ctx, id)
+		if err == nil && (upload == nil || time.Now().After(upload.ExpiresAt)) {
+			err = h.remove(ctx, id)
+		}
+		if err != nil {
+			log.Errorf("tus: failed to remove expired upload %s: %v", id, err)
+		}
+		h.unlock(id)
+	}
+}
+
+// lock reserves an upload for a request, false if another request holds it.
+func (h *handler) lock(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.locks[id]; ok {
+		return false
+	}
+	h.locks[id] = struct{}{}
+	return true
+}
+
+func (h *handler) unlock(id string) {
+	h.mu.Lock()
+	delete(h.locks, id)
+	h.mu.Unlock()
+}
+
+// parseChecksum parses an Upload-Checksum header, the name of the algorithm
+// and the base64-encoded checksum separated by a space.
+func parseChecksum(header string) (hash.Hash, []byte, error) {
+	if header == "" {
+		return nil, nil, nil
+	}
+	algorithm, encoded, found := strings.Cut(utils.TrimSpace(header), " ")
+	newHash, ok := checksumHashes[utils.ToLower(algorithm)]
+	if !found || !ok {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "unsupported checksum algorithm")
+	}
+	sum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "invalid checksum")
+	}
+	return newHash(), sum, nil
+}
+
+// validID reports whether id has the format of the generated ids, so that
+// sinks never see ids chosen by clients.
+func validID(id string) bool {
+	if len(id) != idLength {
+		return false
+	}
+	for i := range len(id) {
+		if (id[i] < '0' || id[i] > '9') && (id[i] < 'a' || id[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newID returns a random upload id.
+func newID() (string, error) {
+	var b [idLength / 2]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("tus: failed to generate upload id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,396 @@
+package tus
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/internal/storage/memory"
+)
+
+func tusRequest(method, target, body string, headers ...string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(HeaderTusResumable, Version)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return req
+}
+
+func createUpload(t *testing.T, app *fiber.App, size int, headers ...string) string {
+	t.Helper()
+	resp, err := app.Test(tusRequest(fiber.MethodPost, "/files", "", append([]string{HeaderUploadLength, strconv.Itoa(size)}, headers...)...))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	location := resp.Header.Get(fiber.HeaderLocation)
+	require.True(t, strings.HasPrefix(location, "/files/"), location)
+	return location
+}
+
+func patchUpload(t *testing.T, app *fiber.App, location string, offset int, chunk string, headers ...string) *http.Response {
+	t.Helper()
+	resp, err := app.Test(tusRequest(fiber.MethodPatch, location, chunk, append([]string{
+		fiber.HeaderContentType, MIMEOffsetOctetStream,
+		HeaderUploadOffset, strconv.Itoa(offset),
+	}, headers...)...))
+	require.NoError(t, err)
+	return resp
+}
+
+// go test -run Test_Tus
+func Test_Tus(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var (
+		completed atomic.Int32
+		received  string
+		filename  string
+	)
+	app := fiber.New()
+	app.Use(New(Config{
+		Sink: NewFileSink(dir),
+		OnComplete: func(_ fiber.Ctx, upload *Upload, file io.Reader) error {
+			completed.Add(1)
+			content, err := io.ReadAll(file)
+			if err != nil {
+				return err
+			}
+			received = string(content)
+			filename = upload.Metadata["filename"]
+			return nil
+		},
+	}))
+	app.Get("/files/:id", func(c fiber.Ctx) error {
+		return c.SendString("download " + c.Params("id"))
+	})
+
+	// OPTIONS announces the protocol without requiring Tus-Resumable
+	req := httptest.NewRequest(fiber.MethodOptions, "/files", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, Version, resp.Header.Get(HeaderTusVersion))
+	require.Equal(t, Extensions, resp.Header.Get(HeaderTusExtension))
+	require.Equal(t, ChecksumAlgorithms, resp.Header.Get(HeaderTusChecksumAlgorithm))
+	require.Empty(t, resp.Header.Get(HeaderTusMaxSize))
+
+	location := createUpload(t, app, 11, HeaderUploadMetadata, "filename "+base64.StdEncoding.EncodeToString([]byte("hello.txt"))+",private")
+	id := strings.TrimPrefix(location, "/files/")
+	require.FileExists(t, filepath.Join(dir, id))
+
+	resp, err = app.Test(tusRequest(fiber.MethodHead, location, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "0", resp.Header.Get(HeaderUploadOffset))
+	require.Equal(t, "11", resp.Header.Get(HeaderUploadLength))
+	require.Equal(t, "no-store", resp.Header.Get(fiber.HeaderCacheControl))
+	require.Equal(t, "filename aGVsbG8udHh0,private", resp.Header.Get(HeaderUploadMetadata))
+	require.NotEmpty(t, resp.Header.Get(HeaderUploadExpires))
+	require.Equal(t, Version, resp.Header.Get(HeaderTusResumable))
+
+	resp = patchUpload(t, app, location, 0, "hello ")
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "6", resp.Header.Get(HeaderUploadOffset))
+	require.Zero(t, completed.Load())
+
+	// chunks must continue at the offset
+	resp = patchUpload(t, app, location, 3, "world")
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	resp = patchUpload(t, app, location, 6, "world")
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "11", resp.Header.Get(HeaderUploadOffset))
+	require.Equal(t, int32(1), completed.Load())
+	require.Equal(t, "hello world", received)
+	require.Equal(t, "hello.txt", filename)
+
+	// empty chunks of complete uploads don't complete them again
+	resp = patchUpload(t, app, location, 11, "")
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, int32(1), completed.Load())
+
+	// other methods and paths reach the next handlers
+	resp, err = app.Test(tusRequest(fiber.MethodGet, location, ""))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "download "+id, string(body))
+
+	// termination
+	resp, err = app.Test(tusRequest(fiber.MethodDelete, location, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.NoFileExists(t, filepath.Join(dir, id))
+
+	resp, err = app.Test(tusRequest(fiber.MethodHead, location, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	// empty uploads are complete at creation
+	createUpload(t, app, 0)
+	require.Equal(t, int32(2), completed.Load())
+	require.Empty(t, received)
+}
+
+// go test -run Test_Tus_Errors
+func Test_Tus_Errors(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{Sink: NewFileSink(t.TempDir()), MaxSize: 10}))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodOptions, "/files", nil))
+	require.NoError(t, err)
+	require.Equal(t, "10", resp.Header.Get(HeaderTusMaxSize))
+
+	// unsupported protocol versions
+	req := tusRequest(fiber.MethodPost, "/files", "", HeaderUploadLength, "5")
+	req.Header.Set(HeaderTusResumable, "0.2.2")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+	require.Equal(t, Version, resp.Header.Get(HeaderTusVersion))
+
+	resp, err = app.Test(tusRequest(fiber.MethodPost, "/files", "", HeaderUploadLength, "11"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	for _, headers := range [][]string{
+		{},
+		{HeaderUploadLength, "-1"},
+		{HeaderUploadLength, "5", HeaderUploadMetadata, "filename !!"},
+		{HeaderUploadLength, "5", HeaderUploadMetadata, "a,a"},
+	} {
+		resp, err = app.Test(tusRequest(fiber.MethodPost, "/files", "", headers...))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, headers)
+	}
+
+	location := createUpload(t, app, 5)
+
+	resp, err = app.Test(tusRequest(fiber.MethodPatch, location, "12", fiber.HeaderContentType, fiber.MIMEOctetStream, HeaderUploadOffset, "0"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode)
+
+	resp = patchUpload(t, app, location, 0, "123456")
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp = patchUpload(t, app, location, 0, "12", HeaderUploadChecksum, "md5 AAAA")
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	for _, target := range []string{"/files/unknown", "/files/" + strings.Repeat("A", idLength)} {
+		resp, err = app.Test(tusRequest(fiber.MethodHead, target, ""))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusNotFound, resp.StatusCode, target)
+	}
+	resp, err = app.Test(tusRequest(fiber.MethodHead, "/files/a/b", ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	require.Empty(t, resp.Header.Get(HeaderTusResumable))
+}
+
+// go test -run Test_Tus_Checksum
+func Test_Tus_Checksum(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{Sink: NewFileSink(t.TempDir())}))
+	location := createUpload(t, app, 8)
+
+	checksum := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+	}
+
+	// mismatching chunks aren't committed
+	resp := patchUpload(t, app, location, 0, "abcd", HeaderUploadChecksum, checksum("abce"))
+	require.Equal(t, StatusChecksumMismatch, resp.StatusCode)
+	resp, err := app.Test(tusRequest(fiber.MethodHead, location, ""))
+	require.NoError(t, err)
+	require.Equal(t, "0", resp.Header.Get(HeaderUploadOffset))
+
+	resp = patchUpload(t, app, location, 0, "abcd", HeaderUploadChecksum, checksum("abcd"))
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "4", resp.Header.Get(HeaderUploadOffset))
+}
+
+// go test -run Test_Tus_Expiration
+func Test_Tus_Expiration(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	storage := memory.New()
+	app := fiber.New()
+	app.Use(New(Config{Storage: storage, Sink: NewFileSink(dir), Expiration: time.Hour}))
+	location := createUpload(t, app, 8)
+	id := strings.TrimPrefix(location, "/files/")
+
+	// chunks extend the expiration
+	resp := patchUpload(t, app, location, 0, "abcd")
+	expires, err := http.ParseTime(resp.Header.Get(HeaderUploadExpires))
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Hour), expires, 2*time.Second)
+
+	// expired uploads still known to the storage are gone
+	raw, err := storage.Get(id)
+	require.NoError(t, err)
+	var upload Upload
+	require.NoError(t, json.Unmarshal(raw, &upload))
+	require.Equal(t, int64(4), upload.Offset)
+	upload.ExpiresAt = time.Now().Add(-time.Second)
+	raw, err = json.Marshal(upload)
+	require.NoError(t, err)
+	require.NoError(t, storage.Set(id, raw, 0))
+
+	resp = patchUpload(t, app, location, 4, "efgh")
+	require.Equal(t, fiber.StatusGone, resp.StatusCode)
+	require.NoFileExists(t, filepath.Join(dir, id))
+
+	resp, err = app.Test(tusRequest(fiber.MethodHead, location, ""))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+// go test -run Test_Tus_Expiration_Sweep
+func Test_Tus_Expiration_Sweep(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	storage := memory.New()
+	cfg := Config{Storage: storage, Sink: NewFileSink(dir), Expiration: time.Hour}
+	app := fiber.New()
+	app.Use(New(cfg))
+
+	ids := make(map[string]string)
+	for _, name := range []string{"forgotten", "expired", "active", "recent"} {
+		ids[name] = strings.TrimPrefix(createUpload(t, app, 8), "/files/")
+	}
+	require.NoError(t, storage.Delete(ids["forgotten"]))
+	require.NoError(t, storage.Delete(ids["recent"]))
+	raw, err := storage.Get(ids["expired"])
+	require.NoError(t, err)
+	var upload Upload
+	require.NoError(t, json.Unmarshal(raw, &upload))
+	upload.ExpiresAt = time.Now().Add(-time.Second)
+	raw, err = json.Marshal(upload)
+	require.NoError(t, err)
+	require.NoError(t, storage.Set(ids["expired"], raw, 0))
+
+	// not written to during the expiration time
+	old := time.Now().Add(-2 * time.Hour)
+	for _, name := range []string{"forgotten", "expired", "active"} {
+		require.NoError(t, os.Chtimes(filepath.Join(dir, ids[name]), old, old))
+	}
+
+	h := &handler{cfg: configDefault(cfg), locks: make(map[string]struct{})}
+	sink, ok := h.cfg.Sink.(ExpiringSink)
+	require.True(t, ok)
+	h.removeExpired(t.Context(), sink)
+
+	require.NoFileExists(t, filepath.Join(dir, ids["forgotten"]))
+	require.NoFileExists(t, filepath.Join(dir, ids["expired"]))
+	require.FileExists(t, filepath.Join(dir, ids["active"]))
+	require.FileExists(t, filepath.Join(dir, ids["recent"]))
+	raw, err = storage.Get(ids["expired"])
+	require.NoError(t, err)
+	require.Nil(t, raw)
+}
+
+// go test -run Test_Tus_Expiration_SweepShutdown
+func Test_Tus_Expiration_SweepShutdown(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	h := &handler{cfg: configDefault(Config{Sink: NewFileSink(t.TempDir())}), locks: make(map[string]struct{})}
+	sink, ok := h.cfg.Sink.(ExpiringSink)
+	require.True(t, ok)
+
+	done := make(chan struct{})
+	go func() {
+		h.sweep(app, sink)
+		close(done)
+	}()
+
+	// the sweeper registers its hook once started, shut the app down until
+	// it stopped
+	deadline := time.After(5 * time.Second)
+	for {
+		require.NoError(t, app.Shutdown())
+		select {
+		case <-done:
+			return
+		case <-deadline:
+			t.Fatal("the sweeper didn't stop")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// go test -run Test_Tus_MethodOverride
+func Test_Tus_MethodOverride(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Use(New(Config{Sink: NewFileSink(t.TempDir()), BasePath: "/api/uploads/"}))
+
+	resp, err := app.Test(tusRequest(fiber.MethodPost, "/api/uploads", "", HeaderUploadLength, "4"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	location := resp.Header.Get(fiber.HeaderLocation)
+	require.True(t, strings.HasPrefix(location, "/api/uploads/"), location)
+
+	resp, err = app.Test(tusRequest(fiber.MethodPost, location, "abcd",
+		HeaderXHTTPMethodOverride, "patch",
+		fiber.HeaderContentType, MIMEOffsetOctetStream,
+		HeaderUploadOffset, "0",
+	))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "4", resp.Header.Get(HeaderUploadOffset))
+}
+
+// go test -run Test_Tus_OnComplete_Error
+func Test_Tus_OnComplete_Error(t *testing.T) {
+	t.Parallel()
+
+	errProcess := errors.New("processing failed")
+	dir := t.TempDir()
+	app := fiber.New()
+	app.Use(New(Config{
+		Sink: NewFileSink(dir),
+		OnComplete: func(_ fiber.Ctx, upload *Upload, file io.Reader) error {
+			f, ok := file.(*os.File)
+			if !ok || f.Name() != filepath.Join(dir, upload.ID) {
+				return errors.New("unexpected file")
+			}
+			return fiber.NewError(fiber.StatusUnprocessableEntity, errProcess.Error())
+		},
+	}))
+	location := createUpload(t, app, 2)
+
+	resp := patchUpload(t, app, location, 0, "ab")
+	require.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, errProcess.Error(), string(body))
+
+	// the upload stays complete
+	resp, err = app.Test(tusRequest(fiber.MethodHead, location, ""))
+	require.NoError(t, err)
+	require.Equal(t, "2", resp.Header.Get(HeaderUploadOffset))
+}
@@ -0,0 +1,76 @@
+package tus
+
+import (
+	"encoding/base64"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMetadata is returned for Upload-Metadata headers that can't be
+// decoded.
+var ErrInvalidMetadata = errors.New("tus: invalid Upload-Metadata")
+
+// Upload is the state of an upload.
+type Upload struct {
+	// Metadata holds the decoded Upload-Metadata of the upload, e.g. the
+	// "filename" chosen by the client. Keys sent without a value map to "".
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// ExpiresAt is the time after which the upload can't be resumed.
+	ExpiresAt time.Time `json:"expires_at"`
+	// ID identifies the upload in its URL and in the BlobSink.
+	ID string `json:"id"`
+	// Size is the announced size in bytes of the upload.
+	Size int64 `json:"size"`
+	// Offset is the number of bytes received.
+	Offset int64 `json:"offset"`
+}
+
+// Complete reports whether all the content of the upload is received.
+func (u *Upload) Complete() bool {
+	return u.Offset == u.Size
+}
+
+// parseMetadata decodes an Upload-Metadata header: comma-separated pairs of
+// a key and an optional base64-encoded value, separated by a space.
+func parseMetadata(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil //nolint:nilnil // no metadata is valid
+	}
+
+	metadata := make(map[string]string)
+	for pair := range strings.SplitSeq(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" || strings.ContainsAny(key, " ,") {
+			return nil, ErrInvalidMetadata
+		}
+		if _, ok := metadata[key]; ok {
+			return nil, ErrInvalidMetadata
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, ErrInvalidMetadata
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
+
+// encodeMetadata encodes metadata as an Upload-Metadata header, the keys
+// sorted.
+func encodeMetadata(metadata map[string]string) string {
+	var b strings.Builder
+	for i, key := range slices.Sorted(maps.Keys(metadata)) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(key)
+		if value := metadata[key]; value != "" {
+			b.WriteByte(' ')
+			b.WriteString(base64.StdEncoding.EncodeToString([]byte(value)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Handler serves a single WebSocket connection. The connection is closed
+// once it returns, with CloseInternalServerErr if it returns an error.
+type Handler func(conn *Conn) error
+
+// Config defines the config for middleware.
+type Config struct {
+	// Handler serves the upgraded connections.
+	//
+	// Required.
+	Handler Handler
+
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c fiber.Ctx) bool
+
+	// AllowOrigins lists the origins, e.g. "https://example.com", allowed to
+	// open a connection from a browser. "*" allows all of them. When empty,
+	// only the origin of the request host is allowed. Requests without an
+	// Origin header, i.e. not sent by a browser, are always allowed.
+	//
+	// Optional. Default: nil
+	AllowOrigins []string
+
+	// Subprotocols lists the supported subprotocols in order of preference.
+	// The first one the client offers in Sec-WebSocket-Protocol is selected.
+	//
+	// Optional. Default: nil
+	Subprotocols []string
+
+	// MaxMessageSize is the maximum size of a received message, after
+	// decompression. Larger messages close the connection with
+	// CloseMessageTooBig.
+	//
+	// Optional. Default: 1048576 (1 MiB)
+	MaxMessageSize int
+
+	// EnableCompression negotiates the permessage-deflate extension
+	// (RFC 7692) with the clients offering it.
+	//
+	// Optional. Default: false
+	EnableCompression bool
+
+	// PingInterval is the interval of the pings sent to the client. A
+	// connection that receives nothing, not even a pong, for PingInterval
+	// plus PongTimeout is closed.
+	//
+	// Optional. Default: 30 * time.Second
+	PingInterval time.Duration
+
+	// PongTimeout is the time the client has to answer a ping.
+	//
+	// Optional. Default: 10 * time.Second
+	PongTimeout time.Duration
+
+	// DisableKeepalive disables the pings and the read timeout.
+	//
+	// Optional. Default: false
+	DisableKeepalive bool
+
+	// WriteTimeout is the maximum duration of writing a message.
+	//
+	// Optional. Default: 10 * time.Second
+	WriteTimeout time.Duration
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Handler:           nil,
+	Next:              nil,
+	AllowOrigins:      nil,
+	Subprotocols:      nil,
+	MaxMessageSize:    1024 * 1024,
+	EnableCompression: false,
+	PingInterval:      30 * time.Second,
+	PongTimeout:       10 * time.Second,
+	DisableKeepalive:  false,
+	WriteTimeout:      10 * time.Second,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = ConfigDefault.MaxMessageSize
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = ConfigDefault.PingInterval
+	}
+	if cfg.PongTimeout <= 0 {
+		cfg.PongTimeout = ConfigDefault.PongTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = ConfigDefault.WriteTimeout
+	}
+
+	return cfg
+}
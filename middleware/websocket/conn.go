@@ -0,0 +1,469 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Close codes of RFC 6455 §7.4.1.
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseMandatoryExtension      = 1010
+	CloseInternalServerErr       = 1011
+	CloseServiceRestart          = 1012
+	CloseTryAgainLater           = 1013
+)
+
+var (
+	// ErrClosed is returned when writing to a connection after it was closed.
+	ErrClosed = errors.New("websocket: connection closed")
+	// ErrMessageType is returned when writing a message that is neither a
+	// TextMessage nor a BinaryMessage.
+	ErrMessageType = errors.New("websocket: invalid message type")
+)
+
+// CloseError is returned by ReadMessage once the connection is closed. It
+// holds the close code and reason sent by the client, or by the server if
+// it closed the connection. A connection that broke without a close
+// message has the code CloseAbnormalClosure.
+type CloseError struct {
+	Text string
+	Code int
+}
+
+func (e *CloseError) Error() string {
+	if e.Text == "" {
+		return "websocket: close " + strconv.Itoa(e.Code)
+	}
+	return "websocket: close " + strconv.Itoa(e.Code) + ": " + e.Text
+}
+
+// IsCloseError reports whether err is a *CloseError with one of the codes,
+// any code if none are given.
+func IsCloseError(err error, codes ...int) bool {
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) {
+		return false
+	}
+	if len(codes) == 0 {
+		return true
+	}
+	for _, code := range codes {
+		if closeErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Conn is an upgraded WebSocket connection. One goroutine may read messages
+// while others write them concurrently. The connection must not be used
+// after the Handler returned.
+type Conn struct {
+	c           fiber.Ctx
+	netConn     net.Conn
+	br          *bufio.Reader
+	ctx         context.Context //nolint:containedctx // the context lives as long as the connection
+	cancel      context.CancelCauseFunc
+	cfg         *Config
+	readErr     error // only used by the reading goroutine
+	subprotocol string
+	writeBuf    []byte // guarded by writeMu
+	headerBuf   [8]byte
+	writeMu     sync.Mutex
+	closeSent   bool // guarded by writeMu
+	compress    bool
+}
+
+func newConn(c fiber.Ctx, netConn net.Conn, cfg *Config, subprotocol string, compress bool) *Conn {
+	ctx, cancel := context.WithCancelCause(context.WithoutCancel(c.Context()))
+	return &Conn{
+		c:           c,
+		netConn:     netConn,
+		br:          bufio.NewReader(netConn),
+		ctx:         ctx,
+		cancel:      cancel,
+		cfg:         cfg,
+		subprotocol: subprotocol,
+		compress:    compress,
+	}
+}
+
+// serve runs handler and closes the connection once it returns.
+func (c *Conn) serve(handler Handler) {
+	if !c.cfg.DisableKeepalive {
+		go c.keepalive()
+	}
+
+	code := CloseNormalClosure
+	if err := c.run(handler); err != nil {
+		code = CloseInternalServerErr
+	}
+	_ = c.Close(code, "") //nolint:errcheck // the connection is closed anyway
+	c.awaitClose()
+}
+
+func (c *Conn) run(handler Handler) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("websocket: handler panic: %v", recovered)
+		}
+	}()
+	return handler(c)
+}
+
+// keepalive pings the client until the connection is closed.
+func (c *Conn) keepalive() {
+	ticker := time.NewTicker(c.cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.writeFrame(PingMessage, false, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// awaitClose waits for the close message answering the one sent by the
+// server, unless the reader already saw it, so that the client can read
+// the whole close handshake before the connection is closed.
+func (c *Conn) awaitClose() {
+	if c.readErr != nil {
+		return
+	}
+	for {
+		h, err := readFrameHeader(c.br, c.headerBuf[:])
+		if err != nil || h.opcode == CloseMessage {
+			return
+		}
+		if _, err := io.CopyN(io.Discard, c.br, h.length); err != nil {
+			return
+		}
+	}
+}
+
+// Context returns a context carrying the values of the request context. It
+// is canceled once the connection is closed, with a *CloseError as its
+// cause.
+func (c *Conn) Context() context.Context {
+	return c.ctx
+}
+
+// Locals returns the value stored under key in the Locals of the upgrade
+// request, or stores value under key, see fiber.Ctx.Locals.
+func (c *Conn) Locals(key any, value ...any) any {
+	return c.c.Locals(key, value...)
+}
+
+// Params returns the route parameter of the upgrade request, see
+// fiber.Ctx.Params.
+func (c *Conn) Params(key string, defaultValue ...string) string {
+	return c.c.Params(key, defaultValue...)
+}
+
+// Query returns the query parameter of the upgrade request, see
+// fiber.Ctx.Query.
+func (c *Conn) Query(key string, defaultValue ...string) string {
+	return c.c.Query(key, defaultValue...)
+}
+
+// Cookies returns the cookie of the upgrade request, see fiber.Ctx.Cookies.
+func (c *Conn) Cookies(key string, defaultValue ...string) string {
+	return c.c.Cookies(key, defaultValue...)
+}
+
+// Get returns the header of the upgrade request, see fiber.Ctx.Get.
+func (c *Conn) Get(key string, defaultValue ...string) string {
+	return c.c.Get(key, defaultValue...)
+}
+
+// IP returns the client IP of the upgrade request, see fiber.Ctx.IP.
+func (c *Conn) IP() string {
+	return c.c.IP()
+}
+
+// Subprotocol returns the negotiated subprotocol, empty if none.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// LocalAddr returns the local network address.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.netConn.LocalAddr()
+}
+
+// RemoteAddr returns the remote network address.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.netConn.RemoteAddr()
+}
+
+// ReadMessage returns the next TextMessage or BinaryMessage. Pings are
+// answered while reading. Once the connection is closed, it returns a
+// *CloseError, see IsCloseError.
+func (c *Conn) ReadMessage() (messageType int, p []byte, err error) { //nolint:nonamedreturns // documents the results
+	if c.readErr != nil {
+		return 0, nil, c.readErr
+	}
+	messageType, p, err = c.readMessage()
+	if err != nil {
+		c.readErr = err
+	}
+	return messageType, p, err
+}
+
+// ReadJSON reads the next message and unmarshals it into v with the
+// JSONDecoder of the app.
+func (c *Conn) ReadJSON(v any) error {
+	_, p, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return c.c.App().Config().JSONDecoder(p, v)
+}
+
+func (c *Conn) readMessage() (int, []byte, error) {
+	var (
+		message     []byte
+		messageType int
+		compressed  bool
+	)
+	for {
+		if !c.cfg.DisableKeepalive && c.ctx.Err() == nil {
+			if err := c.netConn.SetReadDeadline(time.Now().Add(c.cfg.PingInterval + c.cfg.PongTimeout)); err != nil {
+				return 0, nil, c.fail(CloseAbnormalClosure, err)
+			}
+		}
+		h, err := readFrameHeader(c.br, c.headerBuf[:])
+		switch {
+		case errors.Is(err, errReservedBits), errors.Is(err, errFrameLength):
+			return 0, nil, c.fail(CloseProtocolError, err)
+		case err != nil:
+			return 0, nil, c.fail(CloseAbnormalClosure, err)
+		case !h.masked:
+			return 0, nil, c.fail(CloseProtocolError, errUnmaskedFrame)
+		case h.rsv1 && (!c.compress || h.opcode == continuationFrame || h.opcode >= CloseMessage):
+			return 0, nil, c.fail(CloseProtocolError, errCompressedCtl)
+		}
+
+		switch h.opcode {
+		case CloseMessage, PingMessage, PongMessage:
+			if !h.fin || h.length > maxControlPayload {
+				return 0, nil, c.fail(CloseProtocolError, errControlFrame)
+			}
+			payload, err := c.readPayload(h, nil)
+			if err != nil {
+				return 0, nil, c.fail(CloseAbnormalClosure, err)
+			}
+			switch h.opcode {
+			case PingMessage:
+				if err := c.writeFrame(PongMessage, false, payload); err != nil {
+					return 0, nil, c.fail(CloseAbnormalClosure, err)
+				}
+			case CloseMessage:
+				return 0, nil, c.closeReceived(payload)
+			default:
+				// a pong only extends the read deadline
+			}
+			continue
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, errExpectedCont)
+			}
+			messageType, compressed = h.opcode, h.rsv1
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, errUnexpectedCont)
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, errUnknownOpcode)
+		}
+
+		if int64(len(message))+h.length > int64(c.cfg.MaxMessageSize) {
+			return 0, nil, c.fail(CloseMessageTooBig, errMessageTooBig)
+		}
+		if message, err = c.readPayload(h, message); err != nil {
+			return 0, nil, c.fail(CloseAbnormalClosure, err)
+		}
+		if !h.fin {
+			continue
+		}
+
+		if compressed {
+			if message, err = decompressMessage(message, c.cfg.MaxMessageSize); errors.Is(err, errMessageTooBig) {
+				return 0, nil, c.fail(CloseMessageTooBig, err)
+			} else if err != nil {
+				return 0, nil, c.fail(CloseInvalidFramePayloadData, err)
+			}
+		}
+		if messageType == TextMessage && !utf8.Valid(message) {
+			return 0, nil, c.fail(CloseInvalidFramePayloadData, errInvalidUTF8)
+		}
+		return messageType, message, nil
+	}
+}
+
+// readPayload appends the unmasked payload of the frame to dst.
+func (c *Conn) readPayload(h frameHeader, dst []byte) ([]byte, error) {
+	start := len(dst)
+	dst = append(dst, make([]byte, h.length)...)
+	if _, err := io.ReadFull(c.br, dst[start:]); err != nil {
+		return dst, err //nolint:wrapcheck // io errors are returned as is
+	}
+	maskBytes(h.mask, 0, dst[start:])
+	return dst, nil
+}
+
+// closeReceived answers the close message of the client.
+func (c *Conn) closeReceived(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+	switch {
+	case len(payload) == 1:
+		return c.fail(CloseProtocolError, errControlFrame)
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Text = string(payload[2:])
+		if !validCloseCode(closeErr.Code) || !utf8.ValidString(closeErr.Text) {
+			return c.fail(CloseProtocolError, errControlFrame)
+		}
+	default:
+		// no status code
+	}
+	_ = c.writeClose(closeErr.Code, "") //nolint:errcheck // the connection is closed anyway
+	c.cancel(closeErr)
+	return closeErr
+}
+
+// fail closes the connection because of err, sending a close message with
+// code unless the connection broke, and returns the resulting *CloseError.
+// A read failing once the server closed the connection returns the
+// *CloseError of Close.
+func (c *Conn) fail(code int, err error) error {
+	var closeErr *CloseError
+	if c.ctx.Err() != nil && errors.As(context.Cause(c.ctx), &closeErr) {
+		return closeErr
+	}
+	closeErr = &CloseError{Code: code, Text: err.Error()}
+	if code != CloseAbnormalClosure {
+		_ = c.writeClose(code, closeErr.Text) //nolint:errcheck // the connection is closed anyway
+	}
+	c.cancel(closeErr)
+	_ = c.netConn.SetDeadline(time.Now()) //nolint:errcheck // unblocks the writers
+	return closeErr
+}
+
+// validCloseCode reports whether code may be sent in a close message.
+func validCloseCode(code int) bool {
+	switch {
+	case code >= CloseNormalClosure && code <= CloseUnsupportedData,
+		code >= CloseInvalidFramePayloadData && code <= CloseTryAgainLater,
+		code >= 3000 && code <= 4999:
+		return true
+	default:
+		return false
+	}
+}
+
+// WriteMessage sends a TextMessage or BinaryMessage.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return ErrMessageType
+	}
+	if c.compress {
+		return c.writeFrame(messageType, true, compressMessage(data))
+	}
+	return c.writeFrame(messageType, false, data)
+}
+
+// WriteJSON marshals v with the JSONEncoder of the app and sends it as a
+// TextMessage.
+func (c *Conn) WriteJSON(v any) error {
+	data, err := c.c.App().Config().JSONEncoder(v)
+	if err != nil {
+		return err //nolint:wrapcheck // the encoder error is returned as is
+	}
+	return c.WriteMessage(TextMessage, data)
+}
+
+// Close sends a close message with code and reason and cancels the
+// context of the connection. A reader blocked in ReadMessage returns the
+// close message of the client, or after PongTimeout a *CloseError with
+// code. Close is called with CloseNormalClosure when the Handler returns,
+// if it wasn't before.
+func (c *Conn) Close(code int, reason string) error {
+	err := c.writeClose(code, reason)
+	c.cancel(&CloseError{Code: code, Text: reason})
+	if deadlineErr := c.netConn.SetReadDeadline(time.Now().Add(c.cfg.PongTimeout)); err == nil {
+		err = deadlineErr
+	}
+	return err
+}
+
+// writeClose sends a close message, unless one was sent already.
+func (c *Conn) writeClose(code int, reason string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return nil
+	}
+	c.closeSent = true
+
+	var payload []byte
+	if code != CloseNoStatusReceived {
+		payload = binary.BigEndian.AppendUint16(make([]byte, 0, maxControlPayload), uint16(code)) //nolint:gosec // close codes fit in 16 bits
+		if len(reason) > maxControlPayload-2 {
+			reason = reason[:maxControlPayload-2]
+		}
+		payload = append(payload, reason...)
+	}
+	return c.writeFrameLocked(CloseMessage, false, payload)
+}
+
+// writeFrame sends a frame, unless a close message was sent already.
+func (c *Conn) writeFrame(opcode int, compressed bool, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrClosed
+	}
+	return c.writeFrameLocked(opcode, compressed, payload)
+}
+
+func (c *Conn) writeFrameLocked(opcode int, compressed bool, payload []byte) error {
+	if err := c.netConn.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout)); err != nil {
+		return err //nolint:wrapcheck // net errors are returned as is
+	}
+	buf := appendFrameHeader(c.writeBuf[:0], opcode, compressed, len(payload))
+	buf = append(buf, payload...)
+	_, err := c.netConn.Write(buf)
+	if cap(buf) <= maxFrameHeader+4096 {
+		c.writeBuf = buf
+	}
+	if err != nil {
+		c.cancel(&CloseError{Code: CloseAbnormalClosure, Text: err.Error()})
+		return err //nolint:wrapcheck // net errors are returned as is
+	}
+	return nil
+}
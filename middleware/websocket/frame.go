@@ -0,0 +1,168 @@
+package websocket
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sync"
+)
+
+// Message types, the opcodes of RFC 6455 §5.2.
+const (
+	// TextMessage denotes a UTF-8 encoded text message.
+	TextMessage = 1
+	// BinaryMessage denotes a binary message.
+	BinaryMessage = 2
+	// CloseMessage denotes a close control message.
+	CloseMessage = 8
+	// PingMessage denotes a ping control message.
+	PingMessage = 9
+	// PongMessage denotes a pong control message.
+	PongMessage = 10
+)
+
+const (
+	continuationFrame = 0
+
+	finBit  = 1 << 7
+	rsv1Bit = 1 << 6
+	rsv2Bit = 1 << 5
+	rsv3Bit = 1 << 4
+	maskBit = 1 << 7
+
+	maxControlPayload = 125
+	maxFrameHeader    = 14
+)
+
+var (
+	errReservedBits   = errors.New("reserved bits set")
+	errFrameLength    = errors.New("invalid frame length")
+	errUnmaskedFrame  = errors.New("client frames must be masked")
+	errControlFrame   = errors.New("invalid control frame")
+	errUnexpectedCont = errors.New("unexpected continuation frame")
+	errExpectedCont   = errors.New("expected continuation frame")
+	errUnknownOpcode  = errors.New("unknown opcode")
+	errCompressedCtl  = errors.New("compressed control or continuation frame")
+	errInvalidUTF8    = errors.New("invalid UTF-8 in text message")
+	errMessageTooBig  = errors.New("message too big")
+)
+
+// frameHeader is the header of a received frame.
+type frameHeader struct {
+	length int64
+	opcode int
+	mask   [4]byte
+	fin    bool
+	rsv1   bool
+	masked bool
+}
+
+// readFrameHeader reads the header of the next frame from r.
+func readFrameHeader(r io.Reader, buf []byte) (frameHeader, error) {
+	var h frameHeader
+	if _, err := io.ReadFull(r, buf[:2]); err != nil {
+		return h, err //nolint:wrapcheck // io errors are returned as is
+	}
+	if buf[0]&(rsv2Bit|rsv3Bit) != 0 {
+		return h, errReservedBits
+	}
+	h.fin = buf[0]&finBit != 0
+	h.rsv1 = buf[0]&rsv1Bit != 0
+	h.opcode = int(buf[0] & 0x0f)
+	h.masked = buf[1]&maskBit != 0
+
+	switch length := buf[1] &^ maskBit; length {
+	case 126:
+		if _, err := io.ReadFull(r, buf[:2]); err != nil {
+			return h, err //nolint:wrapcheck // io errors are returned as is
+		}
+		h.length = int64(binary.BigEndian.Uint16(buf))
+	case 127:
+		if _, err := io.ReadFull(r, buf[:8]); err != nil {
+			return h, err //nolint:wrapcheck // io errors are returned as is
+		}
+		length := binary.BigEndian.Uint64(buf)
+		if length > math.MaxInt64 {
+			return h, errFrameLength
+		}
+		h.length = int64(length)
+	default:
+		h.length = int64(length)
+	}
+
+	if h.masked {
+		if _, err := io.ReadFull(r, h.mask[:]); err != nil {
+			return h, err //nolint:wrapcheck // io errors are returned as is
+		}
+	}
+	return h, nil
+}
+
+// appendFrameHeader appends the header of an unmasked frame to dst.
+func appendFrameHeader(dst []byte, opcode int, compressed bool, length int) []byte {
+	b0 := byte(finBit | opcode) //nolint:gosec // opcodes fit in a byte
+	if compressed {
+		b0 |= rsv1Bit
+	}
+	switch {
+	case length <= maxControlPayload:
+		return append(dst, b0, byte(length))
+	case length <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, b0, 126), uint16(length))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, b0, 127), uint64(length))
+	}
+}
+
+// maskBytes applies the mask to b, starting at position pos of the mask,
+// and returns the position for the following bytes.
+func maskBytes(mask [4]byte, pos int, b []byte) int {
+	for i := range b {
+		b[i] ^= mask[(pos+i)&3]
+	}
+	return (pos + len(b)) & 3
+}
+
+// deflateTail completes a message compressed with permessage-deflate, whose
+// sync flush marker is stripped (RFC 7692 §7.2.2), and adds a final empty
+// block so that the decompressor reports io.EOF.
+var deflateTail = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+
+var flateWriterPool sync.Pool
+
+// compressMessage compresses a message for permessage-deflate without
+// context takeover.
+func compressMessage(data []byte) []byte {
+	var buf bytes.Buffer
+	fw, ok := flateWriterPool.Get().(*flate.Writer)
+	if ok {
+		fw.Reset(&buf)
+	} else {
+		fw, _ = flate.NewWriter(&buf, flate.BestSpeed) //nolint:errcheck // the level is valid
+	}
+	fw.Write(data) //nolint:errcheck // writes to a bytes.Buffer don't fail
+	fw.Flush()     //nolint:errcheck // writes to a bytes.Buffer don't fail
+	flateWriterPool.Put(fw)
+
+	// strip the 0x00 0x00 0xff 0xff marker of the sync flush
+	b := buf.Bytes()
+	return b[:len(b)-4]
+}
+
+// decompressMessage decompresses a permessage-deflate message, which must
+// not exceed limit bytes once decompressed.
+func decompressMessage(data []byte, limit int) ([]byte, error) {
+	fr := flate.NewReader(io.MultiReader(bytes.NewReader(data), bytes.NewReader(deflateTail)))
+	defer fr.Close() //nolint:errcheck // closing a flate reader doesn't fail
+	out, err := io.ReadAll(io.LimitReader(fr, int64(limit)+1))
+	if err != nil {
+		return nil, err //nolint:wrapcheck // the flate error is returned as is
+	}
+	if len(out) > limit {
+		return nil, errMessageTooBig
+	}
+	return out, nil
+}
@@ -0,0 +1,169 @@
+// Package websocket implements WebSocket connections (RFC 6455) for Fiber.
+//
+// The middleware accepts the upgrade request, hijacks the connection once
+// the 101 response is sent and serves it with Config.Handler. The Ctx of the
+// request stays valid while the connection is open, so route parameters,
+// Locals and the request context set by earlier middleware remain
+// accessible through the Conn.
+package websocket
+
+import (
+	"crypto/sha1" //nolint:gosec // RFC 6455 mandates SHA-1 for the accept key
+	"encoding/base64"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/utils/v2"
+	utilsstrings "github.com/gofiber/utils/v2/strings"
+)
+
+const (
+	// acceptGUID is the GUID of RFC 6455 §1.3 appended to the key.
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// deflateResponse accepts permessage-deflate without context takeover,
+	// so that each message is compressed on its own.
+	deflateResponse = "permessage-deflate; server_no_context_takeover; client_no_context_takeover"
+)
+
+// New creates a new middleware handler that upgrades WebSocket requests and
+// serves the connections with Config.Handler. Requests that aren't WebSocket
+// upgrades are rejected with 426 Upgrade Required.
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+	if cfg.Handler == nil {
+		panic("websocket: Handler must not be nil")
+	}
+
+	// Return new handler
+	return func(c fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		if !c.IsWebSocket() || c.Method() != fiber.MethodGet {
+			c.Set(fiber.HeaderUpgrade, "websocket")
+			return fiber.ErrUpgradeRequired
+		}
+		if c.Get(fiber.HeaderSecWebSocketVersion) != "13" {
+			c.Set(fiber.HeaderSecWebSocketVersion, "13")
+			return fiber.ErrUpgradeRequired
+		}
+		key := c.Get(fiber.HeaderSecWebSocketKey)
+		if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+			return fiber.NewError(fiber.StatusBadRequest, "websocket: invalid "+fiber.HeaderSecWebSocketKey)
+		}
+		if !allowOrigin(c, cfg.AllowOrigins) {
+			return fiber.NewError(fiber.StatusForbidden, "websocket: origin not allowed")
+		}
+
+		subprotocol := selectSubprotocol(headerValues(c, fiber.HeaderSecWebSocketProtocol), cfg.Subprotocols)
+		compress := cfg.EnableCompression && offersDeflate(headerValues(c, fiber.HeaderSecWebSocketExtensions))
+
+		c.Status(fiber.StatusSwitchingProtocols)
+		c.Set(fiber.HeaderUpgrade, "websocket")
+		c.Set(fiber.HeaderConnection, "Upgrade")
+		c.Set(fiber.HeaderSecWebSocketAccept, acceptKey(key))
+		if subprotocol != "" {
+			c.Set(fiber.HeaderSecWebSocketProtocol, subprotocol)
+		}
+		if compress {
+			c.Set(fiber.HeaderSecWebSocketExtensions, deflateResponse)
+		}
+
+		// fasthttp calls the hijack handler once the request handler returned
+		// and the response is sent, so c can be kept until the connection is
+		// closed and then released without racing with the request handler.
+		// If the response can't be sent, c is left to the garbage collector.
+		c.Abandon()
+		c.RequestCtx().Hijack(func(netConn net.Conn) {
+			conn := newConn(c, netConn, &cfg, subprotocol, compress)
+			conn.serve(cfg.Handler)
+			c.ForceRelease()
+		})
+
+		return nil
+	}
+}
+
+// acceptKey returns the Sec-WebSocket-Accept value for key.
+func acceptKey(key string) string {
+	h := sha1.New() //nolint:gosec // RFC 6455 mandates SHA-1 for the accept key
+	h.Write([]byte(key))
+	h.Write([]byte(acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerValues returns the comma-separated elements of all values of the
+// request header key.
+func headerValues(c fiber.Ctx, key string) []string {
+	var values []string
+	for _, value := range c.Request().Header.PeekAll(key) {
+		for element := range strings.SplitSeq(utils.UnsafeString(value), ",") {
+			if element = utils.TrimSpace(element); element != "" {
+				values = append(values, element)
+			}
+		}
+	}
+	return values
+}
+
+// allowOrigin reports whether the Origin of the request is allowed.
+func allowOrigin(c fiber.Ctx, allowed []string) bool {
+	origin := c.Get(fiber.HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+	if len(allowed) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && utils.EqualFold(u.Host, c.Host())
+	}
+	for _, allow := range allowed {
+		if allow == "*" || utils.EqualFold(allow, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectSubprotocol returns the first supported subprotocol the client
+// offers, in the order of supported.
+func selectSubprotocol(offered, supported []string) string {
+	for _, protocol := range supported {
+		if slices.Contains(offered, protocol) {
+			return protocol
+		}
+	}
+	return ""
+}
+
+// offersDeflate reports whether the client offers permessage-deflate with
+// parameters deflateResponse can accept. The window of the compressor
+// can't be reduced, so offers limiting server_max_window_bits are declined.
+func offersDeflate(extensions []string) bool {
+	for _, extension := range extensions {
+		params := strings.Split(extension, ";")
+		if !utils.EqualFold(utils.TrimSpace(params[0]), "permessage-deflate") {
+			continue
+		}
+		acceptable := true
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(utils.TrimSpace(param), "=")
+			switch utilsstrings.ToLower(utils.TrimSpace(name)) {
+			case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+			case "server_max_window_bits":
+				acceptable = acceptable && strings.Trim(utils.TrimSpace(value), `"`) == "15"
+			default:
+				acceptable = false
+			}
+		}
+		if acceptable {
+			return true
+		}
+	}
+	return false
+}
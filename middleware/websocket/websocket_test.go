@@ -0,0 +1,397 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "dGhlIHNhbXBsZSBub25jZQ=="
+
+func listen(t *testing.T, app *fiber.App) string {
+	t.Helper()
+
+	ln, err := net.Listen(fiber.NetworkTCP4, "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		if err := app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}); err != nil && !errors.Is(err, net.ErrClosed) {
+			panic(err)
+		}
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+	return ln.Addr().String()
+}
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+	resp *http.Response
+}
+
+// dial sends an upgrade request for target with the headers, given as
+// key-value pairs, and reads the response.
+func dial(t *testing.T, addr, target string, headers ...string) *testClient {
+	t.Helper()
+
+	conn, err := net.Dial(fiber.NetworkTCP4, addr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close() //nolint:errcheck // the server may have closed it
+	})
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	var req strings.Builder
+	req.WriteString("GET " + target + " HTTP/1.1\r\nHost: " + addr + "\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	req.WriteString("Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: " + testKey + "\r\n")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.WriteString(headers[i] + ": " + headers[i+1] + "\r\n")
+	}
+	req.WriteString("\r\n")
+	_, err = conn.Write([]byte(req.String()))
+	require.NoError(t, err)
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	return &testClient{t: t, conn: conn, br: br, resp: resp}
+}
+
+// writeFrame sends a masked frame with the first header byte b0.
+func (tc *testClient) writeFrame(b0 byte, payload []byte) {
+	tc.t.Helper()
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	frame := []byte{b0}
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, maskBit|byte(len(payload)))
+	default:
+		frame = binary.BigEndian.AppendUint16(append(frame, maskBit|126), uint16(len(payload))) //nolint:gosec // test payloads are small
+	}
+	frame = append(frame, mask[:]...)
+	masked := append([]byte(nil), payload...)
+	maskBytes(mask, 0, masked)
+	_, err := tc.conn.Write(append(frame, masked...))
+	require.NoError(tc.t, err)
+}
+
+// readFrame reads an unmasked frame and returns its first header byte.
+func (tc *testClient) readFrame() (byte, []byte) {
+	tc.t.Helper()
+	h, err := readFrameHeader(tc.br, make([]byte, 8))
+	require.NoError(tc.t, err)
+	require.False(tc.t, h.masked)
+	payload := make([]byte, h.length)
+	_, err = io.ReadFull(tc.br, payload)
+	require.NoError(tc.t, err)
+	b0 := byte(h.opcode)
+	if h.fin {
+		b0 |= finBit
+	}
+	if h.rsv1 {
+		b0 |= rsv1Bit
+	}
+	return b0, payload
+}
+
+// readClose reads frames until a close message and returns its code.
+func (tc *testClient) readClose() int {
+	tc.t.Helper()
+	for {
+		b0, payload := tc.readFrame()
+		if int(b0&0x0f) == CloseMessage {
+			if len(payload) < 2 {
+				return CloseNoStatusReceived
+			}
+			return int(binary.BigEndian.Uint16(payload))
+		}
+	}
+}
+
+func closePayload(code int, reason string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(code)), reason...) //nolint:gosec // valid close codes
+}
+
+// go test -run Test_WebSocket_Handshake
+func Test_WebSocket_Handshake(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{
+		Handler: func(*Conn) error {
+			return nil
+		},
+		AllowOrigins: []string{"https://example.com"},
+	}))
+
+	for _, tc := range []struct {
+		header  map[string]string
+		name    string
+		status  int
+		respKey string
+		respVal string
+	}{
+		{name: "not an upgrade", header: map[string]string{}, status: fiber.StatusUpgradeRequired, respKey: fiber.HeaderUpgrade, respVal: "websocket"},
+		{name: "version", header: map[string]string{fiber.HeaderSecWebSocketVersion: "8", fiber.HeaderSecWebSocketKey: testKey}, status: fiber.StatusUpgradeRequired, respKey: fiber.HeaderSecWebSocketVersion, respVal: "13"},
+		{name: "key", header: map[string]string{fiber.HeaderSecWebSocketVersion: "13", fiber.HeaderSecWebSocketKey: "short"}, status: fiber.StatusBadRequest},
+		{name: "origin", header: map[string]string{fiber.HeaderSecWebSocketVersion: "13", fiber.HeaderSecWebSocketKey: testKey, fiber.HeaderOrigin: "https://evil.com"}, status: fiber.StatusForbidden},
+	} {
+		req := httptest.NewRequest(fiber.MethodGet, "/ws", http.NoBody)
+		if tc.name != "not an upgrade" {
+			req.Header.Set(fiber.HeaderConnection, "Upgrade")
+			req.Header.Set(fiber.HeaderUpgrade, "websocket")
+		}
+		for key, val := range tc.header {
+			req.Header.Set(key, val)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.status, resp.StatusCode, tc.name)
+		if tc.respKey != "" {
+			require.Equal(t, tc.respVal, resp.Header.Get(tc.respKey), tc.name)
+		}
+	}
+
+	require.PanicsWithValue(t, "websocket: Handler must not be nil", func() {
+		New()
+	})
+}
+
+// go test -run Test_WebSocket_Origin
+func Test_WebSocket_Origin(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{
+		Handler: func(*Conn) error {
+			return nil
+		},
+	}))
+	addr := listen(t, app)
+
+	for origin, status := range map[string]int{
+		"":                      fiber.StatusSwitchingProtocols,
+		"http://" + addr:        fiber.StatusSwitchingProtocols,
+		"https://other.example": fiber.StatusForbidden,
+	} {
+		var headers []string
+		if origin != "" {
+			headers = []string{fiber.HeaderOrigin, origin}
+		}
+		tc := dial(t, addr, "/ws", headers...)
+		require.Equal(t, status, tc.resp.StatusCode, origin)
+	}
+}
+
+// go test -run Test_WebSocket_Echo
+func Test_WebSocket_Echo(t *testing.T) {
+	t.Parallel()
+
+	closed := make(chan error, 1)
+	app := fiber.New()
+	app.Use(requestid.New(requestid.Config{
+		Generator: func() string {
+			return "request-1"
+		},
+	}))
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("user", "john")
+		return c.Next()
+	})
+	app.Get("/rooms/:room", New(Config{
+		Subprotocols: []string{"chat.v2", "chat.v1"},
+		Handler: func(conn *Conn) error {
+			prefix := fmt.Sprintf("%s/%v/%s/%s/%s: ", conn.Params("room"), conn.Locals("user"), requestid.FromContext(conn), conn.Query("lang"), conn.Subprotocol())
+			for {
+				messageType, message, err := conn.ReadMessage()
+				if err != nil {
+					<-conn.Context().Done()
+					closed <- err
+					return nil
+				}
+				if err := conn.WriteMessage(messageType, append([]byte(prefix), message...)); err != nil {
+					return err
+				}
+			}
+		},
+	}))
+	addr := listen(t, app)
+
+	tc := dial(t, addr, "/rooms/general?lang=en", fiber.HeaderSecWebSocketProtocol, "chat.v1, chat.v2")
+	require.Equal(t, fiber.StatusSwitchingProtocols, tc.resp.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", tc.resp.Header.Get(fiber.HeaderSecWebSocketAccept))
+	require.Equal(t, "chat.v2", tc.resp.Header.Get(fiber.HeaderSecWebSocketProtocol))
+	require.Empty(t, tc.resp.Header.Get(fiber.HeaderSecWebSocketExtensions))
+
+	tc.writeFrame(finBit|TextMessage, []byte("hello"))
+	b0, payload := tc.readFrame()
+	require.Equal(t, byte(finBit|TextMessage), b0)
+	require.Equal(t, "general/john/request-1/en/chat.v2: hello", string(payload))
+
+	// fragmented message with an interleaved ping
+	tc.writeFrame(BinaryMessage, []byte("frag"))
+	tc.writeFrame(finBit|PingMessage, []byte("ping"))
+	tc.writeFrame(finBit|continuationFrame, []byte("mented"))
+	b0, payload = tc.readFrame()
+	require.Equal(t, byte(finBit|PongMessage), b0)
+	require.Equal(t, "ping", string(payload))
+	b0, payload = tc.readFrame()
+	require.Equal(t, byte(finBit|BinaryMessage), b0)
+	require.Equal(t, "general/john/request-1/en/chat.v2: fragmented", string(payload))
+
+	tc.writeFrame(finBit|CloseMessage, closePayload(CloseGoingAway, "bye"))
+	require.Equal(t, CloseGoingAway, tc.readClose())
+	err := <-closed
+	require.True(t, IsCloseError(err, CloseGoingAway))
+	require.EqualError(t, err, "websocket: close 1001: bye")
+}
+
+// go test -run Test_WebSocket_Close
+func Test_WebSocket_Close(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{
+		MaxMessageSize: 10,
+		Handler: func(conn *Conn) error {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return nil //nolint:nilerr // the connection is closed already
+			}
+			switch string(message) {
+			case "fail":
+				return errors.New("failed")
+			case "panic":
+				panic("boom")
+			case "close":
+				return conn.Close(4000, "custom")
+			default:
+				_, _, err = conn.ReadMessage()
+				return err
+			}
+		},
+	}))
+	addr := listen(t, app)
+
+	for _, tc := range []struct {
+		name    string
+		b0      byte
+		payload []byte
+		code    int
+	}{
+		{name: "returns", b0: finBit | TextMessage, payload: []byte("fail"), code: CloseInternalServerErr},
+		{name: "panics", b0: finBit | TextMessage, payload: []byte("panic"), code: CloseInternalServerErr},
+		{name: "closes", b0: finBit | TextMessage, payload: []byte("close"), code: 4000},
+		{name: "too big", b0: finBit | BinaryMessage, payload: []byte("more than ten bytes"), code: CloseMessageTooBig},
+		{name: "invalid utf-8", b0: finBit | TextMessage, payload: []byte{0xff, 0xfe}, code: CloseInvalidFramePayloadData},
+		{name: "reserved bits", b0: finBit | rsv2Bit | TextMessage, payload: []byte("x"), code: CloseProtocolError},
+		{name: "compressed", b0: finBit | rsv1Bit | TextMessage, payload: []byte("x"), code: CloseProtocolError},
+		{name: "continuation", b0: finBit | continuationFrame, payload: []byte("x"), code: CloseProtocolError},
+		{name: "opcode", b0: finBit | 3, payload: []byte("x"), code: CloseProtocolError},
+	} {
+		client := dial(t, addr, "/ws")
+		client.writeFrame(tc.b0, tc.payload)
+		require.Equal(t, tc.code, client.readClose(), tc.name)
+		client.writeFrame(finBit|CloseMessage, closePayload(tc.code, ""))
+		// the server closes the connection after the close handshake
+		_, err := client.br.ReadByte()
+		require.ErrorIs(t, err, io.EOF, tc.name)
+	}
+
+	// unmasked frames
+	client := dial(t, addr, "/ws")
+	_, err := client.conn.Write([]byte{finBit | TextMessage, 1, 'x'})
+	require.NoError(t, err)
+	require.Equal(t, CloseProtocolError, client.readClose())
+}
+
+// go test -run Test_WebSocket_Compression
+func Test_WebSocket_Compression(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/ws", New(Config{
+		EnableCompression: true,
+		Handler: func(conn *Conn) error {
+			var v map[string]string
+			if err := conn.ReadJSON(&v); err != nil {
+				return err
+			}
+			v["reply"] = strings.Repeat("pong ", 20)
+			return conn.WriteJSON(v)
+		},
+	}))
+	addr := listen(t, app)
+
+	// offers limiting the window of the server are declined
+	tc := dial(t, addr, "/ws", fiber.HeaderSecWebSocketExtensions, "permessage-deflate; server_max_window_bits=10")
+	require.Empty(t, tc.resp.Header.Get(fiber.HeaderSecWebSocketExtensions))
+
+	tc = dial(t, addr, "/ws", fiber.HeaderSecWebSocketExtensions, "permessage-deflate; server_max_window_bits=10, permessage-deflate; client_max_window_bits")
+	require.Equal(t, deflateResponse, tc.resp.Header.Get(fiber.HeaderSecWebSocketExtensions))
+
+	var buf bytes.Buffer
+	fw, err := flate.NewWriter(&buf, flate.BestCompression)
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(`{"ping":"` + strings.Repeat("ping ", 20) + `"}`))
+	require.NoError(t, err)
+	require.NoError(t, fw.Flush())
+	tc.writeFrame(finBit|rsv1Bit|TextMessage, bytes.TrimSuffix(buf.Bytes(), []byte{0x00, 0x00, 0xff, 0xff}))
+
+	b0, payload := tc.readFrame()
+	require.Equal(t, byte(finBit|rsv1Bit|TextMessage), b0)
+	message, err := decompressMessage(payload, 1024)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"ping":"`+strings.Repeat("ping ", 20)+`","reply":"`+strings.Repeat("pong ", 20)+`"}`, string(message))
+	require.Less(t, len(payload), len(message))
+	require.Equal(t, CloseNormalClosure, tc.readClose())
+}
+
+// go test -run Test_WebSocket_Keepalive
+func Test_WebSocket_Keepalive(t *testing.T) {
+	t.Parallel()
+
+	closed := make(chan error, 1)
+	app := fiber.New()
+	app.Get("/ws", New(Config{
+		PingInterval: 50 * time.Millisecond,
+		PongTimeout:  50 * time.Millisecond,
+		Handler: func(conn *Conn) error {
+			_, _, err := conn.ReadMessage()
+			closed <- err
+			return nil
+		},
+	}))
+	addr := listen(t, app)
+
+	tc := dial(t, addr, "/ws")
+	for range 3 {
+		b0, _ := tc.readFrame()
+		require.Equal(t, byte(finBit|PingMessage), b0)
+		tc.writeFrame(finBit|PongMessage, nil)
+	}
+	select {
+	case err := <-closed:
+		t.Fatalf("closed while answering pings: %v", err)
+	default:
+	}
+
+	// a client that stops answering is disconnected
+	require.True(t, IsCloseError(<-closed, CloseAbnormalClosure))
+}
@@ -89,8 +89,8 @@ func (grp *Group) mount(prefix string, subApp *App) Router {
 	}
 	grp.app.mutex.Unlock()
 
-	// register mounted group
-	mountGroup := &Group{Prefix: groupPath, app: subApp}
+	// register mounted group, the routes of the sub-app inherit the metadata of the group
+	mountGroup := &Group{Prefix: groupPath, app: subApp, meta: grp.routeMeta()}
 	grp.app.register([]string{methodUse}, groupPath, mountGroup)
 
 	// Execute onMount hooks
@@ -205,6 +205,8 @@ func (app *App) processSubAppsRoutes() {
 
 				// Add the parent route's path as a prefix to the sub-app's route
 				app.addPrefixToRoute(route.path, subAppRouteClone, route.group.app.config.RegexHandler, route.group.app.customConstraints...)
+				// Keep the metadata of the route, inheriting the metadata of the mount point
+				subAppRouteClone.meta = mergeMeta(route.meta, subAppRouteClone.meta)
 
 				// Add the cloned sub-app's route to the slice of sub-app routes
 				subRoutes[j] = subAppRouteClone
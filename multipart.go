@@ -0,0 +1,274 @@
+package fiber
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+
+	"github.com/valyala/bytebufferpool"
+	"github.com/valyala/fasthttp"
+)
+
+// defaultMultipartMaxParts is the part count limit of MultipartConfig, the
+// same as the one of mime/multipart.
+const defaultMultipartMaxParts = 1000
+
+// MultipartConfig limits what a MultipartReader reads.
+type MultipartConfig struct {
+	// MaxPartSize is the max size in bytes of the content of a part.
+	//
+	// Optional. Default: 0 (only MaxTotalSize applies)
+	MaxPartSize int64
+
+	// MaxTotalSize is the max size in bytes of the whole body, headers and
+	// boundaries of the parts included.
+	//
+	// Optional. Default: the body limit of the route
+	MaxTotalSize int64
+
+	// MaxParts is the max number of parts.
+	//
+	// Optional. Default: 1000
+	MaxParts int
+}
+
+// MultipartReader reads the parts of a multipart/form-data body one after
+// another, as they arrive. Unlike MultipartForm, it doesn't buffer the form,
+// so with Config.StreamRequestBody and Config.DisablePreParseMultipartForm
+// set, uploads of any size are handled in constant memory.
+type MultipartReader struct {
+	c      *DefaultCtx
+	reader *multipart.Reader
+	cfg    MultipartConfig
+	parts  int
+}
+
+// MultipartPart is a part of a multipart body. Its content is read with
+// Read, Value or the Save helpers, and is only available until the next
+// part is requested.
+type MultipartPart struct {
+	part   *multipart.Part
+	reader *MultipartReader
+	size   int64
+}
+
+// MultipartReader returns a reader streaming the parts of the
+// multipart/form-data request body. With Config.StreamRequestBody, the parts
+// are read from the connection as they are requested; set
+// Config.DisablePreParseMultipartForm too, or fasthttp reads the whole form
+// before the handler runs. The body can only be read once.
+func (r *DefaultReq) MultipartReader(config ...MultipartConfig) (*MultipartReader, error) {
+	var cfg MultipartConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MaxTotalSize <= 0 {
+		cfg.MaxTotalSize = int64(requestBodyLimit(r.c))
+	}
+	if cfg.MaxParts <= 0 {
+		cfg.MaxParts = defaultMultipartMaxParts
+	}
+
+	request := &r.c.fasthttp.Request
+	boundary := request.Header.MultipartFormBoundary()
+	if len(boundary) == 0 {
+		return nil, fasthttp.ErrNoMultipartForm
+	}
+
+	body := r.c.requestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(request.Body())
+	}
+	body = &multipartLimitReader{r: body, remaining: cfg.MaxTotalSize}
+
+	return &MultipartReader{
+		c:      r.c,
+		reader: multipart.NewReader(body, string(boundary)),
+		cfg:    cfg,
+	}, nil
+}
+
+// NextPart returns the next part of the body, skipping the unread content of
+// the previous one. io.EOF is returned after the last part.
+func (mr *MultipartReader) NextPart() (*MultipartPart, error) {
+	if mr.parts >= mr.cfg.MaxParts {
+		if _, err := mr.reader.NextPart(); err != nil {
+			return nil, err //nolint:wrapcheck // io.EOF must be returned as is
+		}
+		return nil, ErrMultipartTooManyParts
+	}
+
+	part, err := mr.reader.NextPart()
+	if err != nil {
+		return nil, err //nolint:wrapcheck // io.EOF must be returned as is
+	}
+	mr.parts++
+	return &MultipartPart{part: part, reader: mr}, nil
+}
+
+// Parts returns an iterator over the remaining parts. It stops after the last
+// part, or after yielding the error that ended the body:
+//
+//	for part, err := range mr.Parts() {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    ...
+//	}
+func (mr *MultipartReader) Parts() iter.Seq2[*MultipartPart, error] {
+	return func(yield func(*MultipartPart, error) bool) {
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(part, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Header returns the MIME header of the part.
+func (p *MultipartPart) Header() textproto.MIMEHeader {
+	return p.part.Header
+}
+
+// FormName returns the name parameter of the Content-Disposition of the part.
+func (p *MultipartPart) FormName() string {
+	return p.part.FormName()
+}
+
+// FileName returns the base name of the filename parameter of the
+// Content-Disposition of the part, empty for form values.
+func (p *MultipartPart) FileName() string {
+	return p.part.FileName()
+}
+
+// ContentType returns the Content-Type of the part.
+func (p *MultipartPart) ContentType() string {
+	return p.part.Header.Get(HeaderContentType)
+}
+
+// Size returns the number of content bytes read from the part so far.
+func (p *MultipartPart) Size() int64 {
+	return p.size
+}
+
+// Read reads the content of the part. ErrMultipartPartTooLarge is returned
+// once the content exceeds MaxPartSize.
+func (p *MultipartPart) Read(b []byte) (int, error) {
+	if limit := p.reader.cfg.MaxPartSize; limit > 0 {
+		remaining := limit - p.size
+		if remaining <= 0 {
+			// a part of exactly the limit ends here
+			var probe [1]byte
+			for {
+				n, err := p.part.Read(probe[:])
+				if n > 0 {
+					return 0, ErrMultipartPartTooLarge
+				}
+				if err != nil {
+					return 0, err //nolint:wrapcheck // io.EOF must be returned as is
+				}
+			}
+		}
+		if int64(len(b)) > remaining {
+			b = b[:remaining]
+		}
+	}
+
+	n, err := p.part.Read(b)
+	p.size += int64(n)
+	return n, err //nolint:wrapcheck // io.EOF must be returned as is
+}
+
+// Value reads the remaining content of the part as a string, for form
+// values.
+func (p *MultipartPart) Value() (string, error) {
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+
+	if _, err := buf.ReadFrom(p); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// SaveFile streams the remaining content of the part to a file at path,
+// creating or truncating it. The file is removed if the part can't be read
+// completely.
+func (p *MultipartPart) SaveFile(path string) error {
+	file, err := os.Create(path) //nolint:gosec // the path is chosen by the application
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrFileStore, p.FileName(), err)
+	}
+
+	if _, err = io.Copy(file, p); err == nil {
+		err = file.Close()
+	} else {
+		_ = file.Close() //nolint:errcheck // the copy error is reported
+	}
+	if err != nil {
+		_ = os.Remove(path) //nolint:errcheck // the copy error is reported
+		return fmt.Errorf("%w: %q: %w", ErrFileStore, p.FileName(), err)
+	}
+	return nil
+}
+
+// SaveToStorage stores the remaining content of the part under key. Storages
+// implementing StreamStorage receive the content as a stream, others get it
+// buffered in memory, bounded by MaxPartSize and MaxTotalSize.
+func (p *MultipartPart) SaveToStorage(storage Storage, key string) error {
+	ctx := p.reader.c.Context()
+
+	if ss, ok := storage.(StreamStorage); ok {
+		if err := ss.SetReaderWithContext(ctx, key, p, 0); err != nil {
+			return fmt.Errorf("%w: %q to %q: %w", ErrFileStore, p.FileName(), key, err)
+		}
+		return nil
+	}
+
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+
+	if _, err := buf.ReadFrom(p); err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrFileRead, p.FileName(), err)
+	}
+
+	// storages may keep the value, the buffer goes back to the pool
+	data := append([]byte(nil), buf.Bytes()...)
+	if err := storage.SetWithContext(ctx, key, data, 0); err != nil {
+		return fmt.Errorf("%w: %q to %q: %w", ErrFileStore, p.FileName(), key, err)
+	}
+	return nil
+}
+
+// multipartLimitReader returns ErrMultipartTooLarge once more than remaining
+// bytes are read.
+type multipartLimitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *multipartLimitReader) Read(b []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrMultipartTooLarge
+	}
+	// read one byte more than allowed to tell a body of exactly the limit
+	// from a larger one
+	if int64(len(b)) > l.remaining+1 {
+		b = b[:l.remaining+1]
+	}
+	n, err := l.r.Read(b)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ErrMultipartTooLarge
+	}
+	return n, err
+}
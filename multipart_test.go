@@ -0,0 +1,245 @@
+package fiber
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	storagememory "github.com/gofiber/fiber/v3/internal/storage/memory"
+)
+
+type multipartField struct {
+	name     string
+	filename string
+	content  string
+}
+
+func newMultipartRequest(t *testing.T, fields ...multipartField) (*bytes.Buffer, string) {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, f := range fields {
+		var (
+			w   io.Writer
+			err error
+		)
+		if f.filename != "" {
+			w, err = writer.CreateFormFile(f.name, f.filename)
+		} else {
+			w, err = writer.CreateFormField(f.name)
+		}
+		require.NoError(t, err)
+		_, err = w.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+// go test -run Test_Req_MultipartReader
+func Test_Req_MultipartReader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := storagememory.New()
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	app := New(Config{StreamRequestBody: true, DisablePreParseMultipartForm: true})
+	app.Post("/", func(c Ctx) error {
+		if !c.Request().IsBodyStream() {
+			return errors.New("not a body stream")
+		}
+		mr, err := c.MultipartReader()
+		if err != nil {
+			return err
+		}
+
+		var result []string
+		for part, err := range mr.Parts() {
+			if err != nil {
+				return err
+			}
+			switch part.FormName() {
+			case "title":
+				value, err := part.Value()
+				if err != nil {
+					return err
+				}
+				result = append(result, "title="+value)
+			case "video":
+				if err := part.SaveFile(filepath.Join(dir, part.FileName())); err != nil {
+					return err
+				}
+				result = append(result, fmt.Sprintf("video=%s:%d:%s", part.FileName(), part.Size(), part.ContentType()))
+			case "thumb":
+				if err := part.SaveToStorage(store, "thumb"); err != nil {
+					return err
+				}
+				result = append(result, "thumb="+part.Header().Get(HeaderContentDisposition))
+			default:
+				// unread parts are skipped
+			}
+		}
+		return c.SendString(strings.Join(result, "\n"))
+	})
+
+	video := strings.Repeat("0123456789", 1<<17)
+	body, contentType := newMultipartRequest(t,
+		multipartField{name: "title", content: "holidays"},
+		multipartField{name: "skipped", content: "ignored"},
+		multipartField{name: "video", filename: "../clip.mp4", content: video},
+		multipartField{name: "thumb", filename: "thumb.png", content: "png"},
+	)
+	req := httptest.NewRequest(MethodPost, "/", body)
+	req.Header.Set(HeaderContentType, contentType)
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode, string(raw))
+	require.Equal(t, "title=holidays\n"+
+		fmt.Sprintf("video=clip.mp4:%d:application/octet-stream\n", len(video))+
+		`thumb=form-data; name="thumb"; filename="thumb.png"`, string(raw))
+
+	saved, err := os.ReadFile(filepath.Join(dir, "clip.mp4")) //nolint:gosec // test file
+	require.NoError(t, err)
+	require.Equal(t, video, string(saved))
+
+	stored, err := store.Get("thumb")
+	require.NoError(t, err)
+	require.Equal(t, "png", string(stored))
+}
+
+// go test -run Test_Req_MultipartReader_Limits
+func Test_Req_MultipartReader_Limits(t *testing.T) {
+	t.Parallel()
+
+	run := func(t *testing.T, app *App, fields ...multipartField) (int, string) {
+		t.Helper()
+		body, contentType := newMultipartRequest(t, fields...)
+		req := httptest.NewRequest(MethodPost, "/", body)
+		req.Header.Set(HeaderContentType, contentType)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(raw)
+	}
+	readAll := func(cfg MultipartConfig) Handler {
+		return func(c Ctx) error {
+			mr, err := c.MultipartReader(cfg)
+			if err != nil {
+				return err
+			}
+			n := 0
+			for part, err := range mr.Parts() {
+				if err != nil {
+					return err
+				}
+				if _, err := io.Copy(io.Discard, part); err != nil {
+					return err
+				}
+				n++
+			}
+			return c.SendString(fmt.Sprint(n))
+		}
+	}
+
+	app := New(Config{StreamRequestBody: true, DisablePreParseMultipartForm: true})
+	app.Post("/", readAll(MultipartConfig{MaxPartSize: 4, MaxParts: 2}))
+
+	status, body := run(t, app, multipartField{name: "a", content: "1234"}, multipartField{name: "b", filename: "b.txt", content: "abcd"})
+	require.Equal(t, StatusOK, status)
+	require.Equal(t, "2", body)
+
+	status, body = run(t, app, multipartField{name: "a", content: "12345"})
+	require.Equal(t, StatusRequestEntityTooLarge, status)
+	require.Equal(t, ErrMultipartPartTooLarge.Message, body)
+
+	status, body = run(t, app, multipartField{name: "a"}, multipartField{name: "b"}, multipartField{name: "c"})
+	require.Equal(t, StatusRequestEntityTooLarge, status)
+	require.Equal(t, ErrMultipartTooManyParts.Message, body)
+
+	// the total size defaults to the body limit of the route
+	limited := New(Config{StreamRequestBody: true})
+	limited.Post("/", readAll(MultipartConfig{}), RouteConfig{BodyLimit: 1024})
+	status, body = run(t, limited, multipartField{name: "a", content: strings.Repeat("x", 100)})
+	require.Equal(t, StatusOK, status)
+	require.Equal(t, "1", body)
+
+	limited = New()
+	limited.Post("/", readAll(MultipartConfig{MaxTotalSize: 256}))
+	status, body = run(t, limited, multipartField{name: "a", content: strings.Repeat("x", 300)})
+	require.Equal(t, StatusRequestEntityTooLarge, status)
+	require.Equal(t, ErrMultipartTooLarge.Message, body)
+}
+
+// go test -run Test_Req_MultipartReader_Errors
+func Test_Req_MultipartReader_Errors(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	t.Cleanup(func() { app.ReleaseCtx(c) })
+
+	c.Request().Header.SetContentType(MIMEApplicationJSON)
+	_, err := c.MultipartReader()
+	require.ErrorIs(t, err, fasthttp.ErrNoMultipartForm)
+
+	// truncated bodies are reported
+	body, contentType := newMultipartRequest(t, multipartField{name: "a", filename: "a.txt", content: "content"})
+	c.Request().Header.SetContentType(contentType)
+	c.Request().SetBody(body.Bytes()[:bytes.LastIndex(body.Bytes(), []byte("content"))+3])
+	mr, err := c.MultipartReader()
+	require.NoError(t, err)
+	part, err := mr.NextPart()
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "a.txt")
+	require.ErrorIs(t, part.SaveFile(path), io.ErrUnexpectedEOF)
+	require.NoFileExists(t, path)
+
+	// streaming storages receive the content as a stream
+	c.Request().SetBody(body.Bytes())
+	mr, err = c.MultipartReader()
+	require.NoError(t, err)
+	part, err = mr.NextPart()
+	require.NoError(t, err)
+	store := &streamStorage{Storage: storagememory.New()}
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+	require.NoError(t, part.SaveToStorage(store, "a"))
+	require.True(t, store.streamed)
+	stored, err := store.Get("a")
+	require.NoError(t, err)
+	require.Equal(t, "content", string(stored))
+
+	_, err = mr.NextPart()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+type streamStorage struct {
+	Storage
+	streamed bool
+}
+
+func (s *streamStorage) SetReaderWithContext(ctx context.Context, key string, r io.Reader, exp time.Duration) error {
+	s.streamed = true
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return s.SetWithContext(ctx, key, data, exp)
+}
@@ -2,6 +2,7 @@ package fiber
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -54,6 +55,15 @@ func (app *App) prefork(addr string, tlsConfig *tls.Config, cfg *ListenConfig) e
 		OnMasterDeath:    func() { os.Exit(1) }, //nolint:revive // Exiting child process is intentional
 	}
 
+	// Share the listener of the master for graceful restarts and socket activation
+	if cfg.EnableGracefulRestart || cfg.EnableSocketActivation {
+		release, err := app.preforkShare(p, addr, cfg)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
 	// Use test command producer if in test mode
 	if testPreforkMaster {
 		p.CommandProducer = func(_ []*os.File) (*exec.Cmd, error) {
@@ -69,13 +79,23 @@ func (app *App) prefork(addr string, tlsConfig *tls.Config, cfg *ListenConfig) e
 
 	// Child process: serve function wraps TLS, starts up process, etc.
 	p.ServeFunc = func(ln net.Listener) error {
+		// the PROXY protocol header precedes the TLS handshake
+		if cfg.EnableProxyProtocol {
+			var err error
+			if ln, err = newProxyProtocolListener(ln, cfg.ProxyProtocolConfig); err != nil {
+				return err
+			}
+		}
+
 		// wrap a tls config around the listener if provided
 		if tlsConfig != nil {
 			ln = tls.NewListener(ln, tlsConfig)
 		}
 
 		// prepare the server for the start
-		app.startupProcess()
+		if err := app.startupProcess(); err != nil {
+			return err
+		}
 
 		if cfg.ListenerAddrFunc != nil {
 			cfg.ListenerAddrFunc(ln.Addr())
@@ -98,9 +118,17 @@ func (app *App) prefork(addr string, tlsConfig *tls.Config, cfg *ListenConfig) e
 	}
 
 	// Master callback: all children spawned → startup message & OnListen hooks
+	stopNotify := func() {}
+	defer func() { stopNotify() }()
 	p.OnMasterReady = func(childPIDs []int) error {
 		listenData := app.prepareListenData(addr, tlsConfig != nil, cfg, childPIDs)
 		app.runOnListenHooks(listenData)
+		if cfg.EnableGracefulRestart {
+			restarts.listening(app)
+		}
+		if cfg.EnableSystemdNotify {
+			stopNotify = app.notifySystemd()
+		}
 		app.printMessages(cfg, listenData)
 		return nil
 	}
@@ -111,6 +139,9 @@ func (app *App) prefork(addr string, tlsConfig *tls.Config, cfg *ListenConfig) e
 	}
 
 	if err := p.ListenAndServe(addr); err != nil {
+		if errors.Is(err, errRestarted) {
+			return nil
+		}
 		return fmt.Errorf("prefork: %w", err)
 	}
 
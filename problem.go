@@ -0,0 +1,267 @@
+package fiber
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/gofiber/utils/v2"
+
+	"github.com/gofiber/fiber/v3/internal/nilerror"
+)
+
+// problemXMLNamespace is the XML namespace of problem documents, see RFC 9457, appendix B.
+const problemXMLNamespace = "urn:ietf:rfc:7807"
+
+// problemMembers are the member names defined by RFC 9457. Extensions can't
+// override them.
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// Problem is an error rendered as a problem details document (RFC 9457).
+//
+//	return fiber.NewProblem(fiber.StatusForbidden, "Your balance is 30, but that costs 50.").
+//	    With("balance", 30)
+type Problem struct {
+	// Extensions are additional members of the problem document.
+	Extensions map[string]any
+	// cause is the error the problem was created from, if any.
+	cause error
+	// Type is a URI reference that identifies the problem type.
+	// Default: "about:blank"
+	Type string
+	// Title is a short, human-readable summary of the problem type.
+	// Default: the status message of Status
+	Title string
+	// Detail is a human-readable explanation specific to this occurrence.
+	Detail string
+	// Instance is a URI reference that identifies this occurrence.
+	Instance string
+	// Status is the HTTP status code of the response.
+	Status int
+}
+
+// NewProblem creates a new problem with the status code, its status message
+// as title and an optional detail.
+func NewProblem(status int, detail ...string) *Problem {
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  utils.StatusMessage(status),
+		Status: status,
+	}
+	if len(detail) > 0 {
+		p.Detail = detail[0]
+	}
+	return p
+}
+
+// ProblemFromError converts err to a problem. A *Problem in the chain of err
+// is returned as is, a *BindError becomes a 400 Bad Request with its source
+// and field as extensions, ValidationErrors of a validator become a 422
+// Unprocessable Entity, both listing the rejected fields in the "errors"
+// extension, and a *Error keeps its code and message. Any other error becomes
+// a 500 Internal Server Error with err.Error() as detail.
+func ProblemFromError(err error) *Problem {
+	if nilerror.IsNil(err) {
+		return NewProblem(StatusInternalServerError)
+	}
+
+	var problem *Problem
+	if errors.As(err, &problem) && problem != nil {
+		return problem
+	}
+
+	var verrs ValidationErrors
+	hasFields := errors.As(err, &verrs) && len(verrs) > 0
+
+	var bindErr *BindError
+	if errors.As(err, &bindErr) && bindErr != nil {
+		p := NewProblem(StatusBadRequest, err.Error()).With("source", bindErr.Source)
+		if bindErr.Field != "" {
+			p.With("field", bindErr.Field)
+		}
+		if hasFields {
+			p.With("errors", verrs)
+		}
+		p.cause = err
+		return p
+	}
+
+	if hasFields {
+		p := NewProblem(StatusUnprocessableEntity, err.Error()).With("errors", verrs)
+		p.cause = err
+		return p
+	}
+
+	if e, matched := asFiberError(err); matched {
+		if e == nil {
+			return NewProblem(StatusInternalServerError)
+		}
+		p := NewProblem(e.Code)
+		// built-in errors carry their status message, which is the title already
+		if e.Message != p.Title {
+			p.Detail = e.Message
+		}
+		p.cause = err
+		return p
+	}
+
+	p := NewProblem(StatusInternalServerError, err.Error())
+	p.cause = err
+	return p
+}
+
+// With sets the extension member key to value and returns the problem.
+// Keys of the members defined by RFC 9457 are ignored when rendering.
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// Error returns the title and detail of the problem.
+func (p *Problem) Error() string {
+	title := p.Title
+	if title == "" {
+		title = utils.StatusMessage(p.Status)
+	}
+	if p.Detail == "" {
+		return title
+	}
+	return title + ": " + p.Detail
+}
+
+// Unwrap returns the error the problem was created from by ProblemFromError.
+func (p *Problem) Unwrap() error {
+	return p.cause
+}
+
+// extensionKeys returns the sorted names of the extension members to render.
+func (p *Problem) extensionKeys() []string {
+	keys := make([]string, 0, len(p.Extensions))
+	for key := range p.Extensions {
+		if key != "" && !slices.Contains(problemMembers, key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// MarshalJSON encodes the problem as a problem+json document, with the
+// extension members next to the standard ones.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	write := func(key string, value any) error {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.WriteString(strconv.Quote(key))
+		buf.WriteByte(':')
+		buf.Write(raw)
+		return nil
+	}
+
+	members := []struct {
+		value any
+		key   string
+		set   bool
+	}{
+		{key: "type", value: p.Type, set: p.Type != ""},
+		{key: "title", value: p.Title, set: p.Title != ""},
+		{key: "status", value: p.Status, set: p.Status != 0},
+		{key: "detail", value: p.Detail, set: p.Detail != ""},
+		{key: "instance", value: p.Instance, set: p.Instance != ""},
+	}
+	for _, member := range members {
+		if !member.set {
+			continue
+		}
+		if err := write(member.key, member.value); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range p.extensionKeys() {
+		if err := write(key, p.Extensions[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalXML encodes the problem as a problem+xml document, with each
+// extension member as a child element.
+func (p *Problem) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Space: problemXMLNamespace, Local: "problem"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	members := []struct {
+		key   string
+		value string
+	}{
+		{key: "type", value: p.Type},
+		{key: "title", value: p.Title},
+		{key: "status", value: strconv.Itoa(p.Status)},
+		{key: "detail", value: p.Detail},
+		{key: "instance", value: p.Instance},
+	}
+	for _, member := range members {
+		if member.value == "" || member.value == "0" {
+			continue
+		}
+		if err := e.EncodeElement(member.value, xml.StartElement{Name: xml.Name{Local: member.key}}); err != nil {
+			return err
+		}
+	}
+	for _, key := range p.extensionKeys() {
+		if err := e.EncodeElement(p.Extensions[key], xml.StartElement{Name: xml.Name{Local: key}}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// SendProblem writes the problem as response, as application/problem+xml if
+// the client prefers XML and as application/problem+json otherwise.
+func SendProblem(c Ctx, p *Problem) error {
+	status := p.Status
+	if status == 0 {
+		status = StatusInternalServerError
+	}
+	c.Status(status)
+
+	switch c.Accepts(MIMEApplicationProblemJSON, MIMEApplicationProblemXML, MIMEApplicationJSON, MIMEApplicationXML) {
+	case MIMEApplicationProblemXML, MIMEApplicationXML:
+		raw, err := c.App().config.XMLEncoder(p)
+		if err != nil {
+			return err
+		}
+		c.Set(HeaderContentType, MIMEApplicationProblemXMLCharsetUTF8)
+		return c.Send(raw)
+	default:
+		return c.JSON(p, MIMEApplicationProblemJSONCharsetUTF8)
+	}
+}
+
+// ProblemErrorHandler is an ErrorHandler that renders errors as problem
+// details documents (RFC 9457), see ProblemFromError and SendProblem.
+// It is the default when Config.ProblemDetails is enabled.
+func ProblemErrorHandler(c Ctx, err error) error {
+	return SendProblem(c, ProblemFromError(err))
+}
@@ -0,0 +1,150 @@
+package fiber
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/utils/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func problemRequest(t *testing.T, app *App, method, target, accept string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, http.NoBody)
+	if accept != "" {
+		req.Header.Set(HeaderAccept, accept)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+// go test -run Test_Problem_MarshalJSON
+func Test_Problem_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	p := NewProblem(StatusForbidden, "Your balance is 30, but that costs 50.").
+		With("balance", 30).
+		With("accounts", []string{"/account/12345"}).
+		With("status", 200)
+	p.Type = "https://example.com/probs/out-of-credit"
+	p.Instance = "/account/12345/msgs/abc"
+
+	raw, err := json.Marshal(p)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "Forbidden",
+		"status": 403,
+		"detail": "Your balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc",
+		"accounts": ["/account/12345"],
+		"balance": 30
+	}`, string(raw))
+
+	raw, err = json.Marshal(&Problem{Title: "Custom"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"title": "Custom"}`, string(raw))
+
+	_, err = json.Marshal(NewProblem(StatusBadRequest).With("invalid", func() {}))
+	require.Error(t, err)
+}
+
+// go test -run Test_Problem_FromError
+func Test_Problem_FromError(t *testing.T) {
+	t.Parallel()
+
+	errCustom := errors.New("custom")
+	problem := NewProblem(StatusConflict, "exists")
+	bindErr := &BindError{Source: BindSourceQuery, Field: "age", Err: errCustom}
+	var typedNil *Error
+
+	testCases := []struct {
+		err        error
+		extensions map[string]any
+		name       string
+		detail     string
+		status     int
+	}{
+		{name: "problem", err: fmt.Errorf("wrapped: %w", problem), status: StatusConflict, detail: "exists"},
+		{name: "bind", err: bindErr, status: StatusBadRequest, detail: bindErr.Error(), extensions: map[string]any{"source": "query", "field": "age"}},
+		{name: "builtin", err: ErrNotFound, status: StatusNotFound},
+		{name: "validator", err: ValidationErrors{{Field: "age", Rule: "min"}}, status: StatusUnprocessableEntity, detail: `age: failed on the "min" rule`, extensions: map[string]any{"errors": ValidationErrors{{Field: "age", Rule: "min"}}}},
+		{name: "fiber error", err: NewError(StatusTeapot, "short and stout"), status: StatusTeapot, detail: "short and stout"},
+		{name: "typed nil", err: typedNil, status: StatusInternalServerError},
+		{name: "nil", err: nil, status: StatusInternalServerError},
+		{name: "other", err: errCustom, status: StatusInternalServerError, detail: "custom"},
+	}
+	for _, tc := range testCases {
+		p := ProblemFromError(tc.err)
+		require.Equal(t, tc.status, p.Status, tc.name)
+		require.Equal(t, utils.StatusMessage(tc.status), p.Title, tc.name)
+		require.Equal(t, tc.detail, p.Detail, tc.name)
+		require.Equal(t, tc.extensions, p.Extensions, tc.name)
+	}
+
+	require.Same(t, problem, ProblemFromError(problem))
+	require.ErrorIs(t, ProblemFromError(bindErr), errCustom)
+	require.ErrorIs(t, ProblemFromError(errCustom), errCustom)
+	require.Equal(t, "Conflict: exists", problem.Error())
+	require.Equal(t, "Not Found", (&Problem{Status: StatusNotFound}).Error())
+}
+
+// go test -run Test_Problem_ErrorHandler
+func Test_Problem_ErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{ProblemDetails: true})
+	app.Get("/users/:id", func(_ Ctx) error {
+		return NewProblem(StatusNotFound, "user 42 does not exist").With("id", 42)
+	})
+	app.Post("/bind", func(c Ctx) error {
+		var req struct {
+			Age int `query:"age"`
+		}
+		return c.Bind().Query(&req)
+	})
+
+	resp, body := problemRequest(t, app, MethodGet, "/users/42", "")
+	require.Equal(t, StatusNotFound, resp.StatusCode)
+	require.Equal(t, MIMEApplicationProblemJSONCharsetUTF8, resp.Header.Get(HeaderContentType))
+	require.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"user 42 does not exist","id":42}`, body)
+
+	resp, body = problemRequest(t, app, MethodGet, "/missing", MIMEApplicationJSON)
+	require.Equal(t, StatusNotFound, resp.StatusCode)
+	require.Equal(t, MIMEApplicationProblemJSONCharsetUTF8, resp.Header.Get(HeaderContentType))
+	require.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404}`, body)
+
+	resp, body = problemRequest(t, app, MethodPut, "/users/42", MIMEApplicationProblemXML)
+	require.Equal(t, StatusMethodNotAllowed, resp.StatusCode)
+	require.Equal(t, MIMEApplicationProblemXMLCharsetUTF8, resp.Header.Get(HeaderContentType))
+	require.Equal(t, `<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Method Not Allowed</title><status>405</status></problem>`, body)
+
+	resp, body = problemRequest(t, app, MethodGet, "/users/42", "text/html, application/xml;q=0.9")
+	require.Equal(t, StatusNotFound, resp.StatusCode)
+	require.Equal(t, MIMEApplicationProblemXMLCharsetUTF8, resp.Header.Get(HeaderContentType))
+	require.True(t, strings.HasSuffix(body, "<detail>user 42 does not exist</detail><id>42</id></problem>"), body)
+
+	resp, body = problemRequest(t, app, MethodPost, "/bind?age=old", "")
+	require.Equal(t, StatusBadRequest, resp.StatusCode)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &doc))
+	require.Equal(t, "query", doc["source"])
+	require.Equal(t, "age", doc["field"])
+	require.Equal(t, []any{map[string]any{"field": "age", "rule": "type", "message_key": "validation.type", "value": "old"}}, doc["errors"])
+
+	// an explicit error handler is kept
+	app = New(Config{ProblemDetails: true, ErrorHandler: DefaultErrorHandler})
+	resp, body = problemRequest(t, app, MethodGet, "/missing", "")
+	require.Equal(t, StatusNotFound, resp.StatusCode)
+	require.Equal(t, MIMETextPlainCharsetUTF8, resp.Header.Get(HeaderContentType))
+	require.Equal(t, ErrNotFound.Message, body)
+}
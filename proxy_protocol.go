@@ -0,0 +1,504 @@
+package fiber
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyProtocolConfig configures the PROXY protocol, see
+// ListenConfig.EnableProxyProtocol.
+type ProxyProtocolConfig struct {
+	// TrustedSources lists the IP addresses and CIDR ranges, e.g.
+	// "10.0.0.0/8", of the load balancers allowed to send PROXY protocol
+	// headers. Connections from other peers are served as is, without
+	// reading a header. It's required for TCP listeners, the peers of unix
+	// sockets are always trusted, the permissions of the socket file decide
+	// who connects.
+	//
+	// Default: nil
+	TrustedSources []string `json:"trusted_sources"`
+
+	// HeaderTimeout is the maximum duration of reading the header at the
+	// start of a connection.
+	//
+	// Default: 10 * time.Second
+	HeaderTimeout time.Duration `json:"header_timeout"`
+
+	// Required closes the connections of trusted sources that don't start
+	// with a PROXY protocol header. Otherwise, they're served as is.
+	//
+	// Default: false
+	Required bool `json:"required"`
+}
+
+// ProxyProtocolHeader is the PROXY protocol header a connection started with.
+type ProxyProtocolHeader struct {
+	// Source is the address of the client. It's nil for LOCAL connections,
+	// e.g. health checks of the load balancer, and unknown protocols.
+	Source net.Addr
+	// Destination is the address the client connected to.
+	Destination net.Addr
+	// SSL holds the TLS details of the connection between the client and
+	// the load balancer, from the PP2_TYPE_SSL TLV.
+	SSL *ProxyProtocolSSL
+	// Authority is the host name the client connected to, usually the TLS
+	// SNI, from the PP2_TYPE_AUTHORITY TLV.
+	Authority string
+	// ALPN is the protocol negotiated with the client, from the
+	// PP2_TYPE_ALPN TLV.
+	ALPN string
+	// UniqueID identifies the connection, from the PP2_TYPE_UNIQUE_ID TLV.
+	UniqueID []byte
+	// TLVs holds all the TLVs of a v2 header, in order.
+	TLVs []ProxyProtocolTLV
+	// Version is the version of the header, 1 (text) or 2 (binary).
+	Version int
+	// Local reports a v2 header with the LOCAL command, sent for connections
+	// established by the load balancer itself.
+	Local bool
+}
+
+// ProxyProtocolTLV is a type-length-value field of a v2 header.
+type ProxyProtocolTLV struct {
+	Value []byte
+	Type  byte
+}
+
+// ProxyProtocolSSL holds the PP2_TYPE_SSL TLV of a v2 header.
+type ProxyProtocolSSL struct {
+	// Version is the TLS version, e.g. "TLSv1.3".
+	Version string
+	// CommonName is the common name of the client certificate.
+	CommonName string
+	// Cipher is the cipher suite, e.g. "ECDHE-RSA-AES128-GCM-SHA256".
+	Cipher string
+	// SignatureAlgorithm is the signature algorithm of the client certificate.
+	SignatureAlgorithm string
+	// KeyAlgorithm is the key algorithm of the client certificate.
+	KeyAlgorithm string
+	// TLS reports that the client connected over TLS.
+	TLS bool
+	// ClientCert reports that the client presented a certificate.
+	ClientCert bool
+	// Verified reports that the client certificate was verified.
+	Verified bool
+}
+
+// PROXY protocol v2 TLV types
+const (
+	proxyTLVALPN       = 0x01
+	proxyTLVAuthority  = 0x02
+	proxyTLVCRC32C     = 0x03
+	proxyTLVUniqueID   = 0x05
+	proxyTLVSSL        = 0x20
+	proxySSLVersion    = 0x21
+	proxySSLCommonName = 0x22
+	proxySSLCipher     = 0x23
+	proxySSLSigAlg     = 0x24
+	proxySSLKeyAlg     = 0x25
+
+	proxySSLClientSSL      = 0x01
+	proxySSLClientCertConn = 0x02
+	proxySSLClientCertSess = 0x04
+
+	proxyV1MaxLength   = 107
+	proxyV2HeaderSize  = 16
+	proxyMaxUniqueID   = 128
+	proxyReadAheadSize = 256
+)
+
+var (
+	proxyV1Prefix    = []byte("PROXY ")
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+	crc32cTable      = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// ProxyProtocol returns the PROXY protocol header of the connection of the
+// request, or nil if it didn't start with one. See
+// ListenConfig.EnableProxyProtocol.
+func ProxyProtocol(c Ctx) *ProxyProtocolHeader {
+	conn := c.RequestCtx().Conn()
+	for conn != nil {
+		if pc, ok := conn.(*proxyProtocolConn); ok {
+			pc.once.Do(pc.readHeader)
+			return pc.header
+		}
+		wrapper, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return nil
+		}
+		conn = wrapper.NetConn()
+	}
+	return nil
+}
+
+// proxyProtocolListener reads the PROXY protocol header of the connections
+// of trusted sources.
+type proxyProtocolListener struct {
+	net.Listener
+	trusted  []netip.Prefix
+	timeout  time.Duration
+	required bool
+}
+
+// newProxyProtocolListener wraps ln to read the PROXY protocol headers.
+func newProxyProtocolListener(ln net.Listener, cfg ProxyProtocolConfig) (net.Listener, error) {
+	pln := &proxyProtocolListener{Listener: ln, timeout: cfg.HeaderTimeout, required: cfg.Required}
+	for _, source := range cfg.TrustedSources {
+		if !strings.Contains(source, "/") {
+			addr, err := netip.ParseAddr(source)
+			if err != nil {
+				return nil, fmt.Errorf("proxy protocol: invalid trusted source %q: %w", source, err)
+			}
+			pln.trusted = append(pln.trusted, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(source)
+		if err != nil {
+			return nil, fmt.Errorf("proxy protocol: invalid trusted source %q: %w", source, err)
+		}
+		pln.trusted = append(pln.trusted, prefix.Masked())
+	}
+	if len(pln.trusted) == 0 && ln.Addr().Network() != NetworkUnix {
+		return nil, ErrProxyProtocolNoTrustedSources
+	}
+	return pln, nil
+}
+
+// Accept accepts the next connection. The header is read on the first
+// Read, RemoteAddr or LocalAddr call, so that slow clients don't block the
+// accept loop.
+func (ln *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := ln.Listener.Accept()
+	if err != nil {
+		return nil, err //nolint:wrapcheck // the error of the listener is returned as is
+	}
+	if !ln.trusts(conn.RemoteAddr()) {
+		return conn, nil
+	}
+	return &proxyProtocolConn{Conn: conn, ln: ln}, nil
+}
+
+// trusts reports whether the peer at addr may send a header.
+func (ln *proxyProtocolListener) trusts(addr net.Addr) bool {
+	if _, ok := addr.(*net.UnixAddr); ok {
+		return true
+	}
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	ip := tcpAddr.AddrPort().Addr().Unmap()
+	for _, prefix := range ln.trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyProtocolConn is a connection starting with a PROXY protocol header.
+type proxyProtocolConn struct {
+	net.Conn
+	deadline time.Time // read deadline set by the server, restored after the header is read
+	err      error
+	ln       *proxyProtocolListener
+	br       *bufio.Reader
+	header   *ProxyProtocolHeader
+	once     sync.Once
+	mu       sync.Mutex
+}
+
+// readHeader reads the header, once.
+func (c *proxyProtocolConn) readHeader() {
+	if c.ln.timeout > 0 {
+		_ = c.Conn.SetReadDeadline(time.Now().Add(c.ln.timeout)) //nolint:errcheck // reading fails if the deadline can't be set
+		defer func() {
+			c.mu.Lock()
+			_ = c.Conn.SetReadDeadline(c.deadline) //nolint:errcheck // the next read fails if the deadline can't be set
+			c.mu.Unlock()
+		}()
+	}
+
+	c.br = bufio.NewReaderSize(c.Conn, proxyReadAheadSize)
+	c.header, c.err = readProxyProtocolHeader(c.br)
+	if c.err == nil && c.header == nil && c.ln.required {
+		c.err = ErrProxyProtocolMissing
+	}
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		// the connection is dropped without a response, as the spec requires
+		return 0, io.EOF
+	}
+	if c.br != nil {
+		if c.br.Buffered() > 0 {
+			return c.br.Read(b) //nolint:wrapcheck // the error of the connection is returned as is
+		}
+		c.br = nil
+	}
+	return c.Conn.Read(b) //nolint:wrapcheck // the error of the connection is returned as is
+}
+
+// RemoteAddr returns the address of the client sent in the header.
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.header != nil && c.header.Source != nil {
+		return c.header.Source
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the address the client connected to sent in the header.
+func (c *proxyProtocolConn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.header != nil && c.header.Destination != nil {
+		return c.header.Destination
+	}
+	return c.Conn.LocalAddr()
+}
+
+// NetConn returns the underlying connection.
+func (c *proxyProtocolConn) NetConn() net.Conn {
+	return c.Conn
+}
+
+func (c *proxyProtocolConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadline = t
+	return c.Conn.SetDeadline(t) //nolint:wrapcheck // the error of the connection is returned as is
+}
+
+func (c *proxyProtocolConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadline = t
+	return c.Conn.SetReadDeadline(t) //nolint:wrapcheck // the error of the connection is returned as is
+}
+
+// readProxyProtocolHeader reads a v1 or v2 header from br. It returns nil
+// without consuming anything if br doesn't start with a header.
+func readProxyProtocolHeader(br *bufio.Reader) (*ProxyProtocolHeader, error) {
+	if ok, err := peekPrefix(br, proxyV1Prefix); !ok {
+		if err != nil {
+			return nil, err
+		}
+		if ok, err = peekPrefix(br, proxyV2Signature); !ok {
+			return nil, err
+		}
+		return readProxyProtocolV2(br)
+	}
+	return readProxyProtocolV1(br)
+}
+
+// peekPrefix reports whether br starts with prefix. It only fails if the
+// data read so far matches the prefix.
+func peekPrefix(br *bufio.Reader, prefix []byte) (bool, error) {
+	peek, err := br.Peek(len(prefix))
+	if !bytes.HasPrefix(prefix, peek) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrProxyProtocolMalformed, err)
+	}
+	return true, nil
+}
+
+// readProxyProtocolV1 reads a header of the text format, e.g.
+// "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func readProxyProtocolV1(br *bufio.Reader) (*ProxyProtocolHeader, error) {
+	line, err := br.ReadSlice('\n')
+	if err != nil || len(line) > proxyV1MaxLength || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, fmt.Errorf("%w: invalid v1 header line", ErrProxyProtocolMalformed)
+	}
+
+	header := &ProxyProtocolHeader{Version: 1}
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return header, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("%w: invalid v1 header fields", ErrProxyProtocolMalformed)
+	}
+
+	source, err := parseProxyV1Addr(fields[2], fields[4], fields[1] == "TCP4")
+	if err != nil {
+		return nil, err
+	}
+	destination, err := parseProxyV1Addr(fields[3], fields[5], fields[1] == "TCP4")
+	if err != nil {
+		return nil, err
+	}
+	header.Source, header.Destination = source, destination
+	return header, nil
+}
+
+func parseProxyV1Addr(ip, port string, ipv4 bool) (*net.TCPAddr, error) { //revive:disable-line:flag-parameter // the family is part of the address
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || addr.Is4() != ipv4 || addr.Zone() != "" {
+		return nil, fmt.Errorf("%w: invalid v1 address %q", ErrProxyProtocolMalformed, ip)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || (len(port) > 1 && port[0] == '0') {
+		return nil, fmt.Errorf("%w: invalid v1 port %q", ErrProxyProtocolMalformed, port)
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, uint16(p))), nil
+}
+
+// readProxyProtocolV2 reads a header of the binary format.
+func readProxyProtocolV2(br *bufio.Reader) (*ProxyProtocolHeader, error) {
+	raw := make([]byte, proxyV2HeaderSize)
+	if _, err := io.ReadFull(br, raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProxyProtocolMalformed, err)
+	}
+	if raw[12]>>4 != 2 || raw[12]&0x0f > 1 {
+		return nil, fmt.Errorf("%w: unsupported v2 version or command", ErrProxyProtocolMalformed)
+	}
+	raw = append(raw, make([]byte, binary.BigEndian.Uint16(raw[14:16]))...)
+	if _, err := io.ReadFull(br, raw[proxyV2HeaderSize:]); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProxyProtocolMalformed, err)
+	}
+
+	header := &ProxyProtocolHeader{Version: 2, Local: raw[12]&0x0f == 0}
+	payload := raw[proxyV2HeaderSize:]
+	var addrLen int
+	switch raw[13] >> 4 {
+	case 0: // AF_UNSPEC
+	case 1: // AF_INET
+		addrLen = 12
+	case 2: // AF_INET6
+		addrLen = 36
+	case 3: // AF_UNIX
+		addrLen = 216
+	default:
+		return nil, fmt.Errorf("%w: unsupported v2 address family", ErrProxyProtocolMalformed)
+	}
+	if len(payload) < addrLen {
+		return nil, fmt.Errorf("%w: truncated v2 addresses", ErrProxyProtocolMalformed)
+	}
+
+	if !header.Local {
+		header.Source, header.Destination = parseProxyV2Addrs(raw[13]>>4, payload[:addrLen])
+	}
+	if err := parseProxyV2TLVs(header, raw, proxyV2HeaderSize+addrLen); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// parseProxyV2Addrs returns the source and destination addresses of family.
+func parseProxyV2Addrs(family byte, b []byte) (source, destination net.Addr) { //nolint:nonamedreturns // documents the results
+	switch family {
+	case 1:
+		src := netip.AddrFrom4([4]byte(b[0:4]))
+		dst := netip.AddrFrom4([4]byte(b[4:8]))
+		return net.TCPAddrFromAddrPort(netip.AddrPortFrom(src, binary.BigEndian.Uint16(b[8:10]))),
+			net.TCPAddrFromAddrPort(netip.AddrPortFrom(dst, binary.BigEndian.Uint16(b[10:12])))
+	case 2:
+		src := netip.AddrFrom16([16]byte(b[0:16]))
+		dst := netip.AddrFrom16([16]byte(b[16:32]))
+		return net.TCPAddrFromAddrPort(netip.AddrPortFrom(src, binary.BigEndian.Uint16(b[32:34]))),
+			net.TCPAddrFromAddrPort(netip.AddrPortFrom(dst, binary.BigEndian.Uint16(b[34:36])))
+	case 3:
+		return &net.UnixAddr{Name: string(bytes.TrimRight(b[:108], "\x00")), Net: "unix"},
+			&net.UnixAddr{Name: string(bytes.TrimRight(b[108:216], "\x00")), Net: "unix"}
+	default:
+		return nil, nil
+	}
+}
+
+// parseProxyV2TLVs parses the TLVs of raw, the whole header, starting at
+// offset, and verifies its checksum if it has a PP2_TYPE_CRC32C TLV.
+func parseProxyV2TLVs(header *ProxyProtocolHeader, raw []byte, offset int) error {
+	for offset < len(raw) {
+		if len(raw)-offset < 3 {
+			return fmt.Errorf("%w: truncated v2 TLV", ErrProxyProtocolMalformed)
+		}
+		typ := raw[offset]
+		length := int(binary.BigEndian.Uint16(raw[offset+1 : offset+3]))
+		start := offset + 3
+		if len(raw)-start < length {
+			return fmt.Errorf("%w: truncated v2 TLV", ErrProxyProtocolMalformed)
+		}
+		value := raw[start : start+length]
+		header.TLVs = append(header.TLVs, ProxyProtocolTLV{Type: typ, Value: value})
+
+		switch typ {
+		case proxyTLVALPN:
+			header.ALPN = string(value)
+		case proxyTLVAuthority:
+			header.Authority = string(value)
+		case proxyTLVUniqueID:
+			if length > proxyMaxUniqueID {
+				return fmt.Errorf("%w: v2 unique ID too long", ErrProxyProtocolMalformed)
+			}
+			header.UniqueID = value
+		case proxyTLVSSL:
+			ssl, err := parseProxyV2SSL(value)
+			if err != nil {
+				return err
+			}
+			header.SSL = ssl
+		case proxyTLVCRC32C:
+			if length != 4 {
+				return fmt.Errorf("%w: invalid v2 CRC32C TLV", ErrProxyProtocolMalformed)
+			}
+			// the checksum is computed with the value of the TLV set to zero
+			checksum := bytes.Clone(raw)
+			clear(checksum[start : start+4])
+			if crc32.Checksum(checksum, crc32cTable) != binary.BigEndian.Uint32(value) {
+				return fmt.Errorf("%w: v2 checksum mismatch", ErrProxyProtocolMalformed)
+			}
+		default:
+		}
+		offset = start + length
+	}
+	return nil
+}
+
+// parseProxyV2SSL parses the value of a PP2_TYPE_SSL TLV.
+func parseProxyV2SSL(value []byte) (*ProxyProtocolSSL, error) {
+	if len(value) < 5 {
+		return nil, fmt.Errorf("%w: invalid v2 SSL TLV", ErrProxyProtocolMalformed)
+	}
+	client := value[0]
+	ssl := &ProxyProtocolSSL{
+		TLS:        client&proxySSLClientSSL != 0,
+		ClientCert: client&(proxySSLClientCertConn|proxySSLClientCertSess) != 0,
+	}
+	ssl.Verified = ssl.ClientCert && binary.BigEndian.Uint32(value[1:5]) == 0
+
+	sub := &ProxyProtocolHeader{}
+	if err := parseProxyV2TLVs(sub, value, 5); err != nil {
+		return nil, err
+	}
+	for _, tlv := range sub.TLVs {
+		switch tlv.Type {
+		case proxySSLVersion:
+			ssl.Version = string(tlv.Value)
+		case proxySSLCommonName:
+			ssl.CommonName = string(tlv.Value)
+		case proxySSLCipher:
+			ssl.Cipher = string(tlv.Value)
+		case proxySSLSigAlg:
+			ssl.SignatureAlgorithm = string(tlv.Value)
+		case proxySSLKeyAlg:
+			ssl.KeyAlgorithm = string(tlv.Value)
+		default:
+		}
+	}
+	return ssl, nil
+}
@@ -0,0 +1,342 @@
+package fiber
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// proxyV2 builds a v2 header with the command, the address family and
+// transport byte, the addresses and the TLVs.
+func proxyV2(command, family byte, addrs []byte, tlvs ...[]byte) []byte {
+	payload := addrs
+	for _, tlv := range tlvs {
+		payload = append(payload, tlv...)
+	}
+	header := append([]byte(nil), proxyV2Signature...)
+	header = append(header, 0x20|command, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(payload))) //nolint:gosec // test headers are small
+	return append(header, payload...)
+}
+
+func proxyTLV(typ byte, value []byte) []byte {
+	return append(binary.BigEndian.AppendUint16([]byte{typ}, uint16(len(value))), value...) //nolint:gosec // test values are small
+}
+
+// withCRC32C appends a valid PP2_TYPE_CRC32C TLV to header.
+func withCRC32C(header []byte) []byte {
+	header = append(header, proxyTLV(proxyTLVCRC32C, make([]byte, 4))...)
+	binary.BigEndian.PutUint16(header[14:16], binary.BigEndian.Uint16(header[14:16])+7)
+	binary.BigEndian.PutUint32(header[len(header)-4:], crc32.Checksum(header, crc32cTable))
+	return header
+}
+
+var proxyInet4Addrs = []byte{192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb}
+
+// go test -run Test_ProxyProtocol_ReadHeader
+func Test_ProxyProtocol_ReadHeader(t *testing.T) {
+	t.Parallel()
+
+	ssl := append([]byte{proxySSLClientSSL | proxySSLClientCertConn, 0, 0, 0, 0}, proxyTLV(proxySSLVersion, []byte("TLSv1.3"))...)
+	ssl = append(ssl, proxyTLV(proxySSLCommonName, []byte("client"))...)
+	ssl = append(ssl, proxyTLV(proxySSLCipher, []byte("TLS_AES_128_GCM_SHA256"))...)
+	full := withCRC32C(proxyV2(1, 0x11, proxyInet4Addrs,
+		proxyTLV(proxyTLVALPN, []byte("h2")),
+		proxyTLV(proxyTLVAuthority, []byte("example.com")),
+		proxyTLV(proxyTLVUniqueID, []byte{1, 2, 3}),
+		proxyTLV(proxyTLVSSL, ssl),
+		proxyTLV(0xe0, []byte("custom")),
+	))
+	badCRC := withCRC32C(proxyV2(1, 0x11, proxyInet4Addrs))
+	badCRC[len(badCRC)-1]++
+
+	inet6 := make([]byte, 36)
+	inet6[15], inet6[31] = 1, 2
+	binary.BigEndian.PutUint16(inet6[32:], 1234)
+	binary.BigEndian.PutUint16(inet6[34:], 443)
+	unix := make([]byte, 216)
+	copy(unix, "/tmp/client.sock")
+	copy(unix[108:], "/tmp/server.sock")
+
+	for _, tc := range []struct {
+		check  func(t *testing.T, header *ProxyProtocolHeader)
+		name   string
+		input  string
+		source string
+		err    bool
+	}{
+		{name: "v1 tcp4", input: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n", source: "192.0.2.1:56324"},
+		{name: "v1 tcp6", input: "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n", source: "[2001:db8::1]:56324"},
+		{name: "v1 unknown", input: "PROXY UNKNOWN ffff::1 ffff::2 1 2\r\n", check: func(t *testing.T, header *ProxyProtocolHeader) {
+			t.Helper()
+			require.Equal(t, 1, header.Version)
+			require.Nil(t, header.Source)
+		}},
+		{name: "v1 family mismatch", input: "PROXY TCP4 2001:db8::1 2001:db8::2 1 2\r\n", err: true},
+		{name: "v1 missing port", input: "PROXY TCP4 192.0.2.1 198.51.100.1 56324\r\n", err: true},
+		{name: "v1 leading zero", input: "PROXY TCP4 192.0.2.1 198.51.100.1 056324 443\r\n", err: true},
+		{name: "v1 port range", input: "PROXY TCP4 192.0.2.1 198.51.100.1 65536 443\r\n", err: true},
+		{name: "v1 without CR", input: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\n", err: true},
+		{name: "v1 too long", input: "PROXY UNKNOWN " + strings.Repeat("x", 100) + "\r\n", err: true},
+		{name: "v2 tcp4", input: string(full), source: "192.0.2.1:56324", check: func(t *testing.T, header *ProxyProtocolHeader) {
+			t.Helper()
+			require.Equal(t, 2, header.Version)
+			require.Equal(t, "198.51.100.1:443", header.Destination.String())
+			require.Equal(t, "h2", header.ALPN)
+			require.Equal(t, "example.com", header.Authority)
+			require.Equal(t, []byte{1, 2, 3}, header.UniqueID)
+			require.Equal(t, &ProxyProtocolSSL{Version: "TLSv1.3", CommonName: "client", Cipher: "TLS_AES_128_GCM_SHA256", TLS: true, ClientCert: true, Verified: true}, header.SSL)
+			require.Len(t, header.TLVs, 6)
+			require.Equal(t, ProxyProtocolTLV{Type: 0xe0, Value: []byte("custom")}, header.TLVs[4])
+		}},
+		{name: "v2 tcp6", input: string(proxyV2(1, 0x21, inet6)), source: "[::1]:1234"},
+		{name: "v2 unix", input: string(proxyV2(1, 0x31, unix)), source: "/tmp/client.sock"},
+		{name: "v2 local", input: string(proxyV2(0, 0x11, proxyInet4Addrs)), check: func(t *testing.T, header *ProxyProtocolHeader) {
+			t.Helper()
+			require.True(t, header.Local)
+			require.Nil(t, header.Source)
+		}},
+		{name: "v2 checksum", input: string(badCRC), err: true},
+		{name: "v2 version", input: string(append(append([]byte(nil), proxyV2Signature...), 0x11, 0x11, 0, 0)), err: true},
+		{name: "v2 command", input: string(proxyV2(2, 0x11, proxyInet4Addrs)), err: true},
+		{name: "v2 truncated addresses", input: string(proxyV2(1, 0x21, proxyInet4Addrs)), err: true},
+		{name: "v2 truncated TLV", input: string(proxyV2(1, 0x11, proxyInet4Addrs, []byte{proxyTLVALPN, 0, 5, 'h'})), err: true},
+		{name: "v2 truncated header", input: string(proxyV2Signature) + "\x21\x11\x00\x20", err: true},
+		{name: "no header", input: "GET / HTTP/1.1\r\n"},
+		{name: "no header with P", input: "POST / HTTP/1.1\r\n"},
+		{name: "leading CRLF", input: "\r\nGET / HTTP/1.1\r\n"},
+	} {
+		br := bufio.NewReaderSize(strings.NewReader(tc.input+"rest"), proxyReadAheadSize)
+		header, err := readProxyProtocolHeader(br)
+		if tc.err {
+			require.ErrorIs(t, err, ErrProxyProtocolMalformed, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+		rest, err := io.ReadAll(br)
+		require.NoError(t, err)
+
+		if strings.HasPrefix(tc.name, "no header") || tc.name == "leading CRLF" {
+			require.Nil(t, header, tc.name)
+			require.Equal(t, tc.input+"rest", string(rest), tc.name)
+			continue
+		}
+		require.Equal(t, "rest", string(rest), tc.name)
+		if tc.source != "" {
+			require.Equal(t, tc.source, header.Source.String(), tc.name)
+		}
+		if tc.check != nil {
+			tc.check(t, header)
+		}
+	}
+}
+
+func proxyRequest(t *testing.T, conn net.Conn, br *bufio.Reader) (int, string) {
+	t.Helper()
+	_, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp.StatusCode, string(body)
+}
+
+func proxyProtocolApp() *App {
+	app := New()
+	app.Get("/", func(c Ctx) error {
+		result := c.IP() + " " + c.Port()
+		if header := ProxyProtocol(c); header != nil {
+			result += " " + header.Authority
+		}
+		return c.SendString(result)
+	})
+	return app
+}
+
+// go test -run Test_Listen_ProxyProtocol
+func Test_Listen_ProxyProtocol(t *testing.T) {
+	addr := listenAsync(t, proxyProtocolApp(), ListenConfig{
+		EnableProxyProtocol: true,
+		ProxyProtocolConfig: ProxyProtocolConfig{
+			TrustedSources: []string{"10.0.0.0/8", "127.0.0.1"},
+		},
+	})
+
+	// v2 header with an authority, kept for the whole connection
+	conn, err := net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write(proxyV2(1, 0x11, proxyInet4Addrs, proxyTLV(proxyTLVAuthority, []byte("example.com"))))
+	require.NoError(t, err)
+	br := bufio.NewReader(conn)
+	for range 2 {
+		status, body := proxyRequest(t, conn, br)
+		require.Equal(t, StatusOK, status)
+		require.Equal(t, "192.0.2.1 56324 example.com", body)
+	}
+
+	// v1 header
+	conn, err = net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	_, err = conn.Write([]byte("PROXY TCP6 2001:db8::1 2001:db8::2 4000 443\r\n"))
+	require.NoError(t, err)
+	status, body := proxyRequest(t, conn, bufio.NewReader(conn))
+	require.Equal(t, StatusOK, status)
+	require.Equal(t, "2001:db8::1 4000 ", body)
+
+	// the header is optional
+	conn, err = net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	status, body = proxyRequest(t, conn, bufio.NewReader(conn))
+	require.Equal(t, StatusOK, status)
+	require.True(t, strings.HasPrefix(body, "127.0.0.1 "))
+
+	// malformed headers close the connection
+	conn, err = net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte("PROXY TCP4 nope\r\nGET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	_, err = http.ReadResponse(bufio.NewReader(conn), nil)
+	require.Error(t, err)
+}
+
+// go test -run Test_Listen_ProxyProtocol_Sources
+func Test_Listen_ProxyProtocol_Sources(t *testing.T) {
+	addr := listenAsync(t, proxyProtocolApp(), ListenConfig{
+		EnableProxyProtocol: true,
+		ProxyProtocolConfig: ProxyProtocolConfig{
+			TrustedSources: []string{"10.0.0.0/8"},
+		},
+	})
+
+	// headers of untrusted peers aren't parsed
+	conn, err := net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"))
+	require.NoError(t, err)
+	status, _ := proxyRequest(t, conn, bufio.NewReader(conn))
+	require.Equal(t, StatusBadRequest, status)
+
+	err = New().Listen(":0", ListenConfig{
+		DisableStartupMessage: true,
+		EnableProxyProtocol:   true,
+		ProxyProtocolConfig: ProxyProtocolConfig{
+			TrustedSources: []string{"10.0.0.0/33"},
+		},
+	})
+	require.ErrorContains(t, err, `proxy protocol: invalid trusted source "10.0.0.0/33"`)
+
+	// TCP peers are only trusted when listed
+	err = New().Listen(":0", ListenConfig{
+		DisableStartupMessage: true,
+		EnableProxyProtocol:   true,
+	})
+	require.ErrorIs(t, err, ErrProxyProtocolNoTrustedSources)
+}
+
+// go test -run Test_Listen_ProxyProtocol_Required
+func Test_Listen_ProxyProtocol_Required(t *testing.T) {
+	addr := listenAsync(t, proxyProtocolApp(), ListenConfig{
+		EnableProxyProtocol: true,
+		ProxyProtocolConfig: ProxyProtocolConfig{
+			TrustedSources: []string{"127.0.0.1"},
+			Required:       true,
+			HeaderTimeout:  100 * time.Millisecond,
+		},
+	})
+
+	// connections without header are closed
+	conn, err := net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	_, err = http.ReadResponse(bufio.NewReader(conn), nil)
+	require.Error(t, err)
+
+	// as are the ones not sending the header in time
+	conn, err = net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte("PROXY TCP4"))
+	require.NoError(t, err)
+	_, err = conn.Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.EOF)
+}
+
+// go test -run Test_Listen_ProxyProtocol_TLS
+func Test_Listen_ProxyProtocol_TLS(t *testing.T) {
+	addr := listenAsync(t, proxyProtocolApp(), ListenConfig{
+		EnableProxyProtocol: true,
+		ProxyProtocolConfig: ProxyProtocolConfig{
+			TrustedSources: []string{"127.0.0.1"},
+		},
+		CertFile:    "./.github/testdata/ssl.pem",
+		CertKeyFile: "./.github/testdata/ssl.key",
+	})
+
+	conn, err := net.Dial(NetworkTCP4, addr)
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"))
+	require.NoError(t, err)
+
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true}) //nolint:gosec // self-signed test certificate
+	status, body := proxyRequest(t, tlsConn, bufio.NewReader(tlsConn))
+	require.Equal(t, StatusOK, status)
+	require.Equal(t, "192.0.2.1 56324 ", body)
+}
+
+// go test -run Test_Listener_ProxyProtocol_Unix
+func Test_Listener_ProxyProtocol_Unix(t *testing.T) {
+	ln, err := net.Listen(NetworkUnix, filepath.Join(t.TempDir(), "fiber.sock"))
+	require.NoError(t, err)
+	app := proxyProtocolApp()
+	served := make(chan error, 1)
+	go func() {
+		// the peers of unix sockets are trusted without TrustedSources
+		served <- app.Listener(ln, ListenConfig{
+			DisableStartupMessage: true,
+			EnableProxyProtocol:   true,
+		})
+	}()
+	defer func() {
+		require.NoError(t, app.Shutdown())
+		require.NoError(t, <-served)
+	}()
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial(NetworkUnix, ln.Addr().String())
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"))
+	require.NoError(t, err)
+	status, body := proxyRequest(t, conn, bufio.NewReader(conn))
+	require.Equal(t, StatusOK, status)
+	require.Equal(t, "192.0.2.1 56324 ", body)
+}
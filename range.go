@@ -0,0 +1,250 @@
+package fiber
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/utils/v2"
+	"github.com/valyala/bytebufferpool"
+	"github.com/valyala/fasthttp"
+)
+
+var acceptRangesBytes = []byte("bytes")
+
+// rangeStream is the body stream SendStream sets for io.ReadSeeker streams
+// when the request asks for ranges, so that they can be served from it after
+// the handler returns.
+type rangeStream struct {
+	stream io.ReadSeeker
+	reader io.Reader // reads the whole body or the selected ranges
+	base   int64     // offset of the first byte of the body in stream
+	size   int64
+}
+
+// wrappedRangeStream is a rangeStream wrapped with WrapBodyStream. The
+// wrappers read from the rangeStream, so ranges are still served from it.
+type wrappedRangeStream struct {
+	io.ReadCloser
+	ranges *rangeStream
+}
+
+// wantsRanges reports whether serveRanges may serve ranges of the response
+// to request.
+func wantsRanges(request *fasthttp.Request) bool {
+	return request.Header.IsGet() && len(request.Header.Peek(HeaderRange)) > 0
+}
+
+// newRangeStream returns a rangeStream reading size bytes from the current
+// offset of stream, all of the remaining bytes for a negative size. It
+// returns nil if the stream can't seek.
+func newRangeStream(stream io.ReadSeeker, size int) *rangeStream {
+	base, err := stream.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+	s := &rangeStream{stream: stream, reader: stream, base: base, size: int64(size)}
+	if size < 0 {
+		end, err := stream.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil
+		}
+		if _, err := stream.Seek(base, io.SeekStart); err != nil {
+			return nil
+		}
+		s.size = end - base
+	}
+	return s
+}
+
+func (s *rangeStream) Read(p []byte) (int, error) {
+	return s.reader.Read(p) //nolint:wrapcheck // io.EOF must be returned as is
+}
+
+// Close closes the stream if it's an io.Closer, as fasthttp does for the
+// streams it's given.
+func (s *rangeStream) Close() error {
+	if closer, ok := s.stream.(io.Closer); ok {
+		return closer.Close() //nolint:wrapcheck // the error of the stream is returned as is
+	}
+	return nil
+}
+
+// section returns a reader of n bytes of the body from offset.
+func (s *rangeStream) section(offset, n int64) io.Reader {
+	return &streamSection{stream: s.stream, offset: s.base + offset, remaining: n}
+}
+
+// streamSection reads remaining bytes of a stream from offset, seeking on
+// the first read so that sections can be read one after another.
+type streamSection struct {
+	stream    io.ReadSeeker
+	offset    int64
+	remaining int64
+	seeked    bool
+}
+
+func (s *streamSection) Read(p []byte) (int, error) {
+	if !s.seeked {
+		if _, err := s.stream.Seek(s.offset, io.SeekStart); err != nil {
+			return 0, err //nolint:wrapcheck // the error of the stream is returned as is
+		}
+		s.seeked = true
+	}
+	if s.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > s.remaining {
+		p = p[:s.remaining]
+	}
+	n, err := s.stream.Read(p)
+	s.remaining -= int64(n)
+	if errors.Is(err, io.EOF) && s.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// serveRanges answers a GET request with a Range header with the selected
+// ranges of its 200 response, if the response announces Accept-Ranges: bytes
+// and its body is set by Send, SendString or SendStream with an
+// io.ReadSeeker. A single range is sent as is, several ranges as
+// multipart/byteranges, and unsatisfiable ranges get 416. Malformed Range
+// headers and a stale If-Range get the whole response.
+func serveRanges(c Ctx) {
+	if c.Method() != MethodGet || c.IsAbandoned() {
+		return
+	}
+	response := c.Response()
+	if response.StatusCode() != StatusOK || !bytes.EqualFold(response.Header.Peek(HeaderAcceptRanges), acceptRangesBytes) {
+		return
+	}
+	header := &c.Request().Header
+	if len(header.Peek(HeaderRange)) == 0 || len(response.Header.Peek(HeaderContentEncoding)) > 0 {
+		return
+	}
+	if ifRange := header.Peek(HeaderIfRange); len(ifRange) > 0 && !ifRangeMatches(ifRange, &response.Header) {
+		return
+	}
+
+	var (
+		body   []byte
+		stream *rangeStream
+		size   int64
+	)
+	if response.IsBodyStream() {
+		switch s := response.BodyStream().(type) {
+		case *rangeStream:
+			stream = s
+		case *wrappedRangeStream:
+			stream = s.ranges
+		default:
+			return
+		}
+		size = stream.size
+	} else {
+		body = response.Body()
+		size = int64(len(body))
+	}
+
+	ranges, err := c.Range(size)
+	if err != nil {
+		if !errors.Is(err, ErrRangeMalformed) {
+			// Range set the 416 status and Content-Range
+			response.ResetBody()
+			response.Header.Del(HeaderContentDigest)
+		}
+		return
+	}
+
+	// a digest of the whole content doesn't match the ranges, unlike
+	// Repr-Digest
+	response.Header.Del(HeaderContentDigest)
+	response.SetStatusCode(StatusPartialContent)
+	if len(ranges.Ranges) == 1 {
+		rng := ranges.Ranges[0]
+		response.Header.Set(HeaderContentRange, contentRange(rng, size))
+		if stream != nil {
+			stream.reader = stream.section(rng.Start, rng.End-rng.Start+1)
+			response.Header.SetContentLength(int(rng.End - rng.Start + 1))
+		} else {
+			response.SetBody(body[rng.Start : rng.End+1])
+		}
+		return
+	}
+
+	boundary := rangeBoundary()
+	contentType := string(response.Header.ContentType())
+	response.Header.SetContentType("multipart/byteranges; boundary=" + boundary)
+	closing := "\r\n--" + boundary + "--\r\n"
+
+	if stream != nil {
+		readers := make([]io.Reader, 0, 2*len(ranges.Ranges)+1)
+		length := int64(len(closing))
+		for i, rng := range ranges.Ranges {
+			part := rangePartHeader(i, boundary, contentType, rng, size)
+			readers = append(readers, strings.NewReader(part), stream.section(rng.Start, rng.End-rng.Start+1))
+			length += int64(len(part)) + rng.End - rng.Start + 1
+		}
+		stream.reader = io.MultiReader(append(readers, strings.NewReader(closing))...)
+		response.Header.SetContentLength(int(length))
+		return
+	}
+
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	for i, rng := range ranges.Ranges {
+		buf.WriteString(rangePartHeader(i, boundary, contentType, rng, size))
+		buf.Write(body[rng.Start : rng.End+1])
+	}
+	buf.WriteString(closing)
+	response.SetBody(buf.B)
+}
+
+// rangePartHeader returns the delimiter and the headers of the i-th part of a
+// multipart/byteranges body.
+func rangePartHeader(i int, boundary, contentType string, rng RangeSet, size int64) string {
+	var b strings.Builder
+	if i > 0 {
+		b.WriteString("\r\n")
+	}
+	b.WriteString("--" + boundary + "\r\n")
+	if contentType != "" {
+		b.WriteString(HeaderContentType + ": " + contentType + "\r\n")
+	}
+	b.WriteString(HeaderContentRange + ": " + contentRange(rng, size) + "\r\n\r\n")
+	return b.String()
+}
+
+// ifRangeMatches reports whether the If-Range validator matches the
+// response: an entity tag equal to its ETag by strong comparison, or a date
+// equal to its Last-Modified (RFC 9110 §13.1.5).
+func ifRangeMatches(ifRange []byte, response *fasthttp.ResponseHeader) bool {
+	value := utils.UnsafeString(utils.TrimSpace(ifRange))
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "W/") {
+		return matchEtagStrong(value, utils.UnsafeString(response.Peek(HeaderETag)))
+	}
+	date, err := fasthttp.ParseHTTPDate(ifRange)
+	if err != nil {
+		return false
+	}
+	lastModified, err := fasthttp.ParseHTTPDate(response.Peek(HeaderLastModified))
+	return err == nil && lastModified.Equal(date)
+}
+
+// contentRange returns the Content-Range value of a range of a body of size
+// bytes.
+func contentRange(rng RangeSet, size int64) string {
+	return "bytes " + strconv.FormatInt(rng.Start, 10) + "-" + strconv.FormatInt(rng.End, 10) + "/" + strconv.FormatInt(size, 10)
+}
+
+// rangeBoundary returns a random boundary for multipart/byteranges bodies.
+func rangeBoundary() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) //nolint:errcheck // crypto/rand.Read never fails
+	return hex.EncodeToString(b[:])
+}
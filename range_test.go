@@ -0,0 +1,233 @@
+package fiber
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const rangeBody = "0123456789abcdefghij"
+
+func rangeRequest(t *testing.T, app *App, method, target string, headers ...string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, http.NoBody)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func rangeApp() *App {
+	app := New()
+	app.Get("/bytes", func(c Ctx) error {
+		c.Set(HeaderAcceptRanges, "bytes")
+		c.Set(HeaderETag, `"v1"`)
+		c.Set(HeaderLastModified, "Wed, 21 Oct 2015 07:28:00 GMT")
+		c.Type("txt")
+		return c.SendString(rangeBody)
+	})
+	app.Get("/stream", func(c Ctx) error {
+		reader := strings.NewReader("--" + rangeBody)
+		if _, err := reader.Seek(2, io.SeekStart); err != nil {
+			return err
+		}
+		// the header may be set after the body
+		if err := c.SendStream(reader); err != nil {
+			return err
+		}
+		c.Set(HeaderAcceptRanges, "bytes")
+		return nil
+	})
+	app.Get("/plain", func(c Ctx) error {
+		return c.SendString(rangeBody)
+	})
+	app.Get("/reader", func(c Ctx) error {
+		c.Set(HeaderAcceptRanges, "bytes")
+		return c.SendStream(io.MultiReader(strings.NewReader(rangeBody)))
+	})
+	return app
+}
+
+// go test -run Test_Ctx_SendRanges
+func Test_Ctx_SendRanges(t *testing.T) {
+	t.Parallel()
+
+	app := rangeApp()
+	for _, target := range []string{"/bytes", "/stream"} {
+		resp, body := rangeRequest(t, app, MethodGet, target)
+		require.Equal(t, StatusOK, resp.StatusCode, target)
+		require.Equal(t, rangeBody, body, target)
+
+		resp, body = rangeRequest(t, app, MethodGet, target, HeaderRange, "bytes=2-5")
+		require.Equal(t, StatusPartialContent, resp.StatusCode, target)
+		require.Equal(t, "2345", body, target)
+		require.Equal(t, "bytes 2-5/20", resp.Header.Get(HeaderContentRange), target)
+		require.Equal(t, int64(4), resp.ContentLength, target)
+
+		resp, body = rangeRequest(t, app, MethodGet, target, HeaderRange, "bytes=-3")
+		require.Equal(t, StatusPartialContent, resp.StatusCode, target)
+		require.Equal(t, "hij", body, target)
+
+		resp, body = rangeRequest(t, app, MethodGet, target, HeaderRange, "bytes=30-")
+		require.Equal(t, StatusRequestedRangeNotSatisfiable, resp.StatusCode, target)
+		require.Equal(t, "bytes */20", resp.Header.Get(HeaderContentRange), target)
+		require.Empty(t, body, target)
+
+		// malformed ranges are ignored
+		resp, body = rangeRequest(t, app, MethodGet, target, HeaderRange, "lines=1-2")
+		require.Equal(t, StatusOK, resp.StatusCode, target)
+		require.Equal(t, rangeBody, body, target)
+	}
+
+	// responses without Accept-Ranges, other methods and plain readers are sent whole
+	for _, target := range []string{"/plain", "/reader"} {
+		resp, body := rangeRequest(t, app, MethodGet, target, HeaderRange, "bytes=2-5")
+		require.Equal(t, StatusOK, resp.StatusCode, target)
+		require.Equal(t, rangeBody, body, target)
+	}
+	resp, _ := rangeRequest(t, app, MethodHead, "/bytes", HeaderRange, "bytes=2-5")
+	require.Equal(t, StatusOK, resp.StatusCode)
+}
+
+// go test -run Test_Ctx_SendRanges_Multipart
+func Test_Ctx_SendRanges_Multipart(t *testing.T) {
+	t.Parallel()
+
+	app := rangeApp()
+	for _, target := range []string{"/bytes", "/stream"} {
+		resp, body := rangeRequest(t, app, MethodGet, target, HeaderRange, "bytes=0-1, 10-12,-2")
+		require.Equal(t, StatusPartialContent, resp.StatusCode, target)
+		require.Equal(t, int64(len(body)), resp.ContentLength, target)
+
+		mediaType, params, err := mime.ParseMediaType(resp.Header.Get(HeaderContentType))
+		require.NoError(t, err)
+		require.Equal(t, "multipart/byteranges", mediaType)
+
+		reader := multipart.NewReader(strings.NewReader(body), params["boundary"])
+		var parts []string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			content, err := io.ReadAll(part)
+			require.NoError(t, err)
+			parts = append(parts, part.Header.Get(HeaderContentRange)+" "+string(content))
+			if target == "/bytes" {
+				require.Equal(t, MIMETextPlainCharsetUTF8, part.Header.Get(HeaderContentType))
+			}
+		}
+		require.Equal(t, []string{"bytes 0-1/20 01", "bytes 10-12/20 abc", "bytes 18-19/20 ij"}, parts, target)
+	}
+
+	// too many ranges
+	limited := New(Config{MaxRanges: 2})
+	limited.Get("/", func(c Ctx) error {
+		c.Set(HeaderAcceptRanges, "bytes")
+		return c.SendString(rangeBody)
+	})
+	resp, body := rangeRequest(t, limited, MethodGet, "/", HeaderRange, "bytes=0-1,2-3,4-5")
+	require.Equal(t, StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+	require.Empty(t, body)
+}
+
+// go test -run Test_Ctx_SendRanges_IfRange
+func Test_Ctx_SendRanges_IfRange(t *testing.T) {
+	t.Parallel()
+
+	app := rangeApp()
+	for _, tc := range []struct {
+		ifRange string
+		status  int
+	}{
+		{ifRange: `"v1"`, status: StatusPartialContent},
+		{ifRange: `"v0"`, status: StatusOK},
+		{ifRange: `W/"v1"`, status: StatusOK},
+		{ifRange: "Wed, 21 Oct 2015 07:28:00 GMT", status: StatusPartialContent},
+		{ifRange: "Wed, 21 Oct 2015 07:27:00 GMT", status: StatusOK},
+		{ifRange: "yesterday", status: StatusOK},
+	} {
+		resp, _ := rangeRequest(t, app, MethodGet, "/bytes", HeaderRange, "bytes=2-5", HeaderIfRange, tc.ifRange)
+		require.Equal(t, tc.status, resp.StatusCode, tc.ifRange)
+	}
+}
+
+// go test -run Test_Ctx_SendRanges_WrapBodyStream
+func Test_Ctx_SendRanges_WrapBodyStream(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	var seen bytes.Buffer
+	app.Use(func(c Ctx) error {
+		c.WrapBodyStream(func(stream io.Reader, _ int) io.Reader {
+			return io.TeeReader(stream, &seen)
+		})
+		return c.Next()
+	})
+	app.Get("/", func(c Ctx) error {
+		c.Set(HeaderAcceptRanges, "bytes")
+		return c.SendStream(strings.NewReader(rangeBody))
+	})
+
+	resp, body := rangeRequest(t, app, MethodGet, "/", HeaderRange, "bytes=2-5")
+	require.Equal(t, StatusPartialContent, resp.StatusCode)
+	require.Equal(t, "bytes 2-5/20", resp.Header.Get(HeaderContentRange))
+	require.Equal(t, "2345", body)
+	// the wrapper reads what is sent
+	require.Equal(t, "2345", seen.String())
+}
+
+// go test -run Test_Ctx_SendStream_File
+func Test_Ctx_SendStream_File(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte(rangeBody), 1000), 0o600))
+
+	var (
+		file *os.File
+		asIs bool
+	)
+	app := New()
+	app.Get("/", func(c Ctx) error {
+		var err error
+		file, err = os.Open(path) //nolint:gosec // test file
+		if err != nil {
+			return err
+		}
+		c.Set(HeaderAcceptRanges, "bytes")
+		err = c.SendStream(file)
+		asIs = c.Response().BodyStream() == io.Reader(file)
+		return err
+	})
+
+	// without a Range header, the file is sent as is with sendfile
+	resp, body := rangeRequest(t, app, MethodGet, "/")
+	require.Equal(t, StatusOK, resp.StatusCode)
+	require.Len(t, body, 20000)
+	require.True(t, asIs)
+
+	resp, body = rangeRequest(t, app, MethodGet, "/", HeaderRange, "bytes=19990-")
+	require.Equal(t, StatusPartialContent, resp.StatusCode)
+	require.False(t, asIs)
+	require.Equal(t, "bytes 19990-19999/20000", resp.Header.Get(HeaderContentRange))
+	require.Equal(t, "abcdefghij", body)
+
+	// the file is closed after the response is sent
+	_, err := file.Stat()
+	require.ErrorIs(t, err, os.ErrClosed)
+}
@@ -9,6 +9,7 @@ import (
 	"net/netip"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/utils/v2"
 	utilsbytes "github.com/gofiber/utils/v2/bytes"
@@ -102,7 +103,7 @@ func (r *DefaultReq) tryDecodeBodyInOrder(
 	encodings []string,
 ) (body []byte, decodesRealized uint8, err error) {
 	request := &r.c.fasthttp.Request
-	maxBodySize := r.c.app.config.BodyLimit
+	maxBodySize := requestBodyLimit(r.c)
 	for idx := range encodings {
 		i := len(encodings) - 1 - idx
 		encoding := encodings[i]
@@ -156,6 +157,7 @@ func (r *DefaultReq) Body() []byte {
 	)
 
 	request := &r.c.fasthttp.Request
+	r.c.readBodyStream()
 
 	// Get Content-Encoding header
 	headerEncoding = utils.UnsafeString(utilsbytes.UnsafeToLower(request.Header.ContentEncoding()))
@@ -196,7 +198,7 @@ func (r *DefaultReq) Body() []byte {
 		return []byte(err.Error())
 	}
 
-	return r.c.app.GetBytes(body)
+	return r.c.getBytes(body)
 }
 
 // RequestCtx returns *fasthttp.RequestCtx that carries a deadline
@@ -220,22 +222,22 @@ func (c *DefaultCtx) FullURL() string {
 
 // UserAgent returns the User-Agent request header.
 func (c *DefaultCtx) UserAgent() string {
-	return c.app.toString(c.fasthttp.Request.Header.UserAgent())
+	return c.toString(c.fasthttp.Request.Header.UserAgent())
 }
 
 // Referer returns the Referer request header.
 func (c *DefaultCtx) Referer() string {
-	return c.app.toString(c.fasthttp.Request.Header.Referer())
+	return c.toString(c.fasthttp.Request.Header.Referer())
 }
 
 // AcceptLanguage returns the Accept-Language request header.
 func (c *DefaultCtx) AcceptLanguage() string {
-	return c.app.toString(c.fasthttp.Request.Header.Peek(HeaderAcceptLanguage))
+	return c.toString(c.fasthttp.Request.Header.Peek(HeaderAcceptLanguage))
 }
 
 // AcceptEncoding returns the Accept-Encoding request header.
 func (c *DefaultCtx) AcceptEncoding() string {
-	return c.app.toString(c.fasthttp.Request.Header.Peek(HeaderAcceptEncoding))
+	return c.toString(c.fasthttp.Request.Header.Peek(HeaderAcceptEncoding))
 }
 
 // HasHeader reports whether the request includes a header with the given key.
@@ -253,7 +255,7 @@ func (c *DefaultCtx) MediaType() string {
 		contentType = contentType[:idx]
 	}
 	contentType = utils.TrimSpace(contentType)
-	return c.app.toString(contentType)
+	return c.toString(contentType)
 }
 
 // Charset returns the charset parameter from the Content-Type header.
@@ -296,7 +298,7 @@ func (c *DefaultCtx) Charset() string {
 		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
 			value = value[1 : len(value)-1]
 		}
-		return c.app.toString(value)
+		return c.toString(value)
 	}
 	return ""
 }
@@ -342,7 +344,7 @@ func (c *DefaultCtx) AcceptsEventStream() bool {
 // The returned value is only valid within the handler. Do not store any references.
 // Make copies or use the Immutable setting to use the value outside the Handler.
 func (r *DefaultReq) Cookies(key string, defaultValue ...string) string {
-	return defaultString(r.c.app.toString(r.c.fasthttp.Request.Header.Cookie(key)), defaultValue)
+	return defaultString(r.c.toString(r.c.fasthttp.Request.Header.Cookie(key)), defaultValue)
 }
 
 // Request return the *fasthttp.Request object
@@ -378,10 +380,10 @@ func (r *DefaultReq) FormValue(key string, defaultValue ...string) string {
 		//
 		// Preserve the original search order: QueryArgs → PostArgs → MultipartForm.
 		if v := r.c.fasthttp.QueryArgs().Peek(key); len(v) > 0 {
-			return r.c.app.toString(v)
+			return r.c.toString(v)
 		}
 		if v := r.c.fasthttp.PostArgs().Peek(key); len(v) > 0 {
-			return r.c.app.toString(v)
+			return r.c.toString(v)
 		}
 		mf, err := r.MultipartForm()
 		if err != nil {
@@ -392,7 +394,7 @@ func (r *DefaultReq) FormValue(key string, defaultValue ...string) string {
 		}
 		return defaultString("", defaultValue)
 	}
-	return defaultString(r.c.app.toString(r.c.fasthttp.FormValue(key)), defaultValue)
+	return defaultString(r.c.toString(r.c.fasthttp.FormValue(key)), defaultValue)
 }
 
 // Fresh returns true when the response is still “fresh” in the client's cache,
@@ -451,6 +453,63 @@ func (r *DefaultReq) Fresh() bool {
 	return true
 }
 
+// CheckPreconditions evaluates the If-Match, If-Unmodified-Since,
+// If-None-Match and If-Modified-Since request headers against the current
+// validators of the target resource, in the order of RFC 9110 §13.2.2.
+// Pass an empty etag when the resource doesn't exist and a zero lastModified
+// when it's unknown.
+// It returns StatusOK when the request can proceed, StatusNotModified for GET
+// and HEAD requests whose cached representation is still valid, with the
+// validators set as the ETag and Last-Modified response headers, and
+// StatusPreconditionFailed otherwise.
+func (r *DefaultReq) CheckPreconditions(etag string, lastModified time.Time) int {
+	header := &r.c.fasthttp.Request.Header
+	app := r.c.app
+	lastModified = lastModified.Truncate(time.Second)
+
+	// step 1 and 2: If-Match, otherwise If-Unmodified-Since
+	if ifMatch := header.Peek(HeaderIfMatch); len(ifMatch) > 0 {
+		if etag == "" || !matchEtagList(app.toString(ifMatch), etag, true) {
+			return StatusPreconditionFailed
+		}
+	} else if since := header.Peek(HeaderIfUnmodifiedSince); len(since) > 0 && !lastModified.IsZero() {
+		if sinceTime, err := fasthttp.ParseHTTPDate(since); err == nil && lastModified.After(sinceTime) {
+			return StatusPreconditionFailed
+		}
+	}
+
+	method := r.c.Method()
+	safe := method == MethodGet || method == MethodHead
+
+	// step 3 and 4: If-None-Match, otherwise If-Modified-Since for GET and HEAD
+	notModified := false
+	if ifNoneMatch := header.Peek(HeaderIfNoneMatch); len(ifNoneMatch) > 0 {
+		if etag != "" && matchEtagList(app.toString(ifNoneMatch), etag, false) {
+			if !safe {
+				return StatusPreconditionFailed
+			}
+			notModified = true
+		}
+	} else if since := header.Peek(HeaderIfModifiedSince); safe && len(since) > 0 && !lastModified.IsZero() {
+		if sinceTime, err := fasthttp.ParseHTTPDate(since); err == nil && !lastModified.After(sinceTime) {
+			notModified = true
+		}
+	}
+	if !notModified {
+		return StatusOK
+	}
+
+	// a 304 response carries the validators a 200 response would have
+	response := &r.c.fasthttp.Response
+	if etag != "" {
+		response.Header.Set(HeaderETag, etag)
+	}
+	if !lastModified.IsZero() {
+		response.Header.SetBytesV(HeaderLastModified, fasthttp.AppendHTTPDate(nil, lastModified))
+	}
+	return StatusNotModified
+}
+
 // Get returns the HTTP request header specified by field.
 // Field names are case-insensitive
 // Returned value is only valid within the handler. Do not store any references.
@@ -464,7 +523,7 @@ func (r *DefaultReq) Get(key string, defaultValue ...string) string {
 // If the generic type cannot be matched to a supported type, the function
 // returns the default value (if provided) or the zero value of type V.
 func GetReqHeader[V GenericType](c Ctx, key string, defaultValue ...V) V {
-	v, err := genericParseType[V](c.App().toString(c.Request().Header.Peek(key)))
+	v, err := genericParseType[V](requestString(c, c.Request().Header.Peek(key)))
 	if err != nil && len(defaultValue) > 0 {
 		return defaultValue[0]
 	}
@@ -475,13 +534,12 @@ func GetReqHeader[V GenericType](c Ctx, key string, defaultValue ...V) V {
 // Returned value is only valid within the handler. Do not store any references.
 // Make copies or use the Immutable setting instead.
 func (r *DefaultReq) GetHeaders() map[string][]string {
-	app := r.c.app
 	reqHeader := &r.c.fasthttp.Request.Header
 	// Pre-allocate map with known header count to avoid reallocations
 	headers := make(map[string][]string, reqHeader.Len())
 	for k, v := range reqHeader.All() {
-		key := app.toString(k)
-		headers[key] = append(headers[key], app.toString(v))
+		key := r.c.toString(k)
+		headers[key] = append(headers[key], r.c.toString(v))
 	}
 	return headers
 }
@@ -502,7 +560,7 @@ func (r *DefaultReq) Host() string {
 			return utils.TrimSpace(host)
 		}
 	}
-	return r.c.app.toString(r.c.fasthttp.Request.URI().Host())
+	return r.c.toString(r.c.fasthttp.Request.URI().Host())
 }
 
 // Hostname contains the hostname derived from the X-Forwarded-Host or Host HTTP header using the c.Host() method.
@@ -752,7 +810,7 @@ func (r *DefaultReq) Is(extension string) bool {
 		return false
 	}
 
-	ct := r.c.app.toString(r.c.fasthttp.Request.Header.ContentType())
+	ct := r.c.toString(r.c.fasthttp.Request.Header.ContentType())
 	if i := strings.IndexByte(ct, ';'); i != -1 {
 		ct = ct[:i]
 	}
@@ -824,14 +882,14 @@ func (r *DefaultReq) Method(override ...string) string {
 // MultipartForm parse form entries from binary.
 // This returns a map[string][]string, so given a key, the value will be a string slice.
 func (r *DefaultReq) MultipartForm() (*multipart.Form, error) {
-	return r.c.fasthttp.MultipartFormWithLimit(r.c.app.config.BodyLimit)
+	return r.c.fasthttp.MultipartFormWithLimit(requestBodyLimit(r.c))
 }
 
 // OriginalURL contains the original request URL.
 // Returned value is only valid within the handler. Do not store any references.
 // Make copies or use the Immutable setting to use the value outside the Handler.
 func (r *DefaultReq) OriginalURL() string {
-	return r.c.app.toString(r.c.fasthttp.Request.Header.RequestURI())
+	return r.c.toString(r.c.fasthttp.Request.Header.RequestURI())
 }
 
 // Params is used to get the route parameters.
@@ -857,7 +915,7 @@ func (r *DefaultReq) Params(key string, defaultValue ...string) string {
 				break
 			}
 			val := values[i]
-			return r.c.app.GetString(val)
+			return r.c.getString(val)
 		}
 	}
 	return defaultString("", defaultValue)
@@ -897,7 +955,6 @@ func (r *DefaultReq) Scheme() string {
 		return schemeHTTP
 	}
 
-	app := r.c.app
 	scheme := schemeHTTP
 	const lenXHeaderName = 12
 	for key, val := range ctx.Request.Header.All() {
@@ -908,7 +965,7 @@ func (r *DefaultReq) Scheme() string {
 		case utils.EqualFold(key[:len(xForwardedPrefix)], xForwardedPrefix):
 			if utils.EqualFold(key, xForwardedProtoBytes) ||
 				utils.EqualFold(key, xForwardedProtocolBytes) {
-				v := app.toString(val)
+				v := r.c.toString(val)
 				if before, _, found := strings.Cut(v, ","); found {
 					scheme = utils.TrimSpace(before)
 				} else {
@@ -919,7 +976,7 @@ func (r *DefaultReq) Scheme() string {
 			}
 
 		case utils.EqualFold(key, xURLSchemeBytes):
-			scheme = utils.TrimSpace(app.toString(val))
+			scheme = utils.TrimSpace(r.c.toString(val))
 		default:
 			continue
 		}
@@ -929,7 +986,7 @@ func (r *DefaultReq) Scheme() string {
 
 // Protocol returns the HTTP protocol of request: HTTP/1.1 and HTTP/2.
 func (r *DefaultReq) Protocol() string {
-	return r.c.app.toString(r.c.fasthttp.Request.Header.Protocol())
+	return r.c.toString(r.c.fasthttp.Request.Header.Protocol())
 }
 
 // Query returns the query string parameter in the url.
@@ -963,12 +1020,11 @@ func (r *DefaultReq) Query(key string, defaultValue ...string) string {
 // Queries()["filters[customer][name]"] == "Alice"
 // Queries()["filters[status]"] == "pending"
 func (r *DefaultReq) Queries() map[string]string {
-	app := r.c.app
 	queryArgs := r.c.fasthttp.QueryArgs()
 
 	m := make(map[string]string, queryArgs.Len())
 	for key, value := range queryArgs.All() {
-		m[app.toString(key)] = app.toString(value)
+		m[r.c.toString(key)] = r.c.toString(value)
 	}
 	return m
 }
@@ -995,7 +1051,7 @@ func (r *DefaultReq) Queries() map[string]string {
 //	age := Query[int](c, "age") // Returns 8
 //	unknown := Query[string](c, "unknown", "default") // Returns "default" since the query parameter "unknown" is not found
 func Query[V GenericType](c Ctx, key string, defaultValue ...V) V {
-	q := c.App().toString(c.RequestCtx().QueryArgs().Peek(key))
+	q := requestString(c, c.RequestCtx().QueryArgs().Peek(key))
 	v, err := genericParseType[V](q)
 	if err != nil && len(defaultValue) > 0 {
 		return defaultValue[0]
@@ -1239,5 +1295,6 @@ func (r *DefaultReq) IsFromUnixSocket() bool {
 }
 
 func (r *DefaultReq) getBody() []byte {
-	return r.c.app.GetBytes(r.c.fasthttp.Request.Body())
+	r.c.readBodyStream()
+	return r.c.getBytes(r.c.fasthttp.Request.Body())
 }
@@ -4,6 +4,7 @@ package fiber
 
 import (
 	"mime/multipart"
+	"time"
 
 	"github.com/valyala/fasthttp"
 )
@@ -71,6 +72,16 @@ type Req interface {
 	// reload request, this module will return false to make handling these requests transparent.
 	// https://github.com/jshttp/fresh/blob/master/index.js#L33
 	Fresh() bool
+	// CheckPreconditions evaluates the If-Match, If-Unmodified-Since,
+	// If-None-Match and If-Modified-Since request headers against the current
+	// validators of the target resource, in the order of RFC 9110 §13.2.2.
+	// Pass an empty etag when the resource doesn't exist and a zero lastModified
+	// when it's unknown.
+	// It returns StatusOK when the request can proceed, StatusNotModified for GET
+	// and HEAD requests whose cached representation is still valid, with the
+	// validators set as the ETag and Last-Modified response headers, and
+	// StatusPreconditionFailed otherwise.
+	CheckPreconditions(etag string, lastModified time.Time) int
 	// Get returns the HTTP request header specified by field.
 	// Field names are case-insensitive
 	// Returned value is only valid within the handler. Do not store any references.
@@ -135,6 +146,12 @@ type Req interface {
 	// MultipartForm parse form entries from binary.
 	// This returns a map[string][]string, so given a key, the value will be a string slice.
 	MultipartForm() (*multipart.Form, error)
+	// MultipartReader returns a reader streaming the parts of the
+	// multipart/form-data request body. With Config.StreamRequestBody, the parts
+	// are read from the connection as they are requested; set
+	// Config.DisablePreParseMultipartForm too, or fasthttp reads the whole form
+	// before the handler runs. The body can only be read once.
+	MultipartReader(config ...MultipartConfig) (*MultipartReader, error)
 	// OriginalURL contains the original request URL.
 	// Returned value is only valid within the handler. Do not store any references.
 	// Make copies or use the Immutable setting to use the value outside the Handler.
@@ -2,6 +2,8 @@ package fiber
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -141,7 +143,7 @@ func (r *DefaultRes) Append(field string, values ...string) {
 	if len(values) == 0 {
 		return
 	}
-	h := r.c.app.toString(r.c.fasthttp.Response.Header.Peek(field))
+	h := r.c.toString(r.c.fasthttp.Response.Header.Peek(field))
 	originalH := h
 	for _, value := range values {
 		if h == "" {
@@ -425,15 +427,48 @@ func (r *DefaultRes) Format(handlers ...ResFmt) error {
 	return fmt.Errorf("%w: format: an Accept was found but no handler was called", errUnreachable)
 }
 
+// validationErrorsBody is the body AutoFormat renders ValidationErrors as.
+type validationErrorsBody struct {
+	Errors ValidationErrors `json:"errors" msgpack:"errors" cbor:"errors"`
+}
+
 // AutoFormat performs content-negotiation on the Accept HTTP header.
 // It uses Accepts to select a proper format.
 // The supported content types are text/html, text/plain, application/json, application/xml, application/vnd.msgpack, and application/cbor.
+// The media types of codecs registered with App.RegisterCodec are supported too, after the ones above.
 // When text/html is selected, the body is treated as plain text and HTML-escaped before being wrapped in a `<p>` element.
+// ValidationErrors, or errors wrapping them, are rendered as a standard error body listing the rejected fields.
 // For more flexible content negotiation, use Format.
 // If the header is not specified or there is no proper format, text/plain is used.
 func (r *DefaultRes) AutoFormat(body any) error {
 	// Get accepted content type
-	accept := r.c.DefaultReq.Accepts("html", "json", "txt", "xml", "msgpack", "cbor") //nolint:staticcheck // It is fine to ignore the static check
+	accept := r.c.DefaultReq.Accepts(r.c.app.autoFormatTypes...) //nolint:staticcheck // It is fine to ignore the static check
+	codecType := autoFormatCodecType(accept)
+
+	if err, ok := body.(error); ok {
+		var verrs ValidationErrors
+		if errors.As(err, &verrs) {
+			switch {
+			case accept == "xml":
+				body = verrs
+			case codecType != "":
+				body = validationErrorsBody{Errors: verrs}
+			default:
+				body = verrs.Error()
+			}
+		}
+	}
+
+	// Registered codecs replace the built-in encoders
+	if rc, ok := r.c.app.codecs[codecType]; ok && !rc.builtin {
+		raw, err := rc.codec.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r.c.fasthttp.Response.SetBodyRaw(raw)
+		r.c.fasthttp.Response.Header.SetContentType(codecType)
+		return nil
+	}
 
 	// Set accepted content type
 	r.Type(accept)
@@ -443,7 +478,7 @@ func (r *DefaultRes) AutoFormat(body any) error {
 	case string:
 		b = val
 	case []byte:
-		b = r.c.app.toString(val)
+		b = r.c.toString(val)
 	default:
 		b = fmt.Sprintf("%v", val)
 	}
@@ -468,25 +503,43 @@ func (r *DefaultRes) AutoFormat(body any) error {
 	return r.SendString(b)
 }
 
+// autoFormatCodecType returns the media type of the codec encoding the type
+// negotiated by AutoFormat, empty for text.
+func autoFormatCodecType(accept string) string {
+	switch accept {
+	case "json":
+		return MIMEApplicationJSON
+	case "xml":
+		return MIMEApplicationXML
+	case "msgpack":
+		return MIMEApplicationMsgPack
+	case "cbor":
+		return MIMEApplicationCBOR
+	case "", "html", "txt":
+		return ""
+	default:
+		return accept
+	}
+}
+
 // Get (a.k.a. GetRespHeader) returns the HTTP response header specified by field.
 // Field names are case-insensitive
 // Returned value is only valid within the handler. Do not store any references.
 // Make copies or use the Immutable setting instead.
 func (r *DefaultRes) Get(key string, defaultValue ...string) string {
-	return defaultString(r.c.app.toString(r.c.fasthttp.Response.Header.Peek(key)), defaultValue)
+	return defaultString(r.c.toString(r.c.fasthttp.Response.Header.Peek(key)), defaultValue)
 }
 
 // GetHeaders (a.k.a GetRespHeaders) returns the HTTP response headers.
 // Returned value is only valid within the handler. Do not store any references.
 // Make copies or use the Immutable setting instead.
 func (r *DefaultRes) GetHeaders() map[string][]string {
-	app := r.c.app
 	respHeader := &r.c.fasthttp.Response.Header
 	// Pre-allocate map with known header count to avoid reallocations
 	headers := make(map[string][]string, respHeader.Len())
 	for k, v := range respHeader.All() {
-		key := app.toString(k)
-		headers[key] = append(headers[key], app.toString(v))
+		key := r.c.toString(k)
+		headers[key] = append(headers[key], r.c.toString(v))
 	}
 	return headers
 }
@@ -615,7 +668,7 @@ func (r *DefaultRes) Links(link ...string) {
 			bb.WriteString(`",`)
 		}
 	}
-	r.setCanonical(HeaderLink, utils.TrimRight(r.c.app.toString(bb.Bytes()), ','))
+	r.setCanonical(HeaderLink, utils.TrimRight(r.c.toString(bb.Bytes()), ','))
 	bytebufferpool.Put(bb)
 }
 
@@ -743,6 +796,8 @@ func (r *DefaultRes) renderExtensions(bind any) {
 
 // Send sets the HTTP response body without copying it.
 // From this point onward the body argument must not be changed.
+// Ranges of the body are served when the response announces
+// Accept-Ranges: bytes.
 func (r *DefaultRes) Send(body []byte) error {
 	// Write response body
 	r.c.fasthttp.Response.SetBodyRaw(body)
@@ -766,6 +821,10 @@ func (r *DefaultRes) SendEarlyHints(hints []string) error {
 	for _, h := range hints {
 		r.c.fasthttp.Response.Header.Add("Link", h)
 	}
+	if conn, ok := r.c.fasthttp.Conn().(interface{ earlyHints(links []string) error }); ok {
+		// served by net/http, see ListenConfig.EnableHTTP2
+		return conn.earlyHints(hints)
+	}
 	return r.c.fasthttp.EarlyHints()
 }
 
@@ -981,23 +1040,82 @@ func (r *DefaultRes) SendString(body string) error {
 }
 
 // SendStream sets response body stream and optional body size.
+// For an io.ReadSeeker and a GET request with a Range header, the size
+// defaults to the remaining bytes of the stream, and ranges are served from
+// it when the response announces Accept-Ranges: bytes.
 func (r *DefaultRes) SendStream(stream io.Reader, size ...int) error {
+	bodySize := -1
 	if len(size) > 0 && size[0] >= 0 {
-		r.c.fasthttp.Response.SetBodyStream(stream, size[0])
-	} else {
-		r.c.fasthttp.Response.SetBodyStream(stream, -1)
+		bodySize = size[0]
+	}
+	// other streams are passed on as is, fasthttp sends an *os.File with
+	// sendfile
+	var ranges *rangeStream
+	if seeker, ok := stream.(io.ReadSeeker); ok && wantsRanges(&r.c.fasthttp.Request) {
+		if ranges = newRangeStream(seeker, bodySize); ranges != nil {
+			stream = ranges
+			bodySize = int(ranges.size)
+		}
+	}
+	body := wrapBodyStream(r.c.streamWrappers, stream, bodySize)
+	if wrapped, ok := body.(io.ReadCloser); ok && ranges != nil && body != stream {
+		body = &wrappedRangeStream{ReadCloser: wrapped, ranges: ranges}
 	}
+	r.c.fasthttp.Response.SetBodyStream(body, bodySize)
 
 	return nil
 }
 
 // SendStreamWriter sets response body stream writer
 func (r *DefaultRes) SendStreamWriter(streamWriter func(*bufio.Writer)) error {
-	r.c.fasthttp.Response.SetBodyStreamWriter(fasthttp.StreamWriter(streamWriter))
+	stream := fasthttp.NewStreamReader(streamWriter)
+	r.c.fasthttp.Response.SetBodyStream(wrapBodyStream(r.c.streamWrappers, stream, -1), -1)
 
 	return nil
 }
 
+// SendStreamFunc sets a response body stream writer like SendStreamWriter.
+// The StreamWriter passed to fn reports when the client disconnects and sets
+// the values of the trailers declared with Trailer once the body is written.
+func (r *DefaultRes) SendStreamFunc(fn func(w *StreamWriter)) error {
+	header := &r.c.fasthttp.Response.Header
+	// the handler returns before the body is streamed
+	ctx, cancel := context.WithCancelCause(context.WithoutCancel(r.c.Context()))
+	w := &StreamWriter{ctx: ctx, cancel: cancel, disconnect: r.c.cancelDisconnect}
+	for trailer := range header.Trailers() {
+		w.declared = append(w.declared, string(trailer))
+	}
+	stop := func() bool { return false }
+	if disconnect := r.c.disconnect; disconnect != nil {
+		stop = context.AfterFunc(disconnect, func() {
+			cancel(context.Cause(disconnect))
+		})
+	}
+	stream := fasthttp.NewStreamReader(func(bw *bufio.Writer) {
+		defer cancel(nil)
+		defer stop()
+		w.w = bw
+		fn(w)
+	})
+	body := &streamBody{ReadCloser: stream, w: w, header: header}
+	r.c.fasthttp.Response.SetBodyStream(wrapBodyStream(r.c.streamWrappers, body, -1), -1)
+
+	return nil
+}
+
+// WrapBodyStream registers wrap for the body streams set later in the
+// request with SendStream, SendStreamWriter or SendStreamFunc, so that a
+// middleware can observe streamed bodies before calling c.Next(). wrap gets
+// the stream and its size, -1 if unknown, and returns the stream to send,
+// or nil to leave it as is. The stream is closed once it's sent,
+// even if the returned reader isn't an io.Closer. When ranges of a
+// SendStream body are served, wrap reads the selected ranges, so it must
+// not change the length of the stream. Streams set on the fasthttp
+// response directly aren't wrapped.
+func (r *DefaultRes) WrapBodyStream(wrap func(stream io.Reader, size int) io.Reader) {
+	r.c.streamWrappers = append(r.c.streamWrappers, wrap)
+}
+
 // Set sets the response's HTTP header field to the specified key, value.
 func (r *DefaultRes) Set(key, val string) {
 	r.c.fasthttp.Response.Header.Set(key, val)
@@ -1007,6 +1125,34 @@ func (r *DefaultRes) setCanonical(key, val string) {
 	r.c.fasthttp.Response.Header.SetCanonical(utils.UnsafeBytes(key), utils.UnsafeBytes(val))
 }
 
+// Trailer declares the given header fields as trailers of the response.
+// Trailers are only sent with chunked bodies, i.e. with SendStreamWriter,
+// SendStreamFunc or SendStream without a size. It returns ErrBadTrailer for
+// fields that aren't allowed in trailers, such as Content-Length.
+func (r *DefaultRes) Trailer(keys ...string) error {
+	header := &r.c.fasthttp.Response.Header
+	for _, key := range keys {
+		if trailerDeclared(header, key) {
+			continue
+		}
+		if key == "" || strings.Contains(key, ",") || header.AddTrailer(key) != nil {
+			return fmt.Errorf("%w: %q", ErrBadTrailer, key)
+		}
+	}
+	return nil
+}
+
+// SetTrailer declares the header field as a trailer of the response, like
+// Trailer, and sets its value. Values computed while the body is streamed
+// are set with StreamWriter.SetTrailer.
+func (r *DefaultRes) SetTrailer(key, val string) error {
+	if err := r.Trailer(key); err != nil {
+		return err
+	}
+	r.c.fasthttp.Response.Header.Set(key, val)
+	return nil
+}
+
 // Status sets the HTTP status for the response.
 // This method is chainable.
 func (r *DefaultRes) Status(status int) Ctx {
@@ -45,7 +45,9 @@ type Res interface {
 	// AutoFormat performs content-negotiation on the Accept HTTP header.
 	// It uses Accepts to select a proper format.
 	// The supported content types are text/html, text/plain, application/json, application/xml, application/vnd.msgpack, and application/cbor.
+	// The media types of codecs registered with App.RegisterCodec are supported too, after the ones above.
 	// When text/html is selected, the body is treated as plain text and HTML-escaped before being wrapped in a `<p>` element.
+	// ValidationErrors, or errors wrapping them, are rendered as a standard error body listing the rejected fields.
 	// For more flexible content negotiation, use Format.
 	// If the header is not specified or there is no proper format, text/plain is used.
 	AutoFormat(body any) error
@@ -110,6 +112,8 @@ type Res interface {
 	renderExtensions(bind any)
 	// Send sets the HTTP response body without copying it.
 	// From this point onward the body argument must not be changed.
+	// Ranges of the body are served when the response announces
+	// Accept-Ranges: bytes.
 	Send(body []byte) error
 	// SendEarlyHints allows the server to hint to the browser what resources a page would need
 	// so the browser can preload them while waiting for the server's full response. Only Link
@@ -134,12 +138,38 @@ type Res interface {
 	// This means no type assertion, recommended for faster performance
 	SendString(body string) error
 	// SendStream sets response body stream and optional body size.
+	// For an io.ReadSeeker, the size defaults to the remaining bytes of the
+	// stream, and ranges are served from it when the response announces
+	// Accept-Ranges: bytes.
 	SendStream(stream io.Reader, size ...int) error
 	// SendStreamWriter sets response body stream writer
 	SendStreamWriter(streamWriter func(*bufio.Writer)) error
+	// SendStreamFunc sets a response body stream writer like SendStreamWriter.
+	// The StreamWriter passed to fn reports when the client disconnects and sets
+	// the values of the trailers declared with Trailer once the body is written.
+	SendStreamFunc(fn func(w *StreamWriter)) error
+	// WrapBodyStream registers wrap for the body streams set later in the
+	// request with SendStream, SendStreamWriter or SendStreamFunc, so that a
+	// middleware can observe streamed bodies before calling c.Next(). wrap gets
+	// the stream and its size, -1 if unknown, and returns the stream to send,
+	// or nil to leave it as is. The stream is closed once it's sent,
+	// even if the returned reader isn't an io.Closer. When ranges of a
+	// SendStream body are served, wrap reads the selected ranges, so it must
+	// not change the length of the stream. Streams set on the fasthttp
+	// response directly aren't wrapped.
+	WrapBodyStream(wrap func(stream io.Reader, size int) io.Reader)
 	// Set sets the response's HTTP header field to the specified key, value.
 	Set(key, val string)
 	setCanonical(key, val string)
+	// Trailer declares the given header fields as trailers of the response.
+	// Trailers are only sent with chunked bodies, i.e. with SendStreamWriter,
+	// SendStreamFunc or SendStream without a size. It returns ErrBadTrailer for
+	// fields that aren't allowed in trailers, such as Content-Length.
+	Trailer(keys ...string) error
+	// SetTrailer declares the header field as a trailer of the response, like
+	// Trailer, and sets its value. Values computed while the body is streamed
+	// are set with StreamWriter.SetTrailer.
+	SetTrailer(key, val string) error
 	// Status sets the HTTP status for the response.
 	// This method is chainable.
 	Status(status int) Ctx
@@ -0,0 +1,506 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package fiber
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/valyala/fasthttp/prefork"
+
+	"github.com/gofiber/fiber/v3/log"
+)
+
+const (
+	// restartListenersEnv lists the keys of the listeners passed to the new
+	// process of a graceful restart, as a JSON array.
+	restartListenersEnv = "FIBER_RESTART_LISTENERS"
+	// restartReadyFd is the pipe the new process closes once it listens. The
+	// listeners are passed as the following files.
+	restartReadyFd = 3
+	// preforkDrainFd is the pipe the prefork master closes to drain its
+	// children, which inherit the listener as fd 3.
+	preforkDrainFd = 4
+	// preforkChildEnv marks the prefork children, see prefork.IsChild.
+	preforkChildEnv = "FASTHTTP_PREFORK_CHILD=1"
+)
+
+// errRestarted ends the prefork master of a gracefully restarted app.
+var errRestarted = errors.New("restart: the app was restarted")
+
+// restartCommand returns the command of the processes started by a graceful
+// restart and the prefork masters, the current executable with the same
+// arguments. Tests replace it.
+var restartCommand = func() (*exec.Cmd, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("restart: cannot find the executable: %w", err)
+	}
+	cmd := exec.Command(executable, os.Args[1:]...) //nolint:gosec // the current executable is started again
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd, nil
+}
+
+// restarts coordinates the graceful restarts of the process, so that the
+// listeners of all the apps are passed to a single new process.
+var restarts = &restarter{}
+
+// restarter passes the listeners of the apps with EnableGracefulRestart to a
+// new process on SIGHUP and SIGUSR2, and drains the apps once it listens.
+type restarter struct {
+	inherited map[string]net.Listener // listeners passed by the previous process
+	pending   map[string]*App         // inherited listeners not served yet, by app
+	ready     *os.File                // closed once the inherited listeners are served
+	signals   chan os.Signal
+	keys      []string // keys of the inherited listeners, in the order they were passed
+	listeners []*restartListener
+	mu        sync.Mutex
+	once      sync.Once
+}
+
+// restartListener is a listener passed to the new process.
+type restartListener struct {
+	ln    net.Listener
+	app   *App
+	drain func()
+	key   string
+	cfg   *ListenConfig
+}
+
+// load reads the listeners passed by the previous process, once.
+func (r *restarter) load() {
+	r.once.Do(func() {
+		keys := os.Getenv(restartListenersEnv)
+		if keys == "" {
+			return
+		}
+		// the prefork children and later restarts don't inherit them
+		_ = os.Unsetenv(restartListenersEnv) //nolint:errcheck // the variable is valid
+
+		var names []string
+		if err := json.Unmarshal([]byte(keys), &names); err != nil {
+			log.Errorf("restart: invalid %s: %v", restartListenersEnv, err)
+			return
+		}
+		r.ready = os.NewFile(restartReadyFd, "restart-ready")
+		r.inherited = make(map[string]net.Listener, len(names))
+		r.pending = make(map[string]*App, len(names))
+		for i, key := range names {
+			file := os.NewFile(uintptr(restartReadyFd+1+i), key)
+			ln, err := net.FileListener(file)
+			if closeErr := file.Close(); closeErr != nil {
+				log.Errorf("restart: failed to close inherited file: %v", closeErr)
+			}
+			if err != nil {
+				log.Errorf("restart: cannot inherit listener %q: %v", key, err)
+				continue
+			}
+			r.inherited[key] = ln
+			r.pending[key] = nil
+			r.keys = append(r.keys, key)
+		}
+	})
+}
+
+// restarted reports whether the process was started by a graceful restart.
+func (r *restarter) restarted() bool {
+	r.load()
+	return r.inherited != nil
+}
+
+// listener returns the listener for network and addr passed by the previous
+// process to be served by app, or nil.
+func (r *restarter) listener(app *App, network, addr string) net.Listener {
+	key := restartKey(network, addr)
+	lns, _ := r.inheritedListeners(app, func(k string) bool { return k == key })
+	if len(lns) == 0 {
+		return nil
+	}
+	return lns[0]
+}
+
+// inheritedListeners returns the listeners passed by the previous process
+// whose keys match, in the order they were passed, to be served by app. It
+// also returns their keys.
+func (r *restarter) inheritedListeners(app *App, match func(key string) bool) ([]net.Listener, []string) {
+	r.load()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var (
+		lns  []net.Listener
+		keys []string
+	)
+	for _, key := range r.keys {
+		ln, ok := r.inherited[key]
+		if !ok || !match(key) {
+			continue
+		}
+		delete(r.inherited, key)
+		if _, ok := r.pending[key]; ok {
+			r.pending[key] = app
+		}
+		lns = append(lns, ln)
+		keys = append(keys, key)
+	}
+	return lns, keys
+}
+
+// listening notifies the previous process once all the listeners it passed
+// are served, which is after the OnListen hooks of their apps.
+func (r *restarter) listening(app *App) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ready == nil {
+		return
+	}
+	for key, served := range r.pending {
+		if served == app {
+			delete(r.pending, key)
+		}
+	}
+	if len(r.pending) > 0 {
+		return
+	}
+	if _, err := r.ready.Write([]byte{1}); err != nil {
+		log.Errorf("restart: failed to notify the previous process: %v", err)
+	}
+	if err := r.ready.Close(); err != nil {
+		log.Errorf("restart: failed to close the readiness pipe: %v", err)
+	}
+	r.ready = nil
+}
+
+// watch passes ln, served by app, to the new process of the graceful
+// restarts until unwatch is called. drain shuts app down once it listens.
+func (r *restarter) watch(app *App, ln net.Listener, key string, cfg *ListenConfig, drain func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, &restartListener{ln: ln, app: app, drain: drain, key: key, cfg: cfg})
+	if r.signals == nil {
+		r.signals = make(chan os.Signal, 1)
+		signal.Notify(r.signals, syscall.SIGHUP, syscall.SIGUSR2)
+		go r.run(r.signals)
+	}
+}
+
+// unwatch stops passing the listeners of app.
+func (r *restarter) unwatch(app *App) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remove(func(l *restartListener) bool { return l.app == app })
+}
+
+// remove removes the listeners matching del and stops handling the signals
+// once there are none left. r.mu must be held.
+func (r *restarter) remove(del func(*restartListener) bool) {
+	r.listeners = slices.DeleteFunc(r.listeners, del)
+	if len(r.listeners) == 0 && r.signals != nil {
+		signal.Stop(r.signals)
+		close(r.signals)
+		r.signals = nil
+	}
+}
+
+func (r *restarter) run(signals <-chan os.Signal) {
+	for range signals {
+		r.restart()
+	}
+}
+
+// restart starts the new process and drains the apps once it listens. The
+// apps keep serving if the restart fails.
+func (r *restarter) restart() {
+	r.mu.Lock()
+	listeners := slices.Clone(r.listeners)
+	r.mu.Unlock()
+	if len(listeners) == 0 {
+		return
+	}
+
+	var apps []*App
+	for _, l := range listeners {
+		if !slices.Contains(apps, l.app) {
+			apps = append(apps, l.app)
+		}
+	}
+	for _, app := range apps {
+		if err := app.hooks.executeOnPreRestartHooks(); err != nil {
+			log.Errorf("restart: aborted by pre restart hook: %v", err)
+			return
+		}
+	}
+
+	pid, err := startRestartProcess(listeners)
+	for _, app := range apps {
+		app.hooks.executeOnPostRestartHooks(pid, err)
+	}
+	if err != nil {
+		log.Errorf("%v", err)
+		return
+	}
+
+	// the listeners are served by the new process now
+	r.mu.Lock()
+	r.remove(func(l *restartListener) bool { return slices.Contains(listeners, l) })
+	r.mu.Unlock()
+	for _, l := range listeners {
+		if ul, ok := l.ln.(*net.UnixListener); ok {
+			// keep the socket file of the new process
+			ul.SetUnlinkOnClose(false)
+		}
+		go l.drain()
+	}
+}
+
+// startRestartProcess starts the new process with the listeners and waits for
+// it to listen. It returns the PID of the new process.
+func startRestartProcess(listeners []*restartListener) (int, error) {
+	cmd, err := restartCommand()
+	if err != nil {
+		return 0, err
+	}
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("restart: cannot create the readiness pipe: %w", err)
+	}
+	defer readyR.Close() //nolint:errcheck // the pipe is only read
+
+	files := []*os.File{readyW}
+	closeFiles := func() {
+		for _, file := range files {
+			if err := file.Close(); err != nil {
+				log.Errorf("restart: failed to close file: %v", err)
+			}
+		}
+		files = nil
+	}
+	defer closeFiles()
+
+	keys := make([]string, 0, len(listeners))
+	timeout := time.Duration(0)
+	for _, l := range listeners {
+		file, err := listenerFile(l.ln)
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, file)
+		keys = append(keys, l.key)
+		timeout = max(timeout, l.cfg.RestartTimeout)
+	}
+	env, err := json.Marshal(keys)
+	if err != nil {
+		return 0, fmt.Errorf("restart: cannot encode the listeners: %w", err)
+	}
+
+	cmd.Env = append(cmd.Environ(), restartListenersEnv+"="+string(env))
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("restart: cannot start the new process: %w", err)
+	}
+	// the new process holds the only write end of the pipe now, so reading
+	// fails as soon as it exits
+	closeFiles()
+
+	if err := readyR.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		log.Errorf("restart: failed to set the readiness timeout: %v", err)
+	}
+	if _, err := readyR.Read(make([]byte, 1)); err != nil {
+		_ = cmd.Process.Kill() //nolint:errcheck // the process may have exited
+		_ = cmd.Wait()         //nolint:errcheck // the restart failed
+		return cmd.Process.Pid, fmt.Errorf("restart: the new process didn't listen: %w", err)
+	}
+	// reap the new process if it exits before this one
+	go func() {
+		_ = cmd.Wait() //nolint:errcheck // the new process is independent
+	}()
+	return cmd.Process.Pid, nil
+}
+
+// listenerFile returns a duplicate of the file of ln. Unlike the one of its
+// File method, it stays in nonblocking mode when passed to a process, which
+// would block the accept calls of ln, as the duplicates share the mode.
+func listenerFile(ln net.Listener) (*os.File, error) {
+	conn, ok := ln.(syscall.Conn)
+	if !ok {
+		return nil, fmt.Errorf("restart: cannot pass listeners of type %T", ln)
+	}
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return nil, fmt.Errorf("restart: cannot pass listener %s: %w", ln.Addr(), err)
+	}
+	var fd int
+	var dupErr error
+	err = raw.Control(func(sysfd uintptr) {
+		syscall.ForkLock.RLock()
+		defer syscall.ForkLock.RUnlock()
+		if fd, dupErr = syscall.Dup(int(sysfd)); dupErr == nil {
+			syscall.CloseOnExec(fd)
+		}
+	})
+	if err == nil {
+		err = dupErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("restart: cannot pass listener %s: %w", ln.Addr(), err)
+	}
+	return os.NewFile(uintptr(fd), ln.Addr().String()), nil
+}
+
+// watchRestart makes ln, identified by key, restart gracefully.
+func (app *App) watchRestart(ln net.Listener, key string, cfg *ListenConfig) {
+	restarts.watch(app, ln, key, cfg, func() {
+		app.drain(cfg)
+	})
+}
+
+// drain shuts the app down after a graceful restart.
+func (app *App) drain(cfg *ListenConfig) {
+	// systemd is notified by the new process
+	app.systemdNotify.Store(false)
+
+	var err error
+	if cfg.ShutdownTimeout != 0 {
+		err = app.ShutdownWithTimeout(cfg.ShutdownTimeout) //nolint:contextcheck // the restart runs in the background
+	} else {
+		err = app.Shutdown() //nolint:contextcheck // the restart runs in the background
+	}
+	if err != nil {
+		log.Errorf("restart: failed to shut down: %v", err)
+	}
+}
+
+// preforkShare makes the prefork master own the listener of addr, or the
+// socket of systemd socket activation, so that it's shared by the children
+// and passed to the new process of a graceful restart. The master drains the
+// children over a pipe, which also drains them if the master dies. It returns
+// a function releasing the listener.
+func (app *App) preforkShare(p *prefork.Prefork, addr string, cfg *ListenConfig) (func(), error) {
+	if IsChild() {
+		// the signals sent to the process group are handled by the master
+		signal.Ignore(syscall.SIGHUP, syscall.SIGUSR2)
+		// serve the listener of the master, passed as fd 3
+		p.Reuseport = false
+		p.OnMasterDeath = nil
+		go func() {
+			drain := os.NewFile(preforkDrainFd, "prefork-drain")
+			_, _ = io.Copy(io.Discard, drain) //nolint:errcheck // returns once the master drains or dies
+			app.drain(cfg)
+		}()
+		return func() {}, nil
+	}
+
+	ln, key, err := app.preforkListener(addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	file, err := listenerFile(ln)
+	if err != nil {
+		_ = ln.Close() //nolint:errcheck // the listener isn't served
+		return nil, err
+	}
+	drainR, drainW, err := os.Pipe()
+	if err != nil {
+		_ = ln.Close()   //nolint:errcheck // the listener isn't served
+		_ = file.Close() //nolint:errcheck // the listener isn't served
+		return nil, fmt.Errorf("restart: cannot create the drain pipe: %w", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		children []int
+		draining atomic.Bool
+	)
+	// the master doesn't listen itself, it passes ln to the children
+	p.Reuseport = true
+	p.CommandProducer = func([]*os.File) (*exec.Cmd, error) {
+		if draining.Load() {
+			// a child exited, wait for the others before ending the master
+			mu.Lock()
+			defer mu.Unlock()
+			waitProcesses(children, cfg.ShutdownTimeout)
+			return nil, errRestarted
+		}
+		cmd, err := restartCommand()
+		if err != nil {
+			return nil, err
+		}
+		cmd.Env = append(cmd.Environ(), preforkChildEnv)
+		cmd.ExtraFiles = []*os.File{file, drainR}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("prefork: failed to start child: %w", err)
+		}
+		mu.Lock()
+		children = append(children, cmd.Process.Pid)
+		mu.Unlock()
+		return cmd, nil
+	}
+
+	if cfg.EnableGracefulRestart {
+		restarts.watch(app, ln, key, cfg, func() {
+			draining.Store(true)
+			if err := drainW.Close(); err != nil {
+				log.Errorf("restart: failed to drain the prefork children: %v", err)
+			}
+		})
+	}
+
+	return func() {
+		for _, closer := range []io.Closer{ln, file, drainR, drainW} {
+			_ = closer.Close() //nolint:errcheck // the pipe may be closed by the restart
+		}
+	}, nil
+}
+
+// preforkListener returns the listener the prefork master shares and its
+// restart key.
+func (app *App) preforkListener(addr string, cfg *ListenConfig) (net.Listener, string, error) {
+	if cfg.EnableSocketActivation {
+		lns, keys, err := app.activatedListeners(cfg)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to listen: %w", err)
+		}
+		if len(lns) > 1 {
+			closeListeners(lns)
+			return nil, "", ErrPreforkWithSockets
+		}
+		return lns[0], keys[0], nil
+	}
+
+	key := restartKey(cfg.ListenerNetwork, addr)
+	if cfg.EnableGracefulRestart {
+		if ln := restarts.listener(app, cfg.ListenerNetwork, addr); ln != nil {
+			return ln, key, nil
+		}
+	}
+	ln, err := net.Listen(cfg.ListenerNetwork, addr)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to listen: %w", err)
+	}
+	return ln, key, nil
+}
+
+// waitProcesses waits up to timeout, or indefinitely if it's 0, for the
+// processes with pids to exit.
+func waitProcesses(pids []int, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for _, pid := range pids {
+		for syscall.Kill(pid, 0) == nil {
+			if timeout != 0 && time.Now().After(deadline) {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
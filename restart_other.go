@@ -0,0 +1,46 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package fiber
+
+import (
+	"errors"
+	"net"
+
+	"github.com/valyala/fasthttp/prefork"
+
+	"github.com/gofiber/fiber/v3/log"
+)
+
+// errRestarted ends the prefork master of a gracefully restarted app.
+var errRestarted = errors.New("restart: the app was restarted")
+
+// restarts can't restart gracefully on this platform, as listeners can't be
+// passed to other processes.
+var restarts = restarter{}
+
+type restarter struct{}
+
+func (restarter) restarted() bool {
+	return false
+}
+
+func (restarter) listener(*App, string, string) net.Listener {
+	return nil
+}
+
+func (restarter) inheritedListeners(*App, func(string) bool) ([]net.Listener, []string) {
+	return nil, nil
+}
+
+func (restarter) listening(*App) {}
+
+func (restarter) unwatch(*App) {}
+
+func (*App) watchRestart(net.Listener, string, *ListenConfig) {
+	log.Warn("Graceful restart isn't supported on this platform.")
+}
+
+func (*App) preforkShare(*prefork.Prefork, string, *ListenConfig) (func(), error) {
+	log.Warn("Graceful restart and socket activation aren't supported with prefork on this platform.")
+	return func() {}, nil
+}
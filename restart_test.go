@@ -0,0 +1,340 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package fiber
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp/prefork"
+)
+
+const (
+	testRestartNetworkEnv = "FIBER_TEST_RESTART_NETWORK"
+	testRestartAddrEnv    = "FIBER_TEST_RESTART_ADDR"
+	testRestartFailEnv    = "FIBER_TEST_RESTART_FAIL"
+)
+
+// setRestartCommand makes the graceful restarts run Test_Listen_GracefulRestart
+// of the test binary as the new process, with env.
+func setRestartCommand(t *testing.T, env ...string) {
+	t.Helper()
+	original := restartCommand
+	restartCommand = func() (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^Test_Listen_GracefulRestart$") //nolint:gosec // the test binary is started again
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stderr = os.Stderr
+		return cmd, nil
+	}
+	t.Cleanup(func() {
+		restartCommand = original
+	})
+}
+
+// waitExit waits for the process with pid, started by a restart, to exit.
+func waitExit(t *testing.T, pid int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return syscall.Kill(pid, 0) != nil
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+// go test -run Test_Listen_GracefulRestart
+func Test_Listen_GracefulRestart(t *testing.T) {
+	if network := os.Getenv(testRestartNetworkEnv); network != "" {
+		// the new process, serving until SIGTERM
+		app := New()
+		app.Get("/", func(c Ctx) error {
+			return c.SendString("new")
+		})
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+		defer stop()
+		cfg := ListenConfig{
+			ListenerNetwork:       network,
+			EnableGracefulRestart: true,
+			DisableStartupMessage: true,
+			GracefulContext:       ctx,
+		}
+		if os.Getenv(testRestartFailEnv) != "" {
+			// the new process fails before serving
+			cfg.BeforeServeFunc = func(*App) error {
+				return errors.New("not ready")
+			}
+			require.Error(t, app.Listen(os.Getenv(testRestartAddrEnv), cfg))
+			return
+		}
+		require.NoError(t, app.Listen(os.Getenv(testRestartAddrEnv), cfg))
+		return
+	}
+
+	for _, tc := range []struct {
+		network string
+		addr    string
+	}{
+		{network: NetworkTCP4, addr: "127.0.0.1:0"},
+		{network: NetworkUnix, addr: filepath.Join(t.TempDir(), "fiber.sock")},
+	} {
+		t.Run(tc.network, func(t *testing.T) {
+			setRestartCommand(t, testRestartNetworkEnv+"="+tc.network, testRestartAddrEnv+"="+tc.addr)
+
+			hooks := make(chan string, 3)
+			pids := make(chan int, 1)
+			app := New()
+			app.Get("/", func(c Ctx) error {
+				return c.SendString("old")
+			})
+			app.Hooks().OnPreRestart(func() error {
+				hooks <- "pre-restart"
+				return nil
+			})
+			app.Hooks().OnPostRestart(func(pid int, err error) error {
+				hooks <- "post-restart"
+				if err == nil {
+					pids <- pid
+				}
+				return nil
+			})
+			app.Hooks().OnPostShutdown(func(error) error {
+				hooks <- "post-shutdown"
+				return nil
+			})
+
+			addrs := make(chan net.Addr, 1)
+			served := make(chan error, 1)
+			go func() {
+				served <- app.Listen(tc.addr, ListenConfig{
+					ListenerNetwork:       tc.network,
+					EnableGracefulRestart: true,
+					DisableStartupMessage: true,
+					ListenerAddrFunc: func(addr net.Addr) {
+						addrs <- addr
+					},
+				})
+			}()
+			addr := <-addrs
+
+			// send requests until the new process served all the clients
+			var (
+				failures  atomic.Int64
+				mu        sync.Mutex
+				responses = map[string]int{}
+				wg        sync.WaitGroup
+				stop      atomic.Bool
+			)
+			for range 8 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					client := &http.Client{
+						Timeout: 5 * time.Second,
+						Transport: &http.Transport{
+							DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+								return (&net.Dialer{}).DialContext(ctx, addr.Network(), addr.String())
+							},
+						},
+					}
+					served := false
+					for !stop.Load() || !served {
+						resp, err := client.Get("http://fiber/")
+						if err != nil {
+							t.Logf("request failed: %v", err)
+							failures.Add(1)
+							continue
+						}
+						body, err := io.ReadAll(resp.Body)
+						if err != nil || resp.Body.Close() != nil || resp.StatusCode != StatusOK {
+							failures.Add(1)
+							continue
+						}
+						served = string(body) == "new"
+						mu.Lock()
+						responses[string(body)]++
+						mu.Unlock()
+					}
+				}()
+			}
+
+			time.Sleep(100 * time.Millisecond)
+			require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+			pid := <-pids
+			t.Cleanup(func() {
+				require.NoError(t, syscall.Kill(pid, syscall.SIGTERM))
+				waitExit(t, pid)
+			})
+			require.NoError(t, <-served)
+			stop.Store(true)
+			wg.Wait()
+
+			require.Zero(t, failures.Load())
+			require.Positive(t, responses["old"])
+			require.Positive(t, responses["new"])
+			for _, hook := range []string{"pre-restart", "post-restart", "post-shutdown"} {
+				require.Equal(t, hook, <-hooks)
+			}
+
+			if tc.network == NetworkUnix {
+				// the socket file is kept for the new process
+				require.FileExists(t, tc.addr)
+			}
+		})
+	}
+}
+
+// go test -run Test_Listen_GracefulRestart_Abort
+func Test_Listen_GracefulRestart_Abort(t *testing.T) {
+	// the new process exits without listening
+	original := restartCommand
+	restartCommand = func() (*exec.Cmd, error) {
+		return dummyCmd(), nil
+	}
+	t.Cleanup(func() {
+		restartCommand = original
+	})
+
+	app := New()
+	app.Get("/", func(c Ctx) error {
+		return c.SendString("old")
+	})
+	var preRestarts atomic.Int32
+	app.Hooks().OnPreRestart(func() error {
+		if preRestarts.Add(1) == 1 {
+			return errors.New("not now")
+		}
+		return nil
+	})
+	restarted := make(chan error, 1)
+	app.Hooks().OnPostRestart(func(pid int, err error) error {
+		require.Positive(t, pid)
+		restarted <- err
+		return nil
+	})
+	addr := listenAsync(t, app, ListenConfig{EnableGracefulRestart: true})
+
+	// aborted by the hook
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+	require.Eventually(t, func() bool {
+		return preRestarts.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Empty(t, restarted)
+
+	// failed
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	select {
+	case err := <-restarted:
+		require.ErrorContains(t, err, "restart: the new process didn't listen")
+	case <-time.After(10 * time.Second):
+		t.Fatal("the restart didn't end")
+	}
+
+	// failed before serving
+	setRestartCommand(t, testRestartNetworkEnv+"="+NetworkTCP4, testRestartAddrEnv+"=127.0.0.1:0", testRestartFailEnv+"=1")
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	select {
+	case err := <-restarted:
+		require.ErrorContains(t, err, "restart: the new process didn't listen")
+	case <-time.After(10 * time.Second):
+		t.Fatal("the restart didn't end")
+	}
+
+	// still served by this process
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + addr) //nolint:noctx // the request is local
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "old", string(body))
+}
+
+// go test -run Test_Prefork_GracefulRestart
+func Test_Prefork_GracefulRestart(t *testing.T) {
+	original := restartCommand
+	restartCommand = func() (*exec.Cmd, error) {
+		return dummyCmd(), nil
+	}
+	t.Cleanup(func() {
+		restartCommand = original
+	})
+
+	app := New()
+	cfg := listenConfigDefault(ListenConfig{EnableGracefulRestart: true})
+	p := &prefork.Prefork{}
+	release, err := app.preforkShare(p, "127.0.0.1:0", &cfg)
+	require.NoError(t, err)
+	defer release()
+	defer restarts.unwatch(app)
+
+	// the master listens and passes the listener to the children
+	require.True(t, p.Reuseport)
+	cmd, err := p.CommandProducer(nil)
+	require.NoError(t, err)
+	require.NoError(t, cmd.Wait())
+	require.Len(t, cmd.ExtraFiles, 2)
+	require.Contains(t, cmd.Env, preforkChildEnv)
+
+	restarts.mu.Lock()
+	i := slices.IndexFunc(restarts.listeners, func(l *restartListener) bool { return l.app == app })
+	require.NotEqual(t, -1, i)
+	l := restarts.listeners[i]
+	restarts.mu.Unlock()
+	require.Equal(t, "tcp4 127.0.0.1:0", l.key)
+
+	// once drained, the master ends after its children
+	l.drain()
+	_, err = p.CommandProducer(nil)
+	require.ErrorIs(t, err, errRestarted)
+}
+
+// go test -run Test_Restarter_Inherit
+func Test_Restarter_Inherit(t *testing.T) {
+	ln, err := net.Listen(NetworkTCP4, "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close() //nolint:errcheck // closed by the test
+	readyR, readyW, err := os.Pipe()
+	require.NoError(t, err)
+	defer readyR.Close() //nolint:errcheck // closed by the test
+
+	// the files of a previous process
+	file, err := listenerFile(ln)
+	require.NoError(t, err)
+	r := &restarter{}
+	r.once.Do(func() {})
+	inherited, err := net.FileListener(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	r.inherited = map[string]net.Listener{"tcp4 127.0.0.1:0": inherited}
+	r.pending = map[string]*App{"tcp4 127.0.0.1:0": nil}
+	r.keys = []string{"tcp4 127.0.0.1:0"}
+	r.ready = readyW
+
+	app, other := New(), New()
+	require.Nil(t, r.listener(app, NetworkTCP4, "127.0.0.1:1"))
+	got := r.listener(app, NetworkTCP4, "127.0.0.1:0")
+	require.NotNil(t, got)
+	defer got.Close() //nolint:errcheck // closed by the test
+	require.Equal(t, ln.Addr().String(), got.Addr().String())
+	require.Nil(t, r.listener(app, NetworkTCP4, "127.0.0.1:0"))
+
+	// the previous process is notified once the inherited listeners are served
+	r.listening(other)
+	require.NotNil(t, r.ready)
+	r.listening(app)
+	require.Nil(t, r.ready)
+	ready, err := io.ReadAll(readyR)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, ready)
+}
@@ -0,0 +1,297 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gofiber/utils/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// RouteConfig overrides app-wide request settings for the routes it is
+// registered with. Pass it along the handlers of a route, a RouteChain or a
+// Group, routes inherit the settings of their groups and override them with
+// their own:
+//
+//	app.Post("/upload", upload, fiber.RouteConfig{BodyLimit: 2 << 30})
+//	api := app.Group("/api", fiber.RouteConfig{Timeout: 5 * time.Second})
+//
+// The settings of the first route matching the request that is not a
+// middleware apply to the whole request, including the middleware that runs
+// before it. Middleware registered with Use and routes registered through
+// Domain or Version do not accept a RouteConfig, nor do they inherit one.
+type RouteConfig struct {
+	// Max body size in bytes, violations are answered with 413 Request Entity
+	// Too Large. Requests that match no route with a limit are checked against
+	// Config.BodyLimit.
+	//
+	// Without StreamRequestBody, the server reads bodies up to the largest
+	// route limit into memory for every route, and smaller route limits are
+	// only checked once the body is read. The server limit is set when the app
+	// starts, routes added later by UpdateRoutes cannot raise it. Enable
+	// StreamRequestBody for limits above Config.BodyLimit: a Content-Length
+	// above the limit is rejected before the body is read, and chunked bodies
+	// fail as soon as they exceed it when read with Body, BodyRaw, Bind or
+	// MultipartReader. The limit does not apply to the body stream of
+	// c.Request().
+	//
+	// Default: Config.BodyLimit
+	BodyLimit int
+
+	// Deadline of the request context returned by c.Context(). Errors
+	// wrapping context.DeadlineExceeded are answered with 408 Request Timeout.
+	// Handlers must watch c.Context().Done(), use the timeout middleware to
+	// abandon handlers that do not.
+	//
+	// Default: no deadline
+	Timeout time.Duration
+
+	// Makes the values returned by the context immutable for the request,
+	// like Config.Immutable does for all requests.
+	//
+	// Default: Config.Immutable
+	Immutable bool
+}
+
+// merge returns c overridden with the settings set in o.
+func (c RouteConfig) merge(o RouteConfig) RouteConfig {
+	if o.BodyLimit > 0 {
+		c.BodyLimit = o.BodyLimit
+	}
+	if o.Timeout > 0 {
+		c.Timeout = o.Timeout
+	}
+	c.Immutable = c.Immutable || o.Immutable
+	return c
+}
+
+// useRouteConfigPanic is the panic value of Use when it is passed a
+// RouteConfig.
+const useRouteConfigPanic = "use: RouteConfig is not supported by middleware, pass it to Group or the routes"
+
+// routeArgs removes the RouteConfig values from the handler arguments and
+// returns the remaining handlers with the route options derived from args.
+func routeArgs(args []any) ([]any, routeOptions) {
+	opts := typedRouteOptions(args)
+	handlers := args[:0:0]
+	for _, arg := range args {
+		switch cfg := arg.(type) {
+		case RouteConfig:
+			opts.config = opts.config.merge(cfg)
+		case *RouteConfig:
+			if cfg != nil {
+				opts.config = opts.config.merge(*cfg)
+			}
+		default:
+			handlers = append(handlers, arg)
+		}
+	}
+	return handlers, opts
+}
+
+// hasRouteConfigs reports whether requests need to be checked against route
+// settings. The caller must hold app.mutex.
+func (app *App) hasRouteConfigs() bool {
+	for _, stack := range app.stack {
+		for _, route := range stack {
+			if route.config != (RouteConfig{}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// raiseBodyLimit raises the server body limit to the largest route limit.
+// The server reads it while serving, so it is only set on startup, before
+// the app serves requests. The caller must hold app.mutex.
+func (app *App) raiseBodyLimit() {
+	bodyLimit := app.config.BodyLimit
+	for _, stack := range app.stack {
+		for _, route := range stack {
+			bodyLimit = max(bodyLimit, route.config.BodyLimit)
+		}
+	}
+	if app.server != nil && app.server.MaxRequestBodySize != bodyLimit {
+		app.server.MaxRequestBodySize = bodyLimit
+	}
+}
+
+// endpoint returns the first route that is not a middleware and matches the
+// request, nil if there is none. Domain and versioned routes are skipped, the
+// request may be passed on to another route.
+func (t *routeTable) endpoint(methodInt int, detectionPath, path string, values *[maxParams]string) *Route {
+	for _, route := range t.find(methodInt, detectionPath) {
+		if route.use || route.mount || route.domain || route.Version != "" {
+			continue
+		}
+		if route.match(detectionPath, path, values) {
+			return route
+		}
+	}
+	return nil
+}
+
+// serveWithRouteConfig applies the RouteConfig of the endpoint matching the
+// request to c and runs next, which walks the route stack.
+func (app *App) serveWithRouteConfig(c CustomCtx, endpoint *Route, next func() error) error {
+	var cfg RouteConfig
+	if endpoint != nil {
+		cfg = endpoint.config
+	}
+	c.setRouteConfig(cfg)
+
+	bodyLimit := app.config.BodyLimit
+	if cfg.BodyLimit > 0 {
+		bodyLimit = cfg.BodyLimit
+	}
+	request := &c.RequestCtx().Request
+	switch size := request.Header.ContentLength(); {
+	case !app.config.StreamRequestBody:
+		if len(request.Body()) > bodyLimit {
+			return ErrRequestEntityTooLarge
+		}
+	case size > bodyLimit:
+		// the Content-Length delimits the body, reject it before it is read,
+		// the unread body must not be parsed as the next request
+		c.RequestCtx().SetConnectionClose()
+		return ErrRequestEntityTooLarge
+	default:
+		// the size of a chunked body is only known once it is read, see
+		// requestBodyStream
+	}
+
+	err := serveWithTimeout(c, cfg.Timeout, next)
+	if c.bodyLimitExceeded() {
+		return ErrRequestEntityTooLarge
+	}
+	return err
+}
+
+// serveWithTimeout runs next with the deadline of the request context set to
+// timeout, if any.
+func serveWithTimeout(c CustomCtx, timeout time.Duration, next func() error) error {
+	if timeout <= 0 {
+		return next()
+	}
+
+	parent := c.Context()
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	c.SetContext(ctx)
+	err := next()
+	c.SetContext(parent)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrRequestTimeout
+	}
+	return err
+}
+
+// limitedBodyStream reads a streamed request body and fails with
+// ErrRequestEntityTooLarge once it exceeds limit.
+type limitedBodyStream struct {
+	r        io.Reader
+	fctx     *fasthttp.RequestCtx
+	read     int
+	limit    int
+	exceeded bool
+}
+
+// requestBodyStream returns the streamed body of the request. Chunked bodies
+// are read through a limitedBodyStream bounded by the body limit of the route,
+// the server reads them without limit. It returns nil if the body is not
+// streamed.
+func (c *DefaultCtx) requestBodyStream() io.Reader {
+	request := &c.fasthttp.Request
+	if !request.IsBodyStream() {
+		return nil
+	}
+	body := request.BodyStream()
+	if request.Header.ContentLength() != -1 {
+		return body
+	}
+	if c.bodyStream == nil || c.bodyStream.r != body {
+		c.bodyStream = &limitedBodyStream{r: body, fctx: c.fasthttp, limit: requestBodyLimit(c)}
+	}
+	return c.bodyStream
+}
+
+// readBodyStream reads a chunked request body through its limit, so that
+// fasthttp doesn't read it without one when the body is requested.
+func (c *DefaultCtx) readBodyStream() {
+	stream, ok := c.requestBodyStream().(*limitedBodyStream)
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(stream)
+	if err != nil {
+		// like fasthttp, the error replaces the body
+		body = []byte(err.Error())
+	}
+	c.fasthttp.Request.SetBodyRaw(body)
+}
+
+func (s *limitedBodyStream) Read(p []byte) (int, error) {
+	if s.exceeded {
+		return 0, ErrRequestEntityTooLarge
+	}
+	n, err := s.r.Read(p)
+	s.read += n
+	if s.read > s.limit {
+		s.exceeded = true
+		// the unread body must not be parsed as the next request
+		s.fctx.SetConnectionClose()
+		return n - (s.read - s.limit), ErrRequestEntityTooLarge
+	}
+	return n, err //nolint:wrapcheck // the errors of the body stream are passed on
+}
+
+// requestBodyLimit returns the body limit that applies to the request
+// handled by c.
+func requestBodyLimit(c Ctx) int {
+	if cc, ok := c.(CustomCtx); ok {
+		if limit := cc.getRouteConfig().BodyLimit; limit > 0 {
+			return limit
+		}
+	}
+	return c.App().config.BodyLimit
+}
+
+// requestString converts b like App.toString, honoring the Immutable setting
+// of the route handling the request.
+func requestString(c Ctx, b []byte) string {
+	if cc, ok := c.(CustomCtx); ok && cc.getRouteConfig().Immutable {
+		return toStringImmutable(b)
+	}
+	return c.App().toString(b)
+}
+
+// toString converts b like App.toString, honoring the Immutable setting of
+// the route handling the request.
+func (c *DefaultCtx) toString(b []byte) string {
+	if c.routeConfig.Immutable {
+		return toStringImmutable(b)
+	}
+	return c.app.toString(b)
+}
+
+// getString returns s like App.GetString, honoring the Immutable setting of
+// the route handling the request.
+func (c *DefaultCtx) getString(s string) string {
+	if c.routeConfig.Immutable && !c.app.config.Immutable {
+		return strings.Clone(s)
+	}
+	return c.app.GetString(s)
+}
+
+// getBytes returns b like App.GetBytes, honoring the Immutable setting of the
+// route handling the request.
+func (c *DefaultCtx) getBytes(b []byte) []byte {
+	if c.routeConfig.Immutable && !c.app.config.Immutable {
+		return utils.CopyBytes(b)
+	}
+	return c.app.GetBytes(b)
+}
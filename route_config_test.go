@@ -0,0 +1,240 @@
+package fiber
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func routeConfigRequest(t *testing.T, app *App, method, target string, size int) int {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(strings.Repeat("a", size)))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+// go test -run Test_RouteConfig_BodyLimit
+func Test_RouteConfig_BodyLimit(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{BodyLimit: 16})
+	app.Post("/json", testEmptyHandler)
+	app.Post("/upload", testEmptyHandler, RouteConfig{BodyLimit: 64})
+	app.RouteChain("/chain").Post(testEmptyHandler, &RouteConfig{BodyLimit: 32})
+
+	api := app.Group("/api", RouteConfig{BodyLimit: 8})
+	api.Post("/small", testEmptyHandler)
+	api.Post("/big", testEmptyHandler, RouteConfig{BodyLimit: 48})
+	api.Group("/v1").Post("/nested", testEmptyHandler)
+
+	testCases := []struct {
+		path   string
+		size   int
+		status int
+	}{
+		{path: "/json", size: 16, status: StatusOK},
+		{path: "/json", size: 17, status: StatusRequestEntityTooLarge},
+		{path: "/upload", size: 64, status: StatusOK},
+		{path: "/chain", size: 32, status: StatusOK},
+		{path: "/chain", size: 33, status: StatusRequestEntityTooLarge},
+		{path: "/api/small", size: 8, status: StatusOK},
+		{path: "/api/small", size: 9, status: StatusRequestEntityTooLarge},
+		{path: "/api/big", size: 48, status: StatusOK},
+		{path: "/api/v1/nested", size: 9, status: StatusRequestEntityTooLarge},
+		{path: "/unknown", size: 17, status: StatusRequestEntityTooLarge},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.status, routeConfigRequest(t, app, MethodPost, tc.path, tc.size), "%s with %d bytes", tc.path, tc.size)
+	}
+
+	// the server rejects bodies above the largest route limit
+	_, err := app.Test(httptest.NewRequest(MethodPost, "/upload", strings.NewReader(strings.Repeat("a", 65))))
+	require.ErrorContains(t, err, "body size exceeds the given limit")
+}
+
+// go test -run Test_RouteConfig_BodyLimit_StreamRequestBody
+func Test_RouteConfig_BodyLimit_StreamRequestBody(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{BodyLimit: 16, StreamRequestBody: true})
+	var middlewareCalls int
+	app.Use(func(c Ctx) error {
+		middlewareCalls++
+		return c.Next()
+	})
+	app.Post("/upload", func(c Ctx) error {
+		return c.SendString(string(c.Body()))
+	}, RouteConfig{BodyLimit: 32})
+
+	require.Equal(t, StatusOK, routeConfigRequest(t, app, MethodPost, "/upload", 32))
+	require.Equal(t, 1, middlewareCalls)
+
+	// rejected before any handler reads the body
+	require.Equal(t, StatusRequestEntityTooLarge, routeConfigRequest(t, app, MethodPost, "/upload", 33))
+	require.Equal(t, 1, middlewareCalls)
+}
+
+// go test -run Test_RouteConfig_BodyLimit_Chunked
+func Test_RouteConfig_BodyLimit_Chunked(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{StreamRequestBody: true})
+	app.Post("/body", func(c Ctx) error {
+		return c.SendString(string(c.Body()))
+	}, RouteConfig{BodyLimit: 1024})
+	app.Post("/raw", func(c Ctx) error {
+		return c.Send(c.BodyRaw())
+	}, RouteConfig{BodyLimit: 1024})
+
+	chunked := func(path string, size int) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(MethodPost, path, strings.NewReader(strings.Repeat("a", size)))
+		// the size is only known once the body is read
+		req.ContentLength = 0
+		req.TransferEncoding = []string{"chunked"}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	for _, path := range []string{"/body", "/raw"} {
+		resp := chunked(path, 1024)
+		require.Equal(t, StatusOK, resp.StatusCode, path)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Len(t, body, 1024)
+
+		resp = chunked(path, 5000)
+		require.Equal(t, StatusRequestEntityTooLarge, resp.StatusCode, path)
+		require.True(t, resp.Close, path)
+	}
+}
+
+// go test -run Test_RouteConfig_Timeout
+func Test_RouteConfig_Timeout(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	slow := func(c Ctx) error {
+		select {
+		case <-c.Context().Done():
+			return c.Context().Err()
+		case <-time.After(time.Second):
+			return c.SendStatus(StatusOK)
+		}
+	}
+	app.Get("/slow", slow, RouteConfig{Timeout: 10 * time.Millisecond})
+	app.Get("/deadline", func(c Ctx) error {
+		_, ok := c.Context().Deadline()
+		return c.SendString(map[bool]string{true: "deadline", false: "none"}[ok])
+	})
+
+	require.Equal(t, StatusRequestTimeout, routeConfigRequest(t, app, MethodGet, "/slow", 0))
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/deadline", http.NoBody))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "none", string(body))
+
+	// handler errors unrelated to the deadline are passed on
+	app = New()
+	app.Get("/", func(_ Ctx) error {
+		return context.Canceled
+	}, RouteConfig{Timeout: time.Second})
+	require.Equal(t, StatusInternalServerError, routeConfigRequest(t, app, MethodGet, "/", 0))
+}
+
+// go test -run Test_RouteConfig_Immutable
+func Test_RouteConfig_Immutable(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	aliased := func(c Ctx) error {
+		value := c.Get("X-Value")
+		raw := c.Request().Header.Peek("X-Value")
+		if unsafe.StringData(value) == unsafe.SliceData(raw) {
+			return c.SendString("aliased")
+		}
+		return c.SendString("copied")
+	}
+	app.Get("/mutable", aliased)
+	app.Get("/immutable", aliased, RouteConfig{Immutable: true})
+
+	for path, expected := range map[string]string{"/mutable": "aliased", "/immutable": "copied"} {
+		req := httptest.NewRequest(MethodGet, path, http.NoBody)
+		req.Header.Set("X-Value", "value")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(body), path)
+	}
+}
+
+// go test -run Test_RouteConfig_CustomCtx
+func Test_RouteConfig_CustomCtx(t *testing.T) {
+	t.Parallel()
+
+	app := NewWithCustomCtx(func(app *App) CustomCtx {
+		return &customCtx{DefaultCtx: *NewDefaultCtx(app)}
+	}, Config{BodyLimit: 16})
+	app.Post("/upload", testEmptyHandler, RouteConfig{BodyLimit: 32})
+	app.Post("/json", testEmptyHandler)
+
+	require.Equal(t, StatusOK, routeConfigRequest(t, app, MethodPost, "/upload", 32))
+	require.Equal(t, StatusRequestEntityTooLarge, routeConfigRequest(t, app, MethodPost, "/json", 17))
+}
+
+// go test -run Test_RouteConfig_Domain
+func Test_RouteConfig_Domain(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	const msg = "domain: RouteConfig is not supported for domain routes"
+	domain := app.Domain("api.example.com")
+	require.PanicsWithValue(t, msg, func() {
+		domain.Post("/", testEmptyHandler, RouteConfig{BodyLimit: 32})
+	})
+	require.PanicsWithValue(t, msg, func() {
+		domain.RouteChain("/chain").Post(testEmptyHandler, &RouteConfig{BodyLimit: 32})
+	})
+	require.PanicsWithValue(t, msg, func() {
+		domain.Group("/admin", RouteConfig{Immutable: true})
+	})
+
+	// nor inherited from a group
+	api := app.Group("/api", RouteConfig{Timeout: time.Second})
+	require.PanicsWithValue(t, msg, func() {
+		api.Domain("api.example.com").Get("/", testEmptyHandler)
+	})
+	require.PanicsWithValue(t, msg, func() {
+		api.Domain("api.example.com").Group("/admin").Get("/", testEmptyHandler)
+	})
+	require.False(t, app.hasRouteConfigs())
+}
+
+// go test -run Test_RouteConfig_Use
+func Test_RouteConfig_Use(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	const msg = "use: RouteConfig is not supported by middleware, pass it to Group or the routes"
+	require.PanicsWithValue(t, msg, func() {
+		app.Use(testEmptyHandler, RouteConfig{BodyLimit: 32})
+	})
+	require.PanicsWithValue(t, msg, func() {
+		app.Group("/api").Use(&RouteConfig{Timeout: time.Second})
+	})
+	require.PanicsWithValue(t, msg, func() {
+		app.Domain("api.example.com").Use(RouteConfig{Immutable: true})
+	})
+}
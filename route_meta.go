@@ -0,0 +1,143 @@
+package fiber
+
+import "maps"
+
+// withMeta returns a copy of meta with key set to value. Routes and groups
+// never modify their metadata in place, copies made of them, e.g. for other
+// methods or mounted apps, keep their own.
+func withMeta(meta map[string]any, key string, value any) map[string]any {
+	merged := make(map[string]any, len(meta)+1)
+	maps.Copy(merged, meta)
+	merged[key] = value
+	return merged
+}
+
+// mergeMeta returns the metadata of parent overridden by the metadata of
+// child, without copying when one of them is empty.
+func mergeMeta(parent, child map[string]any) map[string]any {
+	if len(parent) == 0 {
+		return child
+	}
+	if len(child) == 0 {
+		return parent
+	}
+	merged := make(map[string]any, len(parent)+len(child))
+	maps.Copy(merged, parent)
+	maps.Copy(merged, child)
+	return merged
+}
+
+// routeMeta returns the metadata routes of the group inherit, merged from its
+// parent groups.
+func (grp *Group) routeMeta() map[string]any {
+	if grp == nil {
+		return nil
+	}
+	return mergeMeta(grp.parentGroup.routeMeta(), grp.meta)
+}
+
+// Meta returns the metadata value stored for key with Router.Meta.
+//
+//nolint:gocritic // hugeParam: c.Route and app.GetRoute return values, so Meta must be callable on a value directly.
+func (r Route) Meta(key string) (any, bool) {
+	value, ok := r.meta[key]
+	return value, ok
+}
+
+// RouteMeta returns the metadata value stored for key on the route handling
+// the request and casts it to the desired type. It returns the casted value
+// and a boolean indicating if the key exists with that type.
+//
+// In middleware, the metadata of the first route matching the request that is
+// not a middleware is returned, so the middleware can apply the policy of the
+// endpoint the request is going to. Routes registered through Domain or
+// Version are not considered there.
+//
+//	app.Use(func(c fiber.Ctx) error {
+//	    scopes, _ := fiber.RouteMeta[[]string](c, "auth.scopes")
+//	    ...
+//	})
+//	app.Get("/admin", handler).Meta("auth.scopes", []string{"admin"})
+func RouteMeta[T any](c Ctx, key string) (T, bool) {
+	route := c.Route()
+	if route.use {
+		if endpoint := requestEndpoint(c); endpoint != nil {
+			route = endpoint
+		}
+	}
+
+	value, ok := route.Meta(key)
+	if ok {
+		valueT, okCast := value.(T)
+		return valueT, okCast
+	}
+
+	var zeroVal T
+	return zeroVal, false
+}
+
+// requestEndpoint returns the first route matching the request handled by c
+// that is not a middleware, nil if there is none.
+func requestEndpoint(c Ctx) *Route {
+	cc, ok := c.(CustomCtx)
+	if !ok {
+		return nil
+	}
+	// match into a copy, the params of the current route must not change
+	var values [maxParams]string
+	return requestRouteTable(c).endpoint(cc.getMethodInt(), cc.getDetectionPath(), cc.Path(), &values)
+}
+
+// Meta attaches a metadata value to the most recently registered route, for
+// all methods it was registered for. Handlers and middleware read it with
+// c.Route().Meta or [RouteMeta].
+//
+//	app.Get("/admin", handler).Meta("auth.scopes", []string{"admin"})
+func (app *App) Meta(key string, value any) Router {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	for _, routes := range app.stack {
+		for _, route := range routes {
+			if app.isLatestRoute(route) {
+				route.meta = withMeta(route.meta, key, value)
+			}
+		}
+	}
+
+	return app
+}
+
+// isLatestRoute reports whether the route was created by the latest
+// registration, including the HEAD routes generated for its GET routes.
+// The caller must hold app.mutex.
+func (app *App) isLatestRoute(route *Route) bool {
+	for _, latest := range app.latestRoutes {
+		if route == latest {
+			return true
+		}
+		if route.autoHead && latest.Method == MethodGet && route.path == latest.path && route.Version == latest.Version {
+			return true
+		}
+	}
+	return false
+}
+
+// Meta attaches a metadata value to the group itself or to a specific route.
+//
+// If this method is used before any route added to group, the value is
+// inherited by all routes and groups created in the group later, which can
+// override it. Otherwise, it's attached to the most recently registered route.
+func (grp *Group) Meta(key string, value any) Router {
+	if grp.hasAnyRoute {
+		grp.app.Meta(key, value)
+
+		return grp
+	}
+
+	grp.app.mutex.Lock()
+	grp.meta = withMeta(grp.meta, key, value)
+	grp.app.mutex.Unlock()
+
+	return grp
+}
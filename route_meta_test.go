@@ -0,0 +1,176 @@
+package fiber
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func metaRequest(t *testing.T, app *App, method, target string) string {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(method, target, http.NoBody))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func scopesHandler(c Ctx) error {
+	scopes, _ := RouteMeta[[]string](c, "auth.scopes")
+	return c.SendString(strings.Join(scopes, ","))
+}
+
+// go test -run Test_Route_Meta
+func Test_Route_Meta(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Get("/admin", scopesHandler).Meta("auth.scopes", []string{"admin"}).Name("admin").Meta("rate", 10)
+	app.Get("/public", scopesHandler)
+	app.All("/all", scopesHandler).Meta("auth.scopes", []string{"all"})
+
+	require.Equal(t, "admin", metaRequest(t, app, MethodGet, "/admin"))
+	require.Empty(t, metaRequest(t, app, MethodGet, "/public"))
+	require.Equal(t, "all", metaRequest(t, app, MethodPost, "/all"))
+
+	route := app.GetRoute("admin")
+	rate, ok := route.Meta("rate")
+	require.True(t, ok)
+	require.Equal(t, 10, rate)
+	_, ok = route.Meta("missing")
+	require.False(t, ok)
+
+	// the automatic HEAD route carries the metadata of the GET route
+	for _, r := range app.GetRoutes() {
+		if r.Path == "/admin" {
+			scopes, ok := r.Meta("auth.scopes")
+			require.True(t, ok, r.Method)
+			require.Equal(t, []string{"admin"}, scopes, r.Method)
+		}
+	}
+}
+
+// go test -run Test_Route_Meta_Name
+func Test_Route_Meta_Name(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	api := app.Group("/api")
+	api.Get("/users", scopesHandler)
+	api.Get("/profile", scopesHandler)
+	api.Get("/users", scopesHandler).Name("users").Meta("auth.scopes", []string{"admin"})
+
+	// both apply to the routes of the latest registration only
+	var named, tagged []*Route
+	for _, routes := range app.stack {
+		for _, route := range routes {
+			if route.Name == "users" {
+				named = append(named, route)
+			}
+			if _, ok := route.Meta("auth.scopes"); ok {
+				tagged = append(tagged, route)
+			}
+		}
+	}
+	require.Len(t, named, 1)
+	require.Equal(t, named, tagged)
+}
+
+// go test -run Test_Route_Meta_Group
+func Test_Route_Meta_Group(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	api := app.Group("/api").Meta("auth.scopes", []string{"user"}).Meta("cache", true)
+	api.Get("/profile", scopesHandler)
+	api.Get("/settings", scopesHandler).Meta("auth.scopes", []string{"owner"})
+
+	admin := api.Group("/admin").Meta("auth.scopes", []string{"admin"})
+	admin.Get("/users", scopesHandler).Name("users")
+
+	require.Equal(t, "user", metaRequest(t, app, MethodGet, "/api/profile"))
+	require.Equal(t, "owner", metaRequest(t, app, MethodGet, "/api/settings"))
+	require.Equal(t, "admin", metaRequest(t, app, MethodGet, "/api/admin/users"))
+
+	// values of parent groups are merged into the routes of child groups
+	cache, ok := app.GetRoute("users").Meta("cache")
+	require.True(t, ok)
+	require.Equal(t, true, cache)
+
+	// metadata of a route does not leak into its group
+	_, ok = api.(*Group).meta["rate"]
+	require.False(t, ok)
+	require.Equal(t, []string{"user"}, api.(*Group).meta["auth.scopes"])
+}
+
+// go test -run Test_Route_Meta_Middleware
+func Test_Route_Meta_Middleware(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Use("/api", func(c Ctx) error {
+		scopes, ok := RouteMeta[[]string](c, "auth.scopes")
+		if ok && !strings.Contains(c.Get("X-Scopes"), strings.Join(scopes, ",")) {
+			return c.SendStatus(StatusForbidden)
+		}
+		return c.Next()
+	})
+	app.Get("/api/users/:id", func(c Ctx) error {
+		return c.SendString(c.Params("id"))
+	}).Meta("auth.scopes", []string{"admin"})
+	app.Get("/api/public", testEmptyHandler)
+
+	req := httptest.NewRequest(MethodGet, "/api/users/42", http.NoBody)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, StatusForbidden, resp.StatusCode)
+
+	req = httptest.NewRequest(MethodGet, "/api/users/42", http.NoBody)
+	req.Header.Set("X-Scopes", "admin")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "42", string(body))
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/api/public", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+
+	// wrong types are reported like missing keys
+	app = New()
+	app.Get("/", func(c Ctx) error {
+		_, ok := RouteMeta[int](c, "auth.scopes")
+		require.False(t, ok)
+		return nil
+	}).Meta("auth.scopes", []string{"admin"})
+	_ = metaRequest(t, app, MethodGet, "/")
+}
+
+// go test -run Test_Route_Meta_Mount
+func Test_Route_Meta_Mount(t *testing.T) {
+	t.Parallel()
+
+	sub := New()
+	sub.Get("/users", scopesHandler).Meta("auth.scopes", []string{"admin"}).Name("users")
+	sub.Get("/profile", scopesHandler)
+
+	app := New()
+	app.Group("/api").Meta("auth.scopes", []string{"user"}).Meta("tenant", "acme").Use("/sub", sub)
+
+	require.Equal(t, "admin", metaRequest(t, app, MethodGet, "/api/sub/users"))
+	require.Equal(t, "user", metaRequest(t, app, MethodGet, "/api/sub/profile"))
+
+	tenant, ok := app.GetRoute("users").Meta("tenant")
+	require.True(t, ok)
+	require.Equal(t, "acme", tenant)
+
+	// the sub-app keeps its own routes unchanged
+	_, ok = sub.GetRoute("users").Meta("tenant")
+	require.False(t, ok)
+}
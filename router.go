@@ -6,6 +6,7 @@ package fiber
 
 import (
 	"fmt"
+	"reflect"
 	"slices"
 	"sync/atomic"
 
@@ -36,11 +37,13 @@ type Router interface {
 	Group(prefix string, handlers ...any) Router
 
 	Domain(host string) Router
+	Version(version string, config ...VersionConfig) Router
 
 	RouteChain(path string) Register
 	Route(prefix string, fn func(router Router), name ...string) Router
 
 	Name(name string) Router
+	Meta(key string, value any) Router
 }
 
 // Route is a struct that holds all metadata for each registered handler.
@@ -54,17 +57,26 @@ type Route struct {
 	Method string `json:"method"` // HTTP method
 	Name   string `json:"name"`   // Route's name
 	//nolint:revive // Having both a Path (uppercase) and a path (lowercase) is fine
-	Path        string      `json:"path"`   // Original registered route path
-	Params      []string    `json:"params"` // Case-sensitive param keys
-	Handlers    []Handler   `json:"-"`      // Ctx handlers
+	Path        string      `json:"path"`              // Original registered route path
+	Params      []string    `json:"params"`            // Case-sensitive param keys
+	Version     string      `json:"version,omitempty"` // API version, set when registered through Router.Version
+	Handlers    []Handler   `json:"-"`                 // Ctx handlers
 	routeParser routeParser // Parameter parser
 
+	// Request and response types of the endpoint, set when it was created with Typed
+	RequestType  reflect.Type `json:"-"`
+	ResponseType reflect.Type `json:"-"`
+
+	config RouteConfig    // Request settings overriding the app config
+	meta   map[string]any // Metadata set with Router.Meta, never modified in place
+
 	// Data for routing
 	use           bool // USE matches path prefixes
 	mount         bool // Indicated a mounted app on a specific route
 	star          bool // Path equals '*'
 	root          bool // Path equals '/'
 	autoHead      bool // Automatically generated HEAD route
+	domain        bool // Registered on a domain router, handlers pass on other hostnames
 	caseSensitive bool // Whether parameter matching is case-sensitive
 }
 
@@ -93,6 +105,65 @@ func (r Route) URL(params Map) (string, error) {
 	return buildRouteURL(&r, params)
 }
 
+// RouteSegment describes a constant part or a parameter of a parsed route path.
+type RouteSegment struct {
+	// Const holds the constant part of the path, it is empty for parameters
+	Const string `json:"const,omitempty"`
+	// Param holds the parameter key as used with Ctx.Params, it is empty for constant parts
+	Param string `json:"param,omitempty"`
+	// Constraints declared for the parameter, e.g. "/:id<int;min(1)>"
+	Constraints []*Constraint `json:"-"`
+	// IsParam reports whether the segment is a parameter
+	IsParam bool `json:"is_param"`
+	// IsOptional reports whether the parameter is optional (":name?" or "*")
+	IsOptional bool `json:"is_optional"`
+	// IsGreedy reports whether the parameter is a wildcard ("*") or plus ("+") parameter
+	IsGreedy bool `json:"is_greedy"`
+}
+
+// Segments returns the parsed segments of the route path in order.
+// Constant parts are normalized the same way the router matches them,
+// parameter keys keep the case used at registration.
+//
+// Example:
+//
+//	app.Get("/user/:id<int>", handler).Name("user")
+//	segs := app.GetRoute("user").Segments()
+//	// Returns: [{Const: "/user/"}, {Param: "id", IsParam: true, Constraints: [int]}]
+//
+//nolint:gocritic // hugeParam: app.GetRoutes returns values, so Segments must be callable on a value directly.
+func (r Route) Segments() []RouteSegment {
+	segs := r.routeParser.segs
+	if len(segs) == 0 {
+		return []RouteSegment{{Const: r.path}}
+	}
+
+	result := make([]RouteSegment, len(segs))
+	paramIndex := 0
+	for i, seg := range segs {
+		if !seg.IsParam {
+			result[i] = RouteSegment{Const: seg.Const}
+			continue
+		}
+
+		name := seg.ParamName
+		if paramIndex < len(r.Params) {
+			name = r.Params[paramIndex]
+		}
+		paramIndex++
+
+		result[i] = RouteSegment{
+			Param:       name,
+			Constraints: seg.Constraints,
+			IsParam:     true,
+			IsOptional:  seg.IsOptional,
+			IsGreedy:    seg.IsGreedy,
+		}
+	}
+
+	return result
+}
+
 // buildRouteURL generates a URL from route segments and parameters.
 // This shared helper is used by both Route.URL() and DefaultRes.getLocationFromRoute()
 // to ensure consistent URL generation behavior across APIs.
@@ -224,14 +295,11 @@ func (r *Route) match(detectionPath, path string, params *[maxParams]string) boo
 
 func (app *App) next(c *DefaultCtx) (bool, error) {
 	methodInt := c.methodInt
-	treeHash := c.treePathHash
 	detectionPath := utils.UnsafeString(c.detectionPath)
 	path := utils.UnsafeString(c.path)
-	// Get stack length
-	tree, ok := app.treeStack[methodInt][treeHash]
-	if !ok {
-		tree = app.treeStack[methodInt][0]
-	}
+	table := c.getRouteTable()
+	// Get candidate routes for the detection path
+	tree := table.find(methodInt, detectionPath)
 	lenr := len(tree) - 1
 
 	indexRoute := c.indexRoute
@@ -285,6 +353,7 @@ func (app *App) next(c *DefaultCtx) (bool, error) {
 	}
 
 	exists := false
+	optionsAllowed := false
 	methods := app.config.RequestMethods
 	for i := range methods {
 		// Skip original method
@@ -294,10 +363,7 @@ func (app *App) next(c *DefaultCtx) (bool, error) {
 		// Reset stack index
 		indexRoute := -1
 
-		tree, ok := app.treeStack[i][treeHash]
-		if !ok {
-			tree = app.treeStack[i][0]
-		}
+		tree := table.find(i, detectionPath)
 		// Get stack length
 		lenr := len(tree) - 1
 		// Loop over the route stack starting from previous index
@@ -315,6 +381,7 @@ func (app *App) next(c *DefaultCtx) (bool, error) {
 			if route.match(detectionPath, path, &c.values) {
 				// We matched
 				exists = true
+				optionsAllowed = optionsAllowed || methods[i] == MethodOptions
 				// Add method to Allow header
 				c.Append(HeaderAllow, methods[i])
 				// Break stack loop
@@ -324,19 +391,17 @@ func (app *App) next(c *DefaultCtx) (bool, error) {
 		c.indexRoute = indexRoute
 	}
 	if exists {
-		return false, ErrMethodNotAllowed
+		return false, app.methodNotAllowed(c, methodInt, optionsAllowed)
 	}
 	return false, ErrNotFound
 }
 
 func (app *App) nextCustom(c CustomCtx) (bool, error) {
 	methodInt := c.getMethodInt()
-	treeHash := c.getTreePathHash()
-	// Get stack length
-	tree, ok := app.treeStack[methodInt][treeHash]
-	if !ok {
-		tree = app.treeStack[methodInt][0]
-	}
+	detectionPath := c.getDetectionPath()
+	table := c.getRouteTable()
+	// Get candidate routes for the detection path
+	tree := table.find(methodInt, detectionPath)
 	lenr := len(tree) - 1
 
 	indexRoute := c.getIndexRoute()
@@ -354,7 +419,7 @@ func (app *App) nextCustom(c CustomCtx) (bool, error) {
 		}
 
 		// Check if it matches the request path
-		if !route.match(c.getDetectionPath(), c.Path(), c.getValues()) {
+		if !route.match(detectionPath, c.Path(), c.getValues()) {
 			continue
 		}
 		if c.getSkipNonUseRoutes() && !route.use {
@@ -388,6 +453,7 @@ func (app *App) nextCustom(c CustomCtx) (bool, error) {
 	}
 
 	exists := false
+	optionsAllowed := false
 	methods := app.config.RequestMethods
 	for i := range methods {
 		// Skip original method
@@ -397,10 +463,7 @@ func (app *App) nextCustom(c CustomCtx) (bool, error) {
 		// Reset stack index
 		indexRoute := -1
 
-		tree, ok := app.treeStack[i][treeHash]
-		if !ok {
-			tree = app.treeStack[i][0]
-		}
+		tree := table.find(i, detectionPath)
 		// Get stack length
 		lenr := len(tree) - 1
 		// Loop over the route stack starting from previous index
@@ -415,9 +478,10 @@ func (app *App) nextCustom(c CustomCtx) (bool, error) {
 			}
 			// Check if it matches the request path
 			// No match, next route
-			if route.match(c.getDetectionPath(), c.Path(), c.getValues()) {
+			if route.match(detectionPath, c.Path(), c.getValues()) {
 				// We matched
 				exists = true
+				optionsAllowed = optionsAllowed || methods[i] == MethodOptions
 				// Add method to Allow header
 				c.Append(HeaderAllow, methods[i])
 				// Break stack loop
@@ -427,11 +491,35 @@ func (app *App) nextCustom(c CustomCtx) (bool, error) {
 		c.setIndexRoute(indexRoute)
 	}
 	if exists {
-		return false, ErrMethodNotAllowed
+		return false, app.methodNotAllowed(c, methodInt, optionsAllowed)
 	}
 	return false, ErrNotFound
 }
 
+// methodNotAllowed completes a request whose path only has routes for other
+// methods, after they were added to the Allow header. With EnableAutoOptions,
+// OPTIONS is allowed as well and OPTIONS requests are answered with
+// 204 No Content. All other requests fail with ErrMethodNotAllowed.
+func (app *App) methodNotAllowed(c Ctx, methodInt int, optionsAllowed bool) error {
+	if !app.config.EnableAutoOptions {
+		return ErrMethodNotAllowed
+	}
+
+	optionsInt := app.methodInt(MethodOptions)
+	if optionsInt == -1 {
+		return ErrMethodNotAllowed
+	}
+	if !optionsAllowed {
+		c.Append(HeaderAllow, MethodOptions)
+	}
+	if methodInt != optionsInt {
+		return ErrMethodNotAllowed
+	}
+
+	c.Status(StatusNoContent)
+	return nil
+}
+
 func (app *App) defaultRequestHandler(rctx *fasthttp.RequestCtx) {
 	ctx, ok := app.acquireDefaultCtx(rctx)
 	if !ok {
@@ -440,6 +528,10 @@ func (app *App) defaultRequestHandler(rctx *fasthttp.RequestCtx) {
 	}
 	defer app.releaseDefaultCtx(ctx)
 
+	if app.config.CancelOnDisconnect {
+		watchDisconnect(ctx)
+	}
+
 	// Check if the HTTP method is valid
 	if ctx.methodInt == -1 {
 		_ = ctx.SendStatus(StatusNotImplemented) //nolint:errcheck // Always return nil
@@ -451,18 +543,32 @@ func (app *App) defaultRequestHandler(rctx *fasthttp.RequestCtx) {
 		ctx.Redirect().parseAndClearFlashMessages()
 	}
 
-	_, err := app.next(ctx)
+	var err error
+	if table := ctx.getRouteTable(); table != nil && table.hasRouteConfigs {
+		endpoint := table.endpoint(ctx.methodInt, utils.UnsafeString(ctx.detectionPath), utils.UnsafeString(ctx.path), &ctx.values)
+		err = app.serveWithRouteConfig(ctx, endpoint, func() error {
+			_, nextErr := app.next(ctx)
+			return nextErr
+		})
+	} else {
+		_, err = app.next(ctx)
+	}
 	if err != nil {
 		if catch := ctx.App().ErrorHandler(ctx, err); catch != nil {
 			_ = ctx.SendStatus(StatusInternalServerError) //nolint:errcheck // Always return nil
 		}
 	}
+	serveRanges(ctx)
 }
 
 func (app *App) customRequestHandler(rctx *fasthttp.RequestCtx) {
 	ctx := app.AcquireCtx(rctx)
 	defer app.ReleaseCtx(ctx)
 
+	if app.config.CancelOnDisconnect {
+		watchDisconnect(ctx)
+	}
+
 	// Check if the HTTP method is valid
 	if ctx.getMethodInt() == -1 {
 		_ = ctx.SendStatus(StatusNotImplemented) //nolint:errcheck // Always return nil
@@ -474,12 +580,22 @@ func (app *App) customRequestHandler(rctx *fasthttp.RequestCtx) {
 		ctx.Redirect().parseAndClearFlashMessages()
 	}
 
-	_, err := app.nextCustom(ctx)
+	var err error
+	if table := ctx.getRouteTable(); table != nil && table.hasRouteConfigs {
+		endpoint := table.endpoint(ctx.getMethodInt(), ctx.getDetectionPath(), ctx.Path(), ctx.getValues())
+		err = app.serveWithRouteConfig(ctx, endpoint, func() error {
+			_, nextErr := app.nextCustom(ctx)
+			return nextErr
+		})
+	} else {
+		_, err = app.nextCustom(ctx)
+	}
 	if err != nil {
 		if catch := ctx.App().ErrorHandler(ctx, err); catch != nil {
 			_ = ctx.SendStatus(StatusInternalServerError) //nolint:errcheck // Always return nil
 		}
 	}
+	serveRanges(ctx)
 }
 
 func (app *App) addPrefixToRoute(prefix string, route *Route, regexHandler any, customConstraints ...CustomConstraint) *Route {
@@ -512,6 +628,7 @@ func (*App) copyRoute(route *Route) *Route {
 		star:          route.star,
 		root:          route.root,
 		autoHead:      route.autoHead,
+		domain:        route.domain,
 		caseSensitive: route.caseSensitive,
 
 		// Path data
@@ -524,6 +641,13 @@ func (*App) copyRoute(route *Route) *Route {
 		Name:     route.Name,
 		Method:   route.Method,
 		Handlers: route.Handlers,
+		Version:  route.Version,
+
+		RequestType:  route.RequestType,
+		ResponseType: route.ResponseType,
+
+		config: route.config,
+		meta:   route.meta,
 	}
 }
 
@@ -642,6 +766,21 @@ func (app *App) pruneAutoHeadRouteLocked(path string) {
 }
 
 func (app *App) register(methods []string, pathRaw string, group *Group, handlers ...Handler) {
+	app.registerRoute(methods, pathRaw, group, routeOptions{}, handlers...)
+}
+
+// routeOptions holds the route data that is not derived from the path.
+type routeOptions struct {
+	requestType  reflect.Type // request type of a Typed endpoint
+	responseType reflect.Type // response type of a Typed endpoint
+	config       RouteConfig  // request settings overriding the app config
+	version      string       // API version of the route
+	domain       bool         // handlers only run for a matching hostname
+}
+
+// registerRoute registers the handlers like register and applies the options
+// to the created routes.
+func (app *App) registerRoute(methods []string, pathRaw string, group *Group, opts routeOptions, handlers ...Handler) {
 	// A regular route requires at least one ctx handler
 	if len(handlers) == 0 && group == nil {
 		panic(fmt.Sprintf("missing handler/middleware in route: %s\n", pathRaw))
@@ -673,6 +812,13 @@ func (app *App) register(methods []string, pathRaw string, group *Group, handler
 	parsedPretty := parseRoute(pathPretty, app.config.RegexHandler, app.customConstraints...)
 
 	isMount := group != nil && group.app != app
+	config := opts.config
+	var meta map[string]any
+	var registered []*Route
+	if group != nil {
+		config = group.config.merge(config)
+		meta = group.routeMeta()
+	}
 
 	for _, method := range methods {
 		method = utilsstrings.ToUpper(method)
@@ -689,6 +835,7 @@ func (app *App) register(methods []string, pathRaw string, group *Group, handler
 			mount:         isMount,
 			star:          isStar,
 			root:          isRoot,
+			domain:        opts.domain,
 			caseSensitive: app.config.CaseSensitive,
 
 			path:        pathClean,
@@ -699,6 +846,13 @@ func (app *App) register(methods []string, pathRaw string, group *Group, handler
 			Path:     pathRaw,
 			Method:   method,
 			Handlers: handlers,
+			Version:  opts.version,
+
+			RequestType:  opts.requestType,
+			ResponseType: opts.responseType,
+
+			config: config,
+			meta:   meta,
 		}
 
 		// Increment global handler count
@@ -710,16 +864,24 @@ func (app *App) register(methods []string, pathRaw string, group *Group, handler
 			for _, m := range app.config.RequestMethods {
 				// Create a route copy to avoid duplicates during compression
 				r := route
-				app.addRoute(m, &r)
+				registered = append(registered, app.addRoute(m, &r))
 			}
 		} else {
 			// Add route to stack
-			app.addRoute(method, &route)
+			registered = append(registered, app.addRoute(method, &route))
 		}
 	}
+
+	if !isMount {
+		app.mutex.Lock()
+		app.latestRoutes = registered
+		app.mutex.Unlock()
+	}
 }
 
-func (app *App) addRoute(method string, route *Route) {
+// addRoute adds the route to the stack of the method and returns the route
+// stored in the stack, which is the previous route when they were merged.
+func (app *App) addRoute(method string, route *Route) *Route {
 	app.mutex.Lock()
 	defer app.mutex.Unlock()
 
@@ -731,10 +893,19 @@ func (app *App) addRoute(method string, route *Route) {
 	}
 
 	// prevent identically route registration
+	stored := route
 	l := len(app.stack[m])
-	if l > 0 && app.stack[m][l-1].Path == route.Path && route.use == app.stack[m][l-1].use && !route.mount && !app.stack[m][l-1].mount {
+	if l > 0 && app.stack[m][l-1].Path == route.Path && route.use == app.stack[m][l-1].use && !route.mount && !app.stack[m][l-1].mount &&
+		route.Version == app.stack[m][l-1].Version {
 		preRoute := app.stack[m][l-1]
+		stored = preRoute
 		preRoute.Handlers = append(preRoute.Handlers, route.Handlers...)
+		if route.RequestType != nil {
+			preRoute.RequestType = route.RequestType
+			preRoute.ResponseType = route.ResponseType
+		}
+		preRoute.config = preRoute.config.merge(route.config)
+		preRoute.meta = mergeMeta(preRoute.meta, route.meta)
 	} else {
 		route.Method = method
 		// Add route to the stack
@@ -749,6 +920,8 @@ func (app *App) addRoute(method string, route *Route) {
 			panic(err)
 		}
 	}
+
+	return stored
 }
 
 func (app *App) ensureAutoHeadRoutes() {
@@ -770,12 +943,13 @@ func (app *App) ensureAutoHeadRoutesLocked() {
 	}
 
 	headStack := app.stack[headIndex]
+	// versioned routes have a HEAD route per version
 	existing := make(map[string]struct{}, len(headStack))
 	for _, route := range headStack {
 		if route.mount || route.use {
 			continue
 		}
-		existing[route.path] = struct{}{}
+		existing[route.path+"\x00"+route.Version] = struct{}{}
 	}
 
 	if len(app.stack[getIndex]) == 0 {
@@ -788,7 +962,7 @@ func (app *App) ensureAutoHeadRoutesLocked() {
 		if route.mount || route.use {
 			continue
 		}
-		if _, ok := existing[route.path]; ok {
+		if _, ok := existing[route.path+"\x00"+route.Version]; ok {
 			continue
 		}
 
@@ -801,7 +975,7 @@ func (app *App) ensureAutoHeadRoutesLocked() {
 		// unchanged while still producing an empty body on the wire.
 
 		headStack = append(headStack, headRoute)
-		existing[route.path] = struct{}{}
+		existing[route.path+"\x00"+route.Version] = struct{}{}
 		app.hasRoutesRefreshed = true
 		added = true
 
@@ -818,7 +992,7 @@ func (app *App) ensureAutoHeadRoutesLocked() {
 	}
 }
 
-// RebuildTree rebuilds the prefix tree from the previously registered routes.
+// RebuildTree rebuilds the radix tree from the previously registered routes.
 // This method is useful when you want to register routes dynamically after the app has started.
 // It is not recommended to use this method on production environments because rebuilding
 // the tree is performance-intensive and not thread-safe in runtime. Since building the tree
@@ -827,73 +1001,34 @@ func (app *App) ensureAutoHeadRoutesLocked() {
 // Latest benchmark results showed a degradation from 82.79 ns/op to 94.48 ns/op and can be found in:
 // https://github.com/gofiber/fiber/issues/2769#issuecomment-2227385283
 func (app *App) RebuildTree() *App {
+	app.routesMutex.Lock()
+	defer app.routesMutex.Unlock()
 	app.mutex.Lock()
 	defer app.mutex.Unlock()
 
 	return app.buildTree()
 }
 
-// buildTree build the prefix tree from the previously registered routes
+// buildTree builds the radix tree from the previously registered routes
 func (app *App) buildTree() *App {
 	// If routes haven't been refreshed, nothing to do
 	if !app.hasRoutesRefreshed {
 		return app
 	}
 
-	// 1) First loop: determine all possible 3-char prefixes ("treePaths") for each method
+	table := &routeTable{
+		trees:           make([]*routeTree, len(app.config.RequestMethods)),
+		versions:        newRouteVersions(app.stack),
+		hasRouteConfigs: app.hasRouteConfigs(),
+	}
 	for method := range app.config.RequestMethods {
-		routes := app.stack[method]
-		treePaths := make([]int, len(routes))
-
-		globalCount := 0
-		prefixCounts := make(map[int]int, len(routes))
-
-		for i, route := range routes {
-			if len(route.routeParser.segs) > 0 && len(route.routeParser.segs[0].Const) >= maxDetectionPaths {
-				treePaths[i] = int(route.routeParser.segs[0].Const[0])<<16 |
-					int(route.routeParser.segs[0].Const[1])<<8 |
-					int(route.routeParser.segs[0].Const[2])
-			}
-
-			if treePaths[i] == 0 {
-				globalCount++
-				continue
-			}
-
-			prefixCounts[treePaths[i]]++
-		}
-
-		prevBuckets := app.treeStack[method]
-		tsMap := make(map[int][]*Route, len(prefixCounts)+1)
-		tsMap[0] = reuseRouteBucket(prevBuckets, 0, globalCount)
-		for treePath, count := range prefixCounts {
-			tsMap[treePath] = reuseRouteBucket(prevBuckets, treePath, count+globalCount)
-		}
-
-		for i, route := range routes {
-			treePath := treePaths[i]
-
-			if treePath == 0 {
-				for bucket := range tsMap {
-					tsMap[bucket] = append(tsMap[bucket], route)
-				}
-				continue
-			}
-
-			tsMap[treePath] = append(tsMap[treePath], route)
-		}
-
-		app.treeStack[method] = tsMap
+		table.trees[method] = newRouteTree(app.stack[method])
 	}
+	// swap the whole table, requests in flight keep the one they started with
+	app.routes.Store(table)
+	app.routesGeneration.Add(1)
 
 	// reset the flag and return
 	app.hasRoutesRefreshed = false
 	return app
 }
-
-func reuseRouteBucket(prev map[int][]*Route, key, capHint int) []*Route {
-	if bucket, ok := prev[key]; ok && cap(bucket) >= capHint {
-		return bucket[:0]
-	}
-	return make([]*Route, 0, capHint)
-}
@@ -0,0 +1,393 @@
+package fiber
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RouteIssueKind identifies the kind of problem found by the route analysis.
+type RouteIssueKind int
+
+const (
+	// RouteIssueShadowed reports a route that can never be reached because a
+	// route registered before it matches every path it matches.
+	RouteIssueShadowed RouteIssueKind = iota
+	// RouteIssueDuplicate reports a route with the same method and pattern as
+	// a route registered before it.
+	RouteIssueDuplicate
+	// RouteIssueImpossibleConstraint reports a route parameter whose
+	// constraints can never be satisfied at the same time, e.g. min(10) and max(5).
+	RouteIssueImpossibleConstraint
+)
+
+// String returns a human-readable name of the kind.
+func (k RouteIssueKind) String() string {
+	switch k {
+	case RouteIssueShadowed:
+		return "Shadowed route"
+	case RouteIssueDuplicate:
+		return "Duplicate route"
+	case RouteIssueImpossibleConstraint:
+		return "Impossible constraint"
+	default:
+		return "Unknown route issue"
+	}
+}
+
+// RouteIssue describes a problem found by the route analysis at startup.
+type RouteIssue struct {
+	Path    string         // Registered path of the affected route
+	By      string         // Registered path of the earlier route causing the issue, empty for constraints
+	Message string         // Description of the problem
+	Methods []string       // HTTP methods the issue applies to
+	Kind    RouteIssueKind // Kind of the issue
+}
+
+// String returns the issue in the form used by the startup message.
+func (i RouteIssue) String() string {
+	return fmt.Sprintf("%s %s: %s", strings.Join(i.Methods, ","), i.Path, i.Message)
+}
+
+// RouteIssues returns the problems found by the route analysis during the
+// last startup. Shadowed routes, duplicate patterns and impossible constraints
+// are reported. The analysis runs when the app starts, e.g. on Listen or Test.
+func (app *App) RouteIssues() []RouteIssue {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	return slices.Clone(app.routeIssues)
+}
+
+// routeIssuesError returns an error listing the route issues when startup
+// should fail because of them.
+func (app *App) routeIssuesError() error {
+	if !app.config.FailOnRouteIssues || len(app.routeIssues) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(app.routeIssues))
+	for i, issue := range app.routeIssues {
+		lines[i] = issue.String()
+	}
+	return fmt.Errorf("%w:\n%s", ErrRouteIssues, strings.Join(lines, "\n"))
+}
+
+// analyzeRoutes inspects the route stacks for shadowed routes, duplicate
+// patterns and impossible constraints. Issues that apply to several methods,
+// e.g. for routes registered with All, are reported once.
+func (app *App) analyzeRoutes() []RouteIssue {
+	var issues []RouteIssue
+	index := make(map[string]int)
+
+	add := func(method string, route *Route, by *Route, kind RouteIssueKind, message string) {
+		issue := RouteIssue{Kind: kind, Path: route.Path, Message: message}
+		if by != nil {
+			issue.By = by.Path
+		}
+		key := strconv.Itoa(int(kind)) + "\x00" + issue.Path + "\x00" + issue.By + "\x00" + message
+		if i, ok := index[key]; ok {
+			if !slices.Contains(issues[i].Methods, method) {
+				issues[i].Methods = append(issues[i].Methods, method)
+			}
+			return
+		}
+		issue.Methods = []string{method}
+		index[key] = len(issues)
+		issues = append(issues, issue)
+	}
+
+	for m, stack := range app.stack {
+		method := app.method(m)
+
+		var endpoints []*Route
+		var parts [][]routePart
+		for _, route := range stack {
+			// middleware and mounted apps pass requests on, auto HEAD routes mirror GET,
+			// and domain routes only run for their hostname
+			if route.use || route.mount || route.autoHead || route.domain {
+				continue
+			}
+
+			for _, seg := range route.routeParser.segs {
+				if seg.IsParam {
+					if message := impossibleConstraints(seg); message != "" {
+						add(method, route, nil, RouteIssueImpossibleConstraint,
+							fmt.Sprintf("constraints of parameter %q can never match: %s", seg.ParamName, message))
+					}
+				}
+			}
+
+			routeParts, ok := splitRouteParts(route.routeParser.segs)
+			if !ok {
+				continue
+			}
+
+			for i, earlier := range endpoints {
+				// versioned routes pass requests for other versions on
+				if earlier.Version != "" && earlier.Version != route.Version {
+					continue
+				}
+				if !coversParts(parts[i], routeParts) {
+					continue
+				}
+				if coversParts(routeParts, parts[i]) {
+					add(method, route, earlier, RouteIssueDuplicate,
+						fmt.Sprintf("same pattern as %s registered before", earlier.Path))
+				} else {
+					add(method, route, earlier, RouteIssueShadowed,
+						fmt.Sprintf("never reached, %s registered before matches all its paths", earlier.Path))
+				}
+				break
+			}
+
+			endpoints = append(endpoints, route)
+			parts = append(parts, routeParts)
+		}
+	}
+
+	return issues
+}
+
+// routePart is a slash-separated part of a route path.
+type routePart struct {
+	param   *routeSegment // nil for constant parts
+	literal string
+}
+
+// splitRouteParts splits the parsed route into its slash-separated parts.
+// Routes with parts that mix constants and parameters, such as "/:name.:ext",
+// or with greedy parameters that are not the last part are not supported.
+func splitRouteParts(segs []*routeSegment) ([]routePart, bool) {
+	var parts []routePart
+	var current strings.Builder
+	hasParam := false
+	started := false
+	endsWithSlash := false
+
+	for i, seg := range segs {
+		if seg.IsParam {
+			if current.Len() > 0 || hasParam || (seg.IsGreedy && i != len(segs)-1) {
+				return nil, false
+			}
+			parts = append(parts, routePart{param: seg})
+			hasParam = true
+			started = true
+			endsWithSlash = false
+			continue
+		}
+
+		for j := range len(seg.Const) {
+			c := seg.Const[j]
+			endsWithSlash = c == '/'
+			if c != '/' {
+				if hasParam {
+					return nil, false
+				}
+				current.WriteByte(c)
+				continue
+			}
+			// the leading slash does not end a part
+			if started && !hasParam {
+				parts = append(parts, routePart{literal: current.String()})
+			}
+			current.Reset()
+			hasParam = false
+			started = true
+		}
+	}
+	// a trailing slash is significant with StrictRouting, e.g. "/users/"
+	if current.Len() > 0 || (endsWithSlash && len(parts) > 0) {
+		parts = append(parts, routePart{literal: current.String()})
+	}
+
+	return parts, true
+}
+
+// coversParts reports whether every path matched by b is also matched by a.
+// Only the cases that can be decided safely report true.
+func coversParts(a, b []routePart) bool {
+	if len(a) == 0 {
+		return len(b) == 0
+	}
+
+	pa := a[0]
+	if pa.param == nil {
+		return len(b) > 0 && b[0].param == nil && b[0].literal == pa.literal && coversParts(a[1:], b[1:])
+	}
+
+	if pa.param.IsGreedy {
+		if len(pa.param.Constraints) > 0 {
+			return false
+		}
+		// greedy parameters are always the last part
+		if pa.param.IsOptional {
+			return true
+		}
+		return len(b) > 0 && !matchesEmpty(b)
+	}
+
+	if len(b) == 0 {
+		return pa.param.IsOptional && coversParts(a[1:], b)
+	}
+
+	pb := b[0]
+	switch {
+	case pb.param == nil:
+		if !paramAccepts(pa.param, pb.literal) {
+			return false
+		}
+	case pb.param.IsGreedy:
+		return false
+	case pb.param.IsOptional && !pa.param.IsOptional:
+		return false
+	default:
+		if !constraintsSubset(pa.param.Constraints, pb.param.Constraints) {
+			return false
+		}
+	}
+
+	return coversParts(a[1:], b[1:])
+}
+
+// matchesEmpty reports whether the parts can match an empty remainder.
+func matchesEmpty(parts []routePart) bool {
+	for _, part := range parts {
+		if part.param == nil && part.literal != "" {
+			return false
+		}
+		if part.param != nil && !part.param.IsOptional {
+			return false
+		}
+	}
+	return true
+}
+
+// paramAccepts reports whether the parameter matches the constant part.
+func paramAccepts(param *routeSegment, literal string) bool {
+	if literal == "" {
+		return false
+	}
+	for _, c := range param.Constraints {
+		if !c.matchConstraint(RemoveEscapeChar(literal)) {
+			return false
+		}
+	}
+	return true
+}
+
+// constraintsSubset reports whether all constraints of a are also declared in b.
+func constraintsSubset(a, b []*Constraint) bool {
+	for _, ca := range a {
+		found := slices.ContainsFunc(b, func(cb *Constraint) bool {
+			return constraintName(ca) == constraintName(cb) && slices.Equal(ca.Data, cb.Data)
+		})
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func constraintName(c *Constraint) string {
+	if c.handler != nil {
+		return c.handler.Name()
+	}
+	return resolveConstraintName(c.Name)
+}
+
+// impossibleConstraints returns a description of the conflict when the
+// constraints of the parameter can never be satisfied together.
+func impossibleConstraints(seg *routeSegment) string {
+	if len(seg.Constraints) < 2 && !hasInvalidBounds(seg.Constraints) {
+		return ""
+	}
+
+	numLo, numHi := math.MinInt, math.MaxInt
+	lenLo, lenHi := 0, math.MaxInt
+	var numeric, alpha bool
+
+	for _, c := range seg.Constraints {
+		args := constraintIntArgs(c)
+		switch constraintName(c) {
+		case ConstraintInt:
+			numeric = true
+		case ConstraintAlpha:
+			alpha = true
+		case ConstraintMin:
+			numeric = true
+			if len(args) == 1 {
+				numLo = max(numLo, args[0])
+			}
+		case ConstraintMax:
+			numeric = true
+			if len(args) == 1 {
+				numHi = min(numHi, args[0])
+			}
+		case ConstraintRange:
+			numeric = true
+			if len(args) == 2 {
+				numLo, numHi = max(numLo, args[0]), min(numHi, args[1])
+			}
+		case ConstraintMinLen:
+			if len(args) == 1 {
+				lenLo = max(lenLo, args[0])
+			}
+		case ConstraintMaxLen:
+			if len(args) == 1 {
+				lenHi = min(lenHi, args[0])
+			}
+		case ConstraintLen:
+			if len(args) == 1 {
+				lenLo, lenHi = max(lenLo, args[0]), min(lenHi, args[0])
+			}
+		case ConstraintBetweenLen:
+			if len(args) == 2 {
+				lenLo, lenHi = max(lenLo, args[0]), min(lenHi, args[1])
+			}
+		default:
+		}
+	}
+
+	switch {
+	case numLo > numHi:
+		return fmt.Sprintf("value must be at least %d and at most %d", numLo, numHi)
+	case lenLo > lenHi:
+		return fmt.Sprintf("length must be at least %d and at most %d", lenLo, lenHi)
+	case numeric && alpha:
+		return "value must be an integer and alphabetic"
+	default:
+		return ""
+	}
+}
+
+// hasInvalidBounds reports whether a single range or betweenLen constraint
+// has a lower bound greater than its upper bound.
+func hasInvalidBounds(constraints []*Constraint) bool {
+	for _, c := range constraints {
+		switch constraintName(c) {
+		case ConstraintRange, ConstraintBetweenLen:
+			if args := constraintIntArgs(c); len(args) == 2 && args[0] > args[1] {
+				return true
+			}
+		default:
+		}
+	}
+	return false
+}
+
+// constraintIntArgs returns the integer arguments of the constraint, nil if
+// they could not be parsed.
+func constraintIntArgs(c *Constraint) []int {
+	args := make([]int, 0, len(c.typedData))
+	for _, data := range c.typedData {
+		n, ok := data.(int)
+		if !ok {
+			return nil
+		}
+		args = append(args, n)
+	}
+	return args
+}
@@ -0,0 +1,169 @@
+package fiber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func analyzedIssues(t *testing.T, app *App) []RouteIssue {
+	t.Helper()
+	require.NoError(t, app.startupProcess())
+	return app.RouteIssues()
+}
+
+// go test -run Test_RouteAnalysis_Shadowed
+func Test_RouteAnalysis_Shadowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		first  string
+		second string
+		issue  bool
+	}{
+		{first: "/users/:id", second: "/users/me", issue: true},
+		{first: "/users/:id<int>", second: "/users/me"},
+		{first: "/users/:id<int>", second: "/users/42", issue: true},
+		{first: "/users/:id", second: "/users/:id<int>", issue: true},
+		{first: "/users/:id<int>", second: "/users/:id"},
+		{first: "/files/*", second: "/files/docs/readme", issue: true},
+		{first: "/files/*", second: "/files", issue: true},
+		{first: "/files/+", second: "/files"},
+		{first: "/files/+", second: "/files/:name", issue: true},
+		{first: "/*", second: "/", issue: true},
+		{first: "/posts/:slug?", second: "/posts", issue: true},
+		{first: "/posts/:slug", second: "/posts"},
+		{first: "/posts/:slug", second: "/posts/:slug?"},
+		{first: "/:a/:b", second: "/users/:id/edit"},
+		{first: "/:name.:ext", second: "/readme.md"},
+		{first: "/users/me", second: "/users/:id"},
+	}
+
+	for _, tc := range testCases {
+		app := New()
+		app.Get(tc.first, testEmptyHandler)
+		app.Get(tc.second, testEmptyHandler)
+
+		issues := analyzedIssues(t, app)
+		if !tc.issue {
+			require.Empty(t, issues, "%s before %s", tc.first, tc.second)
+			continue
+		}
+		require.Len(t, issues, 1, "%s before %s", tc.first, tc.second)
+		require.Equal(t, RouteIssueShadowed, issues[0].Kind)
+		require.Equal(t, tc.second, issues[0].Path)
+		require.Equal(t, tc.first, issues[0].By)
+		require.Equal(t, []string{MethodGet}, issues[0].Methods)
+	}
+}
+
+// go test -run Test_RouteAnalysis_Duplicate
+func Test_RouteAnalysis_Duplicate(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.All("/users/:id", testEmptyHandler)
+	app.Get("/health", testEmptyHandler)
+	app.All("/users/:name", testEmptyHandler)
+	app.Post("/users/list", testEmptyHandler)
+	app.Get("/items/", testEmptyHandler)
+
+	issues := analyzedIssues(t, app)
+	require.Len(t, issues, 2)
+
+	require.Equal(t, RouteIssueDuplicate, issues[0].Kind)
+	require.Equal(t, "/users/:name", issues[0].Path)
+	require.Equal(t, "/users/:id", issues[0].By)
+	require.Equal(t, app.config.RequestMethods, issues[0].Methods)
+	require.Equal(t, "GET,HEAD,POST,PUT,DELETE,CONNECT,OPTIONS,TRACE,PATCH,QUERY /users/:name: same pattern as /users/:id registered before", issues[0].String())
+
+	require.Equal(t, RouteIssueShadowed, issues[1].Kind)
+	require.Equal(t, "/users/list", issues[1].Path)
+	require.Equal(t, []string{MethodPost}, issues[1].Methods)
+
+	// trailing slashes are significant with strict routing
+	app = New(Config{StrictRouting: true})
+	app.Get("/items", testEmptyHandler)
+	app.Get("/items/", testEmptyHandler)
+	require.Empty(t, analyzedIssues(t, app))
+}
+
+// go test -run Test_RouteAnalysis_ImpossibleConstraint
+func Test_RouteAnalysis_ImpossibleConstraint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		path    string
+		message string
+	}{
+		{path: "/:n<min(10);max(5)>", message: "value must be at least 10 and at most 5"},
+		{path: "/:n<range(9,1)>", message: "value must be at least 9 and at most 1"},
+		{path: "/:n<range(1,9);min(20)>", message: "value must be at least 20 and at most 9"},
+		{path: "/:s<minLen(5);maxLen(2)>", message: "length must be at least 5 and at most 2"},
+		{path: "/:s<len(3);minLen(4)>", message: "length must be at least 4 and at most 3"},
+		{path: "/:s<betweenLen(8,2)>", message: "length must be at least 8 and at most 2"},
+		{path: "/:v<int;alpha>", message: "value must be an integer and alphabetic"},
+		{path: "/:v<min(1);max(10)>"},
+		{path: "/:v<minLen(1);len(3);maxLen(3)>"},
+		{path: "/:v<int>"},
+	}
+
+	for _, tc := range testCases {
+		app := New()
+		app.Get(tc.path, testEmptyHandler)
+
+		issues := analyzedIssues(t, app)
+		if tc.message == "" {
+			require.Empty(t, issues, tc.path)
+			continue
+		}
+		require.Len(t, issues, 1, tc.path)
+		require.Equal(t, RouteIssueImpossibleConstraint, issues[0].Kind)
+		require.Empty(t, issues[0].By)
+		require.Contains(t, issues[0].Message, tc.message)
+	}
+}
+
+// go test -run Test_RouteAnalysis_Skipped
+func Test_RouteAnalysis_Skipped(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Use("/users", func(c Ctx) error { return c.Next() })
+	app.Use(func(c Ctx) error { return c.Next() })
+	app.Domain("api.example.com").Get("/users/:id", testEmptyHandler)
+	app.Domain("www.example.com").Get("/users/:id", testEmptyHandler)
+	app.Get("/users/:id", testEmptyHandler)
+
+	require.Empty(t, analyzedIssues(t, app))
+}
+
+// go test -run Test_RouteAnalysis_FailOnRouteIssues
+func Test_RouteAnalysis_FailOnRouteIssues(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{FailOnRouteIssues: true})
+	app.Get("/users/:id", testEmptyHandler)
+	app.Get("/users/me", testEmptyHandler)
+
+	_, err := app.Test(httptest.NewRequest(MethodGet, "/users/me", http.NoBody))
+	require.ErrorIs(t, err, ErrRouteIssues)
+	require.ErrorContains(t, err, "GET /users/me: never reached, /users/:id registered before matches all its paths")
+
+	// the handler of the app is not served
+	fctx := &fasthttp.RequestCtx{}
+	fctx.Request.SetRequestURI("/users/me")
+	app.Handler()(fctx)
+	require.Equal(t, StatusInternalServerError, fctx.Response.StatusCode())
+
+	app = New(Config{FailOnRouteIssues: true})
+	app.Get("/users/me", testEmptyHandler)
+	app.Get("/users/:id", testEmptyHandler)
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/users/me", http.NoBody))
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+}
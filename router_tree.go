@@ -0,0 +1,215 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 🤖 GitHub Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+package fiber
+
+import (
+	"slices"
+	"strings"
+)
+
+// routeTree is a compressed prefix tree (radix tree) built over the static
+// prefixes of the routes registered for a single HTTP method.
+//
+// Every route is stored under the longest constant prefix that any matching
+// detection path must start with. A lookup walks the tree along the detection
+// path and returns the precomputed candidate list of the deepest node reached.
+// The candidate list of a node contains the routes of all its ancestors, sorted
+// by their position in the route stack, so the registration-order precedence
+// of the stack is preserved and the final decision is still made by Route.match.
+type routeTree struct {
+	root *routeTreeNode
+}
+
+// routeTable holds the route trees of all methods. It is never modified after
+// it was built, a route change builds a new table that replaces it as a whole.
+type routeTable struct {
+	trees           []*routeTree  // one tree per method, indexed like the route stack
+	versions        routeVersions // versions of the routes registered with Router.Version
+	hasRouteConfigs bool          // some routes override the app config with a RouteConfig
+}
+
+// find returns the candidate routes of the method for the detection path in
+// stack order.
+func (t *routeTable) find(methodInt int, detectionPath string) []*Route {
+	if t == nil {
+		return nil
+	}
+	return t.trees[methodInt].find(detectionPath)
+}
+
+// routeTreeNode is a single node of the routeTree.
+type routeTreeNode struct {
+	prefix   string           // edge label leading to this node
+	indices  string           // first byte of each child's prefix, same order as children
+	children []*routeTreeNode // child nodes
+
+	// routes contains the candidates for every detection path that passes
+	// through this node, in stack order
+	routes []*Route
+	// exact contains the candidates for a detection path that ends exactly
+	// at this node, in stack order (a superset of routes)
+	exact []*Route
+
+	// positions of the routes owned by this node, only used while building
+	ownPrefix []int
+	ownExact  []int
+}
+
+// routeTreeKey returns the constant prefix every detection path matched by the
+// route must start with. The second result reports whether the route can only
+// match a detection path equal to that prefix. The rules mirror Route.match.
+func routeTreeKey(route *Route) (string, bool) {
+	switch {
+	case route.star:
+		return "", false
+	case len(route.Params) > 0:
+		segs := route.routeParser.segs
+		if len(segs) == 0 || segs[0].IsParam {
+			return "", false
+		}
+		key := segs[0].Const
+		// the trailing slash of the segment is optional in front of optional parameters
+		if segs[0].HasOptionalSlash && key != "" {
+			key = key[:len(key)-1]
+		}
+		return key, false
+	case route.use:
+		if route.root {
+			return "", false
+		}
+		return route.path, false
+	default:
+		return route.path, true
+	}
+}
+
+// newRouteTree builds a routeTree from the routes of one method stack.
+func newRouteTree(routes []*Route) *routeTree {
+	tree := &routeTree{root: &routeTreeNode{}}
+
+	for i, route := range routes {
+		key, exact := routeTreeKey(route)
+		node := tree.root.insert(key)
+		if exact {
+			node.ownExact = append(node.ownExact, i)
+		} else {
+			node.ownPrefix = append(node.ownPrefix, i)
+		}
+	}
+
+	tree.root.finalize(routes, nil, nil)
+
+	return tree
+}
+
+// insert returns the node for the given key, splitting edges where needed.
+func (n *routeTreeNode) insert(key string) *routeTreeNode {
+	for key != "" {
+		idx := strings.IndexByte(n.indices, key[0])
+		if idx == -1 {
+			child := &routeTreeNode{prefix: key}
+			n.indices += string(key[0])
+			n.children = append(n.children, child)
+			return child
+		}
+
+		child := n.children[idx]
+		common := commonPrefixLength(key, child.prefix)
+		if common < len(child.prefix) {
+			// split the edge at the common prefix
+			split := &routeTreeNode{
+				prefix:   child.prefix[:common],
+				indices:  string(child.prefix[common]),
+				children: []*routeTreeNode{child},
+			}
+			child.prefix = child.prefix[common:]
+			n.children[idx] = split
+			child = split
+		}
+
+		key = key[common:]
+		n = child
+	}
+
+	return n
+}
+
+// finalize computes the candidate lists of the node and its descendants.
+// inherited holds the stack positions of all prefix routes owned by the
+// ancestors and inheritedRoutes the matching resolved routes.
+func (n *routeTreeNode) finalize(routes []*Route, inherited []int, inheritedRoutes []*Route) {
+	positions := inherited
+	n.routes = inheritedRoutes
+	if len(n.ownPrefix) > 0 {
+		positions = mergeRoutePositions(inherited, n.ownPrefix)
+		n.routes = routesAtPositions(routes, positions)
+	}
+
+	n.exact = n.routes
+	if len(n.ownExact) > 0 {
+		n.exact = routesAtPositions(routes, mergeRoutePositions(positions, n.ownExact))
+	}
+
+	n.ownPrefix, n.ownExact = nil, nil
+
+	for _, child := range n.children {
+		child.finalize(routes, positions, n.routes)
+	}
+}
+
+// find returns the candidate routes for the detection path in stack order.
+func (t *routeTree) find(detectionPath string) []*Route {
+	if t == nil {
+		return nil
+	}
+
+	n := t.root
+	for detectionPath != "" {
+		idx := strings.IndexByte(n.indices, detectionPath[0])
+		if idx == -1 {
+			return n.routes
+		}
+		child := n.children[idx]
+		if len(detectionPath) < len(child.prefix) || detectionPath[:len(child.prefix)] != child.prefix {
+			return n.routes
+		}
+		detectionPath = detectionPath[len(child.prefix):]
+		n = child
+	}
+
+	return n.exact
+}
+
+// commonPrefixLength returns the length of the common prefix of a and b.
+func commonPrefixLength(a, b string) int {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// mergeRoutePositions merges two ascending lists of stack positions.
+func mergeRoutePositions(a, b []int) []int {
+	merged := make([]int, 0, len(a)+len(b))
+	merged = append(merged, a...)
+	merged = append(merged, b...)
+	slices.Sort(merged)
+	return merged
+}
+
+// routesAtPositions resolves stack positions to their routes.
+func routesAtPositions(routes []*Route, positions []int) []*Route {
+	if len(positions) == 0 {
+		return nil
+	}
+	resolved := make([]*Route, len(positions))
+	for i, pos := range positions {
+		resolved[i] = routes[pos]
+	}
+	return resolved
+}
@@ -0,0 +1,235 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 📃 GitHub Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+package fiber
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+// linearRouteMatches returns all routes of the stack matching the path in stack order,
+// which is the reference behavior the radix tree has to reproduce.
+func linearRouteMatches(routes []*Route, detectionPath, path string) []*Route {
+	var params [maxParams]string
+	var matched []*Route
+	for _, route := range routes {
+		if route.match(detectionPath, path, &params) {
+			matched = append(matched, route)
+		}
+	}
+	return matched
+}
+
+// treeRouteMatches returns all candidates of the tree matching the path in candidate order.
+func treeRouteMatches(tree *routeTree, detectionPath, path string) []*Route {
+	var params [maxParams]string
+	var matched []*Route
+	for _, route := range tree.find(detectionPath) {
+		if route.match(detectionPath, path, &params) {
+			matched = append(matched, route)
+		}
+	}
+	return matched
+}
+
+func Test_RouteTree_MatchesLinearScan(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	h := func(c Ctx) error {
+		return c.Next()
+	}
+	app.Use(h)
+	app.Use("/repos", h)
+	app.Get("/:owner", h)
+	app.Get("/api/:id?", h)
+	app.Get("/files/*", h)
+	app.Get("/assets/+", h)
+	app.Get("/shop/product/color::color/size::size", h)
+	app.Get("/v1/some/resource/name\\:customVerb", h)
+	app.Get("/config/:key<minLen(2)>", h)
+	app.Get("/*", h)
+	registerDummyRoutes(app)
+	app.startupProcess()
+
+	paths := []string{
+		"/", "/api", "/api/", "/api/1", "/api/1/2", "/files", "/files/a/b", "/assets", "/assets/x/y",
+		"/shop/product/color:blue/size:xs", "/v1/some/resource/name:customverb", "/config/a", "/config/ab",
+		"/repos", "/reposx", "/repos/gofiber/fiber", "/user/keys/1337", "/user", "/users", "/does/not/exist",
+	}
+	for _, r := range routesFixture.TestRoutes {
+		paths = append(paths, r.Path)
+	}
+
+	for m := range app.config.RequestMethods {
+		for _, p := range paths {
+			c := app.AcquireCtx(&fasthttp.RequestCtx{})
+			c.Path(p)
+			detectionPath := c.getDetectionPath()
+			expected := linearRouteMatches(app.stack[m], detectionPath, c.Path())
+			actual := treeRouteMatches(app.routes.Load().trees[m], detectionPath, c.Path())
+			app.ReleaseCtx(c)
+			require.Equal(t, expected, actual, "method %s path %s", app.config.RequestMethods[m], p)
+		}
+	}
+}
+
+func Test_RouteTree_RegistrationOrder(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Get("/users/:id", func(c Ctx) error {
+		return c.SendString("param " + c.Params("id"))
+	})
+	app.Get("/users/me", func(c Ctx) error {
+		return c.SendString("me")
+	})
+	app.Get("/teams/lead", func(c Ctx) error {
+		return c.SendString("lead")
+	})
+	app.Get("/teams/:id", func(c Ctx) error {
+		return c.SendString("param " + c.Params("id"))
+	})
+
+	testCases := []struct {
+		path string
+		body string
+	}{
+		{path: "/users/me", body: "param me"},
+		{path: "/users/42", body: "param 42"},
+		{path: "/teams/lead", body: "lead"},
+		{path: "/teams/42", body: "param 42"},
+	}
+
+	for _, tc := range testCases {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, tc.path, nil))
+		require.NoError(t, err)
+		require.Equal(t, StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, tc.body, string(body))
+	}
+}
+
+func Test_RouteTree_MiddlewareBoundary(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Use("/api", func(c Ctx) error {
+		c.Set("X-Api", "true")
+		return c.Next()
+	})
+	app.Get("/api/users", func(c Ctx) error {
+		return c.SendString("users")
+	})
+	app.Get("/apiv2", func(c Ctx) error {
+		return c.SendString("v2")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/api/users", nil))
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+	require.Equal(t, "true", resp.Header.Get("X-Api"))
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/apiv2", nil))
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("X-Api"))
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/API/Users", nil))
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+	require.Equal(t, "true", resp.Header.Get("X-Api"))
+}
+
+func Test_RouteTree_Split(t *testing.T) {
+	t.Parallel()
+
+	routes := []*Route{
+		{path: "/api/v1/users", Path: "/api/v1/users"},
+		{path: "/api/v1/teams", Path: "/api/v1/teams"},
+		{path: "/api", Path: "/api", use: true},
+		{path: "/api/v2", Path: "/api/v2"},
+	}
+	tree := newRouteTree(routes)
+
+	require.Equal(t, []*Route{routes[0], routes[2]}, tree.find("/api/v1/users"))
+	require.Equal(t, []*Route{routes[1], routes[2]}, tree.find("/api/v1/teams"))
+	require.Equal(t, []*Route{routes[2], routes[3]}, tree.find("/api/v2"))
+	require.Equal(t, []*Route{routes[2]}, tree.find("/api/v1"))
+	require.Equal(t, []*Route{routes[2]}, tree.find("/api/v3"))
+	require.Empty(t, tree.find("/ap"))
+	require.Empty(t, tree.find("/other"))
+
+	var nilTree *routeTree
+	require.Empty(t, nilTree.find("/api"))
+}
+
+func registerVersionedRoutes(app *App, count int) {
+	h := func(_ Ctx) error {
+		return nil
+	}
+	for i := range count {
+		app.Get("/api/v1/resource"+strconv.Itoa(i)+"/:id", h)
+	}
+}
+
+// go test -v ./... -run=^$ -bench=Benchmark_Router_Next_RouteCount -benchmem -count=4
+func Benchmark_Router_Next_RouteCount(b *testing.B) {
+	for _, count := range []int{10, 100, 1000, 1800, 10000} {
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			app := New()
+			registerVersionedRoutes(app, count)
+			app.startupProcess()
+
+			request := &fasthttp.RequestCtx{}
+			request.Request.Header.SetMethod(MethodGet)
+			request.URI().SetPath("/api/v1/resource" + strconv.Itoa(count-1) + "/1337")
+
+			c := acquireDefaultCtxForRouterBenchmark(b, app, request)
+
+			var (
+				res bool
+				err error
+			)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				c.indexRoute = -1
+				res, err = app.next(c)
+			}
+			require.NoError(b, err)
+			require.True(b, res)
+			require.Equal(b, "1337", c.Params("id"))
+		})
+	}
+}
+
+// go test -v ./... -run=^$ -bench=Benchmark_RouteTree_Find -benchmem -count=4
+func Benchmark_RouteTree_Find(b *testing.B) {
+	for _, count := range []int{10, 100, 1000, 1800, 10000} {
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			app := New()
+			registerVersionedRoutes(app, count)
+			app.startupProcess()
+
+			tree := app.routes.Load().trees[app.methodInt(MethodGet)]
+			detectionPath := "/api/v1/resource" + strconv.Itoa(count/2) + "/1337"
+
+			var candidates []*Route
+
+			b.ReportAllocs()
+			for b.Loop() {
+				candidates = tree.find(detectionPath)
+			}
+			require.NotEmpty(b, candidates)
+		})
+	}
+}
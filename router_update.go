@@ -0,0 +1,244 @@
+package fiber
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+)
+
+// RouteTx stages route changes inside UpdateRoutes. Routes registered or
+// removed through it only become visible to requests once the update commits.
+// Mounting sub-apps is not supported.
+type RouteTx interface {
+	Router
+
+	// RemoveRoute stages the removal of the routes with the given path.
+	// If no methods are specified, the route is removed for all methods.
+	RemoveRoute(path string, methods ...string)
+	// RemoveRouteByName stages the removal of the routes with the given name.
+	RemoveRouteByName(name string, methods ...string)
+	// RemoveRouteFunc stages the removal of the routes matched by matchFunc.
+	RemoveRouteFunc(matchFunc func(r *Route) bool, methods ...string)
+}
+
+// UpdateRoutes adds and removes routes at runtime as a single transaction.
+// The changes made through tx are staged on a copy of the route stack, checked
+// like at startup and then swapped in with the rebuilt route tree in one step.
+// Requests keep being served during the update and see either all or none of
+// the changes, requests in flight finish with the routes they started with.
+//
+// When fn returns an error, a registration panics or the route analysis fails
+// with Config.FailOnRouteIssues, nothing is applied and the error is returned.
+// Updates are serialized, fn must not call UpdateRoutes or RebuildTree and
+// must not keep using the routers returned by tx after it returned.
+//
+//	err := app.UpdateRoutes(func(tx fiber.RouteTx) error {
+//		tx.RemoveRouteByName("plugin.v1")
+//		tx.Get("/plugin", pluginV2).Name("plugin.v2")
+//		return nil
+//	})
+func (app *App) UpdateRoutes(fn func(tx RouteTx) error) error {
+	app.routesMutex.Lock()
+	defer app.routesMutex.Unlock()
+
+	app.mutex.Lock()
+	snapshot := app.stageRoutes()
+	app.mutex.Unlock()
+
+	if err := runRouteTx(fn, &routeTx{app: app}); err != nil {
+		app.mutex.Lock()
+		app.restoreRoutes(snapshot)
+		app.mutex.Unlock()
+		return err
+	}
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	app.ensureAutoHeadRoutesLocked()
+	issues := app.analyzeRoutes()
+	previousIssues := app.routeIssues
+	app.routeIssues = issues
+	if err := app.routeIssuesError(); err != nil {
+		app.routeIssues = previousIssues
+		app.restoreRoutes(snapshot)
+		return err
+	}
+
+	app.hasRoutesRefreshed = true
+	app.buildTree()
+
+	return nil
+}
+
+// runRouteTx runs fn and turns registration panics into errors.
+//
+//nolint:nonamedreturns // the recovered panic is returned through err
+func runRouteTx(fn func(tx RouteTx) error, tx *routeTx) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrRouteUpdate, r)
+		}
+	}()
+	return fn(tx)
+}
+
+// routeSnapshot holds the route state replaced while an update is staged.
+type routeSnapshot struct {
+	stack              [][]*Route
+	latestRoute        *Route
+	latestRoutes       []*Route
+	handlersCount      uint32
+	hasRoutesRefreshed bool
+}
+
+// stageRoutes replaces the route stack with a copy the update can modify while
+// requests are matched against the current route table. Routes are copied as
+// well, because registering can change the last route of a stack and naming
+// changes existing routes. The caller must hold app.mutex.
+func (app *App) stageRoutes() routeSnapshot {
+	snapshot := routeSnapshot{
+		stack:              app.stack,
+		latestRoute:        app.latestRoute,
+		latestRoutes:       app.latestRoutes,
+		handlersCount:      atomic.LoadUint32(&app.handlersCount),
+		hasRoutesRefreshed: app.hasRoutesRefreshed,
+	}
+
+	staged := make([][]*Route, len(app.stack))
+	latestRoutes := slices.Clone(app.latestRoutes)
+	for m, routes := range app.stack {
+		staged[m] = make([]*Route, len(routes))
+		for i, route := range routes {
+			copied := *route
+			staged[m][i] = &copied
+			if route == app.latestRoute {
+				app.latestRoute = &copied
+			}
+			if j := slices.Index(latestRoutes, route); j >= 0 {
+				latestRoutes[j] = &copied
+			}
+		}
+	}
+	app.stack = staged
+	app.latestRoutes = latestRoutes
+
+	return snapshot
+}
+
+// restoreRoutes discards the staged routes. The caller must hold app.mutex.
+func (app *App) restoreRoutes(snapshot routeSnapshot) {
+	app.stack = snapshot.stack
+	app.latestRoute = snapshot.latestRoute
+	app.latestRoutes = snapshot.latestRoutes
+	atomic.StoreUint32(&app.handlersCount, snapshot.handlersCount)
+	app.hasRoutesRefreshed = snapshot.hasRoutesRefreshed
+}
+
+// routeTx implements RouteTx by registering on the staged route stack of the app.
+type routeTx struct {
+	app *App
+}
+
+var _ RouteTx = (*routeTx)(nil)
+
+func (tx *routeTx) Use(args ...any) Router {
+	for _, arg := range args {
+		if _, ok := arg.(*App); ok {
+			panic("update: mounting apps is not supported")
+		}
+	}
+	tx.app.Use(args...)
+	return tx
+}
+
+func (tx *routeTx) Get(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodGet}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Head(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodHead}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Post(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodPost}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Put(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodPut}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Delete(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodDelete}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Connect(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodConnect}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Options(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodOptions}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Trace(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodTrace}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Patch(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodPatch}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Query(path string, handler any, handlers ...any) Router {
+	return tx.Add([]string{MethodQuery}, path, handler, handlers...)
+}
+
+func (tx *routeTx) Add(methods []string, path string, handler any, handlers ...any) Router {
+	tx.app.Add(methods, path, handler, handlers...)
+	return tx
+}
+
+func (tx *routeTx) All(path string, handler any, handlers ...any) Router {
+	return tx.Add(tx.app.config.RequestMethods, path, handler, handlers...)
+}
+
+func (tx *routeTx) Group(prefix string, handlers ...any) Router {
+	return tx.app.Group(prefix, handlers...)
+}
+
+func (tx *routeTx) Domain(host string) Router {
+	return tx.app.Domain(host)
+}
+
+func (tx *routeTx) Version(version string, config ...VersionConfig) Router {
+	return tx.app.Version(version, config...)
+}
+
+func (tx *routeTx) RouteChain(path string) Register {
+	return tx.app.RouteChain(path)
+}
+
+func (tx *routeTx) Route(prefix string, fn func(router Router), name ...string) Router {
+	return tx.app.Route(prefix, fn, name...)
+}
+
+func (tx *routeTx) Name(name string) Router {
+	tx.app.Name(name)
+	return tx
+}
+
+func (tx *routeTx) Meta(key string, value any) Router {
+	tx.app.Meta(key, value)
+	return tx
+}
+
+func (tx *routeTx) RemoveRoute(path string, methods ...string) {
+	tx.app.RemoveRoute(path, methods...)
+}
+
+func (tx *routeTx) RemoveRouteByName(name string, methods ...string) {
+	tx.app.RemoveRouteByName(name, methods...)
+}
+
+func (tx *routeTx) RemoveRouteFunc(matchFunc func(r *Route) bool, methods ...string) {
+	tx.app.RemoveRouteFunc(matchFunc, methods...)
+}
@@ -0,0 +1,302 @@
+package fiber
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func updateRoutesRequest(t *testing.T, app *App, path string) (int, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(MethodGet, path, http.NoBody))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func sendStringHandler(s string) Handler {
+	return func(c Ctx) error {
+		return c.SendString(s)
+	}
+}
+
+// go test -run Test_App_UpdateRoutes
+func Test_App_UpdateRoutes(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Get("/plugin", sendStringHandler("v1")).Name("plugin")
+	app.Get("/keep", sendStringHandler("keep"))
+
+	err := app.UpdateRoutes(func(tx RouteTx) error {
+		tx.RemoveRouteByName("plugin")
+		tx.Get("/plugin", sendStringHandler("v2")).Name("plugin")
+		tx.Group("/api").Get("/users", sendStringHandler("users"))
+		tx.RemoveRoute("/keep", MethodHead)
+		return nil
+	})
+	require.NoError(t, err)
+
+	status, body := updateRoutesRequest(t, app, "/plugin")
+	require.Equal(t, StatusOK, status)
+	require.Equal(t, "v2", body)
+
+	status, body = updateRoutesRequest(t, app, "/api/users")
+	require.Equal(t, StatusOK, status)
+	require.Equal(t, "users", body)
+
+	status, _ = updateRoutesRequest(t, app, "/keep")
+	require.Equal(t, StatusOK, status)
+
+	route := app.GetRoute("plugin")
+	require.Equal(t, "/plugin", route.Path)
+	require.Equal(t, MethodGet, route.Method)
+
+	err = app.UpdateRoutes(func(tx RouteTx) error {
+		tx.RemoveRouteFunc(func(r *Route) bool {
+			return r.Path == "/api/users"
+		})
+		return nil
+	})
+	require.NoError(t, err)
+
+	status, _ = updateRoutesRequest(t, app, "/api/users")
+	require.Equal(t, StatusNotFound, status)
+}
+
+// go test -run Test_App_UpdateRoutes_Rollback
+func Test_App_UpdateRoutes_Rollback(t *testing.T) {
+	t.Parallel()
+
+	errAbort := errors.New("abort")
+
+	app := New(Config{FailOnRouteIssues: true})
+	app.Get("/users/:id", sendStringHandler("user")).Name("user")
+	_, _ = updateRoutesRequest(t, app, "/users/1")
+	handlersCount := app.HandlersCount()
+
+	testCases := []struct {
+		fn   func(tx RouteTx) error
+		err  error
+		name string
+	}{
+		{
+			name: "error",
+			fn: func(tx RouteTx) error {
+				tx.RemoveRouteByName("user")
+				tx.Get("/new", testEmptyHandler)
+				return errAbort
+			},
+			err: errAbort,
+		},
+		{
+			name: "panic",
+			fn: func(tx RouteTx) error {
+				tx.RemoveRouteByName("user")
+				tx.Get("/new", "not a handler")
+				return nil
+			},
+			err: ErrRouteUpdate,
+		},
+		{
+			name: "route issues",
+			fn: func(tx RouteTx) error {
+				tx.Get("/users/me", testEmptyHandler)
+				return nil
+			},
+			err: ErrRouteIssues,
+		},
+		{
+			name: "mount",
+			fn: func(tx RouteTx) error {
+				tx.Use("/sub", New())
+				return nil
+			},
+			err: ErrRouteUpdate,
+		},
+	}
+	for _, tc := range testCases {
+		err := app.UpdateRoutes(tc.fn)
+		require.ErrorIs(t, err, tc.err, tc.name)
+
+		status, body := updateRoutesRequest(t, app, "/users/me")
+		require.Equal(t, StatusOK, status, tc.name)
+		require.Equal(t, "user", body, tc.name)
+
+		status, _ = updateRoutesRequest(t, app, "/new")
+		require.Equal(t, StatusNotFound, status, tc.name)
+
+		require.Equal(t, handlersCount, app.HandlersCount(), tc.name)
+		require.Equal(t, "/users/:id", app.GetRoute("user").Path, tc.name)
+		require.Empty(t, app.RouteIssues(), tc.name)
+	}
+}
+
+// go test -race -run Test_App_UpdateRoutes_Concurrent
+func Test_App_UpdateRoutes_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const (
+		workers  = 8
+		swaps    = 200
+		requests = 2000
+	)
+
+	// every version registers a middleware and an endpoint, a request served
+	// by a half-applied table would see the two disagree or not be found
+	register := func(r Router, version int) {
+		v := strconv.Itoa(version)
+		r.Use(func(c Ctx) error {
+			c.Set("X-Version", v)
+			return c.Next()
+		})
+		r.Get("/version", sendStringHandler(v))
+		r.Get("/v"+v, sendStringHandler(v))
+	}
+
+	app := New()
+	register(app, 0)
+	handler := app.Handler()
+
+	var (
+		served atomic.Int64
+		wg     sync.WaitGroup
+	)
+	done := make(chan struct{})
+	failures := make(chan string, workers)
+
+	for range workers {
+		wg.Go(func() {
+			fctx := &fasthttp.RequestCtx{}
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				fctx.Request.Reset()
+				fctx.Response.Reset()
+				fctx.Request.Header.SetMethod(MethodGet)
+				fctx.Request.SetRequestURI("/version")
+				handler(fctx)
+
+				status := fctx.Response.StatusCode()
+				header := string(fctx.Response.Header.Peek("X-Version"))
+				body := string(fctx.Response.Body())
+				if status != StatusOK || header != body {
+					select {
+					case failures <- fmt.Sprintf("status %d, middleware version %q, endpoint version %q", status, header, body):
+					default:
+					}
+					return
+				}
+				served.Add(1)
+			}
+		})
+	}
+
+	// keep swapping until the workers served enough requests in between
+	version := 0
+	for version < swaps || served.Load() < requests {
+		version++
+		err := app.UpdateRoutes(func(tx RouteTx) error {
+			tx.RemoveRouteFunc(func(_ *Route) bool {
+				return true
+			})
+			register(tx, version)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	close(done)
+	wg.Wait()
+	close(failures)
+
+	for failure := range failures {
+		t.Error(failure)
+	}
+
+	status, body := updateRoutesRequest(t, app, "/version")
+	require.Equal(t, StatusOK, status)
+	require.Equal(t, strconv.Itoa(version), body)
+	status, _ = updateRoutesRequest(t, app, "/v1")
+	require.Equal(t, StatusNotFound, status)
+}
+
+// go test -race -run Test_App_UpdateRoutes_BodyLimit
+func Test_App_UpdateRoutes_BodyLimit(t *testing.T) {
+	app := New(Config{BodyLimit: 16})
+	app.Post("/upload", testEmptyHandler, RouteConfig{BodyLimit: 32})
+
+	ln := fasthttputil.NewInmemoryListener()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- app.Listener(ln, ListenConfig{DisableStartupMessage: true})
+	}()
+	client := fasthttp.HostClient{
+		Dial: func(_ string) (net.Conn, error) { return ln.Dial() },
+	}
+	post := func(path string, size int) int {
+		t.Helper()
+		req, resp := fasthttp.AcquireRequest(), fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseRequest(req)
+		defer fasthttp.ReleaseResponse(resp)
+		req.Header.SetMethod(MethodPost)
+		req.SetRequestURI("http://example.com" + path)
+		req.SetBodyString(strings.Repeat("a", size))
+		assert.NoError(t, client.Do(req, resp))
+		return resp.StatusCode()
+	}
+	require.Eventually(t, func() bool {
+		conn, err := ln.Dial()
+		if err != nil {
+			return false
+		}
+		return conn.Close() == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// the server limit is read while the updates run
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				assert.Equal(t, StatusOK, post("/upload", 32))
+			}
+		}
+	})
+	for i := range 50 {
+		err := app.UpdateRoutes(func(tx RouteTx) error {
+			tx.Post("/upload/"+strconv.Itoa(i), testEmptyHandler, RouteConfig{BodyLimit: 64 + i})
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	close(done)
+	wg.Wait()
+
+	// set on startup, the server limit is not raised by the updates
+	require.Equal(t, StatusRequestEntityTooLarge, post("/upload/49", 64))
+
+	require.NoError(t, app.Shutdown())
+	require.NoError(t, <-errs)
+}
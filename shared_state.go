@@ -18,6 +18,7 @@ var ErrSharedStorageNotConfigured = errors.New("fiber: shared storage is not con
 
 type SharedState struct {
 	storage        Storage
+	codec          func(mediaType string) (Codec, bool)
 	jsonEncoder    utils.JSONMarshal
 	jsonDecoder    utils.JSONUnmarshal
 	msgPackEncoder utils.MsgPackMarshal
@@ -213,6 +214,53 @@ func (s *SharedState) GetXMLWithContext(ctx context.Context, key string, out any
 	return s.getEncodedWithContext(ctx, key, out, s.xmlDecoder, "xml")
 }
 
+// SetEncoded stores v encoded with the codec the app registered for
+// mediaType, see App.RegisterCodec.
+func (s *SharedState) SetEncoded(key, mediaType string, v any, ttl time.Duration) error {
+	return s.SetEncodedWithContext(context.Background(), key, mediaType, v, ttl)
+}
+
+func (s *SharedState) SetEncodedWithContext(ctx context.Context, key, mediaType string, v any, ttl time.Duration) error {
+	if err := s.ensureStorage(); err != nil {
+		return err
+	}
+
+	codec, err := s.lookupCodec(mediaType)
+	if err != nil {
+		return err
+	}
+
+	return s.setEncodedWithContext(ctx, key, v, ttl, codec.Marshal, mediaType)
+}
+
+// GetEncoded decodes the value stored for key with the codec the app
+// registered for mediaType, see App.RegisterCodec.
+func (s *SharedState) GetEncoded(key, mediaType string, out any) ([]byte, bool, error) { //nolint:gocritic // Keep unnamed returns for clarity.
+	return s.GetEncodedWithContext(context.Background(), key, mediaType, out)
+}
+
+func (s *SharedState) GetEncodedWithContext(ctx context.Context, key, mediaType string, out any) ([]byte, bool, error) { //nolint:gocritic // Keep unnamed returns for clarity.
+	if err := s.ensureStorage(); err != nil {
+		return nil, false, err
+	}
+
+	codec, err := s.lookupCodec(mediaType)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return s.getEncodedWithContext(ctx, key, out, codec.Unmarshal, mediaType)
+}
+
+func (s *SharedState) lookupCodec(mediaType string) (Codec, error) {
+	if s.codec != nil {
+		if codec, ok := s.codec(mediaType); ok {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrCodecNotRegistered, mediaType)
+}
+
 func (s *SharedState) Delete(key string) error {
 	return s.DeleteWithContext(context.Background(), key)
 }
@@ -2,6 +2,7 @@ package fiber
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -44,3 +45,14 @@ type Storage interface {
 	// collectors and open connections.
 	Close() error
 }
+
+// StreamStorage is implemented by storages that can store a value read from
+// a stream without holding it in memory. MultipartPart.SaveToStorage uses it
+// when available.
+type StreamStorage interface {
+	Storage
+
+	// SetReaderWithContext stores the value read from r until io.EOF for the
+	// given key with an expiration value, 0 means no expiration.
+	SetReaderWithContext(ctx context.Context, key string, r io.Reader, exp time.Duration) error
+}
@@ -0,0 +1,143 @@
+package fiber
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/gofiber/utils/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// StreamWriter is the writer SendStreamFunc hands to its callback. It
+// buffers the body like the *bufio.Writer of SendStreamWriter, sets the
+// trailers declared with Trailer once the body is written and reports when
+// the client went away, so that long-running streams can stop early.
+type StreamWriter struct {
+	w      *bufio.Writer
+	ctx    context.Context //nolint:containedctx // the context lives as long as the stream
+	cancel context.CancelCauseFunc
+	// disconnect cancels the request context, see Config.CancelOnDisconnect
+	disconnect context.CancelCauseFunc
+	declared   []string
+	trailers   [][2]string // guarded by mu
+	mu         sync.Mutex
+}
+
+// Write buffers p. Once a write to the client failed, it returns an error
+// wrapping ErrClientDisconnected.
+func (w *StreamWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	return n, w.check(err)
+}
+
+// WriteString buffers s. Once a write to the client failed, it returns an
+// error wrapping ErrClientDisconnected.
+func (w *StreamWriter) WriteString(s string) (int, error) {
+	n, err := w.w.WriteString(s)
+	return n, w.check(err)
+}
+
+// Flush sends the buffered body to the client as a chunk. It returns an
+// error wrapping ErrClientDisconnected if the client is gone.
+func (w *StreamWriter) Flush() error {
+	return w.check(w.w.Flush())
+}
+
+// Context returns a context carrying the values of the request context. It
+// is canceled with ErrClientDisconnected as its cause once a write to the
+// client failed or, with Config.CancelOnDisconnect, the client closed the
+// connection, and when the callback returns.
+func (w *StreamWriter) Context() context.Context {
+	return w.ctx
+}
+
+// Disconnected reports whether the client is gone. Without
+// Config.CancelOnDisconnect, a disconnect is only noticed when a write to
+// the client fails, i.e. on Flush or when the buffer is full.
+func (w *StreamWriter) Disconnected() bool {
+	return errors.Is(context.Cause(w.ctx), ErrClientDisconnected)
+}
+
+// SetTrailer sets the value of a trailer field declared with Trailer before
+// SendStreamFunc was called. The trailers are sent after the last chunk.
+func (w *StreamWriter) SetTrailer(key, val string) error {
+	if !slices.ContainsFunc(w.declared, func(declared string) bool {
+		return utils.EqualFold(declared, key)
+	}) {
+		return fmt.Errorf("%w: %q", ErrTrailerNotDeclared, key)
+	}
+	w.mu.Lock()
+	w.trailers = append(w.trailers, [2]string{key, val})
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *StreamWriter) check(err error) error {
+	if err == nil {
+		return nil
+	}
+	w.cancel(ErrClientDisconnected)
+	if w.disconnect != nil {
+		w.disconnect(ErrClientDisconnected)
+	}
+	return fmt.Errorf("%w: %w", ErrClientDisconnected, err)
+}
+
+// streamBody is the body stream of SendStreamFunc. The callback runs
+// alongside the goroutine writing the response, so the trailer values it
+// sets are copied to the response header by that goroutine once the whole
+// body is read.
+type streamBody struct {
+	io.ReadCloser
+	w      *StreamWriter
+	header *fasthttp.ResponseHeader
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if errors.Is(err, io.EOF) {
+		b.w.mu.Lock()
+		for _, trailer := range b.w.trailers {
+			b.header.Set(trailer[0], trailer[1])
+		}
+		b.w.mu.Unlock()
+	}
+	return n, err //nolint:wrapcheck // io.EOF must be returned as is
+}
+
+// wrapBodyStream applies the wrappers registered with WrapBodyStream to
+// stream, the last registered one first, so that the middleware registered
+// first sees the stream as it's sent.
+func wrapBodyStream(wrappers []func(io.Reader, int) io.Reader, stream io.Reader, size int) io.Reader {
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		wrapped := wrappers[i](stream, size)
+		if wrapped == nil {
+			continue
+		}
+		if _, ok := wrapped.(io.Closer); !ok {
+			if closer, ok := stream.(io.Closer); ok {
+				wrapped = struct {
+					io.Reader
+					io.Closer
+				}{wrapped, closer}
+			}
+		}
+		stream = wrapped
+	}
+	return stream
+}
+
+// trailerDeclared reports whether key is a declared trailer of the response.
+func trailerDeclared(header *fasthttp.ResponseHeader, key string) bool {
+	for trailer := range header.Trailers() {
+		if utils.EqualFold(utils.UnsafeString(trailer), key) {
+			return true
+		}
+	}
+	return false
+}
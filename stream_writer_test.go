@@ -0,0 +1,199 @@
+package fiber
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// go test -run Test_Ctx_Trailer
+func Test_Ctx_Trailer(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	require.NoError(t, c.Trailer("Grpc-Status", "grpc-message"))
+	require.NoError(t, c.Trailer("grpc-status"))
+	require.NoError(t, c.SetTrailer("Grpc-Message", "ok"))
+	var trailers []string
+	for trailer := range c.Response().Header.Trailers() {
+		trailers = append(trailers, string(trailer))
+	}
+	require.Equal(t, []string{"Grpc-Status", "Grpc-Message"}, trailers)
+	require.Equal(t, "ok", string(c.Response().Header.Peek("Grpc-Message")))
+
+	for _, key := range []string{HeaderContentLength, HeaderAuthorization, "a,b", "bad key", ""} {
+		require.ErrorIs(t, c.Trailer(key), ErrBadTrailer, key)
+	}
+	require.ErrorIs(t, c.SetTrailer(HeaderContentType, "text/plain"), ErrBadTrailer)
+}
+
+// go test -run Test_Ctx_SendStreamFunc
+func Test_Ctx_SendStreamFunc(t *testing.T) {
+	t.Parallel()
+
+	undeclared := make(chan error, 1)
+	app := New()
+	app.Get("/", func(c Ctx) error {
+		if err := c.Trailer("Content-Digest", "Grpc-Status"); err != nil {
+			return err
+		}
+		if err := c.SetTrailer("Grpc-Message", "done"); err != nil {
+			return err
+		}
+		return c.SendStreamFunc(func(w *StreamWriter) {
+			hash := sha256.New()
+			out := io.MultiWriter(w, hash)
+			for _, chunk := range []string{"hello ", "streaming ", "world"} {
+				if _, err := io.WriteString(out, chunk); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+			require.False(t, w.Disconnected())
+			require.NoError(t, w.Context().Err())
+			undeclared <- w.SetTrailer("X-Undeclared", "1")
+			_ = w.SetTrailer("Content-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(hash.Sum(nil))+":") //nolint:errcheck // declared above
+			_ = w.SetTrailer("grpc-status", "0")                                                                 //nolint:errcheck // declared above
+		})
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/", http.NoBody))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hello streaming world", string(body))
+	require.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+
+	digest := sha256.Sum256(body)
+	require.Equal(t, "sha-256=:"+base64.StdEncoding.EncodeToString(digest[:])+":", resp.Trailer.Get("Content-Digest"))
+	require.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+	require.Equal(t, "done", resp.Trailer.Get("Grpc-Message"))
+	require.Empty(t, resp.Header.Get("Grpc-Message"))
+	require.ErrorIs(t, <-undeclared, ErrTrailerNotDeclared)
+}
+
+// go test -run Test_Ctx_SendStreamFunc_Disconnect
+func Test_Ctx_SendStreamFunc_Disconnect(t *testing.T) {
+	t.Parallel()
+
+	type result struct {
+		err          error
+		cause        error
+		disconnected bool
+	}
+	done := make(chan result, 1)
+	started := make(chan struct{})
+
+	app := New()
+	app.Get("/", func(c Ctx) error {
+		c.SetContext(context.WithValue(c.Context(), testContextKey{}, "value"))
+		return c.SendStreamFunc(func(w *StreamWriter) {
+			require.Equal(t, "value", w.Context().Value(testContextKey{}))
+			close(started)
+			var err error
+			for i := 0; i < 1000 && err == nil; i++ {
+				if _, err = w.WriteString("data: tick\n\n"); err == nil {
+					err = w.Flush()
+				}
+				time.Sleep(time.Millisecond)
+			}
+			done <- result{err: err, disconnected: w.Disconnected(), cause: context.Cause(w.Context())}
+		})
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() {
+		if err := app.Listener(ln, ListenConfig{DisableStartupMessage: true}); err != nil && !errors.Is(err, net.ErrClosed) {
+			panic(err)
+		}
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, app.Shutdown())
+	})
+
+	conn, err := ln.Dial()
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	require.Equal(t, StatusOK, resp.StatusCode)
+	<-started
+	require.NoError(t, conn.Close())
+
+	select {
+	case res := <-done:
+		require.ErrorIs(t, res.err, ErrClientDisconnected)
+		require.True(t, res.disconnected)
+		require.ErrorIs(t, res.cause, ErrClientDisconnected)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream writer didn't notice the disconnect")
+	}
+}
+
+// go test -run Test_Ctx_WrapBodyStream
+func Test_Ctx_WrapBodyStream(t *testing.T) {
+	t.Parallel()
+
+	sizes := make(chan int, 2)
+	app := New()
+	app.Use(func(c Ctx) error {
+		c.WrapBodyStream(func(stream io.Reader, size int) io.Reader {
+			sizes <- size
+			if size >= 0 {
+				return nil
+			}
+			return io.MultiReader(stream, strings.NewReader(" outer"))
+		})
+		c.WrapBodyStream(func(stream io.Reader, size int) io.Reader {
+			if size >= 0 {
+				return nil
+			}
+			return io.MultiReader(stream, strings.NewReader(" inner"))
+		})
+		c.WrapBodyStream(func(io.Reader, int) io.Reader {
+			return nil
+		})
+		return c.Next()
+	})
+	app.Get("/", func(c Ctx) error {
+		return c.SendStream(strings.NewReader("body"), 4)
+	})
+	app.Get("/writer", func(c Ctx) error {
+		return c.SendStreamWriter(func(w *bufio.Writer) {
+			w.WriteString("body") //nolint:errcheck // test stream
+		})
+	})
+
+	for target, size := range map[string]int{"/": 4, "/writer": -1} {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, target, http.NoBody))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, size, <-sizes, target)
+		if size < 0 {
+			// the first registered wrapper sees the stream as it's sent
+			require.Equal(t, "body inner outer", string(body))
+		} else {
+			require.Equal(t, "body", string(body))
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package fiber
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3/log"
+)
+
+const (
+	// listenFdsStart is the first file descriptor passed by systemd socket
+	// activation, SD_LISTEN_FDS_START.
+	listenFdsStart = 3
+	// networkSystemd prefixes the restart keys of the activated sockets.
+	networkSystemd = "systemd"
+)
+
+// systemdSockets holds the sockets passed to the process by systemd socket
+// activation.
+var systemdSockets = &socketActivation{}
+
+// socketActivation reads the sockets of systemd socket activation, see
+// sd_listen_fds(3), and hands them out to the apps.
+type socketActivation struct {
+	err     error
+	sockets []activatedSocket
+	mu      sync.Mutex
+	once    sync.Once
+}
+
+// activatedSocket is a socket passed by systemd, named with
+// FileDescriptorName= in its socket unit.
+type activatedSocket struct {
+	file *os.File
+	name string
+	fd   int
+}
+
+// load reads LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES, once.
+func (s *socketActivation) load() {
+	s.once.Do(func() {
+		pid, fds, names := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES")
+		// the prefork children and the restarted processes don't inherit them
+		for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+			_ = os.Unsetenv(key) //nolint:errcheck // the variables are valid
+		}
+
+		if pid != strconv.Itoa(os.Getpid()) {
+			s.err = ErrSocketActivation
+			return
+		}
+		n, err := strconv.Atoi(fds)
+		if err != nil || n < 1 {
+			s.err = ErrSocketActivation
+			return
+		}
+		fdNames := strings.Split(names, ":")
+		for i := range n {
+			name := "unknown"
+			if i < len(fdNames) && fdNames[i] != "" {
+				name = fdNames[i]
+			}
+			fd := listenFdsStart + i
+			s.sockets = append(s.sockets, activatedSocket{file: os.NewFile(uintptr(fd), name), name: name, fd: fd})
+		}
+	})
+}
+
+// listeners returns the sockets named name, or all of them if name is empty,
+// with their restart keys. Each socket is only returned once.
+func (s *socketActivation) listeners(name string) ([]net.Listener, []string, error) {
+	s.load()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+
+	var (
+		lns  []net.Listener
+		keys []string
+		rest []activatedSocket
+		err  error
+	)
+	for _, socket := range s.sockets {
+		if err != nil || (name != "" && socket.name != name) {
+			rest = append(rest, socket)
+			continue
+		}
+		ln, lnErr := net.FileListener(socket.file)
+		if closeErr := socket.file.Close(); closeErr != nil {
+			log.Errorf("systemd: failed to close socket %q: %v", socket.name, closeErr)
+		}
+		if lnErr != nil {
+			err = fmt.Errorf("systemd: cannot serve socket %q: %w", socket.name, lnErr)
+			continue
+		}
+		lns = append(lns, ln)
+		keys = append(keys, restartKey(networkSystemd, socket.name+"#"+strconv.Itoa(socket.fd)))
+	}
+	s.sockets = rest
+	if err == nil && len(lns) == 0 {
+		err = fmt.Errorf("%w: no socket named %q", ErrSocketActivation, name)
+	}
+	if err != nil {
+		closeListeners(lns)
+		return nil, nil, err
+	}
+	return lns, keys, nil
+}
+
+// activatedListeners returns the sockets of systemd socket activation selected
+// by cfg, or the ones passed by the process this one replaces, with their
+// restart keys.
+func (app *App) activatedListeners(cfg *ListenConfig) ([]net.Listener, []string, error) {
+	if cfg.EnableGracefulRestart {
+		prefix := restartKey(networkSystemd, "")
+		if cfg.SocketActivationName != "" {
+			prefix += cfg.SocketActivationName + "#"
+		}
+		lns, keys := restarts.inheritedListeners(app, func(key string) bool {
+			return strings.HasPrefix(key, prefix)
+		})
+		if len(lns) > 0 {
+			return lns, keys, nil
+		}
+	}
+	return systemdSockets.listeners(cfg.SocketActivationName)
+}
+
+// closeListeners closes the listeners of a failed Listen.
+func closeListeners(lns []net.Listener) {
+	for _, ln := range lns {
+		if err := ln.Close(); err != nil {
+			log.Errorf("failed to close listener: %v", err)
+		}
+	}
+}
+
+// multiListener accepts the connections of several listeners, so that they're
+// served as one.
+type multiListener struct {
+	conns     chan net.Conn
+	closed    chan struct{}
+	listeners []net.Listener
+	errs      chan error
+	once      sync.Once
+}
+
+func newMultiListener(lns []net.Listener) *multiListener {
+	ml := &multiListener{
+		listeners: lns,
+		conns:     make(chan net.Conn),
+		errs:      make(chan error, len(lns)),
+		closed:    make(chan struct{}),
+	}
+	for _, ln := range lns {
+		go ml.accept(ln)
+	}
+	return ml
+}
+
+func (ml *multiListener) accept(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			ml.errs <- err
+			return
+		}
+		select {
+		case ml.conns <- conn:
+		case <-ml.closed:
+			_ = conn.Close() //nolint:errcheck // the listener is closed
+			return
+		}
+	}
+}
+
+// Accept returns the next connection of any of the listeners.
+func (ml *multiListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-ml.conns:
+		return conn, nil
+	case err := <-ml.errs:
+		return nil, err
+	case <-ml.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes all the listeners.
+func (ml *multiListener) Close() error {
+	var errs []error
+	ml.once.Do(func() {
+		close(ml.closed)
+		for _, ln := range ml.listeners {
+			if err := ln.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// Addr returns the address of the first listener.
+func (ml *multiListener) Addr() net.Addr {
+	return ml.listeners[0].Addr()
+}
+
+// sdNotify sends state to the service manager, see sd_notify(3). It does
+// nothing when the process isn't run by systemd.
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("systemd: cannot connect to %q: %w", socket, err)
+	}
+	defer conn.Close() //nolint:errcheck // the datagram is sent
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("systemd: cannot notify %q: %w", state, err)
+	}
+	return nil
+}
+
+// watchdogInterval returns the interval of the pings of the systemd watchdog,
+// half its timeout, or 0 if it's disabled for the process.
+func watchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// notifySystemd notifies systemd that the app is ready, after its OnListen
+// hooks and BeforeServeFunc, and that it's stopping, along with its
+// OnPreShutdown hooks. It pings the watchdog until the returned function is
+// called.
+func (app *App) notifySystemd() func() {
+	state := "READY=1"
+	if restarts.restarted() {
+		// the previous process exits once this one is ready
+		state += "\nMAINPID=" + strconv.Itoa(os.Getpid())
+	}
+	if err := sdNotify(state); err != nil {
+		log.Errorf("%v", err)
+	}
+	app.systemdNotify.Store(true)
+
+	interval := watchdogInterval()
+	if interval == 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := sdNotify("WATCHDOG=1"); err != nil {
+					log.Errorf("%v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+	}
+}
@@ -0,0 +1,237 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package fiber
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testActivationNameEnv = "FIBER_TEST_ACTIVATION_NAME"
+	testActivationTLSEnv  = "FIBER_TEST_ACTIVATION_TLS"
+)
+
+// activationClient returns a client sending its requests to addr.
+func activationClient(addr net.Addr) *http.Client {
+	return &http.Client{
+		Timeout: 500 * time.Millisecond,
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // self-signed test certificate
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, addr.Network(), addr.String())
+			},
+		},
+	}
+}
+
+// go test -run Test_Listen_SocketActivation
+func Test_Listen_SocketActivation(t *testing.T) {
+	if name, ok := os.LookupEnv(testActivationNameEnv); ok {
+		// the activated process, serving until SIGTERM
+		require.NoError(t, os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid())))
+		app := New()
+		app.Get("/", func(c Ctx) error {
+			return c.SendString("activated")
+		})
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+		defer stop()
+		cfg := ListenConfig{
+			EnableSocketActivation: true,
+			SocketActivationName:   name,
+			DisableStartupMessage:  true,
+			GracefulContext:        ctx,
+		}
+		if os.Getenv(testActivationTLSEnv) != "" {
+			cfg.CertFile = "./.github/testdata/ssl.pem"
+			cfg.CertKeyFile = "./.github/testdata/ssl.key"
+		}
+		require.NoError(t, app.Listen("", cfg))
+		return
+	}
+
+	for _, tc := range []struct {
+		name   string
+		served []string
+		tls    bool
+	}{
+		{name: "", served: []string{"web", "admin"}},
+		{name: "admin", served: []string{"admin"}},
+		{name: "web", served: []string{"web"}, tls: true},
+	} {
+		t.Run("name="+tc.name, func(t *testing.T) {
+			// the sockets of the units, as systemd passes them
+			web, err := net.Listen(NetworkTCP4, "127.0.0.1:0")
+			require.NoError(t, err)
+			defer web.Close() //nolint:errcheck // closed by the test
+			admin, err := net.Listen(NetworkUnix, filepath.Join(t.TempDir(), "admin.sock"))
+			require.NoError(t, err)
+			defer admin.Close() //nolint:errcheck // closed by the test
+			sockets := map[string]net.Listener{"web": web, "admin": admin}
+
+			cmd := exec.Command(os.Args[0], "-test.run=^Test_Listen_SocketActivation$") //nolint:gosec // the test binary is started again
+			cmd.Env = append(os.Environ(), "LISTEN_FDS=2", "LISTEN_FDNAMES=web:admin", testActivationNameEnv+"="+tc.name)
+			if tc.tls {
+				cmd.Env = append(cmd.Env, testActivationTLSEnv+"=1")
+			}
+			cmd.Stderr = os.Stderr
+			for _, ln := range []net.Listener{web, admin} {
+				file, err := listenerFile(ln)
+				require.NoError(t, err)
+				defer file.Close() //nolint:errcheck // closed by the test
+				cmd.ExtraFiles = append(cmd.ExtraFiles, file)
+			}
+			require.NoError(t, cmd.Start())
+			defer func() {
+				require.NoError(t, cmd.Process.Signal(syscall.SIGTERM))
+				require.NoError(t, cmd.Wait())
+			}()
+
+			scheme := "http://"
+			if tc.tls {
+				scheme = "https://"
+			}
+			for name, ln := range sockets {
+				client := activationClient(ln.Addr())
+				if !slices.Contains(tc.served, name) {
+					// the connections are queued, but never accepted
+					_, err := client.Get(scheme + "fiber/") //nolint:noctx // the request is local
+					require.Error(t, err)
+					continue
+				}
+				var body []byte
+				require.Eventually(t, func() bool {
+					resp, err := client.Get(scheme + "fiber/") //nolint:noctx // the request is local
+					if err != nil {
+						return false
+					}
+					body, err = io.ReadAll(resp.Body)
+					return resp.Body.Close() == nil && err == nil
+				}, 10*time.Second, 20*time.Millisecond)
+				require.Equal(t, "activated", string(body))
+			}
+		})
+	}
+}
+
+// go test -run Test_SocketActivation_Env
+func Test_SocketActivation_Env(t *testing.T) {
+	for _, tc := range []struct {
+		pid string
+		fds string
+	}{
+		{pid: "", fds: ""},
+		{pid: "1", fds: "1"},
+		{pid: strconv.Itoa(os.Getpid()), fds: "0"},
+		{pid: strconv.Itoa(os.Getpid()), fds: "x"},
+	} {
+		t.Setenv("LISTEN_PID", tc.pid)
+		t.Setenv("LISTEN_FDS", tc.fds)
+		t.Setenv("LISTEN_FDNAMES", "web")
+
+		s := &socketActivation{}
+		_, _, err := s.listeners("")
+		require.ErrorIs(t, err, ErrSocketActivation)
+
+		// not passed to the child processes
+		for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+			_, ok := os.LookupEnv(key)
+			require.False(t, ok)
+		}
+	}
+}
+
+// go test -run Test_Listen_SystemdNotify
+func Test_Listen_SystemdNotify(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	t.Setenv("NOTIFY_SOCKET", socket)
+	t.Setenv("WATCHDOG_USEC", "20000")
+
+	read := func() string {
+		t.Helper()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		buf := make([]byte, 64)
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	app := New()
+	listened := make(chan struct{})
+	app.Hooks().OnListen(func(ListenData) error {
+		close(listened)
+		return nil
+	})
+	served := make(chan error, 1)
+	go func() {
+		served <- app.Listen("127.0.0.1:0", ListenConfig{
+			DisableStartupMessage: true,
+			EnableSystemdNotify:   true,
+		})
+	}()
+
+	// ready once the OnListen hooks ran
+	require.Equal(t, "READY=1", read())
+	select {
+	case <-listened:
+	default:
+		t.Fatal("notified before the OnListen hooks")
+	}
+	require.Equal(t, "WATCHDOG=1", read())
+
+	require.NoError(t, app.Shutdown())
+	for read() != "STOPPING=1" {
+		// watchdog pings sent before the shutdown
+	}
+	require.NoError(t, <-served)
+}
+
+// go test -run Test_Listen_SystemdNotify_BeforeServeFunc
+func Test_Listen_SystemdNotify_BeforeServeFunc(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // closed by the test
+	t.Setenv("NOTIFY_SOCKET", socket)
+
+	// not ready when the app fails before serving
+	err = New().Listen("127.0.0.1:0", ListenConfig{
+		DisableStartupMessage: true,
+		EnableSystemdNotify:   true,
+		BeforeServeFunc: func(*App) error {
+			return errors.New("not ready")
+		},
+	})
+	require.EqualError(t, err, "not ready")
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, err = conn.Read(make([]byte, 64))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+// go test -run Test_SdNotify_Unset
+func Test_SdNotify_Unset(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	require.NoError(t, sdNotify("READY=1"))
+
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+	require.ErrorContains(t, sdNotify("READY=1"), "systemd: cannot connect")
+}
@@ -0,0 +1,141 @@
+package fiber
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// TypedHandler is a handler created with Typed. It can be registered on any
+// router like a regular handler, the router records its request and response
+// types on the Route.
+type TypedHandler struct {
+	handler      Handler
+	requestType  reflect.Type
+	responseType reflect.Type
+}
+
+// RequestType returns the request type the handler binds.
+func (h TypedHandler) RequestType() reflect.Type {
+	return h.requestType
+}
+
+// ResponseType returns the response type the handler encodes.
+func (h TypedHandler) ResponseType() reflect.Type {
+	return h.responseType
+}
+
+// typedRouteOptions returns the route options holding the types of the last
+// typed handler in args, the endpoint of the chain.
+func typedRouteOptions(args []any) routeOptions {
+	for i := len(args) - 1; i >= 0; i-- {
+		switch h := args[i].(type) {
+		case TypedHandler:
+			return routeOptions{requestType: h.requestType, responseType: h.responseType}
+		case *TypedHandler:
+			if h != nil {
+				return routeOptions{requestType: h.requestType, responseType: h.responseType}
+			}
+		}
+	}
+	return routeOptions{}
+}
+
+// Typed adapts a handler that receives a decoded request and returns a response value.
+//
+// The request is bound with Bind().All, so URI params, the body, the query string,
+// headers and cookies are used in that order of precedence, and validated with the
+// configured StructValidator. Binding failures are answered with 400 Bad Request,
+// validation failures with 422 Unprocessable Entity. The returned value is encoded
+// based on the Accept header, JSON is used when the client accepts any type.
+//
+// Req must be a struct or a pointer to a struct. Errors returned by fn are passed
+// to the error handler unchanged.
+//
+// Example:
+//
+//	app.Post("/users/:tenant", fiber.Typed(func(c fiber.Ctx, req CreateUser) (User, error) {
+//		c.Status(fiber.StatusCreated)
+//		return users.Create(c, req)
+//	}))
+func Typed[Req, Resp any](fn func(c Ctx, req Req) (Resp, error)) TypedHandler {
+	if fn == nil {
+		panic("typed: handler must not be nil")
+	}
+
+	requestType := reflect.TypeFor[Req]()
+	isPointer := requestType.Kind() == reflect.Pointer
+	structType := requestType
+	if isPointer {
+		structType = requestType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("typed: request type must be a struct or a pointer to a struct, got %s", requestType))
+	}
+
+	handler := func(c Ctx) error {
+		var req Req
+		var out any = &req
+		if isPointer {
+			ptr := reflect.New(structType)
+			req = ptr.Interface().(Req) //nolint:errcheck,forcetypeassert // ptr is of type Req
+			out = req
+		}
+
+		if err := bindTyped(c, out); err != nil {
+			return err
+		}
+
+		resp, err := fn(c, req)
+		if err != nil {
+			return err
+		}
+
+		return encodeTyped(c, resp)
+	}
+
+	return TypedHandler{
+		handler:      handler,
+		requestType:  requestType,
+		responseType: reflect.TypeFor[Resp](),
+	}
+}
+
+// bindTyped binds and validates the request, mapping failures to HTTP errors.
+func bindTyped(c Ctx, out any) error {
+	err := c.Bind().All(out)
+	if err == nil {
+		return nil
+	}
+
+	var fiberErr *Error
+	if errors.As(err, &fiberErr) {
+		return err
+	}
+
+	// keep the source and rejected fields in the problem document
+	var (
+		bindErr *BindError
+		verrs   ValidationErrors
+	)
+	if c.App().config.ProblemDetails && (errors.As(err, &bindErr) || errors.As(err, &verrs)) {
+		return ProblemFromError(err)
+	}
+
+	if errors.As(err, &bindErr) {
+		return NewError(StatusBadRequest, "Bad request: "+err.Error())
+	}
+
+	// All other errors come from the StructValidator
+	return NewError(StatusUnprocessableEntity, err.Error())
+}
+
+// encodeTyped encodes the response based on the Accept header. JSON is
+// preferred over the text formats picked by AutoFormat when the client accepts
+// any type.
+func encodeTyped(c Ctx, resp any) error {
+	if c.Accepts("json", "html", "txt", "xml", "msgpack", "cbor") == "json" {
+		return c.JSON(resp)
+	}
+	return c.AutoFormat(resp)
+}
@@ -0,0 +1,190 @@
+package fiber
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type typedUserRequest struct {
+	Tenant string `uri:"tenant"`
+	Name   string `json:"name"`
+	Notify bool   `query:"notify"`
+}
+
+type typedUserResponse struct {
+	Tenant string `json:"tenant" xml:"tenant"`
+	Name   string `json:"name" xml:"name"`
+	Notify bool   `json:"notify" xml:"notify"`
+}
+
+type typedNameValidator struct{}
+
+func (typedNameValidator) Validate(out any) error {
+	req, ok := out.(*typedUserRequest)
+	if !ok {
+		return nil
+	}
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func createTypedUser(c Ctx, req typedUserRequest) (typedUserResponse, error) {
+	c.Status(StatusCreated)
+	return typedUserResponse(req), nil
+}
+
+// go test -run Test_Typed
+func Test_Typed(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Post("/:tenant/users", Typed(createTypedUser))
+
+	req := httptest.NewRequest(MethodPost, "/acme/users?notify=true", strings.NewReader(`{"name":"john"}`))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, StatusCreated, resp.StatusCode)
+	require.Equal(t, MIMEApplicationJSONCharsetUTF8, resp.Header.Get(HeaderContentType))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var user typedUserResponse
+	require.NoError(t, json.Unmarshal(body, &user))
+	require.Equal(t, typedUserResponse{Tenant: "acme", Name: "john", Notify: true}, user)
+}
+
+// go test -run Test_Typed_ContentNegotiation
+func Test_Typed_ContentNegotiation(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	app.Get("/:tenant", Typed(func(_ Ctx, req typedUserRequest) (typedUserResponse, error) {
+		return typedUserResponse{Tenant: req.Tenant}, nil
+	}))
+
+	testCases := []struct {
+		accept      string
+		contentType string
+	}{
+		{accept: "", contentType: MIMEApplicationJSONCharsetUTF8},
+		{accept: "*/*", contentType: MIMEApplicationJSONCharsetUTF8},
+		{accept: MIMEApplicationXML, contentType: MIMEApplicationXMLCharsetUTF8},
+		{accept: MIMETextHTML, contentType: MIMETextHTMLCharsetUTF8},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(MethodGet, "/acme", http.NoBody)
+		if tc.accept != "" {
+			req.Header.Set(HeaderAccept, tc.accept)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, StatusOK, resp.StatusCode, tc.accept)
+		require.Equal(t, tc.contentType, resp.Header.Get(HeaderContentType), tc.accept)
+	}
+}
+
+// go test -run Test_Typed_Errors
+func Test_Typed_Errors(t *testing.T) {
+	t.Parallel()
+
+	errHandler := errors.New("handler failed")
+	app := New(Config{StructValidator: typedNameValidator{}})
+	app.Post("/:tenant/users", Typed(createTypedUser))
+	app.Post("/fail", Typed(func(_ Ctx, _ *typedUserRequest) (typedUserResponse, error) {
+		return typedUserResponse{}, NewError(StatusConflict, errHandler.Error())
+	}))
+
+	testCases := []struct {
+		name   string
+		target string
+		body   string
+		status int
+	}{
+		{name: "bind error", target: "/acme/users?notify=maybe", body: `{"name":"john"}`, status: StatusBadRequest},
+		{name: "malformed body", target: "/acme/users", body: `{"name":`, status: StatusBadRequest},
+		{name: "validation error", target: "/acme/users", body: `{"name":""}`, status: StatusUnprocessableEntity},
+		{name: "handler error", target: "/fail", body: `{"name":"john"}`, status: StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(MethodPost, tc.target, strings.NewReader(tc.body))
+		req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, tc.status, resp.StatusCode, tc.name)
+	}
+
+	// bind errors keep their source and field as problem details
+	app = New(Config{ProblemDetails: true})
+	app.Post("/:tenant/users", Typed(createTypedUser))
+	req := httptest.NewRequest(MethodPost, "/acme/users?notify=maybe", strings.NewReader(`{"name":"john"}`))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, StatusBadRequest, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(body, &problem))
+	require.Equal(t, BindSourceQuery, problem["source"])
+	require.Equal(t, "notify", problem["field"])
+}
+
+// go test -run Test_Typed_RouteTypes
+func Test_Typed_RouteTypes(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	logger := func(c Ctx) error { return c.Next() }
+
+	app.Post("/users", logger, Typed(createTypedUser)).Name("create")
+	app.Group("/v1").Put("/users/:id", Typed(func(_ Ctx, _ *typedUserRequest) (*typedUserResponse, error) {
+		return &typedUserResponse{}, nil
+	})).Name("update")
+	app.Get("/plain", func(c Ctx) error { return c.SendStatus(StatusOK) }).Name("plain")
+
+	route := app.GetRoute("create")
+	require.Equal(t, reflect.TypeFor[typedUserRequest](), route.RequestType)
+	require.Equal(t, reflect.TypeFor[typedUserResponse](), route.ResponseType)
+
+	route = app.GetRoute("update")
+	require.Equal(t, reflect.TypeFor[*typedUserRequest](), route.RequestType)
+	require.Equal(t, reflect.TypeFor[*typedUserResponse](), route.ResponseType)
+
+	route = app.GetRoute("plain")
+	require.Nil(t, route.RequestType)
+	require.Nil(t, route.ResponseType)
+
+	// automatically registered HEAD routes keep the types of their GET route
+	app.Get("/typed", Typed(createTypedUser))
+	app.startupProcess()
+	for _, r := range app.GetRoutes() {
+		if r.Path == "/typed" {
+			require.Equal(t, reflect.TypeFor[typedUserResponse](), r.ResponseType, r.Method)
+		}
+	}
+}
+
+// go test -run Test_Typed_InvalidRequestType
+func Test_Typed_InvalidRequestType(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "typed: request type must be a struct or a pointer to a struct, got string", func() {
+		Typed(func(_ Ctx, _ string) (string, error) { return "", nil })
+	})
+	require.PanicsWithValue(t, "typed: handler must not be nil", func() {
+		Typed[typedUserRequest, typedUserResponse](nil)
+	})
+}
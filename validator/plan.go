@@ -0,0 +1,156 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Rules handled by the validator itself instead of a RuleFunc.
+const (
+	ruleRequired  = "required"
+	ruleOmitEmpty = "omitempty"
+	ruleDive      = "dive"
+)
+
+// structPlan is the cached result of parsing the rules of a struct type.
+type structPlan struct {
+	err    error
+	fields []fieldPlan
+}
+
+// fieldPlan describes how to validate a field of a struct.
+type fieldPlan struct {
+	rules     *ruleSet
+	name      string
+	index     int
+	inline    bool
+	sensitive bool
+}
+
+// ruleSet is the parsed content of a rules tag. Rules after "dive" apply to
+// the elements of slices, arrays and maps and are kept in dive.
+type ruleSet struct {
+	dive      *ruleSet
+	calls     []ruleCall
+	required  bool
+	omitEmpty bool
+}
+
+type ruleCall struct {
+	fn    RuleFunc
+	name  string
+	param string
+}
+
+// plan returns the cached plan of the struct type t, building it on first use.
+func (v *Validator) plan(t reflect.Type) *structPlan {
+	if cached, ok := v.plans.Load(t); ok {
+		return cached.(*structPlan) //nolint:forcetypeassert,errcheck // only *structPlan is stored
+	}
+
+	plan := v.buildPlan(t)
+	cached, _ := v.plans.LoadOrStore(t, plan)
+	return cached.(*structPlan) //nolint:forcetypeassert,errcheck // only *structPlan is stored
+}
+
+func (v *Validator) buildPlan(t reflect.Type) *structPlan {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	plan := &structPlan{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get(v.cfg.Tag)
+		name, named := v.fieldName(&f)
+		// embedded structs are decoded into their parent by the binders
+		inline := f.Anonymous && !named
+		if tag == "-" || (!f.IsExported() && !inline) {
+			continue
+		}
+
+		rules, err := v.parseRules(t, &f, tag)
+		if err != nil {
+			plan.err = err
+			return plan
+		}
+		if rules == nil && !hasStructs(f.Type) {
+			continue
+		}
+
+		plan.fields = append(plan.fields, fieldPlan{
+			index:     i,
+			name:      name,
+			rules:     rules,
+			inline:    inline,
+			sensitive: f.Tag.Get("sensitive") == "true",
+		})
+	}
+	return plan
+}
+
+// fieldName returns the name of the field in paths and whether it's set by
+// one of the name tags.
+func (v *Validator) fieldName(f *reflect.StructField) (string, bool) {
+	for _, tag := range v.cfg.NameTags {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name, true
+		}
+	}
+	return f.Name, false
+}
+
+// parseRules parses the rules tag of the field f of the struct type parent.
+func (v *Validator) parseRules(parent reflect.Type, f *reflect.StructField, tag string) (*ruleSet, error) {
+	if tag == "" {
+		return nil, nil //nolint:nilnil // fields without rules have no rule set
+	}
+
+	root := &ruleSet{}
+	rules, typ := root, f.Type
+	for rule := range strings.SplitSeq(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "":
+			continue
+		case ruleRequired:
+			rules.required = true
+			continue
+		case ruleOmitEmpty:
+			rules.omitEmpty = true
+			continue
+		case ruleDive:
+			typ = derefType(typ)
+			if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array && typ.Kind() != reflect.Map {
+				return nil, invalidRules(parent, f, "dive needs a slice, array or map, got %s", typ)
+			}
+			rules.dive = &ruleSet{}
+			rules, typ = rules.dive, typ.Elem()
+			continue
+		}
+
+		fn, ok := v.rules[name]
+		if !ok {
+			return nil, invalidRules(parent, f, "unknown rule %q", name)
+		}
+		if check, ok := v.checks[name]; ok {
+			if err := check(parent, derefType(typ), param); err != "" {
+				return nil, invalidRules(parent, f, "%s: %s", name, err)
+			}
+		}
+		rules.calls = append(rules.calls, ruleCall{name: name, param: param, fn: fn})
+	}
+	return root, nil
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func invalidRules(parent reflect.Type, f *reflect.StructField, format string, args ...any) error {
+	return fmt.Errorf("%w: %s.%s: %s", ErrInvalidRules, parent, f.Name, fmt.Sprintf(format, args...))
+}
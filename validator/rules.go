@@ -0,0 +1,251 @@
+package validator
+
+import (
+	"cmp"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ruleCheck reports why a built-in rule can't be applied to fields of type t
+// in the struct type parent with param, an empty string if it can.
+type ruleCheck func(parent, t reflect.Type, param string) string
+
+var builtinRules = map[string]RuleFunc{
+	"min": func(f Field) bool { c, ok := compareParam(f.Value, f.Param); return ok && c >= 0 },
+	"max": func(f Field) bool { c, ok := compareParam(f.Value, f.Param); return ok && c <= 0 },
+	"len": func(f Field) bool { c, ok := compareParam(f.Value, f.Param); return ok && c == 0 },
+	"gt":  func(f Field) bool { c, ok := compareParam(f.Value, f.Param); return ok && c > 0 },
+	"gte": func(f Field) bool { c, ok := compareParam(f.Value, f.Param); return ok && c >= 0 },
+	"lt":  func(f Field) bool { c, ok := compareParam(f.Value, f.Param); return ok && c < 0 },
+	"lte": func(f Field) bool { c, ok := compareParam(f.Value, f.Param); return ok && c <= 0 },
+	"eq":  func(f Field) bool { return equalParam(f.Value, f.Param) },
+	"ne":  func(f Field) bool { return !equalParam(f.Value, f.Param) },
+	"oneof": func(f Field) bool {
+		for option := range strings.FieldsSeq(f.Param) {
+			if equalParam(f.Value, option) {
+				return true
+			}
+		}
+		return false
+	},
+	"eqfield":  func(f Field) bool { return equalField(f) },
+	"nefield":  func(f Field) bool { return !equalField(f) },
+	"email":    func(f Field) bool { return isEmail(f.Value.String()) },
+	"uuid":     func(f Field) bool { return isUUID(f.Value.String()) },
+	"url":      func(f Field) bool { return isURL(f.Value.String()) },
+	"alpha":    func(f Field) bool { return isRunes(f.Value.String(), unicode.IsLetter) },
+	"alphanum": func(f Field) bool { return isRunes(f.Value.String(), isLetterOrDigit) },
+	"numeric":  func(f Field) bool { return isNumeric(f.Value.String()) },
+}
+
+var builtinChecks = map[string]ruleCheck{
+	"min":      checkSize,
+	"max":      checkSize,
+	"len":      checkSize,
+	"gt":       checkSize,
+	"gte":      checkSize,
+	"lt":       checkSize,
+	"lte":      checkSize,
+	"eq":       checkEqual,
+	"ne":       checkEqual,
+	"oneof":    checkOneOf,
+	"eqfield":  checkField,
+	"nefield":  checkField,
+	"email":    checkString,
+	"uuid":     checkString,
+	"url":      checkString,
+	"alpha":    checkString,
+	"alphanum": checkString,
+	"numeric":  checkString,
+}
+
+func checkSize(_, t reflect.Type, param string) string {
+	if !isSizeKind(t.Kind()) {
+		return "unsupported type " + t.String()
+	}
+	if _, err := strconv.ParseFloat(param, 64); err != nil {
+		return "parameter " + strconv.Quote(param) + " is not a number"
+	}
+	return ""
+}
+
+func checkEqual(_, t reflect.Type, param string) string {
+	if t.Kind() == reflect.String {
+		return ""
+	}
+	return checkSize(nil, t, param)
+}
+
+func checkOneOf(_, t reflect.Type, param string) string {
+	if strings.TrimSpace(param) == "" {
+		return "no options"
+	}
+	for option := range strings.FieldsSeq(param) {
+		if err := checkEqual(nil, t, option); err != "" {
+			return err
+		}
+	}
+	return ""
+}
+
+func checkField(parent, _ reflect.Type, param string) string {
+	if _, ok := parent.FieldByName(param); !ok {
+		return "no field " + strconv.Quote(param) + " in " + parent.String()
+	}
+	return ""
+}
+
+func checkString(_, t reflect.Type, _ string) string {
+	if t.Kind() != reflect.String {
+		return "unsupported type " + t.String()
+	}
+	return ""
+}
+
+func isSizeKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// compareParam compares v with the number param. Strings, slices, arrays
+// and maps are compared by length, strings counting runes.
+func compareParam(v reflect.Value, param string) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return compareInt(int64(utf8.RuneCountInString(v.String())), param)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return compareInt(int64(v.Len()), param)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareInt(v.Int(), param)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if p, err := strconv.ParseUint(param, 10, 64); err == nil {
+			return cmp.Compare(v.Uint(), p), true
+		}
+		return compareFloat(float64(v.Uint()), param)
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(v.Float(), param)
+	default:
+		return 0, false
+	}
+}
+
+func compareInt(n int64, param string) (int, bool) {
+	if p, err := strconv.ParseInt(param, 10, 64); err == nil {
+		return cmp.Compare(n, p), true
+	}
+	return compareFloat(float64(n), param)
+}
+
+func compareFloat(f float64, param string) (int, bool) {
+	p, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, false
+	}
+	return cmp.Compare(f, p), true
+}
+
+// equalParam reports whether v equals param, comparing strings by value.
+func equalParam(v reflect.Value, param string) bool {
+	if v.Kind() == reflect.String {
+		return v.String() == param
+	}
+	c, ok := compareParam(v, param)
+	return ok && c == 0
+}
+
+// equalField reports whether the field equals the field of its parent named
+// by the parameter.
+func equalField(f Field) bool {
+	other := f.Parent.FieldByName(f.Param)
+	for other.Kind() == reflect.Pointer || other.Kind() == reflect.Interface {
+		if other.IsNil() {
+			return false
+		}
+		other = other.Elem()
+	}
+	if !other.IsValid() || other.Type() != f.Value.Type() || !f.Value.Comparable() {
+		return false
+	}
+	return f.Value.Equal(other)
+}
+
+// isEmail reports whether s looks like an email address: a local part and a
+// domain with at least one dot, without spaces.
+func isEmail(s string) bool {
+	at := strings.LastIndexByte(s, '@')
+	if at <= 0 || at > 64 || len(s) > 254 || strings.ContainsAny(s, " \t\r\n<>") {
+		return false
+	}
+	domain := s[at+1:]
+	dot := strings.LastIndexByte(domain, '.')
+	return dot > 0 && dot < len(domain)-1 && !strings.Contains(domain, "..") && strings.IndexByte(s[:at], '@') < 0
+}
+
+// isUUID reports whether s is a UUID in its canonical textual form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := range len(s) {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// isURL reports whether s is an absolute URL with a host.
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func isRunes(s string, valid func(rune) bool) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !valid(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetterOrDigit(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isNumeric reports whether s is a decimal number with an optional sign and
+// fraction, e.g. "-12.5".
+func isNumeric(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	whole, fraction, hasFraction := strings.Cut(s, ".")
+	return isRunes(whole, isASCIIDigit) && (!hasFraction || isRunes(fraction, isASCIIDigit))
+}
+
+func isASCIIDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
@@ -0,0 +1,334 @@
+// Package validator provides a dependency-free implementation of
+// fiber.StructValidator, driven by `validate` struct tags.
+//
+//	app := fiber.New(fiber.Config{
+//	    StructValidator: validator.New(),
+//	})
+//
+//	type User struct {
+//	    Name  string `json:"name" validate:"required,min=3,max=64"`
+//	    Email string `json:"email" validate:"required,email"`
+//	}
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3/binder"
+	"github.com/gofiber/fiber/v3/internal/redact"
+)
+
+// ErrInvalidRules is returned by Validate when the rules of a struct can't be
+// applied, e.g. because a rule is unknown or doesn't fit the field type.
+var ErrInvalidRules = errors.New("validator: invalid rules")
+
+// Config defines the config for the validator.
+type Config struct {
+	// Tag is the struct tag holding the rules of a field.
+	//
+	// Optional. Default: "validate"
+	Tag string
+
+	// NameTags are the struct tags used, in order, to name fields in the
+	// paths of errors. The first tag set on a field wins, fields without any
+	// are named by their Go name.
+	//
+	// Optional. Default: json, query, form, uri, header, cookie, respHeader, xml, cbor, msgpack
+	NameTags []string
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Tag:      "validate",
+	NameTags: []string{"json", "query", "form", "uri", "header", "cookie", "respHeader", "xml", "cbor", "msgpack"},
+}
+
+// Field is the value a rule checks.
+type Field struct {
+	// Value is the value of the field, pointers are dereferenced.
+	Value reflect.Value
+	// Parent is the struct holding the field, for rules comparing fields.
+	Parent reflect.Value
+	// Param is the parameter of the rule, e.g. "3" for "min=3".
+	Param string
+}
+
+// RuleFunc reports whether the field passes a rule.
+type RuleFunc func(f Field) bool
+
+// Validator validates structs by the rules in their struct tags. The rules of
+// a struct type are parsed once and cached. It is safe for concurrent use.
+type Validator struct {
+	rules  map[string]RuleFunc
+	checks map[string]ruleCheck
+	plans  sync.Map // reflect.Type -> *structPlan
+	cfg    Config
+	mu     sync.RWMutex
+}
+
+// New creates a new validator.
+func New(config ...Config) *Validator {
+	cfg := ConfigDefault
+	if len(config) > 0 {
+		cfg = config[0]
+		if cfg.Tag == "" {
+			cfg.Tag = ConfigDefault.Tag
+		}
+		if len(cfg.NameTags) == 0 {
+			cfg.NameTags = ConfigDefault.NameTags
+		}
+	}
+
+	return &Validator{
+		cfg:    cfg,
+		rules:  maps.Clone(builtinRules),
+		checks: maps.Clone(builtinChecks),
+	}
+}
+
+// RegisterRule registers a rule usable in struct tags, replacing any rule of
+// the same name. Rules should be registered before the validator is used.
+//
+//	v.RegisterRule("even", func(f validator.Field) bool {
+//	    return f.Value.Int()%2 == 0
+//	})
+func (v *Validator) RegisterRule(name string, fn RuleFunc) {
+	if name == "" || strings.ContainsAny(name, ",= ") {
+		panic(fmt.Sprintf("validator: invalid rule name %q", name))
+	}
+	if name == ruleRequired || name == ruleOmitEmpty || name == ruleDive {
+		panic(fmt.Sprintf("validator: rule %q can't be replaced", name))
+	}
+	if fn == nil {
+		panic(fmt.Sprintf("validator: rule %q has no function", name))
+	}
+
+	v.mu.Lock()
+	v.rules[name] = fn
+	delete(v.checks, name)
+	v.mu.Unlock()
+
+	// plans hold the functions of the rules they use
+	v.plans.Clear()
+}
+
+// Validate validates a struct, or a pointer to one, and its nested structs,
+// slices and maps. Rejected fields are returned as binder.ValidationErrors,
+// which is fiber.ValidationErrors, with their paths using the same names as
+// the binders.
+func (v *Validator) Validate(out any) error {
+	val := reflect.ValueOf(out)
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	s := acquireState()
+	defer releaseState(s)
+
+	if err := v.validateStruct(s, val); err != nil {
+		return err
+	}
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return s.errs
+}
+
+func (v *Validator) validateStruct(s *state, val reflect.Value) error {
+	plan := v.plan(val.Type())
+	if plan.err != nil {
+		return plan.err
+	}
+
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		if !fp.inline {
+			s.path = append(s.path, segment{name: fp.name, index: -1})
+		}
+		err := v.validateValue(s, val.Field(fp.index), val, fp.rules, fp.sensitive)
+		if !fp.inline {
+			s.path = s.path[:len(s.path)-1]
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateValue(s *state, fv, parent reflect.Value, rules *ruleSet, sensitive bool) error {
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			if rules != nil && rules.required {
+				s.fail(ruleRequired, "", reflect.Value{}, sensitive)
+			}
+			return nil
+		}
+		fv = fv.Elem()
+	}
+
+	var dive *ruleSet
+	if rules != nil {
+		if isEmpty(fv) {
+			if rules.required {
+				s.fail(ruleRequired, "", reflect.Value{}, sensitive)
+				return nil
+			}
+			if rules.omitEmpty {
+				return nil
+			}
+		}
+		for i := range rules.calls {
+			call := &rules.calls[i]
+			if !call.fn(Field{Value: fv, Parent: parent, Param: call.param}) {
+				s.fail(call.name, call.param, fv, sensitive)
+				return nil
+			}
+		}
+		dive = rules.dive
+	}
+
+	switch fv.Kind() {
+	case reflect.Struct:
+		return v.validateStruct(s, fv)
+	case reflect.Slice, reflect.Array:
+		if dive == nil && !hasStructs(fv.Type().Elem()) {
+			return nil
+		}
+		for i := range fv.Len() {
+			s.path = append(s.path, segment{index: i})
+			err := v.validateValue(s, fv.Index(i), parent, dive, sensitive)
+			s.path = s.path[:len(s.path)-1]
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if dive == nil && !hasStructs(fv.Type().Elem()) {
+			return nil
+		}
+		iter := fv.MapRange()
+		for iter.Next() {
+			s.path = append(s.path, segment{key: iter.Key(), index: -1})
+			err := v.validateValue(s, iter.Value(), parent, dive, sensitive)
+			s.path = s.path[:len(s.path)-1]
+			if err != nil {
+				return err
+			}
+		}
+	default:
+	}
+	return nil
+}
+
+// hasStructs reports whether values of t can hold structs to validate.
+func hasStructs(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface:
+		return true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return hasStructs(t.Elem())
+	default:
+		return false
+	}
+}
+
+// isEmpty reports whether the value counts as missing for required and
+// omitempty: empty strings and collections, and zero values otherwise.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Invalid:
+		return true
+	default:
+		return v.IsZero()
+	}
+}
+
+// segment is an element of the path of the validated value, a field name,
+// a slice index or a map key.
+type segment struct {
+	key   reflect.Value
+	name  string
+	index int
+}
+
+// state is the state of a single Validate call.
+type state struct {
+	errs binder.ValidationErrors
+	path []segment
+}
+
+var statePool = sync.Pool{
+	New: func() any {
+		return &state{path: make([]segment, 0, 8)}
+	},
+}
+
+func acquireState() *state {
+	s, ok := statePool.Get().(*state)
+	if !ok {
+		panic(errors.New("validator: failed to type-assert to *state"))
+	}
+	return s
+}
+
+func releaseState(s *state) {
+	s.errs = nil
+	clear(s.path)
+	s.path = s.path[:0]
+	statePool.Put(s)
+}
+
+// fail records that the value at the current path failed the rule.
+func (s *state) fail(rule, param string, value reflect.Value, sensitive bool) {
+	fieldErr := binder.NewFieldError(s.pathString(), rule, nil)
+	fieldErr.Param = param
+	if value.IsValid() && value.CanInterface() {
+		fieldErr.Value = value.Interface()
+		if sensitive {
+			fieldErr.Value = redact.Mask
+		}
+	}
+	s.errs = append(s.errs, fieldErr)
+}
+
+// pathString returns the current path, e.g. "items.0.name", in the format
+// of the binders.
+func (s *state) pathString() string {
+	var b strings.Builder
+	for i, seg := range s.path {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		switch {
+		case seg.key.IsValid():
+			if seg.key.Kind() == reflect.String {
+				b.WriteString(seg.key.String())
+			} else {
+				fmt.Fprint(&b, seg.key.Interface())
+			}
+		case seg.index >= 0:
+			b.WriteString(strconv.Itoa(seg.index))
+		default:
+			b.WriteString(seg.name)
+		}
+	}
+	return b.String()
+}
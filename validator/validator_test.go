@@ -0,0 +1,343 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/binder"
+	"github.com/gofiber/fiber/v3/internal/redact"
+)
+
+type fieldFailure struct {
+	value any
+	field string
+	rule  string
+	param string
+}
+
+func requireFailures(t *testing.T, err error, expected ...fieldFailure) {
+	t.Helper()
+	var verrs binder.ValidationErrors
+	require.ErrorAs(t, err, &verrs)
+	require.Len(t, verrs, len(expected))
+	for i, want := range expected {
+		require.Equal(t, want.field, verrs[i].Field)
+		require.Equal(t, want.rule, verrs[i].Rule)
+		require.Equal(t, want.param, verrs[i].Param)
+		require.Equal(t, binder.MessageKeyPrefix+want.rule, verrs[i].MessageKey)
+		require.Equal(t, want.value, verrs[i].Value)
+	}
+}
+
+// go test -run Test_Validator_Rules
+func Test_Validator_Rules(t *testing.T) {
+	t.Parallel()
+
+	type User struct {
+		Name     string   `json:"name" validate:"required,min=3,max=8"`
+		Email    string   `json:"email" validate:"required,email"`
+		ID       string   `json:"id" validate:"omitempty,uuid"`
+		Role     string   `json:"role" validate:"oneof=admin user"`
+		Website  string   `json:"website" validate:"omitempty,url"`
+		Code     string   `json:"code" validate:"len=2,alpha"`
+		Nick     string   `json:"nick" validate:"omitempty,alphanum"`
+		Amount   string   `json:"amount" validate:"omitempty,numeric"`
+		Tags     []string `json:"tags" validate:"max=2"`
+		Score    float64  `json:"score" validate:"gte=0,lte=1"`
+		Age      int      `json:"age" validate:"gt=0,lt=150"`
+		Level    uint     `json:"level" validate:"ne=13"`
+		Priority int      `json:"priority" validate:"oneof=1 2 3"`
+		Nickname *string  `json:"nickname" validate:"omitempty,min=2"`
+	}
+
+	v := New()
+	valid := User{
+		Name:     "john",
+		Email:    "john@example.com",
+		ID:       "3f2504e0-4f89-11d3-9a0c-0305e82c3301",
+		Role:     "admin",
+		Website:  "https://gofiber.io",
+		Code:     "ab",
+		Nick:     "jö99",
+		Amount:   "-12.50",
+		Tags:     []string{"a"},
+		Score:    0.5,
+		Age:      30,
+		Level:    12,
+		Priority: 2,
+	}
+	require.NoError(t, v.Validate(valid))
+	require.NoError(t, v.Validate(&valid))
+	require.NoError(t, v.Validate((*User)(nil)))
+	require.NoError(t, v.Validate(42))
+
+	nick := "x"
+	invalid := User{
+		Name:     "jo",
+		Email:    "john@example",
+		ID:       "3f2504e0-4f89-11d3-9a0c-0305e82c330",
+		Role:     "root",
+		Website:  "gofiber.io",
+		Code:     "a1",
+		Nick:     "jo hn",
+		Amount:   "1.2.3",
+		Tags:     []string{"a", "b", "c"},
+		Score:    1.5,
+		Level:    13,
+		Priority: 4,
+		Nickname: &nick,
+	}
+	requireFailures(t, v.Validate(&invalid),
+		fieldFailure{field: "name", rule: "min", param: "3", value: "jo"},
+		fieldFailure{field: "email", rule: "email", value: "john@example"},
+		fieldFailure{field: "id", rule: "uuid", value: "3f2504e0-4f89-11d3-9a0c-0305e82c330"},
+		fieldFailure{field: "role", rule: "oneof", param: "admin user", value: "root"},
+		fieldFailure{field: "website", rule: "url", value: "gofiber.io"},
+		fieldFailure{field: "code", rule: "alpha", value: "a1"},
+		fieldFailure{field: "nick", rule: "alphanum", value: "jo hn"},
+		fieldFailure{field: "amount", rule: "numeric", value: "1.2.3"},
+		fieldFailure{field: "tags", rule: "max", param: "2", value: []string{"a", "b", "c"}},
+		fieldFailure{field: "score", rule: "lte", param: "1", value: 1.5},
+		fieldFailure{field: "age", rule: "gt", param: "0", value: 0},
+		fieldFailure{field: "level", rule: "ne", param: "13", value: uint(13)},
+		fieldFailure{field: "priority", rule: "oneof", param: "1 2 3", value: 4},
+		fieldFailure{field: "nickname", rule: "min", param: "2", value: "x"},
+	)
+
+	// required reports missing values without a value
+	requireFailures(t, v.Validate(User{Code: "ab", Role: "user", Age: 1, Priority: 1}),
+		fieldFailure{field: "name", rule: "required"},
+		fieldFailure{field: "email", rule: "required"},
+	)
+}
+
+// go test -run Test_Validator_Nested
+func Test_Validator_Nested(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string `json:"name" validate:"required"`
+	}
+	type Meta struct {
+		Source string `validate:"required"`
+	}
+	type Order struct {
+		Meta
+		Owner  *Item            `json:"owner"`
+		Labels map[string]Item  `json:"labels"`
+		Codes  []string         `json:"codes" validate:"min=1,dive,len=3"`
+		Limits map[string]int   `json:"limits" validate:"dive,gt=0"`
+		Items  []Item           `json:"items" validate:"required"`
+		Groups [][]*Item        `json:"groups"`
+		Extra  any              `json:"extra"`
+		Notes  map[int][]string `json:"notes" validate:"dive,dive,required"`
+	}
+
+	order := Order{
+		Owner:  &Item{},
+		Labels: map[string]Item{"first": {}},
+		Codes:  []string{"abc", "de"},
+		Limits: map[string]int{"cpu": 0},
+		Items:  []Item{{Name: "a"}, {}},
+		Groups: [][]*Item{{nil, {}}},
+		Extra:  &Item{},
+		Notes:  map[int][]string{7: {""}},
+	}
+	requireFailures(t, New().Validate(order),
+		fieldFailure{field: "Source", rule: "required"},
+		fieldFailure{field: "owner.name", rule: "required"},
+		fieldFailure{field: "labels.first.name", rule: "required"},
+		fieldFailure{field: "codes.1", rule: "len", param: "3", value: "de"},
+		fieldFailure{field: "limits.cpu", rule: "gt", param: "0", value: 0},
+		fieldFailure{field: "items.1.name", rule: "required"},
+		fieldFailure{field: "groups.0.1.name", rule: "required"},
+		fieldFailure{field: "extra.name", rule: "required"},
+		fieldFailure{field: "notes.7.0", rule: "required"},
+	)
+
+	// name tags are configurable
+	type Query struct {
+		Page int `query:"page" json:"p" validate:"gt=0"`
+	}
+	requireFailures(t, New(Config{NameTags: []string{"query"}}).Validate(Query{}),
+		fieldFailure{field: "page", rule: "gt", param: "0", value: 0},
+	)
+	requireFailures(t, New(Config{Tag: "check"}).Validate(struct {
+		Page int `check:"gt=0"`
+	}{}),
+		fieldFailure{field: "Page", rule: "gt", param: "0", value: 0},
+	)
+}
+
+// go test -run Test_Validator_CrossField
+func Test_Validator_CrossField(t *testing.T) {
+	t.Parallel()
+
+	type Signup struct {
+		Password string  `json:"password" validate:"required,min=8" sensitive:"true"`
+		Confirm  string  `json:"confirm" validate:"eqfield=Password" sensitive:"true"`
+		Username string  `json:"username" validate:"nefield=Password"`
+		Backup   *string `json:"backup" validate:"omitempty,eqfield=Password"`
+	}
+
+	v := New()
+	require.NoError(t, v.Validate(Signup{Password: "secret123", Confirm: "secret123", Username: "john"}))
+
+	backup := "other"
+	requireFailures(t, v.Validate(Signup{Password: "secret123", Confirm: "secret", Username: "secret123", Backup: &backup}),
+		fieldFailure{field: "confirm", rule: "eqfield", param: "Password", value: redact.Mask},
+		fieldFailure{field: "username", rule: "nefield", param: "Password", value: "secret123"},
+		fieldFailure{field: "backup", rule: "eqfield", param: "Password", value: "other"},
+	)
+}
+
+// go test -run Test_Validator_RegisterRule
+func Test_Validator_RegisterRule(t *testing.T) {
+	t.Parallel()
+
+	type Pair struct {
+		N    int    `json:"n" validate:"even"`
+		Slug string `json:"slug" validate:"prefix=fiber-"`
+	}
+
+	v := New()
+	require.ErrorIs(t, v.Validate(Pair{}), ErrInvalidRules)
+
+	v.RegisterRule("even", func(f Field) bool {
+		return f.Value.Int()%2 == 0
+	})
+	v.RegisterRule("prefix", func(f Field) bool {
+		return strings.HasPrefix(f.Value.String(), f.Param)
+	})
+	require.NoError(t, v.Validate(Pair{N: 2, Slug: "fiber-v3"}))
+	requireFailures(t, v.Validate(Pair{N: 3, Slug: "v3"}),
+		fieldFailure{field: "n", rule: "even", value: 3},
+		fieldFailure{field: "slug", rule: "prefix", param: "fiber-", value: "v3"},
+	)
+
+	// built-in rules can be replaced
+	v.RegisterRule("email", func(Field) bool { return true })
+	require.NoError(t, v.Validate(struct {
+		Email string `validate:"email"`
+	}{Email: "nope"}))
+
+	// validators don't share rules
+	require.ErrorIs(t, New().Validate(Pair{}), ErrInvalidRules)
+
+	require.Panics(t, func() { v.RegisterRule("", func(Field) bool { return true }) })
+	require.Panics(t, func() { v.RegisterRule("a,b", func(Field) bool { return true }) })
+	require.Panics(t, func() { v.RegisterRule("required", func(Field) bool { return true }) })
+	require.Panics(t, func() { v.RegisterRule("odd", nil) })
+}
+
+// go test -run Test_Validator_InvalidRules
+func Test_Validator_InvalidRules(t *testing.T) {
+	t.Parallel()
+
+	v := New()
+	for _, value := range []any{
+		struct {
+			A string `validate:"unknown"`
+		}{},
+		struct {
+			A string `validate:"min=three"`
+		}{},
+		struct {
+			A bool `validate:"max=1"`
+		}{},
+		struct {
+			A int `validate:"email"`
+		}{},
+		struct {
+			A int `validate:"dive,required"`
+		}{},
+		struct {
+			A int `validate:"oneof=a b"`
+		}{},
+		struct {
+			A string `validate:"eqfield=B"`
+		}{},
+	} {
+		err := v.Validate(value)
+		require.ErrorIs(t, err, ErrInvalidRules)
+		require.ErrorContains(t, err, ".A: ")
+	}
+
+	// nested structs are checked when validated
+	type Outer struct {
+		Inner struct {
+			A string `validate:"unknown"`
+		}
+	}
+	require.ErrorIs(t, v.Validate(Outer{}), ErrInvalidRules)
+}
+
+// go test -run Test_Validator_Allocations
+func Test_Validator_Allocations(t *testing.T) {
+	type Item struct {
+		Name string `json:"name" validate:"required,min=2"`
+	}
+	type Order struct {
+		Email string `json:"email" validate:"required,email"`
+		Items []Item `json:"items" validate:"required,dive"`
+		Total int    `json:"total" validate:"gt=0"`
+	}
+
+	v := New()
+	order := &Order{Email: "john@example.com", Items: []Item{{Name: "book"}, {Name: "pen"}}, Total: 3}
+	require.NoError(t, v.Validate(order))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = v.Validate(order) //nolint:errcheck // only allocations are measured
+	})
+	require.Zero(t, allocs)
+}
+
+// go test -run Test_Validator_Bind
+func Test_Validator_Bind(t *testing.T) {
+	t.Parallel()
+
+	type Query struct {
+		Name string `query:"name" validate:"required,min=3"`
+		Page int    `query:"page" validate:"gte=1"`
+	}
+
+	app := fiber.New(fiber.Config{StructValidator: New()})
+	app.Get("/", func(c fiber.Ctx) error {
+		q := new(Query)
+		if err := c.Bind().Query(q); err != nil {
+			var verrs fiber.ValidationErrors
+			if !errors.As(err, &verrs) {
+				return err
+			}
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(verrs)
+		}
+		return c.SendString(q.Name)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/?name=john&page=1", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/?name=jo&page=0", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var body []map[string]any
+	require.NoError(t, json.Unmarshal(raw, &body))
+	require.Len(t, body, 2)
+	require.Equal(t, "name", body[0]["field"])
+	require.Equal(t, "min", body[0]["rule"])
+	require.Equal(t, "3", body[0]["param"])
+	require.Equal(t, "page", body[1]["field"])
+	require.Equal(t, "gte", body[1]["rule"])
+}
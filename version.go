@@ -0,0 +1,678 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 🤖 GitHub Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+package fiber
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/utils/v2"
+)
+
+// VersionSource selects where the API version of a request is read from.
+type VersionSource uint8
+
+const (
+	// VersionSourceHeader reads the version from the VersioningConfig.Header
+	// request header, e.g. "X-API-Version: 2".
+	VersionSourceHeader VersionSource = iota
+	// VersionSourceMediaType reads the version from the
+	// VersioningConfig.MediaTypeParam parameter of the Accept header, e.g.
+	// "Accept: application/vnd.acme+json;version=2".
+	VersionSourceMediaType
+	// VersionSourcePath reads the version from a path segment added in front
+	// of the routes of a version, e.g. "/v2/users".
+	VersionSourcePath
+)
+
+// versionParam is the route parameter holding the version with VersionSourcePath.
+const versionParam = "version"
+
+// VersioningConfig configures how the routes registered with Router.Version
+// select the API version of a request.
+type VersioningConfig struct {
+	// Request header carrying the version with VersionSourceHeader.
+	//
+	// Default: "X-API-Version"
+	Header string `json:"header"`
+
+	// Accept header parameter carrying the version with VersionSourceMediaType.
+	//
+	// Default: "version"
+	MediaTypeParam string `json:"media_type_param"`
+
+	// Text in front of the version in the path segment with VersionSourcePath,
+	// the routes of version "2" are registered under "/v:version" and match
+	// "/v2".
+	//
+	// Default: "v"
+	PathPrefix string `json:"path_prefix"`
+
+	// Version of requests that do not specify one. When empty, they are
+	// served by the latest version of the route.
+	//
+	// Default: ""
+	Default string `json:"default"`
+
+	// Where the version of a request is read from.
+	//
+	// Default: VersionSourceHeader
+	Source VersionSource `json:"source"`
+}
+
+// VersionConfig holds the lifecycle of a version registered with Router.Version.
+type VersionConfig struct {
+	// Date from which the version is deprecated. When set, responses of the
+	// version carry it in the Deprecation header (RFC 9745).
+	//
+	// Optional. Default: not deprecated
+	Deprecation time.Time
+
+	// Date from which the version will not be served anymore. When set,
+	// responses of the version carry it in the Sunset header (RFC 8594).
+	//
+	// Optional. Default: no sunset
+	Sunset time.Time
+
+	// URL of a document describing the deprecation, sent in a Link header
+	// with the "deprecation" relation type.
+	//
+	// Optional. Default: ""
+	DeprecationLink string
+}
+
+// apiVersion is a parsed version, e.g. [2 1] for "2.1".
+type apiVersion []int
+
+// parseAPIVersion parses dot-separated numbers with an optional leading "v",
+// such as "2", "v2" or "2.1".
+func parseAPIVersion(raw string) (apiVersion, bool) {
+	raw = utils.TrimSpace(raw)
+	if raw != "" && (raw[0] == 'v' || raw[0] == 'V') {
+		raw = raw[1:]
+	}
+	if raw == "" {
+		return nil, false
+	}
+	var version apiVersion
+	for part := range strings.SplitSeq(raw, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part[0] == '+' {
+			return nil, false
+		}
+		version = append(version, n)
+	}
+	return version, true
+}
+
+// compare compares the versions, missing parts count as zero so "2" equals "2.0".
+func (v apiVersion) compare(o apiVersion) int {
+	for i := range max(len(v), len(o)) {
+		var a, b int
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(o) {
+			b = o[i]
+		}
+		if c := cmp.Compare(a, b); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// versionKey identifies the routes that are versions of each other.
+type versionKey struct {
+	method string
+	path   string
+	use    bool
+}
+
+// routeVersion is a version registered for a route.
+type routeVersion struct {
+	name   string
+	parsed apiVersion
+}
+
+// routeVersions maps routes to their versions, sorted from oldest to latest.
+type routeVersions map[versionKey][]routeVersion
+
+// newRouteVersions collects the versions of the versioned routes in stack,
+// nil if there are none.
+func newRouteVersions(stack [][]*Route) routeVersions {
+	var versions routeVersions
+	for _, routes := range stack {
+		for _, route := range routes {
+			if route.Version == "" {
+				continue
+			}
+			parsed, ok := parseAPIVersion(route.Version)
+			if !ok {
+				continue
+			}
+			if versions == nil {
+				versions = make(routeVersions)
+			}
+			key := versionKey{method: route.Method, path: route.path, use: route.use}
+			list := versions[key]
+			i, found := slices.BinarySearchFunc(list, parsed, func(rv routeVersion, target apiVersion) int {
+				return rv.parsed.compare(target)
+			})
+			if !found {
+				versions[key] = slices.Insert(list, i, routeVersion{name: route.Version, parsed: parsed})
+			}
+		}
+	}
+	return versions
+}
+
+// resolve returns the version serving the requested version: the latest
+// version not newer than requested, or the latest version if requested is
+// nil. It returns "" when all versions are newer.
+func (vs routeVersions) resolve(key versionKey, requested apiVersion) string {
+	list := vs[key]
+	for i := len(list) - 1; i >= 0; i-- {
+		if requested == nil || list[i].parsed.compare(requested) <= 0 {
+			return list[i].name
+		}
+	}
+	return ""
+}
+
+// versionLocalsKeyType is an unexported type used as the Locals key for the
+// version state of a request, preventing collisions with user or middleware keys.
+type versionLocalsKeyType struct{}
+
+// versionLocalsKey is the typed key used in c.Locals() to store the versionState.
+var versionLocalsKey = versionLocalsKeyType{}
+
+// versionState caches the version of a request.
+type versionState struct {
+	requested apiVersion // nil for the latest version
+	served    string     // version of the route handling the request
+	valid     bool       // false if the request specified an invalid version
+}
+
+// APIVersion returns the version of the versioned route handling the request,
+// which may be lower than the requested version when the route does not exist
+// in it. It returns "" for routes registered without Router.Version.
+//
+//	app.Version("2").Get("/users", func(c fiber.Ctx) error {
+//	    return c.SendString("API " + fiber.APIVersion(c)) // API 2
+//	})
+func APIVersion(c Ctx) string {
+	if state, ok := c.Locals(versionLocalsKey).(*versionState); ok {
+		return state.served
+	}
+	return ""
+}
+
+// requestVersion returns the version state of the request handled by c,
+// reading the requested version on first use.
+func (app *App) requestVersion(c Ctx) *versionState {
+	if state, ok := c.Locals(versionLocalsKey).(*versionState); ok {
+		return state
+	}
+
+	cfg := app.config.Versioning
+	var raw string
+	switch cfg.Source {
+	case VersionSourcePath:
+		raw = c.Params(versionParam)
+	case VersionSourceMediaType:
+		raw = mediaTypeParam(c.Get(HeaderAccept), cfg.MediaTypeParam)
+		c.Vary(HeaderAccept)
+	default:
+		raw = c.Get(cfg.Header)
+		c.Vary(cfg.Header)
+	}
+	if raw == "" {
+		raw = cfg.Default
+	}
+
+	state := &versionState{valid: true}
+	if raw != "" {
+		state.requested, state.valid = parseAPIVersion(raw)
+	}
+	c.Locals(versionLocalsKey, state)
+	return state
+}
+
+// mediaTypeParam returns the value of the first param parameter in the
+// media ranges of an Accept header.
+func mediaTypeParam(accept, param string) string {
+	for mediaRange := range strings.SplitSeq(accept, ",") {
+		_, params, found := strings.Cut(mediaRange, ";")
+		if !found {
+			continue
+		}
+		for p := range strings.SplitSeq(params, ";") {
+			key, value, ok := strings.Cut(p, "=")
+			if ok && utils.EqualFold(utils.TrimSpace(key), param) {
+				return strings.Trim(utils.TrimSpace(value), `"`)
+			}
+		}
+	}
+	return ""
+}
+
+// requestRouteTable returns the route table the request handled by c is
+// matched against.
+func requestRouteTable(c Ctx) *routeTable {
+	if cc, ok := c.(CustomCtx); ok {
+		if table := cc.getRouteTable(); table != nil {
+			return table
+		}
+	}
+	return c.App().routes.Load()
+}
+
+// versionRouter implements [Router] for the routes of an API version.
+// Every version registers its own routes, a request runs the handlers of the
+// latest version of the route that is not newer than the requested version
+// and passes the handlers of the other versions.
+//
+// Like domain routing, the version is checked by wrapping the handlers, so
+// routes registered without a version are not affected.
+type versionRouter struct {
+	app     *App
+	group   *Group // non-nil when created from a Group or with VersionSourcePath
+	version string
+	config  VersionConfig
+}
+
+// Verify versionRouter implements Router at compile time.
+var _ Router = (*versionRouter)(nil)
+
+// normalizeVersion returns version without a leading "v" and leading zeros.
+func normalizeVersion(version string) string {
+	parsed, ok := parseAPIVersion(version)
+	if !ok {
+		panic(fmt.Sprintf("version: invalid version %q, expected numbers separated by dots such as \"2\" or \"2.1\"", version))
+	}
+	parts := make([]string, len(parsed))
+	for i, n := range parsed {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// newVersionRouter creates the router of version for the routes of group,
+// which is nil for the app.
+func newVersionRouter(app *App, group *Group, version string, config []VersionConfig) *versionRouter {
+	v := &versionRouter{
+		app:     app,
+		group:   group,
+		version: normalizeVersion(version),
+	}
+	if len(config) > 0 {
+		v.config = config[0]
+	}
+
+	if app.config.Versioning.Source == VersionSourcePath {
+		prefix := "/" + app.config.Versioning.PathPrefix + ":" + versionParam
+		newGrp := &Group{Prefix: prefix, app: app}
+		if group != nil {
+			newGrp.Prefix = getGroupPath(group.Prefix, prefix)
+			newGrp.parentGroup = group
+			newGrp.name = group.name
+			newGrp.config = group.config
+		}
+		v.group = newGrp
+	}
+
+	return v
+}
+
+// serves reports whether the version of the router serves the request and
+// sets the response headers of the version when an endpoint is reached.
+func (v *versionRouter) serves(c Ctx) bool {
+	state := v.app.requestVersion(c)
+	if !state.valid {
+		return false
+	}
+
+	route := c.Route()
+	key := versionKey{method: route.Method, path: route.path, use: route.use}
+	if requestRouteTable(c).versions.resolve(key, state.requested) != v.version {
+		return false
+	}
+
+	state.served = v.version
+	if !route.use {
+		v.setHeaders(c)
+	}
+	return true
+}
+
+// setHeaders sets the Deprecation, Sunset and Link headers of the version.
+func (v *versionRouter) setHeaders(c Ctx) {
+	if !v.config.Deprecation.IsZero() {
+		c.Set(HeaderDeprecation, "@"+strconv.FormatInt(v.config.Deprecation.Unix(), 10))
+		if v.config.DeprecationLink != "" {
+			c.Append(HeaderLink, "<"+v.config.DeprecationLink+`>; rel="deprecation"`)
+		}
+	}
+	if !v.config.Sunset.IsZero() {
+		c.Set(HeaderSunset, v.config.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// wrapHandlers wraps every handler in the slice with the version check.
+func (v *versionRouter) wrapHandlers(handlers []Handler) []Handler {
+	result := make([]Handler, len(handlers))
+	for i, h := range handlers {
+		result[i] = func(c Ctx) error {
+			if !v.serves(c) {
+				return c.Next()
+			}
+			return h(c)
+		}
+	}
+	return result
+}
+
+// routeArgs returns the handlers in args with the options of a route of the
+// version. Versioned routes are skipped when looking up the route settings, so
+// it panics if args or the group of the router carry a RouteConfig.
+func (v *versionRouter) routeArgs(args []any) ([]any, routeOptions) {
+	args, opts := routeArgs(args)
+	if opts.config != (RouteConfig{}) || (v.group != nil && v.group.config != (RouteConfig{})) {
+		panic("version: RouteConfig is not supported for versioned routes")
+	}
+	opts.version = v.version
+	return args, opts
+}
+
+// registerPath returns the full path for registration, taking the group
+// prefix into account.
+func (v *versionRouter) registerPath(path string) string {
+	if v.group != nil {
+		return getGroupPath(v.group.Prefix, path)
+	}
+	return path
+}
+
+// markGroup marks the group so Name() can distinguish between
+// group-name-prefix calls (before routes) and route-name calls (after routes).
+func (v *versionRouter) markGroup() {
+	if v.group != nil && !v.group.hasAnyRoute {
+		v.group.hasAnyRoute = true
+	}
+}
+
+// Use registers a middleware route for the version. Mounting sub-apps is not
+// supported.
+func (v *versionRouter) Use(args ...any) Router {
+	var prefix string
+	var prefixes []string
+	var handlers []Handler
+
+	for i := range args {
+		switch arg := args[i].(type) {
+		case string:
+			prefix = arg
+		case []string:
+			prefixes = arg
+		case *App:
+			panic("version: mounting apps is not supported")
+		case RouteConfig, *RouteConfig:
+			panic(useRouteConfigPanic)
+		default:
+			handler, ok := toFiberHandler(arg)
+			if !ok {
+				panic(fmt.Sprintf("use: invalid handler %T", arg))
+			}
+			handlers = append(handlers, handler)
+		}
+	}
+
+	if len(prefixes) == 0 {
+		prefixes = append(prefixes, prefix)
+	}
+
+	for _, prefix := range prefixes {
+		opts := routeOptions{version: v.version}
+		v.app.registerRoute([]string{methodUse}, v.registerPath(prefix), v.group, opts, v.wrapHandlers(handlers)...)
+	}
+	v.markGroup()
+
+	return v
+}
+
+// Get registers a route for GET methods of the version.
+func (v *versionRouter) Get(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodGet}, path, handler, handlers...)
+}
+
+// Head registers a route for HEAD methods of the version.
+func (v *versionRouter) Head(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodHead}, path, handler, handlers...)
+}
+
+// Post registers a route for POST methods of the version.
+func (v *versionRouter) Post(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodPost}, path, handler, handlers...)
+}
+
+// Put registers a route for PUT methods of the version.
+func (v *versionRouter) Put(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodPut}, path, handler, handlers...)
+}
+
+// Delete registers a route for DELETE methods of the version.
+func (v *versionRouter) Delete(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodDelete}, path, handler, handlers...)
+}
+
+// Connect registers a route for CONNECT methods of the version.
+func (v *versionRouter) Connect(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodConnect}, path, handler, handlers...)
+}
+
+// Options registers a route for OPTIONS methods of the version.
+func (v *versionRouter) Options(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodOptions}, path, handler, handlers...)
+}
+
+// Trace registers a route for TRACE methods of the version.
+func (v *versionRouter) Trace(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodTrace}, path, handler, handlers...)
+}
+
+// Patch registers a route for PATCH methods of the version.
+func (v *versionRouter) Patch(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodPatch}, path, handler, handlers...)
+}
+
+// Query registers a route for QUERY methods of the version.
+func (v *versionRouter) Query(path string, handler any, handlers ...any) Router {
+	return v.Add([]string{MethodQuery}, path, handler, handlers...)
+}
+
+// Add allows you to specify multiple HTTP methods to register a route of the version.
+func (v *versionRouter) Add(methods []string, path string, handler any, handlers ...any) Router {
+	v.add(methods, v.registerPath(path), append([]any{handler}, handlers...))
+	v.markGroup()
+	return v
+}
+
+// add registers the handlers in args for the full path.
+func (v *versionRouter) add(methods []string, path string, args []any) {
+	args, opts := v.routeArgs(args)
+	converted := collectHandlers("version", args...)
+	v.app.registerRoute(methods, path, v.group, opts, v.wrapHandlers(converted)...)
+}
+
+// All registers the handler on all HTTP methods of the version.
+func (v *versionRouter) All(path string, handler any, handlers ...any) Router {
+	return v.Add(v.app.config.RequestMethods, path, handler, handlers...)
+}
+
+// Group creates a new sub-router with a common prefix within the version.
+func (v *versionRouter) Group(prefix string, handlers ...any) Router {
+	fullPrefix := v.registerPath(prefix)
+
+	handlers, _ = v.routeArgs(handlers)
+	if len(handlers) > 0 {
+		converted := collectHandlers("version", handlers...)
+		opts := routeOptions{version: v.version}
+		v.app.registerRoute([]string{methodUse}, fullPrefix, v.group, opts, v.wrapHandlers(converted)...)
+	}
+
+	newGrp := &Group{Prefix: fullPrefix, app: v.app, parentGroup: v.group}
+	if v.group != nil {
+		newGrp.config = v.group.config
+	}
+	if err := v.app.hooks.executeOnGroupHooks(*newGrp); err != nil {
+		panic(err)
+	}
+
+	return &versionRouter{
+		app:     v.app,
+		group:   newGrp,
+		version: v.version,
+		config:  v.config,
+	}
+}
+
+// Domain is not supported for versioned routes and panics.
+func (*versionRouter) Domain(_ string) Router {
+	panic("version: domain routing is not supported for versioned routes")
+}
+
+// Version creates a router for another version with the prefix of this router.
+func (v *versionRouter) Version(version string, config ...VersionConfig) Router {
+	other := &versionRouter{
+		app:     v.app,
+		group:   v.group,
+		version: normalizeVersion(version),
+	}
+	if len(config) > 0 {
+		other.config = config[0]
+	}
+	return other
+}
+
+// RouteChain creates a Registering instance for the version.
+func (v *versionRouter) RouteChain(path string) Register {
+	return &versionRegistering{
+		version: v,
+		path:    v.registerPath(path),
+	}
+}
+
+// Route defines routes with a common prefix inside the supplied function,
+// scoped to the version.
+func (v *versionRouter) Route(prefix string, fn func(router Router), name ...string) Router {
+	if fn == nil {
+		panic("route handler 'fn' cannot be nil")
+	}
+
+	group := v.Group(prefix)
+	if len(name) > 0 {
+		group.Name(name[0])
+	}
+
+	fn(group)
+
+	return group
+}
+
+// Name assigns a name to the most recently registered route.
+func (v *versionRouter) Name(name string) Router {
+	if v.group != nil {
+		v.group.Name(name)
+	} else {
+		v.app.Name(name)
+	}
+	return v
+}
+
+// Meta attaches a metadata value to the most recently registered route.
+func (v *versionRouter) Meta(key string, value any) Router {
+	if v.group != nil {
+		v.group.Meta(key, value)
+	} else {
+		v.app.Meta(key, value)
+	}
+	return v
+}
+
+// versionRegistering provides route registration helpers for a specific path
+// of a version, implementing the [Register] interface.
+type versionRegistering struct {
+	version *versionRouter
+	path    string
+}
+
+// Verify versionRegistering implements Register at compile time.
+var _ Register = (*versionRegistering)(nil)
+
+func (r *versionRegistering) All(handler any, handlers ...any) Register {
+	args, _ := r.version.routeArgs(append([]any{handler}, handlers...))
+	converted := collectHandlers("version", args...)
+	opts := routeOptions{version: r.version.version}
+	r.version.app.registerRoute([]string{methodUse}, r.path, r.version.group, opts, r.version.wrapHandlers(converted)...)
+	return r
+}
+
+func (r *versionRegistering) Get(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodGet}, handler, handlers...)
+}
+
+func (r *versionRegistering) Head(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodHead}, handler, handlers...)
+}
+
+func (r *versionRegistering) Post(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodPost}, handler, handlers...)
+}
+
+func (r *versionRegistering) Put(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodPut}, handler, handlers...)
+}
+
+func (r *versionRegistering) Delete(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodDelete}, handler, handlers...)
+}
+
+func (r *versionRegistering) Connect(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodConnect}, handler, handlers...)
+}
+
+func (r *versionRegistering) Options(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodOptions}, handler, handlers...)
+}
+
+func (r *versionRegistering) Trace(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodTrace}, handler, handlers...)
+}
+
+func (r *versionRegistering) Patch(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodPatch}, handler, handlers...)
+}
+
+func (r *versionRegistering) Query(handler any, handlers ...any) Register {
+	return r.Add([]string{MethodQuery}, handler, handlers...)
+}
+
+func (r *versionRegistering) Add(methods []string, handler any, handlers ...any) Register {
+	r.version.add(methods, r.path, append([]any{handler}, handlers...))
+	return r
+}
+
+func (r *versionRegistering) RouteChain(path string) Register {
+	return &versionRegistering{
+		version: r.version,
+		path:    getGroupPath(r.path, path),
+	}
+}
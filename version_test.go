@@ -0,0 +1,241 @@
+package fiber
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func versionRequest(t *testing.T, app *App, method, target string, header ...string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, http.NoBody)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func versionHandler(name string) Handler {
+	return func(c Ctx) error {
+		return c.SendString(name + "@" + APIVersion(c))
+	}
+}
+
+// go test -run Test_Version_Header
+func Test_Version_Header(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	v1 := app.Version("1")
+	v1.Get("/users", versionHandler("users"))
+	v1.Get("/orders", versionHandler("orders"))
+	app.Version("v2").Get("/users", versionHandler("users"))
+	app.Version("3.1").Get("/users", versionHandler("users"))
+	app.Get("/health", versionHandler("health"))
+
+	testCases := []struct {
+		path     string
+		version  string
+		expected string
+		status   int
+	}{
+		{path: "/users", version: "1", expected: "users@1", status: StatusOK},
+		{path: "/users", version: "2", expected: "users@2", status: StatusOK},
+		{path: "/users", version: "v2", expected: "users@2", status: StatusOK},
+		{path: "/users", version: "3", expected: "users@2", status: StatusOK},
+		{path: "/users", version: "3.1", expected: "users@3.1", status: StatusOK},
+		{path: "/users", version: "4", expected: "users@3.1", status: StatusOK},
+		{path: "/users", version: "", expected: "users@3.1", status: StatusOK},
+		{path: "/users", version: "0", status: StatusNotFound},
+		{path: "/users", version: "latest", status: StatusNotFound},
+		{path: "/orders", version: "3", expected: "orders@1", status: StatusOK},
+		{path: "/health", version: "2", expected: "health@", status: StatusOK},
+	}
+	for _, tc := range testCases {
+		resp, body := versionRequest(t, app, MethodGet, tc.path, "X-API-Version", tc.version)
+		require.Equal(t, tc.status, resp.StatusCode, "%s version %q", tc.path, tc.version)
+		if tc.status == StatusOK {
+			require.Equal(t, tc.expected, body, "%s version %q", tc.path, tc.version)
+		}
+	}
+
+	// automatic HEAD routes are versioned like their GET routes
+	resp, _ := versionRequest(t, app, MethodHead, "/users", "X-API-Version", "1")
+	require.Equal(t, StatusOK, resp.StatusCode)
+	require.Equal(t, "X-API-Version", resp.Header.Get(HeaderVary))
+	require.Empty(t, app.RouteIssues())
+}
+
+// go test -run Test_Version_MediaType
+func Test_Version_MediaType(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{Versioning: VersioningConfig{
+		Source:  VersionSourceMediaType,
+		Default: "1",
+	}})
+	app.Version("1").Get("/users", versionHandler("users"))
+	app.Version("2").Get("/users", versionHandler("users"))
+
+	testCases := map[string]string{
+		"application/vnd.acme+json;version=2":               "users@2",
+		"text/html, application/vnd.acme+json; version=2":   "users@2",
+		`application/vnd.acme+json;q=0.9;version="1"`:       "users@1",
+		"application/json":                                  "users@1",
+		"application/vnd.acme+json;charset=utf-8;VERSION=5": "users@2",
+	}
+	for accept, expected := range testCases {
+		resp, body := versionRequest(t, app, MethodGet, "/users", HeaderAccept, accept)
+		require.Equal(t, StatusOK, resp.StatusCode, accept)
+		require.Equal(t, expected, body, accept)
+		require.Equal(t, HeaderAccept, resp.Header.Get(HeaderVary))
+	}
+}
+
+// go test -run Test_Version_Path
+func Test_Version_Path(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{Versioning: VersioningConfig{Source: VersionSourcePath}})
+	api := app.Group("/api")
+	v1 := api.Version("1")
+	v1.Get("/users", versionHandler("users")).Name("users.v1")
+	v1.Get("/orders", versionHandler("orders"))
+	v1.Use(func(c Ctx) error {
+		c.Set("X-Middleware", APIVersion(c))
+		return c.Next()
+	})
+	v2 := api.Version("2")
+	v2.Get("/users", versionHandler("users")).Name("users.v2")
+	v2.Group("/admin").Get("/stats", versionHandler("stats"))
+
+	testCases := []struct {
+		path     string
+		expected string
+		status   int
+	}{
+		{path: "/api/v1/users", expected: "users@1", status: StatusOK},
+		{path: "/api/v2/users", expected: "users@2", status: StatusOK},
+		{path: "/api/v5/users", expected: "users@2", status: StatusOK},
+		{path: "/api/v3/orders", expected: "orders@1", status: StatusOK},
+		{path: "/api/v2/admin/stats", expected: "stats@2", status: StatusOK},
+		{path: "/api/v1/admin/stats", status: StatusNotFound},
+		{path: "/api/vx/users", status: StatusNotFound},
+		{path: "/api/users", status: StatusNotFound},
+	}
+	for _, tc := range testCases {
+		resp, body := versionRequest(t, app, MethodGet, tc.path)
+		require.Equal(t, tc.status, resp.StatusCode, tc.path)
+		if tc.status == StatusOK {
+			require.Equal(t, tc.expected, body, tc.path)
+		}
+	}
+
+	route := app.GetRoute("users.v1")
+	require.Equal(t, "/api/v:version/users", route.Path)
+	require.Equal(t, "1", route.Version)
+	require.Equal(t, "2", app.GetRoute("users.v2").Version)
+}
+
+// go test -run Test_Version_Deprecation
+func Test_Version_Deprecation(t *testing.T) {
+	t.Parallel()
+
+	deprecation := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+	app := New()
+	app.Version("1", VersionConfig{
+		Deprecation:     deprecation,
+		Sunset:          sunset,
+		DeprecationLink: "https://example.com/deprecation",
+	}).Get("/users", versionHandler("users"))
+	app.Version("2").Get("/users", versionHandler("users"))
+
+	resp, body := versionRequest(t, app, MethodGet, "/users", "X-API-Version", "1")
+	require.Equal(t, "users@1", body)
+	require.Equal(t, "@1767225600", resp.Header.Get(HeaderDeprecation))
+	require.Equal(t, "Thu, 31 Dec 2026 23:59:59 GMT", resp.Header.Get(HeaderSunset))
+	require.Equal(t, `<https://example.com/deprecation>; rel="deprecation"`, resp.Header.Get(HeaderLink))
+
+	resp, body = versionRequest(t, app, MethodGet, "/users", "X-API-Version", "2")
+	require.Equal(t, "users@2", body)
+	require.Empty(t, resp.Header.Get(HeaderDeprecation))
+	require.Empty(t, resp.Header.Get(HeaderSunset))
+}
+
+// go test -run Test_Version_GetRoutes
+func Test_Version_GetRoutes(t *testing.T) {
+	t.Parallel()
+
+	app := New(Config{DisableHeadAutoRegister: true})
+	app.Get("/health", testEmptyHandler)
+	app.Version("1").Get("/users", testEmptyHandler)
+	app.Version("2").Get("/users", testEmptyHandler)
+
+	versions := make(map[string]string)
+	for _, route := range app.GetRoutes(true) {
+		versions[route.Version] = route.Path
+	}
+	require.Equal(t, map[string]string{"": "/health", "1": "/users", "2": "/users"}, versions)
+
+	// versions of a route are not reported as duplicates
+	app.Get("/users", testEmptyHandler)
+	app.Version("1").Get("/users", testEmptyHandler)
+	issues := analyzedIssues(t, app)
+	require.Len(t, issues, 1)
+	require.Equal(t, RouteIssueDuplicate, issues[0].Kind)
+	require.Equal(t, "/users", issues[0].By)
+}
+
+// go test -run Test_Version_Invalid
+func Test_Version_Invalid(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	require.Panics(t, func() { app.Version("latest") })
+	require.Panics(t, func() { app.Version("1.x") })
+	require.Panics(t, func() { app.Version("1").Use("/sub", New()) })
+	require.Panics(t, func() { app.Version("1").Domain("example.com") })
+	require.Panics(t, func() { app.Domain("example.com").Version("1") })
+	require.Panics(t, func() { New(Config{Versioning: VersioningConfig{Default: "v"}}) })
+}
+
+// go test -run Test_Version_RouteConfig
+func Test_Version_RouteConfig(t *testing.T) {
+	t.Parallel()
+
+	app := New()
+	const msg = "version: RouteConfig is not supported for versioned routes"
+	v1 := app.Version("1")
+	require.PanicsWithValue(t, msg, func() {
+		v1.Get("/users", versionHandler("users"), RouteConfig{Timeout: time.Millisecond})
+	})
+	require.PanicsWithValue(t, msg, func() {
+		v1.Group("/admin", &RouteConfig{Immutable: true})
+	})
+	require.PanicsWithValue(t, msg, func() {
+		v1.RouteChain("/orders").All(versionHandler("orders"), RouteConfig{Immutable: true})
+	})
+	require.PanicsWithValue(t, "use: RouteConfig is not supported by middleware, pass it to Group or the routes", func() {
+		v1.Use(RouteConfig{Immutable: true})
+	})
+
+	// nor inherited from a group
+	api := app.Group("/api", RouteConfig{BodyLimit: 32})
+	require.PanicsWithValue(t, msg, func() {
+		api.Version("1").Post("/upload", versionHandler("upload"))
+	})
+	require.PanicsWithValue(t, msg, func() {
+		api.Version("1").Group("/admin").Get("/stats", versionHandler("stats"))
+	})
+	require.False(t, app.hasRouteConfigs())
+}